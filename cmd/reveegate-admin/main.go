@@ -0,0 +1,288 @@
+// Command reveegate-admin manages admin accounts from the command line.
+// It is used to bootstrap the first owner and to recover locked-out accounts.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/reveegate/reveegate/internal/config"
+	"github.com/reveegate/reveegate/internal/http/middleware"
+	postgresRepo "github.com/reveegate/reveegate/internal/repository/postgres"
+	redisRepo "github.com/reveegate/reveegate/internal/repository/redis"
+	"github.com/reveegate/reveegate/internal/service"
+)
+
+const usage = `Usage: reveegate-admin <command> [flags]
+
+Commands:
+  create-owner    Create the first owner account
+  reset-password  Set a new password for an admin and end their sessions
+  list            List admin accounts
+
+Passwords are read from stdin. Run "reveegate-admin <command> -h" for flags.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: slog.LevelWarn,
+	}))
+
+	var err error
+	switch os.Args[1] {
+	case "create-owner":
+		err = createOwner(os.Args[2:], logger)
+	case "reset-password":
+		err = resetPassword(os.Args[2:], logger)
+	case "list":
+		err = listAdmins(os.Args[2:], logger)
+	case "-h", "--help", "help":
+		fmt.Fprint(os.Stdout, usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+// createOwner handles the create-owner command
+func createOwner(args []string, logger *slog.Logger) error {
+	fs := flag.NewFlagSet("create-owner", flag.ExitOnError)
+	username := fs.String("username", "", "username of the new owner (required)")
+	email := fs.String("email", "", "email of the new owner (required)")
+	force := fs.Bool("force", false, "create the owner even if an active owner already exists")
+	fs.Parse(args)
+
+	if *username == "" || *email == "" {
+		fs.Usage()
+		return errors.New("--username and --email are required")
+	}
+
+	password, err := readPassword()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	env, err := connect(ctx, logger)
+	if err != nil {
+		return err
+	}
+	defer env.Close()
+
+	admin, err := env.adminUsers.BootstrapOwner(ctx, service.CreateAdminParams{
+		Username: *username,
+		Email:    *email,
+		Password: password,
+	}, *force)
+	if err != nil {
+		if errors.Is(err, service.ErrOwnerExists) {
+			return errors.New("an active owner already exists, use --force to create another one")
+		}
+		return err
+	}
+
+	env.audit.Record(ctx, service.AuditEntry{
+		Action:       service.AuditActionAdminCreate,
+		ResourceType: service.AuditResourceAdminUser,
+		ResourceID:   &admin.ID,
+		After: map[string]interface{}{
+			"username": admin.Username,
+			"email":    admin.Email,
+			"role":     admin.Role,
+		},
+		Details: map[string]interface{}{"source": "cli", "force": *force},
+	})
+
+	fmt.Printf("Created owner %s (%s)\n", admin.Username, admin.ID)
+	return nil
+}
+
+// resetPassword handles the reset-password command
+func resetPassword(args []string, logger *slog.Logger) error {
+	fs := flag.NewFlagSet("reset-password", flag.ExitOnError)
+	username := fs.String("username", "", "username of the admin")
+	email := fs.String("email", "", "email of the admin")
+	resetMFA := fs.Bool("reset-mfa", false, "also remove two-factor authentication")
+	fs.Parse(args)
+
+	if (*username == "") == (*email == "") {
+		fs.Usage()
+		return errors.New("exactly one of --username or --email is required")
+	}
+
+	password, err := readPassword()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	env, err := connect(ctx, logger)
+	if err != nil {
+		return err
+	}
+	defer env.Close()
+
+	var admin *postgresRepo.AdminUser
+	if *username != "" {
+		admin, err = env.adminRepo.FindByUsername(ctx, *username)
+	} else {
+		admin, err = env.adminRepo.FindByEmail(ctx, *email)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := env.adminUsers.ResetPassword(ctx, admin.ID, password, *resetMFA); err != nil {
+		return err
+	}
+
+	env.audit.Record(ctx, service.AuditEntry{
+		Action:       service.AuditActionPasswordReset,
+		ResourceType: service.AuditResourceAdminUser,
+		ResourceID:   &admin.ID,
+		Details:      map[string]interface{}{"source": "cli", "reset_mfa": *resetMFA},
+	})
+
+	fmt.Printf("Password reset for %s, all sessions ended\n", admin.Username)
+	if *resetMFA {
+		fmt.Println("Two-factor authentication removed")
+	}
+	return nil
+}
+
+// listAdmins handles the list command
+func listAdmins(args []string, logger *slog.Logger) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	env, err := connect(ctx, logger)
+	if err != nil {
+		return err
+	}
+	defer env.Close()
+
+	admins, err := env.adminUsers.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tUSERNAME\tEMAIL\tROLE\tACTIVE\t2FA")
+	for _, admin := range admins {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%t\t%t\n",
+			admin.ID, admin.Username, admin.Email, admin.Role, admin.IsActive, admin.TOTPEnabled)
+	}
+	return tw.Flush()
+}
+
+// readPassword reads a password from the first line of stdin
+func readPassword() (string, error) {
+	fmt.Fprint(os.Stderr, "Password: ")
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	fmt.Fprintln(os.Stderr)
+
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", errors.New("password must not be empty")
+	}
+	return password, nil
+}
+
+// environment holds the connections used by a command
+type environment struct {
+	db         *pgxpool.Pool
+	redis      *redis.Client
+	adminRepo  *postgresRepo.AdminRepository
+	adminUsers *service.AdminUserService
+	audit      *service.AuditService
+}
+
+// Close closes all connections
+func (e *environment) Close() {
+	e.redis.Close()
+	e.db.Close()
+}
+
+// connect loads configuration and connects to PostgreSQL and Redis
+func connect(ctx context.Context, logger *slog.Logger) (*environment, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	db, err := pgxpool.New(ctx, cfg.Database.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	if err := db.Ping(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	// Redis is only used to expire access tokens of revoked sessions; failures are logged
+	redisClient := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Addr,
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+	cache := redisRepo.NewCache(redisClient)
+
+	auth, err := middleware.NewAuth(cfg.JWT, cache, logger)
+	if err != nil {
+		redisClient.Close()
+		db.Close()
+		return nil, fmt.Errorf("failed to load JWT keys: %w", err)
+	}
+
+	adminRepo := postgresRepo.NewAdminRepository(db)
+	sessionService := service.NewSessionService(
+		postgresRepo.NewSessionRepository(db),
+		adminRepo,
+		auth,
+		cache,
+		cfg.JWT,
+		logger,
+	)
+
+	return &environment{
+		db:         db,
+		redis:      redisClient,
+		adminRepo:  adminRepo,
+		adminUsers: service.NewAdminUserService(adminRepo, postgresRepo.NewInvitationRepository(db), sessionService, cfg.Password, logger),
+		audit:      service.NewAuditService(postgresRepo.NewAuditRepository(db), logger),
+	}, nil
+}
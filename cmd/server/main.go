@@ -9,17 +9,26 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/exaring/otelpgx"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 
 	"github.com/reveegate/reveegate/internal/config"
+	"github.com/reveegate/reveegate/internal/domain/payment"
 	httpServer "github.com/reveegate/reveegate/internal/http"
 	"github.com/reveegate/reveegate/internal/http/middleware"
+	"github.com/reveegate/reveegate/internal/metrics"
+	"github.com/reveegate/reveegate/internal/provider/duitku"
 	"github.com/reveegate/reveegate/internal/provider/midtrans"
+	"github.com/reveegate/reveegate/internal/provider/registry"
+	"github.com/reveegate/reveegate/internal/provider/tripay"
+	"github.com/reveegate/reveegate/internal/provider/xendit"
 	"github.com/reveegate/reveegate/internal/realtime/websocket"
 	postgresRepo "github.com/reveegate/reveegate/internal/repository/postgres"
 	redisRepo "github.com/reveegate/reveegate/internal/repository/redis"
 	"github.com/reveegate/reveegate/internal/service"
+	"github.com/reveegate/reveegate/internal/telemetry"
 )
 
 func main() {
@@ -49,8 +58,19 @@ func main() {
 		"environment", cfg.App.Environment,
 	)
 
-	// Initialize PostgreSQL connection pool
 	ctx := context.Background()
+
+	// Initialize tracing before anything that creates spans
+	shutdownTracing, err := telemetry.Setup(ctx, cfg.Tracing, cfg.App.Version)
+	if err != nil {
+		logger.Error("failed to initialize tracing", "error", err)
+		os.Exit(1)
+	}
+	if cfg.Tracing.Exporter != telemetry.ExporterNone {
+		logger.Info("tracing enabled", "exporter", cfg.Tracing.Exporter, "sample_ratio", cfg.Tracing.SampleRatio)
+	}
+
+	// Initialize PostgreSQL connection pool
 	dbPool, err := initDatabase(ctx, cfg.Database, logger)
 	if err != nil {
 		logger.Error("failed to connect to database", "error", err)
@@ -71,36 +91,104 @@ func main() {
 	paymentRepo := postgresRepo.NewPaymentRepository(dbPool)
 	webhookLogRepo := postgresRepo.NewWebhookLogRepository(dbPool)
 	adminRepo := postgresRepo.NewAdminRepository(dbPool)
+	paymentMethodRepo := postgresRepo.NewPaymentMethodRepository(dbPool)
+	sessionRepo := postgresRepo.NewSessionRepository(dbPool)
+	settingsRepo := postgresRepo.NewSettingsRepository(dbPool)
+	invitationRepo := postgresRepo.NewInvitationRepository(dbPool)
+	auditRepo := postgresRepo.NewAuditRepository(dbPool)
+	apiKeyRepo := postgresRepo.NewAPIKeyRepository(dbPool)
+	idempotencyRepo := postgresRepo.NewIdempotencyRepository(dbPool)
+	fraudRepo := postgresRepo.NewFraudRepository(dbPool)
 
 	// Initialize Redis cache and pubsub
 	cache := redisRepo.NewCache(redisClient)
 	pubsub := redisRepo.NewPubSub(redisClient, logger)
 
-	// Initialize payment provider (Midtrans by default)
-	paymentProvider := midtrans.NewProvider(cfg.Midtrans)
+	// Initialize payment providers (Midtrans by default), measuring their API calls
+	providers := registry.NewRegistry(
+		payment.Provider(cfg.Payment.Provider),
+		metrics.InstrumentProvider(midtrans.NewProvider(cfg.Midtrans)),
+		metrics.InstrumentProvider(xendit.NewProvider(cfg.Xendit)),
+		metrics.InstrumentProvider(tripay.NewProvider(cfg.Tripay)),
+		metrics.InstrumentProvider(duitku.NewProvider(cfg.Duitku)),
+	)
+
+	paymentProvider, err := providers.GetDefaultProvider()
+	if err != nil {
+		logger.Error("failed to initialize payment provider", "error", err)
+		os.Exit(1)
+	}
+
+	// Load fee schedule (defaults merged with PAYMENT_FEE_SCHEDULE overrides)
+	feeSchedule, err := payment.ParseFeeSchedule(cfg.Payment.FeeSchedule)
+	if err != nil {
+		logger.Error("failed to load fee schedule", "error", err)
+		os.Exit(1)
+	}
 
 	// Initialize services
+	paymentMethodService := service.NewPaymentMethodService(paymentMethodRepo, paymentProvider, cache, logger)
+	fraudService := service.NewFraudService(fraudRepo, donationRepo, logger)
+	abuseGuard := service.NewAbuseGuard(donationRepo, cache, cfg.Abuse, logger)
+
 	donationService := service.NewDonationService(
 		donationRepo,
 		paymentRepo,
 		webhookLogRepo,
 		paymentProvider,
+		providers,
+		feeSchedule,
+		paymentMethodService,
+		fraudService,
+		abuseGuard,
 		pubsub,
 		cache,
 		logger,
 	)
 
+	idempotencyService := service.NewIdempotencyService(idempotencyRepo, logger)
+
 	// Initialize WebSocket hub
-	wsHub := websocket.NewHub(pubsub, logger)
+	presence := redisRepo.NewPresence(redisClient)
+	wsHub := websocket.NewHub(pubsub, presence, cfg.App.InstanceID, logger)
 	go wsHub.Run()
 
+	healthService := service.NewHealthService(dbPool, cache, pubsub, wsHub, providers, logger)
+
 	// Initialize auth middleware
-	authMiddleware := middleware.NewAuth(cfg.JWT, cache, logger)
+	authMiddleware, err := middleware.NewAuth(cfg.JWT, cache, logger)
+	if err != nil {
+		logger.Error("failed to load JWT keys", "error", err)
+		os.Exit(1)
+	}
+
+	// Initialize admin sessions and two-factor authentication
+	sessionService := service.NewSessionService(sessionRepo, adminRepo, authMiddleware, cache, cfg.JWT, logger)
+	authMiddleware.SetSessionRevocationChecker(sessionService)
+	mfaService := service.NewMFAService(adminRepo, settingsRepo, authMiddleware, cfg.MFA, logger)
+	adminUserService := service.NewAdminUserService(adminRepo, invitationRepo, sessionService, cfg.Password, logger)
+	auditService := service.NewAuditService(auditRepo, logger)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, cfg.RateLimit.APIPerMinute, logger)
+	authMiddleware.SetAPIKeyAuthenticator(apiKeyService)
+	loginGuard := service.NewLoginGuard(cache, auditService, pubsub, cfg.Login, logger)
 
 	// Initialize HTTP server
 	server := httpServer.NewServer(
 		cfg,
 		donationService,
+		idempotencyService,
+		abuseGuard,
+		fraudService,
+		sessionService,
+		mfaService,
+		adminUserService,
+		loginGuard,
+		auditService,
+		apiKeyService,
+		healthService,
+		paymentMethodService,
+		webhookLogRepo,
+		providers,
 		adminRepo,
 		authMiddleware,
 		cache,
@@ -133,17 +221,32 @@ func main() {
 
 	logger.Info("shutting down server...")
 
+	// Fail readiness first so load balancers stop sending new traffic
+	healthService.BeginShutdown()
+	if cfg.App.ShutdownDelay > 0 {
+		logger.Info("waiting for load balancers to drain", "delay", cfg.App.ShutdownDelay)
+		time.Sleep(cfg.App.ShutdownDelay)
+	}
+
 	// Graceful shutdown with timeout
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer shutdownCancel()
 
+	// Close realtime clients and leave cluster presence first; open event
+	// streams would otherwise hold up the HTTP shutdown
+	if err := wsHub.Shutdown(shutdownCtx); err != nil {
+		logger.Error("WebSocket hub shutdown error", "error", err)
+	}
+
 	// Shutdown HTTP server
 	if err := httpSrv.Shutdown(shutdownCtx); err != nil {
 		logger.Error("HTTP server shutdown error", "error", err)
 	}
 
-	// Stop WebSocket hub
-	wsHub.Stop()
+	// Flush buffered spans
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Error("tracing shutdown error", "error", err)
+	}
 
 	logger.Info("server stopped")
 }
@@ -161,6 +264,9 @@ func initDatabase(ctx context.Context, cfg config.DatabaseConfig, logger *slog.L
 	poolConfig.MaxConnLifetime = cfg.ConnMaxLifetime
 	poolConfig.MaxConnIdleTime = cfg.ConnMaxIdleTime
 
+	// Trace queries; parameters are left out since they hold donor data
+	poolConfig.ConnConfig.Tracer = otelpgx.NewTracer(otelpgx.WithTrimSQLInSpanName())
+
 	// Connect
 	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
@@ -191,6 +297,11 @@ func initRedis(ctx context.Context, cfg config.RedisConfig, logger *slog.Logger)
 		MinIdleConns: cfg.MinIdleConns,
 	})
 
+	// Trace commands without their arguments, which include tokens
+	if err := redisotel.InstrumentTracing(client, redisotel.WithDBStatement(false)); err != nil {
+		return nil, err
+	}
+
 	// Ping to verify connection
 	if err := client.Ping(ctx).Err(); err != nil {
 		return nil, err
@@ -0,0 +1,8 @@
+-- migrations/000002_payment_fees.down.sql
+-- Rollback fee tracking
+
+ALTER TABLE payments
+    DROP COLUMN IF EXISTS fee_covered,
+    DROP COLUMN IF EXISTS fee_source,
+    DROP COLUMN IF EXISTS net_amount,
+    DROP COLUMN IF EXISTS fee_amount;
@@ -0,0 +1,17 @@
+-- migrations/000002_payment_fees.up.sql
+-- Per-method fee and net amount tracking
+
+ALTER TABLE payments
+    ADD COLUMN fee_amount BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN net_amount BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN fee_source VARCHAR(20) NOT NULL DEFAULT 'estimated',
+    ADD COLUMN fee_covered BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Existing payments were recorded without fees
+UPDATE payments SET net_amount = amount;
+
+COMMENT ON COLUMN payments.amount IS 'Gross amount charged to the donor in IDR';
+COMMENT ON COLUMN payments.fee_amount IS 'Provider fee in IDR, estimated at creation and corrected from webhooks';
+COMMENT ON COLUMN payments.net_amount IS 'Amount received after provider fees in IDR';
+COMMENT ON COLUMN payments.fee_source IS 'estimated, provider';
+COMMENT ON COLUMN payments.fee_covered IS 'Donor chose to add the fee on top of the donation';
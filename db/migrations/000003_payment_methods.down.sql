@@ -0,0 +1,5 @@
+-- migrations/000003_payment_methods.down.sql
+-- Rollback payment method catalogue
+
+DROP TRIGGER IF EXISTS update_payment_methods_updated_at ON payment_methods;
+DROP TABLE IF EXISTS payment_methods;
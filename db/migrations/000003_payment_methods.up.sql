@@ -0,0 +1,39 @@
+-- migrations/000003_payment_methods.up.sql
+-- Payment method catalogue with limits and availability
+
+CREATE TABLE payment_methods (
+    method VARCHAR(50) PRIMARY KEY,
+    display_name VARCHAR(100) NOT NULL,
+    category VARCHAR(20) NOT NULL,
+    min_amount BIGINT NOT NULL,
+    max_amount BIGINT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    display_order INTEGER NOT NULL DEFAULT 0,
+    maintenance_windows JSONB NOT NULL DEFAULT '[]',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK (min_amount >= 5000 AND min_amount <= max_amount)
+);
+
+CREATE INDEX idx_payment_methods_display_order ON payment_methods(display_order);
+
+CREATE TRIGGER update_payment_methods_updated_at
+    BEFORE UPDATE ON payment_methods
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Default catalogue based on provider limits
+INSERT INTO payment_methods (method, display_name, category, min_amount, max_amount, enabled, display_order) VALUES
+    ('qris',       'QRIS',       'qris',            5000,  10000000,  TRUE,  10),
+    ('gopay',      'GoPay',      'ewallet',         5000,  2000000,   TRUE,  20),
+    ('shopeepay',  'ShopeePay',  'ewallet',         5000,  2000000,   TRUE,  30),
+    ('dana',       'DANA',       'ewallet',         5000,  2000000,   TRUE,  40),
+    ('ovo',        'OVO',        'ewallet',         5000,  2000000,   FALSE, 50),
+    ('linkaja',    'LinkAja',    'ewallet',         5000,  2000000,   FALSE, 60),
+    ('va_bca',     'VA BCA',     'virtual_account', 10000, 100000000, TRUE,  70),
+    ('va_bni',     'VA BNI',     'virtual_account', 10000, 100000000, TRUE,  80),
+    ('va_bri',     'VA BRI',     'virtual_account', 10000, 100000000, FALSE, 90),
+    ('va_mandiri', 'VA Mandiri', 'virtual_account', 10000, 100000000, FALSE, 100),
+    ('va_permata', 'VA Permata', 'virtual_account', 10000, 100000000, FALSE, 110);
+
+COMMENT ON TABLE payment_methods IS 'Stores payment method limits, availability and display order';
+COMMENT ON COLUMN payment_methods.category IS 'qris, ewallet, virtual_account';
+COMMENT ON COLUMN payment_methods.maintenance_windows IS 'Array of {starts_at, ends_at, reason} during which the method is unavailable';
@@ -0,0 +1,8 @@
+-- migrations/000004_payment_attempts.down.sql
+-- Rollback multiple payment attempts
+
+DROP INDEX IF EXISTS idx_payments_refund_needed;
+DROP INDEX IF EXISTS idx_payments_donation_created;
+
+ALTER TABLE payments
+    DROP COLUMN IF EXISTS refund_needed;
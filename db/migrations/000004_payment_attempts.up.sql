@@ -0,0 +1,11 @@
+-- migrations/000004_payment_attempts.up.sql
+-- Multiple payment attempts per donation
+
+ALTER TABLE payments
+    ADD COLUMN refund_needed BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_payments_donation_created ON payments(donation_id, created_at DESC);
+CREATE INDEX idx_payments_refund_needed ON payments(created_at DESC) WHERE refund_needed;
+
+COMMENT ON COLUMN payments.status IS 'pending, paid, expired, failed, cancelled, refunded';
+COMMENT ON COLUMN payments.refund_needed IS 'Paid after another attempt already completed the donation';
@@ -0,0 +1,19 @@
+-- migrations/000005_session_rotation.down.sql
+-- Rollback server-side admin sessions
+
+DELETE FROM sessions;
+
+DROP INDEX IF EXISTS idx_sessions_user_active;
+DROP INDEX IF EXISTS idx_sessions_family_id;
+
+ALTER TABLE sessions
+    DROP COLUMN IF EXISTS revoke_reason,
+    DROP COLUMN IF EXISTS revoked_at,
+    DROP COLUMN IF EXISTS rotated_at,
+    DROP COLUMN IF EXISTS last_used_at,
+    DROP COLUMN IF EXISTS authenticated_at,
+    DROP COLUMN IF EXISTS family_id,
+    ALTER COLUMN refresh_token_hash DROP NOT NULL,
+    ADD COLUMN IF NOT EXISTS token_hash VARCHAR(64) NOT NULL UNIQUE;
+
+CREATE INDEX IF NOT EXISTS idx_sessions_token_hash ON sessions(token_hash);
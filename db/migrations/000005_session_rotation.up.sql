@@ -0,0 +1,31 @@
+-- migrations/000005_session_rotation.up.sql
+-- Server-side admin sessions with rotating refresh tokens
+--
+-- Every row is one refresh token. Rotating a token marks the row as rotated and
+-- inserts a new row in the same family, so presenting a rotated token again can
+-- be detected and the whole family revoked.
+
+-- Stateless sessions issued before this migration cannot be rotated
+DELETE FROM sessions;
+
+DROP INDEX IF EXISTS idx_sessions_token_hash;
+
+ALTER TABLE sessions
+    DROP COLUMN token_hash,
+    ALTER COLUMN refresh_token_hash SET NOT NULL,
+    ADD COLUMN family_id UUID NOT NULL,
+    ADD COLUMN authenticated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    ADD COLUMN last_used_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    ADD COLUMN rotated_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN revoked_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN revoke_reason VARCHAR(50);
+
+CREATE INDEX idx_sessions_family_id ON sessions(family_id);
+CREATE INDEX idx_sessions_user_active ON sessions(user_id, created_at DESC)
+    WHERE rotated_at IS NULL AND revoked_at IS NULL;
+
+COMMENT ON COLUMN sessions.refresh_token_hash IS 'SHA-256 of the opaque refresh token';
+COMMENT ON COLUMN sessions.family_id IS 'Login session shared by all rotated refresh tokens';
+COMMENT ON COLUMN sessions.authenticated_at IS 'When the user logged in with their password';
+COMMENT ON COLUMN sessions.rotated_at IS 'When this refresh token was exchanged for a new one';
+COMMENT ON COLUMN sessions.revoke_reason IS 'logout, logout_all, revoked, reuse_detected';
@@ -0,0 +1,5 @@
+-- migrations/000006_admin_roles.down.sql
+-- Rollback admin roles
+
+ALTER TABLE admin_users
+    DROP COLUMN IF EXISTS role;
@@ -0,0 +1,12 @@
+-- migrations/000006_admin_roles.up.sql
+-- Role-based access control for admin users
+
+-- Existing admins had full access, so they become owners
+ALTER TABLE admin_users
+    ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'owner'
+        CHECK (role IN ('owner', 'moderator', 'finance', 'viewer'));
+
+-- New admins start with the least privileged role
+ALTER TABLE admin_users ALTER COLUMN role SET DEFAULT 'viewer';
+
+COMMENT ON COLUMN admin_users.role IS 'owner, moderator, finance, viewer';
@@ -0,0 +1,11 @@
+-- migrations/000007_admin_mfa.down.sql
+-- Rollback admin two-factor authentication
+
+DROP TABLE IF EXISTS admin_settings;
+DROP TABLE IF EXISTS admin_recovery_codes;
+
+ALTER TABLE admin_users
+    DROP COLUMN IF EXISTS totp_last_step,
+    DROP COLUMN IF EXISTS totp_confirmed_at,
+    DROP COLUMN IF EXISTS totp_enabled,
+    DROP COLUMN IF EXISTS totp_secret;
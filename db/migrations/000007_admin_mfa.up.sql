@@ -0,0 +1,34 @@
+-- migrations/000007_admin_mfa.up.sql
+-- TOTP two-factor authentication for admin users
+
+ALTER TABLE admin_users
+    ADD COLUMN totp_secret TEXT,
+    ADD COLUMN totp_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN totp_confirmed_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN totp_last_step BIGINT;
+
+-- Recovery codes (one-time use, stored hashed)
+CREATE TABLE admin_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES admin_users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL UNIQUE,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_admin_recovery_codes_user_id ON admin_recovery_codes(user_id) WHERE used_at IS NULL;
+
+-- Security settings managed by owners
+CREATE TABLE admin_settings (
+    key VARCHAR(100) PRIMARY KEY,
+    value JSONB NOT NULL,
+    updated_by UUID REFERENCES admin_users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO admin_settings (key, value) VALUES ('mfa_required', 'false');
+
+COMMENT ON COLUMN admin_users.totp_secret IS 'AES-GCM encrypted base32 TOTP secret';
+COMMENT ON COLUMN admin_users.totp_last_step IS 'Last accepted TOTP time step, prevents code replay';
+COMMENT ON TABLE admin_recovery_codes IS 'SHA-256 hashed one-time 2FA recovery codes';
+COMMENT ON TABLE admin_settings IS 'Security settings such as mfa_required';
@@ -0,0 +1,4 @@
+-- migrations/000008_admin_invitations.down.sql
+-- Rollback admin invitations
+
+DROP TABLE IF EXISTS admin_invitations;
@@ -0,0 +1,18 @@
+-- migrations/000008_admin_invitations.up.sql
+-- Invitations for new admin users
+
+CREATE TABLE admin_invitations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'moderator', 'finance', 'viewer')),
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    invited_by UUID REFERENCES admin_users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    accepted_at TIMESTAMP WITH TIME ZONE,
+    accepted_user_id UUID REFERENCES admin_users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_admin_invitations_email ON admin_invitations(email) WHERE accepted_at IS NULL;
+
+COMMENT ON TABLE admin_invitations IS 'Single-use admin invitations, token stored as SHA-256';
@@ -0,0 +1,12 @@
+-- migrations/000009_audit_log_chain.down.sql
+-- Rollback audit log hash chain
+
+DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs;
+DROP FUNCTION IF EXISTS prevent_audit_log_changes();
+
+DROP INDEX IF EXISTS idx_audit_logs_sequence;
+
+ALTER TABLE audit_logs
+    DROP COLUMN IF EXISTS hash,
+    DROP COLUMN IF EXISTS prev_hash,
+    DROP COLUMN IF EXISTS sequence;
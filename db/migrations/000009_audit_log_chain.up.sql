@@ -0,0 +1,35 @@
+-- migrations/000009_audit_log_chain.up.sql
+-- Hash chain over the audit trail so edits and deletions are detectable
+
+ALTER TABLE audit_logs
+    ADD COLUMN sequence BIGINT,
+    ADD COLUMN prev_hash VARCHAR(64),
+    ADD COLUMN hash VARCHAR(64);
+
+-- Entries written before the chain existed keep their order but stay unhashed
+WITH ordered AS (
+    SELECT id, ROW_NUMBER() OVER (ORDER BY created_at, id) AS sequence
+    FROM audit_logs
+)
+UPDATE audit_logs a
+SET sequence = o.sequence
+FROM ordered o
+WHERE a.id = o.id;
+
+ALTER TABLE audit_logs ALTER COLUMN sequence SET NOT NULL;
+
+CREATE UNIQUE INDEX idx_audit_logs_sequence ON audit_logs(sequence);
+
+-- The application only ever appends
+CREATE OR REPLACE FUNCTION prevent_audit_log_changes()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_logs_append_only
+    BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION prevent_audit_log_changes();
+
+COMMENT ON COLUMN audit_logs.hash IS 'SHA-256 over the entry and prev_hash, see AuditRepository';
@@ -0,0 +1,4 @@
+-- migrations/000010_api_keys.down.sql
+-- Rollback API keys
+
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,22 @@
+-- migrations/000010_api_keys.up.sql
+-- API keys for machine clients such as stream bots and dashboards
+
+CREATE TABLE api_keys (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    rate_limit_per_minute INTEGER CHECK (rate_limit_per_minute > 0),
+    created_by UUID REFERENCES admin_users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    last_used_ip INET,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_api_keys_created_at ON api_keys(created_at DESC);
+
+COMMENT ON TABLE api_keys IS 'Scoped API keys, key stored as SHA-256';
+COMMENT ON COLUMN api_keys.rate_limit_per_minute IS 'NULL uses RATE_LIMIT_API';
@@ -0,0 +1,23 @@
+-- migrations/000011_idempotency_keys.up.sql
+-- Idempotency keys so retried requests replay the original response
+
+CREATE TABLE idempotency_keys (
+    scope VARCHAR(50) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    fingerprint VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'processing' CHECK (status IN ('processing', 'completed')),
+    locked_until TIMESTAMP WITH TIME ZONE,
+    response_status INTEGER,
+    response_body JSONB,
+    resource_id UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+COMMENT ON TABLE idempotency_keys IS 'Client supplied Idempotency-Key headers and the responses they produced';
+COMMENT ON COLUMN idempotency_keys.fingerprint IS 'SHA-256 of the request, a key reused with another request is rejected';
+COMMENT ON COLUMN idempotency_keys.locked_until IS 'A processing key whose lock has passed can be taken over';
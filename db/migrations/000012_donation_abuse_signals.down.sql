@@ -0,0 +1,5 @@
+-- migrations/000012_donation_abuse_signals.down.sql
+-- Rollback anti-abuse indexes
+
+DROP INDEX IF EXISTS idx_donations_message_hash;
+DROP INDEX IF EXISTS idx_donations_pending_client_ip;
@@ -0,0 +1,10 @@
+-- migrations/000012_donation_abuse_signals.up.sql
+-- Indexes for the anti-abuse heuristics on donation creation
+
+-- Pending donations per client IP (stored in metadata)
+CREATE INDEX idx_donations_pending_client_ip ON donations ((metadata->>'client_ip'), created_at)
+    WHERE status = 'pending';
+
+-- Identical messages across donors
+CREATE INDEX idx_donations_message_hash ON donations (md5(lower(btrim(message))), created_at)
+    WHERE message IS NOT NULL AND message <> '';
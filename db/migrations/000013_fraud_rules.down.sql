@@ -0,0 +1,7 @@
+-- migrations/000013_fraud_rules.down.sql
+-- Rollback fraud rules
+
+DROP INDEX IF EXISTS idx_donations_donor_identity;
+DROP TABLE IF EXISTS fraud_decisions;
+DROP TABLE IF EXISTS fraud_rules;
+DROP TABLE IF EXISTS donor_blocks;
@@ -0,0 +1,61 @@
+-- migrations/000013_fraud_rules.up.sql
+-- Donor blocklist, fraud rules and the log of every decision they make
+
+CREATE TABLE donor_blocks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    type VARCHAR(20) NOT NULL CHECK (type IN ('email', 'ip', 'name_pattern', 'fingerprint')),
+    value VARCHAR(255) NOT NULL,
+    reason TEXT,
+    created_by UUID REFERENCES admin_users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (type, value)
+);
+
+CREATE TABLE fraud_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    stage VARCHAR(20) NOT NULL CHECK (stage IN ('create', 'completion')),
+    condition VARCHAR(50) NOT NULL,
+    params JSONB NOT NULL DEFAULT '{}',
+    action VARCHAR(20) NOT NULL CHECK (action IN ('flag', 'hold', 'challenge', 'block')),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by UUID REFERENCES admin_users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_fraud_rules_stage ON fraud_rules(stage) WHERE enabled;
+
+-- Donations are not referenced so the log outlives them
+CREATE TABLE fraud_decisions (
+    id UUID PRIMARY KEY,
+    stage VARCHAR(20) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    donation_id UUID,
+    payment_id UUID,
+    matches JSONB NOT NULL DEFAULT '[]',
+    subject JSONB NOT NULL DEFAULT '{}',
+    review_status VARCHAR(20) CHECK (review_status IN ('pending', 'approved', 'rejected')),
+    reviewed_by UUID REFERENCES admin_users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_fraud_decisions_created_at ON fraud_decisions(created_at DESC);
+CREATE INDEX idx_fraud_decisions_donation_id ON fraud_decisions(donation_id);
+CREATE INDEX idx_fraud_decisions_pending ON fraud_decisions(created_at DESC) WHERE review_status = 'pending';
+
+-- Donor history used by the velocity and new donor rules
+CREATE INDEX idx_donations_donor_identity ON donations (
+    (COALESCE(NULLIF(lower(donor_email), ''), lower(donor_name) || '@' || COALESCE(metadata->>'client_ip', ''))),
+    created_at
+);
+
+COMMENT ON TABLE donor_blocks IS 'Donors refused at creation and held at completion';
+COMMENT ON TABLE fraud_decisions IS 'Every evaluation of blocks and fraud rules, non-allow decisions await review';
+
+-- Default rules
+INSERT INTO fraud_rules (name, stage, condition, params, action) VALUES
+    ('Many pending donations from one IP', 'create', 'pending_per_ip', '{"threshold": 5, "window": "10m"}', 'challenge'),
+    ('Large amount from a new donor', 'completion', 'large_amount', '{"min_amount": 10000000, "new_donor_only": true}', 'hold');
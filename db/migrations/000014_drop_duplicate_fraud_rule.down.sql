@@ -0,0 +1,10 @@
+-- migrations/000014_drop_duplicate_fraud_rule.down.sql
+-- Restore the seeded pending-per-IP rule
+
+INSERT INTO fraud_rules (name, stage, condition, params, action)
+SELECT 'Many pending donations from one IP', 'create', 'pending_per_ip', '{"threshold": 5, "window": "10m"}', 'challenge'
+WHERE NOT EXISTS (
+    SELECT 1 FROM fraud_rules WHERE stage = 'create' AND condition = 'pending_per_ip'
+);
+
+COMMENT ON TABLE fraud_decisions IS 'Every evaluation of blocks and fraud rules, non-allow decisions await review';
@@ -0,0 +1,16 @@
+-- migrations/000014_drop_duplicate_fraud_rule.up.sql
+-- Drop the seeded pending-per-IP rule, which duplicates the anti-abuse
+-- heuristic configured by ABUSE_PENDING_PER_IP. Rules admins changed or
+-- created themselves are kept.
+
+DELETE FROM fraud_rules
+WHERE name = 'Many pending donations from one IP'
+  AND stage = 'create'
+  AND condition = 'pending_per_ip'
+  AND params = '{"threshold": 5, "window": "10m"}'::jsonb
+  AND action = 'challenge';
+
+-- Allowed donations are no longer logged
+DELETE FROM fraud_decisions WHERE action = 'allow';
+
+COMMENT ON TABLE fraud_decisions IS 'Evaluations of blocks and fraud rules that challenged, blocked or held a donation, awaiting review';
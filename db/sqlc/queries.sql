@@ -51,11 +51,21 @@ SELECT
     COALESCE(SUM(amount), 0) as total_amount,
     COALESCE(AVG(amount), 0) as average_amount,
     COUNT(*) FILTER (WHERE status = 'completed') as completed_count,
+    COALESCE(SUM(amount) FILTER (WHERE status = 'completed'), 0) as completed_amount,
     COUNT(*) FILTER (WHERE status = 'pending') as pending_count,
     COUNT(*) FILTER (WHERE status = 'failed') as failed_count
 FROM donations
 WHERE created_at >= $1 AND created_at <= $2;
 
+-- name: GetPaymentFeeStats :one
+SELECT
+    COALESCE(SUM(p.amount), 0) as gross_amount,
+    COALESCE(SUM(p.fee_amount), 0) as total_fees,
+    COALESCE(SUM(p.net_amount), 0) as net_amount
+FROM payments p
+JOIN donations d ON d.id = p.donation_id
+WHERE p.status = 'paid' AND d.created_at >= $1 AND d.created_at <= $2;
+
 -- name: GetRecentCompletedDonations :many
 SELECT * FROM donations 
 WHERE status = 'completed' 
@@ -64,17 +74,21 @@ LIMIT $1;
 
 -- name: CreatePayment :one
 INSERT INTO payments (
-    id, donation_id, provider, external_id, payment_method, amount, status,
-    qr_code_url, va_number, deep_link, expires_at, metadata
+    id, donation_id, provider, external_id, payment_method, amount, fee_amount, net_amount,
+    fee_source, fee_covered, refund_needed, status, qr_code_url, va_number, deep_link, expires_at,
+    metadata
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17
 ) RETURNING *;
 
 -- name: GetPaymentByID :one
 SELECT * FROM payments WHERE id = $1;
 
 -- name: GetPaymentByDonationID :one
-SELECT * FROM payments WHERE donation_id = $1;
+SELECT * FROM payments WHERE donation_id = $1 ORDER BY created_at DESC LIMIT 1;
+
+-- name: ListPaymentsByDonationID :many
+SELECT * FROM payments WHERE donation_id = $1 ORDER BY created_at DESC;
 
 -- name: GetPaymentByExternalID :one
 SELECT * FROM payments WHERE provider = $1 AND external_id = $2;
@@ -87,7 +101,11 @@ UPDATE payments SET
     va_number = COALESCE($5, va_number),
     deep_link = COALESCE($6, deep_link),
     paid_at = COALESCE($7, paid_at),
-    metadata = COALESCE($8, metadata)
+    metadata = COALESCE($8, metadata),
+    fee_amount = COALESCE($9, fee_amount),
+    net_amount = COALESCE($10, net_amount),
+    fee_source = COALESCE($11, fee_source),
+    refund_needed = COALESCE($12, refund_needed)
 WHERE id = $1
 RETURNING *;
 
@@ -156,9 +174,9 @@ WHERE
 
 -- name: CreateAdminUser :one
 INSERT INTO admin_users (
-    id, username, password_hash, email
+    id, username, password_hash, email, role
 ) VALUES (
-    $1, $2, $3, $4
+    $1, $2, $3, $4, $5
 ) RETURNING *;
 
 -- name: GetAdminUserByID :one
@@ -176,9 +194,71 @@ UPDATE admin_users SET last_login_at = NOW() WHERE id = $1;
 -- name: UpdateAdminUserPassword :exec
 UPDATE admin_users SET password_hash = $2 WHERE id = $1;
 
+-- name: UpgradeAdminUserPasswordHash :exec
+UPDATE admin_users SET password_hash = $3 WHERE id = $1 AND password_hash = $2;
+
+-- name: UpdateAdminUserRole :exec
+UPDATE admin_users SET role = $2 WHERE id = $1;
+
+-- name: SetAdminTOTPSecret :exec
+UPDATE admin_users
+SET totp_secret = $2, totp_enabled = FALSE, totp_confirmed_at = NULL, totp_last_step = NULL
+WHERE id = $1;
+
+-- name: EnableAdminTOTP :exec
+UPDATE admin_users SET totp_enabled = TRUE, totp_confirmed_at = NOW()
+WHERE id = $1 AND totp_secret IS NOT NULL;
+
+-- name: ClaimAdminTOTPStep :execrows
+UPDATE admin_users SET totp_last_step = $2
+WHERE id = $1 AND (totp_last_step IS NULL OR totp_last_step < $2);
+
+-- name: CreateRecoveryCode :exec
+INSERT INTO admin_recovery_codes (user_id, code_hash) VALUES ($1, $2);
+
+-- name: UseRecoveryCode :execrows
+UPDATE admin_recovery_codes SET used_at = NOW()
+WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL;
+
+-- name: DeleteRecoveryCodesByUserID :exec
+DELETE FROM admin_recovery_codes WHERE user_id = $1;
+
+-- name: GetAdminSetting :one
+SELECT value FROM admin_settings WHERE key = $1;
+
+-- name: UpsertAdminSetting :exec
+INSERT INTO admin_settings (key, value, updated_by, updated_at)
+VALUES ($1, $2, $3, NOW())
+ON CONFLICT (key) DO UPDATE
+SET value = EXCLUDED.value, updated_by = EXCLUDED.updated_by, updated_at = NOW();
+
 -- name: DeactivateAdminUser :exec
 UPDATE admin_users SET is_active = FALSE WHERE id = $1;
 
+-- name: SetAdminUserActive :execrows
+UPDATE admin_users SET is_active = $2 WHERE id = $1;
+
+-- name: ListAdminUsers :many
+SELECT * FROM admin_users ORDER BY created_at;
+
+-- name: CountActiveOwners :one
+SELECT COUNT(*) FROM admin_users WHERE role = 'owner' AND is_active = TRUE;
+
+-- name: CreateAdminInvitation :one
+INSERT INTO admin_invitations (
+    id, email, role, token_hash, invited_by, expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetPendingAdminInvitation :one
+SELECT * FROM admin_invitations
+WHERE token_hash = $1 AND accepted_at IS NULL AND expires_at > NOW();
+
+-- name: AcceptAdminInvitation :execrows
+UPDATE admin_invitations SET accepted_at = NOW(), accepted_user_id = $2
+WHERE id = $1 AND accepted_at IS NULL AND expires_at > NOW();
+
 -- name: CreateOverlayToken :one
 INSERT INTO overlay_tokens (
     id, token, description
@@ -198,41 +278,185 @@ UPDATE overlay_tokens SET is_active = FALSE WHERE id = $1;
 -- name: ListOverlayTokens :many
 SELECT * FROM overlay_tokens ORDER BY created_at DESC;
 
--- name: CreateAuditLog :one
+-- name: GetAuditChainHead :one
+SELECT sequence, hash FROM audit_logs ORDER BY sequence DESC LIMIT 1;
+
+-- name: CreateAuditLog :exec
 INSERT INTO audit_logs (
-    id, user_id, action, resource_type, resource_id, changes, ip_address, user_agent
+    id, user_id, action, resource_type, resource_id, changes, ip_address, user_agent,
+    sequence, prev_hash, hash, created_at
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8
-) RETURNING *;
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+);
 
 -- name: ListAuditLogs :many
 SELECT * FROM audit_logs
 WHERE 
     ($1::uuid IS NULL OR user_id = $1)
     AND ($2::varchar IS NULL OR action = $2)
-    AND ($3::timestamptz IS NULL OR created_at >= $3)
-    AND ($4::timestamptz IS NULL OR created_at <= $4)
-ORDER BY created_at DESC
-LIMIT $5 OFFSET $6;
+    AND ($3::varchar IS NULL OR resource_type = $3)
+    AND ($4::uuid IS NULL OR resource_id = $4)
+    AND ($5::timestamptz IS NULL OR created_at >= $5)
+    AND ($6::timestamptz IS NULL OR created_at <= $6)
+ORDER BY sequence DESC
+LIMIT $7 OFFSET $8;
+
+-- name: ListAuditChain :many
+SELECT * FROM audit_logs ORDER BY sequence;
 
 -- name: CreateSession :one
 INSERT INTO sessions (
-    id, user_id, token_hash, refresh_token_hash, ip_address, user_agent, expires_at
+    id, family_id, user_id, refresh_token_hash, ip_address, user_agent,
+    authenticated_at, last_used_at, expires_at
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
 ) RETURNING *;
 
--- name: GetSessionByTokenHash :one
-SELECT * FROM sessions WHERE token_hash = $1 AND expires_at > NOW();
-
 -- name: GetSessionByRefreshTokenHash :one
 SELECT * FROM sessions WHERE refresh_token_hash = $1;
 
--- name: DeleteSession :exec
-DELETE FROM sessions WHERE id = $1;
+-- name: RotateSession :execrows
+UPDATE sessions SET rotated_at = NOW()
+WHERE id = $1 AND rotated_at IS NULL AND revoked_at IS NULL;
+
+-- name: ListActiveSessionsByUserID :many
+SELECT * FROM sessions
+WHERE user_id = $1 AND rotated_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()
+ORDER BY last_used_at DESC;
 
--- name: DeleteSessionsByUserID :exec
-DELETE FROM sessions WHERE user_id = $1;
+-- name: RevokeSessionFamily :execrows
+UPDATE sessions SET revoked_at = NOW(), revoke_reason = $2
+WHERE family_id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeSessionsByUserID :many
+UPDATE sessions SET revoked_at = NOW(), revoke_reason = $2
+WHERE user_id = $1 AND revoked_at IS NULL
+RETURNING family_id;
 
 -- name: DeleteExpiredSessions :exec
 DELETE FROM sessions WHERE expires_at < NOW();
+
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (
+    id, name, prefix, key_hash, scopes, rate_limit_per_minute, created_by, expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING created_at;
+
+-- name: ListAPIKeys :many
+SELECT * FROM api_keys ORDER BY created_at DESC;
+
+-- name: GetAPIKeyByID :one
+SELECT * FROM api_keys WHERE id = $1;
+
+-- name: GetAPIKeyByHash :one
+SELECT * FROM api_keys WHERE key_hash = $1;
+
+-- name: RevokeAPIKey :execrows
+UPDATE api_keys SET revoked_at = COALESCE(revoked_at, NOW()) WHERE id = $1;
+
+-- name: TouchAPIKeyLastUsed :exec
+UPDATE api_keys SET last_used_at = NOW(), last_used_ip = $2
+WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute');
+
+-- name: AcquireIdempotencyKey :one
+INSERT INTO idempotency_keys (scope, key, fingerprint, status, locked_until, expires_at)
+VALUES ($1, $2, $3, 'processing', NOW() + $4 * INTERVAL '1 millisecond', NOW() + $5 * INTERVAL '1 millisecond')
+ON CONFLICT (scope, key) DO UPDATE
+SET fingerprint = EXCLUDED.fingerprint, status = 'processing', locked_until = EXCLUDED.locked_until,
+    response_status = NULL, response_body = NULL, resource_id = NULL,
+    created_at = NOW(), completed_at = NULL, expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at < NOW()
+   OR (idempotency_keys.status = 'processing' AND idempotency_keys.locked_until < NOW()
+       AND idempotency_keys.fingerprint = EXCLUDED.fingerprint)
+RETURNING true;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys WHERE scope = $1 AND key = $2;
+
+-- name: CompleteIdempotencyKey :execrows
+UPDATE idempotency_keys
+SET status = 'completed', locked_until = NULL, response_status = $3, response_body = $4,
+    resource_id = $5, completed_at = NOW()
+WHERE scope = $1 AND key = $2 AND status = 'processing';
+
+-- name: ReleaseIdempotencyKey :exec
+DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2 AND status = 'processing';
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys WHERE expires_at < NOW();
+
+-- name: CountPendingDonationsByClientIP :one
+SELECT COUNT(*) FROM donations
+WHERE status = 'pending' AND metadata->>'client_ip' = $1 AND created_at > $2;
+
+-- name: CountOtherDonorsWithMessage :one
+SELECT COUNT(DISTINCT COALESCE(NULLIF(lower(donor_email), ''), lower(donor_name) || '@' || COALESCE(metadata->>'client_ip', '')))
+FROM donations
+WHERE message IS NOT NULL AND message <> ''
+  AND md5(lower(btrim(message))) = md5(lower(btrim($1)))
+  AND created_at > $5
+  AND COALESCE(NULLIF(lower(donor_email), ''), lower(donor_name) || '@' || COALESCE(metadata->>'client_ip', ''))
+      <> COALESCE(NULLIF(lower($2), ''), lower($3) || '@' || $4);
+
+-- name: CountDonationsByDonor :one
+SELECT COUNT(*) FROM donations
+WHERE created_at > $1
+  AND COALESCE(NULLIF(lower(donor_email), ''), lower(donor_name) || '@' || COALESCE(metadata->>'client_ip', ''))
+      = COALESCE(NULLIF(lower($2), ''), lower($3) || '@' || $4);
+
+-- name: HasCompletedDonation :one
+SELECT EXISTS (
+    SELECT 1 FROM donations
+    WHERE status = 'completed' AND id <> $1
+      AND COALESCE(NULLIF(lower(donor_email), ''), lower(donor_name) || '@' || COALESCE(metadata->>'client_ip', ''))
+          = COALESCE(NULLIF(lower($2), ''), lower($3) || '@' || $4)
+);
+
+-- name: ListDonorBlocks :many
+SELECT * FROM donor_blocks ORDER BY created_at DESC;
+
+-- name: CreateDonorBlock :one
+INSERT INTO donor_blocks (id, type, value, reason, created_by, expires_at)
+VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6)
+RETURNING created_at;
+
+-- name: DeleteDonorBlock :one
+DELETE FROM donor_blocks WHERE id = $1 RETURNING *;
+
+-- name: ListFraudRules :many
+SELECT * FROM fraud_rules ORDER BY created_at;
+
+-- name: ListEnabledFraudRules :many
+SELECT * FROM fraud_rules WHERE enabled AND stage = $1 ORDER BY created_at;
+
+-- name: GetFraudRule :one
+SELECT * FROM fraud_rules WHERE id = $1;
+
+-- name: CreateFraudRule :one
+INSERT INTO fraud_rules (id, name, stage, condition, params, action, enabled, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING created_at, updated_at;
+
+-- name: UpdateFraudRule :one
+UPDATE fraud_rules
+SET name = $2, stage = $3, condition = $4, params = $5, action = $6, enabled = $7, updated_at = NOW()
+WHERE id = $1
+RETURNING updated_at;
+
+-- name: DeleteFraudRule :execrows
+DELETE FROM fraud_rules WHERE id = $1;
+
+-- name: CreateFraudDecision :one
+INSERT INTO fraud_decisions (id, stage, action, donation_id, payment_id, matches, subject, review_status)
+VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''))
+RETURNING created_at;
+
+-- name: GetFraudDecision :one
+SELECT * FROM fraud_decisions WHERE id = $1;
+
+-- name: ReviewFraudDecision :one
+UPDATE fraud_decisions
+SET review_status = $2, reviewed_by = $3, reviewed_at = NOW()
+WHERE id = $1 AND review_status = 'pending'
+RETURNING *;
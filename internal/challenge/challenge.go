@@ -0,0 +1,56 @@
+// Package challenge verifies that a request was made by a person, or at least
+// cost its sender real work: a self-hosted proof-of-work, or a CAPTCHA
+// checked with hCaptcha or Cloudflare Turnstile.
+package challenge
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Challenge kinds
+const (
+	KindProofOfWork = "pow"
+	KindHCaptcha    = "hcaptcha"
+	KindTurnstile   = "turnstile"
+)
+
+// Verification errors
+var (
+	ErrMissing  = errors.New("challenge solution missing")
+	ErrInvalid  = errors.New("challenge solution invalid")
+	ErrExpired  = errors.New("challenge expired")
+	ErrReplayed = errors.New("challenge already used")
+	ErrTooEasy  = errors.New("challenge difficulty too low")
+)
+
+// Requirement is the challenge a request has to pass
+type Requirement struct {
+	Kind       string
+	Difficulty int // Leading zero bits, proof-of-work only
+}
+
+// Challenge is what a client needs to solve a requirement
+type Challenge struct {
+	Kind       string
+	Token      string // Proof-of-work challenge to solve
+	Difficulty int
+	SiteKey    string // CAPTCHA widget site key
+	ExpiresAt  *time.Time
+}
+
+// Solution is a client's answer to a challenge
+type Solution struct {
+	Token    string // Proof-of-work challenge or CAPTCHA response token
+	Nonce    string // Proof-of-work answer
+	RemoteIP string
+}
+
+// Verifier checks solutions for one kind of challenge
+type Verifier interface {
+	// Issue returns a challenge meeting the requirement
+	Issue(ctx context.Context, req Requirement) (*Challenge, error)
+	// Verify checks a solution against the requirement
+	Verify(ctx context.Context, req Requirement, solution Solution) error
+}
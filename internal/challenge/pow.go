@@ -0,0 +1,140 @@
+package challenge
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxDifficulty bounds proof-of-work difficulty so a misconfiguration can't
+// ask browsers for days of hashing
+const MaxDifficulty = 32
+
+// powVersion prefixes tokens so the format can change later
+const powVersion = "v1"
+
+// ReplayStore remembers used challenges until they expire
+type ReplayStore interface {
+	// MarkUsed records a challenge ID and reports whether it was unused
+	MarkUsed(ctx context.Context, id string, ttl time.Duration) (bool, error)
+}
+
+// ProofOfWork issues stateless challenges signed with a server secret. A
+// solution is a nonce such that SHA-256("<token>:<nonce>") starts with at
+// least the challenge's difficulty in zero bits. Each challenge can be used
+// once.
+type ProofOfWork struct {
+	secret []byte
+	ttl    time.Duration
+	replay ReplayStore
+	now    func() time.Time
+}
+
+// NewProofOfWork creates a proof-of-work verifier
+func NewProofOfWork(secret string, ttl time.Duration, replay ReplayStore) *ProofOfWork {
+	return &ProofOfWork{
+		secret: []byte(secret),
+		ttl:    ttl,
+		replay: replay,
+		now:    time.Now,
+	}
+}
+
+// Issue implements Verifier. Tokens look like "v1.<id>.<expires>.<difficulty>.<mac>".
+func (p *ProofOfWork) Issue(ctx context.Context, req Requirement) (*Challenge, error) {
+	difficulty := min(max(req.Difficulty, 1), MaxDifficulty)
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate challenge: %w", err)
+	}
+
+	expiresAt := p.now().Add(p.ttl).Truncate(time.Second)
+	payload := fmt.Sprintf("%s.%s.%d.%d", powVersion, hex.EncodeToString(id), expiresAt.Unix(), difficulty)
+
+	return &Challenge{
+		Kind:       KindProofOfWork,
+		Token:      payload + "." + p.sign(payload),
+		Difficulty: difficulty,
+		ExpiresAt:  &expiresAt,
+	}, nil
+}
+
+// Verify implements Verifier
+func (p *ProofOfWork) Verify(ctx context.Context, req Requirement, solution Solution) error {
+	if solution.Token == "" || solution.Nonce == "" {
+		return ErrMissing
+	}
+	if len(solution.Nonce) > 64 {
+		return ErrInvalid
+	}
+
+	parts := strings.Split(solution.Token, ".")
+	if len(parts) != 5 || parts[0] != powVersion {
+		return ErrInvalid
+	}
+
+	payload := strings.Join(parts[:4], ".")
+	if !hmac.Equal([]byte(p.sign(payload)), []byte(parts[4])) {
+		return ErrInvalid
+	}
+
+	expires, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return ErrInvalid
+	}
+	difficulty, err := strconv.Atoi(parts[3])
+	if err != nil {
+		return ErrInvalid
+	}
+
+	expiresAt := time.Unix(expires, 0)
+	if p.now().After(expiresAt) {
+		return ErrExpired
+	}
+	if difficulty < req.Difficulty {
+		return ErrTooEasy
+	}
+
+	sum := sha256.Sum256([]byte(solution.Token + ":" + solution.Nonce))
+	if leadingZeroBits(sum[:]) < difficulty {
+		return ErrInvalid
+	}
+
+	// Only mark the challenge once the work checks out, so garbage can't burn it
+	fresh, err := p.replay.MarkUsed(ctx, parts[1], time.Until(expiresAt)+time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to record challenge use: %w", err)
+	}
+	if !fresh {
+		return ErrReplayed
+	}
+
+	return nil
+}
+
+// sign returns the hex HMAC-SHA256 of a token payload
+func (p *ProofOfWork) sign(payload string) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// leadingZeroBits counts the zero bits at the start of a hash
+func leadingZeroBits(sum []byte) int {
+	n := 0
+	for _, b := range sum {
+		if b != 0 {
+			return n + bits.LeadingZeros8(b)
+		}
+		n += 8
+	}
+	return n
+}
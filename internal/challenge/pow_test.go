@@ -0,0 +1,216 @@
+package challenge
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryReplayStore is a ReplayStore kept in memory
+type memoryReplayStore struct {
+	mu   sync.Mutex
+	used map[string]bool
+	err  error
+}
+
+func (s *memoryReplayStore) MarkUsed(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	if s.err != nil {
+		return false, s.err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.used[id] {
+		return false, nil
+	}
+	if s.used == nil {
+		s.used = make(map[string]bool)
+	}
+	s.used[id] = true
+	return true, nil
+}
+
+// solve finds a nonce that meets (want true) or misses (want false) the difficulty
+func solve(t *testing.T, token string, difficulty int, want bool) string {
+	t.Helper()
+	for i := 0; i < 1<<24; i++ {
+		nonce := strconv.Itoa(i)
+		sum := sha256.Sum256([]byte(token + ":" + nonce))
+		if (leadingZeroBits(sum[:]) >= difficulty) == want {
+			return nonce
+		}
+	}
+	t.Fatalf("no nonce found for difficulty %d", difficulty)
+	return ""
+}
+
+func issue(t *testing.T, p *ProofOfWork, difficulty int) *Challenge {
+	t.Helper()
+	c, err := p.Issue(context.Background(), Requirement{Kind: KindProofOfWork, Difficulty: difficulty})
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	return c
+}
+
+func TestProofOfWorkIssue(t *testing.T) {
+	p := NewProofOfWork("pow-secret", 5*time.Minute, &memoryReplayStore{})
+	now := time.Date(2026, 3, 14, 9, 30, 0, 500, time.UTC)
+	p.now = func() time.Time { return now }
+
+	tests := []struct {
+		requested int
+		want      int
+	}{
+		{0, 1},
+		{8, 8},
+		{MaxDifficulty + 10, MaxDifficulty},
+	}
+
+	for _, tt := range tests {
+		c := issue(t, p, tt.requested)
+		if c.Kind != KindProofOfWork || c.Difficulty != tt.want {
+			t.Errorf("Issue(%d) = %s/%d, want %s/%d", tt.requested, c.Kind, c.Difficulty, KindProofOfWork, tt.want)
+		}
+		if want := now.Add(5 * time.Minute).Truncate(time.Second); c.ExpiresAt == nil || !c.ExpiresAt.Equal(want) {
+			t.Errorf("ExpiresAt = %v, want %v", c.ExpiresAt, want)
+		}
+		if parts := strings.Split(c.Token, "."); len(parts) != 5 || parts[3] != strconv.Itoa(tt.want) {
+			t.Errorf("Token = %q", c.Token)
+		}
+	}
+
+	if a, b := issue(t, p, 4), issue(t, p, 4); a.Token == b.Token {
+		t.Error("Issue() returned the same token twice")
+	}
+}
+
+func TestProofOfWorkVerify(t *testing.T) {
+	const difficulty = 8
+	ctx := context.Background()
+	req := Requirement{Kind: KindProofOfWork, Difficulty: difficulty}
+
+	tests := []struct {
+		name   string
+		verify func(t *testing.T, p *ProofOfWork, c *Challenge) error
+		want   error
+	}{
+		{
+			name: "valid",
+			verify: func(t *testing.T, p *ProofOfWork, c *Challenge) error {
+				return p.Verify(ctx, req, Solution{Token: c.Token, Nonce: solve(t, c.Token, difficulty, true)})
+			},
+		},
+		{
+			name: "missing nonce",
+			verify: func(t *testing.T, p *ProofOfWork, c *Challenge) error {
+				return p.Verify(ctx, req, Solution{Token: c.Token})
+			},
+			want: ErrMissing,
+		},
+		{
+			name: "oversized nonce",
+			verify: func(t *testing.T, p *ProofOfWork, c *Challenge) error {
+				return p.Verify(ctx, req, Solution{Token: c.Token, Nonce: strings.Repeat("0", 65)})
+			},
+			want: ErrInvalid,
+		},
+		{
+			name: "insufficient work",
+			verify: func(t *testing.T, p *ProofOfWork, c *Challenge) error {
+				return p.Verify(ctx, req, Solution{Token: c.Token, Nonce: solve(t, c.Token, difficulty, false)})
+			},
+			want: ErrInvalid,
+		},
+		{
+			name: "expired",
+			verify: func(t *testing.T, p *ProofOfWork, c *Challenge) error {
+				nonce := solve(t, c.Token, difficulty, true)
+				p.now = func() time.Time { return c.ExpiresAt.Add(time.Second) }
+				return p.Verify(ctx, req, Solution{Token: c.Token, Nonce: nonce})
+			},
+			want: ErrExpired,
+		},
+		{
+			name: "replayed",
+			verify: func(t *testing.T, p *ProofOfWork, c *Challenge) error {
+				nonce := solve(t, c.Token, difficulty, true)
+				if err := p.Verify(ctx, req, Solution{Token: c.Token, Nonce: nonce}); err != nil {
+					t.Fatalf("first Verify() error = %v", err)
+				}
+				return p.Verify(ctx, req, Solution{Token: c.Token, Nonce: nonce})
+			},
+			want: ErrReplayed,
+		},
+		{
+			name: "issued easier than required",
+			verify: func(t *testing.T, p *ProofOfWork, c *Challenge) error {
+				easy := issue(t, p, difficulty-4)
+				return p.Verify(ctx, req, Solution{Token: easy.Token, Nonce: solve(t, easy.Token, difficulty, true)})
+			},
+			want: ErrTooEasy,
+		},
+		{
+			name: "difficulty lowered in token",
+			verify: func(t *testing.T, p *ProofOfWork, c *Challenge) error {
+				parts := strings.Split(c.Token, ".")
+				parts[3] = "1"
+				token := strings.Join(parts, ".")
+				return p.Verify(ctx, Requirement{Kind: KindProofOfWork, Difficulty: 1}, Solution{Token: token, Nonce: solve(t, token, 1, true)})
+			},
+			want: ErrInvalid,
+		},
+		{
+			name: "expiry extended in token",
+			verify: func(t *testing.T, p *ProofOfWork, c *Challenge) error {
+				parts := strings.Split(c.Token, ".")
+				parts[2] = strconv.FormatInt(c.ExpiresAt.Add(time.Hour).Unix(), 10)
+				token := strings.Join(parts, ".")
+				return p.Verify(ctx, req, Solution{Token: token, Nonce: solve(t, token, difficulty, true)})
+			},
+			want: ErrInvalid,
+		},
+		{
+			name: "signed with another secret",
+			verify: func(t *testing.T, p *ProofOfWork, c *Challenge) error {
+				other := issue(t, NewProofOfWork("another-secret", 5*time.Minute, &memoryReplayStore{}), difficulty)
+				return p.Verify(ctx, req, Solution{Token: other.Token, Nonce: solve(t, other.Token, difficulty, true)})
+			},
+			want: ErrInvalid,
+		},
+		{
+			name: "unknown version",
+			verify: func(t *testing.T, p *ProofOfWork, c *Challenge) error {
+				token := "v2" + strings.TrimPrefix(c.Token, powVersion)
+				return p.Verify(ctx, req, Solution{Token: token, Nonce: solve(t, token, difficulty, true)})
+			},
+			want: ErrInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProofOfWork("pow-secret", 5*time.Minute, &memoryReplayStore{})
+			err := tt.verify(t, p, issue(t, p, difficulty))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestProofOfWorkVerifyReplayStoreDown(t *testing.T) {
+	storeErr := errors.New("connection refused")
+	p := NewProofOfWork("pow-secret", 5*time.Minute, &memoryReplayStore{err: storeErr})
+	c := issue(t, p, 4)
+
+	err := p.Verify(context.Background(), Requirement{Kind: KindProofOfWork, Difficulty: 4}, Solution{Token: c.Token, Nonce: solve(t, c.Token, 4, true)})
+	if !errors.Is(err, storeErr) {
+		t.Errorf("Verify() error = %v, want %v", err, storeErr)
+	}
+}
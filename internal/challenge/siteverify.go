@@ -0,0 +1,117 @@
+package challenge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Default siteverify endpoints
+const (
+	HCaptchaVerifyURL  = "https://api.hcaptcha.com/siteverify"
+	TurnstileVerifyURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+)
+
+// SiteVerify checks CAPTCHA response tokens with a siteverify endpoint.
+// hCaptcha and Turnstile share the protocol: a form POST of the secret, the
+// response token and the client IP, answered with {"success": bool}.
+type SiteVerify struct {
+	kind       string
+	siteKey    string
+	secret     string
+	verifyURL  string
+	httpClient *http.Client
+}
+
+// NewHCaptcha creates an hCaptcha verifier. An empty verifyURL uses the public endpoint.
+func NewHCaptcha(siteKey, secret, verifyURL string) *SiteVerify {
+	return newSiteVerify(KindHCaptcha, siteKey, secret, verifyURL, HCaptchaVerifyURL)
+}
+
+// NewTurnstile creates a Cloudflare Turnstile verifier. An empty verifyURL uses the public endpoint.
+func NewTurnstile(siteKey, secret, verifyURL string) *SiteVerify {
+	return newSiteVerify(KindTurnstile, siteKey, secret, verifyURL, TurnstileVerifyURL)
+}
+
+func newSiteVerify(kind, siteKey, secret, verifyURL, defaultURL string) *SiteVerify {
+	if verifyURL == "" {
+		verifyURL = defaultURL
+	}
+
+	return &SiteVerify{
+		kind:      kind,
+		siteKey:   siteKey,
+		secret:    secret,
+		verifyURL: verifyURL,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// siteVerifyResponse is the body returned by siteverify endpoints
+type siteVerifyResponse struct {
+	Success    bool     `json:"success"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+// Issue implements Verifier. The widget produces the challenge, so the
+// client only needs the site key.
+func (v *SiteVerify) Issue(ctx context.Context, req Requirement) (*Challenge, error) {
+	return &Challenge{Kind: v.kind, SiteKey: v.siteKey}, nil
+}
+
+// Verify implements Verifier
+func (v *SiteVerify) Verify(ctx context.Context, req Requirement, solution Solution) error {
+	if solution.Token == "" {
+		return ErrMissing
+	}
+
+	form := url.Values{
+		"secret":   {v.secret},
+		"response": {solution.Token},
+	}
+	if solution.RemoteIP != "" {
+		form.Set("remoteip", solution.RemoteIP)
+	}
+	if v.kind == KindHCaptcha && v.siteKey != "" {
+		form.Set("sitekey", v.siteKey)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, v.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %w", v.kind, err)
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", v.kind, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %w", v.kind, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", v.kind, resp.StatusCode)
+	}
+
+	var result siteVerifyResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", v.kind, err)
+	}
+
+	if !result.Success {
+		return fmt.Errorf("%w: %s", ErrInvalid, strings.Join(result.ErrorCodes, ", "))
+	}
+
+	return nil
+}
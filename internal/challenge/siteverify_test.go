@@ -0,0 +1,167 @@
+package challenge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newSiteVerifyStub serves a siteverify endpoint that accepts the response
+// token "valid-token" and records the last request form
+func newSiteVerifyStub(t *testing.T, status int, form *map[string]string) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+			t.Errorf("request = %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("failed to parse form: %v", err)
+			return
+		}
+
+		*form = make(map[string]string)
+		for key := range r.PostForm {
+			(*form)[key] = r.PostForm.Get(key)
+		}
+
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+
+		result := siteVerifyResponse{Success: r.PostForm.Get("response") == "valid-token"}
+		if !result.Success {
+			result.ErrorCodes = []string{"invalid-input-response"}
+		}
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			t.Errorf("failed to write response: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+func TestSiteVerifyIssue(t *testing.T) {
+	tests := []struct {
+		verifier *SiteVerify
+		kind     string
+	}{
+		{NewHCaptcha("site-key", "secret", ""), KindHCaptcha},
+		{NewTurnstile("site-key", "secret", ""), KindTurnstile},
+	}
+
+	for _, tt := range tests {
+		c, err := tt.verifier.Issue(context.Background(), Requirement{Kind: tt.kind})
+		if err != nil {
+			t.Fatalf("Issue() error = %v", err)
+		}
+		if c.Kind != tt.kind || c.SiteKey != "site-key" || c.Token != "" {
+			t.Errorf("Issue() = %+v", c)
+		}
+	}
+
+	if v := NewHCaptcha("", "", ""); v.verifyURL != HCaptchaVerifyURL {
+		t.Errorf("hCaptcha verifyURL = %q, want %q", v.verifyURL, HCaptchaVerifyURL)
+	}
+	if v := NewTurnstile("", "", ""); v.verifyURL != TurnstileVerifyURL {
+		t.Errorf("Turnstile verifyURL = %q, want %q", v.verifyURL, TurnstileVerifyURL)
+	}
+}
+
+func TestSiteVerifyVerify(t *testing.T) {
+	tests := []struct {
+		name     string
+		kind     string
+		status   int
+		solution Solution
+		wantForm map[string]string
+		wantErr  error
+	}{
+		{
+			name:     "hcaptcha valid",
+			kind:     KindHCaptcha,
+			status:   http.StatusOK,
+			solution: Solution{Token: "valid-token", RemoteIP: "203.0.113.7"},
+			wantForm: map[string]string{"secret": "captcha-secret", "response": "valid-token", "remoteip": "203.0.113.7", "sitekey": "site-key"},
+		},
+		{
+			name:     "turnstile valid",
+			kind:     KindTurnstile,
+			status:   http.StatusOK,
+			solution: Solution{Token: "valid-token"},
+			wantForm: map[string]string{"secret": "captcha-secret", "response": "valid-token"},
+		},
+		{
+			name:     "rejected token",
+			kind:     KindTurnstile,
+			status:   http.StatusOK,
+			solution: Solution{Token: "forged-token"},
+			wantErr:  ErrInvalid,
+		},
+		{
+			name:     "missing token",
+			kind:     KindHCaptcha,
+			status:   http.StatusOK,
+			solution: Solution{RemoteIP: "203.0.113.7"},
+			wantErr:  ErrMissing,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var form map[string]string
+			url := newSiteVerifyStub(t, tt.status, &form)
+
+			v := NewTurnstile("site-key", "captcha-secret", url)
+			if tt.kind == KindHCaptcha {
+				v = NewHCaptcha("site-key", "captcha-secret", url)
+			}
+
+			err := v.Verify(context.Background(), Requirement{Kind: tt.kind}, tt.solution)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == ErrMissing && form != nil {
+				t.Error("Verify() called siteverify without a token")
+			}
+			if tt.wantForm == nil {
+				return
+			}
+			if len(form) != len(tt.wantForm) {
+				t.Errorf("form = %v, want %v", form, tt.wantForm)
+			}
+			for key, want := range tt.wantForm {
+				if form[key] != want {
+					t.Errorf("form[%s] = %q, want %q", key, form[key], want)
+				}
+			}
+		})
+	}
+}
+
+func TestSiteVerifyVerifyUnavailable(t *testing.T) {
+	var form map[string]string
+	v := NewTurnstile("site-key", "captcha-secret", newSiteVerifyStub(t, http.StatusInternalServerError, &form))
+
+	err := v.Verify(context.Background(), Requirement{Kind: KindTurnstile}, Solution{Token: "valid-token"})
+	if err == nil || errors.Is(err, ErrInvalid) {
+		t.Errorf("Verify() error = %v, want an outage error", err)
+	}
+
+	// A body that isn't JSON is an outage too, not a rejected token
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html>Bad Gateway</html>"))
+	}))
+	defer server.Close()
+
+	err = NewHCaptcha("site-key", "captcha-secret", server.URL).Verify(context.Background(), Requirement{Kind: KindHCaptcha}, Solution{Token: "valid-token"})
+	if err == nil || errors.Is(err, ErrInvalid) {
+		t.Errorf("Verify() error = %v, want a decode error", err)
+	}
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadRequiresSeparateSecrets(t *testing.T) {
+	const (
+		jwtSecret   = "jwt-secret-0123456789abcdef0123456789"
+		mfaKey      = "mfa-key-0123456789abcdef0123456789"
+		challenge   = "challenge-0123456789abcdef0123456789"
+		donorSecret = "donor-secret-0123456789abcdef0123456789"
+	)
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "all separate"},
+		{name: "mfa key missing", env: map[string]string{"MFA_ENCRYPTION_KEY": ""}, wantErr: "MFA_ENCRYPTION_KEY"},
+		{name: "mfa key reuses jwt secret", env: map[string]string{"MFA_ENCRYPTION_KEY": jwtSecret}, wantErr: "MFA_ENCRYPTION_KEY must differ"},
+		{name: "mfa disabled without key", env: map[string]string{"MFA_ENABLED": "false", "MFA_ENCRYPTION_KEY": ""}},
+		{name: "challenge secret missing", env: map[string]string{"CHALLENGE_SECRET": ""}, wantErr: "CHALLENGE_SECRET"},
+		{name: "challenge secret reuses jwt secret", env: map[string]string{"CHALLENGE_SECRET": jwtSecret}, wantErr: "CHALLENGE_SECRET must differ"},
+		{name: "challenges off without secret", env: map[string]string{"CHALLENGE_MODE": "off", "CHALLENGE_SECRET": ""}},
+		{name: "donor secret missing", env: map[string]string{"DONOR_TOKEN_SECRET": ""}, wantErr: "DONOR_TOKEN_SECRET"},
+		{name: "donor secret reuses jwt secret", env: map[string]string{"DONOR_TOKEN_SECRET": jwtSecret}, wantErr: "DONOR_TOKEN_SECRET must differ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("JWT_SECRET", jwtSecret)
+			t.Setenv("MFA_ENCRYPTION_KEY", mfaKey)
+			t.Setenv("CHALLENGE_SECRET", challenge)
+			t.Setenv("DONOR_TOKEN_SECRET", donorSecret)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := Load()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Load() error = %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Load() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
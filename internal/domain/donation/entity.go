@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/reveegate/reveegate/internal/domain/payment"
 )
 
 // Status represents the status of a donation
@@ -18,6 +20,12 @@ const (
 	StatusCancelled Status = "cancelled"
 )
 
+// Global donation amount limits in IDR, enforced on top of per-method limits
+const (
+	MinAmount int64 = 5000
+	MaxAmount int64 = 100000000
+)
+
 // Donation represents a donation entity
 type Donation struct {
 	ID         uuid.UUID              `json:"id"`
@@ -73,6 +81,22 @@ func (d *Donation) MarkAsFailed() {
 	d.UpdatedAt = time.Now()
 }
 
+// Reopen puts a failed or expired donation back to pending for a new payment attempt
+func (d *Donation) Reopen() {
+	d.Status = StatusPending
+	d.UpdatedAt = time.Now()
+}
+
+// CanRetryPayment checks if a new payment attempt may be started
+func (d *Donation) CanRetryPayment() bool {
+	switch d.Status {
+	case StatusPending, StatusFailed, StatusExpired:
+		return true
+	default:
+		return false
+	}
+}
+
 // IsPending checks if the donation is pending
 func (d *Donation) IsPending() bool {
 	return d.Status == StatusPending
@@ -115,6 +139,7 @@ type Repository interface {
 	Create(ctx context.Context, donation *Donation) error
 	GetByID(ctx context.Context, id uuid.UUID) (*Donation, error)
 	Update(ctx context.Context, donation *Donation) error
+	Complete(ctx context.Context, donation *Donation, payment *payment.Payment) (bool, error)
 	List(ctx context.Context, params ListDonationsParams) (*ListDonationsResult, error)
 	GetPendingExpired(ctx context.Context, before time.Time) ([]*Donation, error)
 	UpdateStatus(ctx context.Context, id uuid.UUID, status Status) error
@@ -131,4 +156,7 @@ type DonationStats struct {
 	CompletedCount     int64   `json:"completed_count"`
 	PendingCount       int64   `json:"pending_count"`
 	FailedCount        int64   `json:"failed_count"`
+	GrossAmount        int64   `json:"gross_amount"` // Charged to donors, including covered fees
+	TotalFees          int64   `json:"total_fees"`
+	NetAmount          int64   `json:"net_amount"`
 }
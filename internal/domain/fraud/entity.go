@@ -0,0 +1,380 @@
+package fraud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Stage is the point in a donation's life where rules are evaluated
+type Stage string
+
+const (
+	StageCreate     Stage = "create"
+	StageCompletion Stage = "completion"
+)
+
+// IsValid checks if the stage is known
+func (s Stage) IsValid() bool {
+	return s == StageCreate || s == StageCompletion
+}
+
+// Action is what a decision does with a donation, from least to most severe
+type Action string
+
+const (
+	ActionAllow     Action = "allow"
+	ActionFlag      Action = "flag"      // Record for review, nothing else
+	ActionHold      Action = "hold"      // Hold the overlay alert until an admin approves it
+	ActionChallenge Action = "challenge" // Require a CAPTCHA or proof-of-work (create only)
+	ActionBlock     Action = "block"     // Reject the donation (create only)
+)
+
+var actionSeverity = map[Action]int{
+	ActionAllow:     0,
+	ActionFlag:      1,
+	ActionHold:      2,
+	ActionChallenge: 3,
+	ActionBlock:     4,
+}
+
+// IsValid checks if the action is known
+func (a Action) IsValid() bool {
+	_, ok := actionSeverity[a]
+	return ok
+}
+
+// Stronger returns the more severe of two actions
+func Stronger(a, b Action) Action {
+	if actionSeverity[b] > actionSeverity[a] {
+		return b
+	}
+	return a
+}
+
+// Review states of a decision
+const (
+	ReviewPending  = "pending"
+	ReviewApproved = "approved"
+	ReviewRejected = "rejected"
+)
+
+// Validation errors
+var (
+	ErrInvalidBlock = errors.New("invalid block")
+	ErrInvalidRule  = errors.New("invalid rule")
+)
+
+// BlockType is what a block matches on
+type BlockType string
+
+const (
+	BlockEmail       BlockType = "email"
+	BlockIP          BlockType = "ip"           // Address or CIDR range
+	BlockNamePattern BlockType = "name_pattern" // Case-insensitive regular expression
+	BlockFingerprint BlockType = "fingerprint"  // Device fingerprint sent by the donor page
+)
+
+// Block rejects donations from a donor
+type Block struct {
+	ID        uuid.UUID  `json:"id"`
+	Type      BlockType  `json:"type"`
+	Value     string     `json:"value"`
+	Reason    string     `json:"reason,omitempty"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Normalize checks the value fits the block type and puts it in canonical form
+func (b *Block) Normalize() error {
+	value := strings.TrimSpace(b.Value)
+	if value == "" {
+		return fmt.Errorf("%w: value is required", ErrInvalidBlock)
+	}
+
+	switch b.Type {
+	case BlockEmail:
+		if !strings.Contains(value, "@") {
+			return fmt.Errorf("%w: %q is not an email address", ErrInvalidBlock, value)
+		}
+		value = strings.ToLower(value)
+	case BlockIP:
+		if prefix, err := netip.ParsePrefix(value); err == nil {
+			value = prefix.Masked().String()
+		} else if addr, err := netip.ParseAddr(value); err == nil {
+			value = addr.Unmap().String()
+		} else {
+			return fmt.Errorf("%w: %q is not an IP address or CIDR range", ErrInvalidBlock, value)
+		}
+	case BlockNamePattern:
+		if _, err := regexp.Compile("(?i)" + value); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidBlock, err)
+		}
+	case BlockFingerprint:
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidBlock, b.Type)
+	}
+
+	b.Value = value
+	return nil
+}
+
+// IsActive checks if the block has not expired
+func (b *Block) IsActive(now time.Time) bool {
+	return b.ExpiresAt == nil || now.Before(*b.ExpiresAt)
+}
+
+// Matches checks if the block applies to a subject
+func (b *Block) Matches(s *Subject) bool {
+	switch b.Type {
+	case BlockEmail:
+		return s.Email != "" && strings.EqualFold(s.Email, b.Value)
+	case BlockIP:
+		addr, err := netip.ParseAddr(s.IP)
+		if err != nil {
+			return false
+		}
+		addr = addr.Unmap()
+		if prefix, err := netip.ParsePrefix(b.Value); err == nil {
+			return prefix.Contains(addr)
+		}
+		blocked, err := netip.ParseAddr(b.Value)
+		return err == nil && blocked == addr
+	case BlockNamePattern:
+		re, err := regexp.Compile("(?i)" + b.Value)
+		return err == nil && re.MatchString(s.Name)
+	case BlockFingerprint:
+		return s.Fingerprint != "" && s.Fingerprint == b.Value
+	default:
+		return false
+	}
+}
+
+// Condition is what a rule checks
+type Condition string
+
+const (
+	// More than Threshold pending donations from the donor's IP within Window
+	ConditionPendingPerIP Condition = "pending_per_ip"
+	// Amount of at least MinAmount, only from first-time donors if NewDonorOnly
+	ConditionLargeAmount Condition = "large_amount"
+	// More than Threshold other donors sent the same message within Window
+	ConditionDuplicateMessage Condition = "duplicate_message"
+	// More than Threshold donations from the same donor within Window
+	ConditionDonorVelocity Condition = "donor_velocity"
+)
+
+// RuleParams are the thresholds of a rule. Which ones apply depends on the condition.
+type RuleParams struct {
+	Threshold    int    `json:"threshold,omitempty"`
+	Window       string `json:"window,omitempty"` // Duration such as "10m"
+	MinAmount    int64  `json:"min_amount,omitempty"`
+	NewDonorOnly bool   `json:"new_donor_only,omitempty"`
+}
+
+// Rule maps a condition at a stage to an action
+type Rule struct {
+	ID        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	Stage     Stage      `json:"stage"`
+	Condition Condition  `json:"condition"`
+	Params    RuleParams `json:"params"`
+	Action    Action     `json:"action"`
+	Enabled   bool       `json:"enabled"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// Validate checks the rule can be evaluated
+func (r *Rule) Validate() error {
+	if !r.Stage.IsValid() {
+		return fmt.Errorf("%w: unknown stage %q", ErrInvalidRule, r.Stage)
+	}
+	if !r.Action.IsValid() || r.Action == ActionAllow {
+		return fmt.Errorf("%w: unknown action %q", ErrInvalidRule, r.Action)
+	}
+	if r.Stage == StageCompletion && (r.Action == ActionChallenge || r.Action == ActionBlock) {
+		return fmt.Errorf("%w: a paid donation can only be flagged or held", ErrInvalidRule)
+	}
+
+	switch r.Condition {
+	case ConditionPendingPerIP, ConditionDuplicateMessage, ConditionDonorVelocity:
+		if r.Params.Threshold < 0 {
+			return fmt.Errorf("%w: threshold must not be negative", ErrInvalidRule)
+		}
+		if d, err := time.ParseDuration(r.Params.Window); err != nil || d <= 0 {
+			return fmt.Errorf("%w: window must be a positive duration such as 10m", ErrInvalidRule)
+		}
+	case ConditionLargeAmount:
+		if r.Params.MinAmount <= 0 {
+			return fmt.Errorf("%w: min_amount must be positive", ErrInvalidRule)
+		}
+	default:
+		return fmt.Errorf("%w: unknown condition %q", ErrInvalidRule, r.Condition)
+	}
+
+	return nil
+}
+
+// window returns the rule's lookback window
+func (r *Rule) window() time.Duration {
+	d, _ := time.ParseDuration(r.Params.Window)
+	return d
+}
+
+// Subject is the donation and donor a decision is about
+type Subject struct {
+	DonationID     *uuid.UUID `json:"donation_id,omitempty"`
+	PaymentID      *uuid.UUID `json:"payment_id,omitempty"`
+	Email          string     `json:"email,omitempty"`
+	Name           string     `json:"name,omitempty"`
+	IP             string     `json:"ip,omitempty"`
+	Fingerprint    string     `json:"fingerprint,omitempty"`
+	Message        string     `json:"message,omitempty"`
+	Amount         int64      `json:"amount"`
+	HeldAtCreation bool       `json:"held_at_creation,omitempty"`
+}
+
+// Facts looks up the history rules compare a subject against
+type Facts interface {
+	PendingFromIP(ctx context.Context, ip string, since time.Time) (int, error)
+	OtherDonorsWithMessage(ctx context.Context, s *Subject, since time.Time) (int, error)
+	DonationsFromDonor(ctx context.Context, s *Subject, since time.Time) (int, error)
+	IsNewDonor(ctx context.Context, s *Subject) (bool, error)
+}
+
+// Match is a block or rule that applied to a subject
+type Match struct {
+	BlockID *uuid.UUID `json:"block_id,omitempty"`
+	RuleID  *uuid.UUID `json:"rule_id,omitempty"`
+	Action  Action     `json:"action"`
+	Reason  string     `json:"reason"`
+}
+
+// Decision is the outcome of evaluating blocks and rules for a subject
+type Decision struct {
+	ID           uuid.UUID  `json:"id"`
+	Stage        Stage      `json:"stage"`
+	Action       Action     `json:"action"`
+	Matches      []Match    `json:"matches"`
+	Subject      Subject    `json:"subject"`
+	ReviewStatus string     `json:"review_status,omitempty"`
+	ReviewedBy   *uuid.UUID `json:"reviewed_by,omitempty"`
+	ReviewedAt   *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// Evaluate applies blocks and the rules of a stage to a subject. The most
+// severe matching action wins. A rule whose facts can't be looked up flags the
+// decision instead of failing it.
+func Evaluate(ctx context.Context, stage Stage, subject Subject, blocks []*Block, rules []*Rule, facts Facts) *Decision {
+	d := &Decision{
+		ID:      uuid.New(),
+		Stage:   stage,
+		Action:  ActionAllow,
+		Matches: make([]Match, 0),
+		Subject: subject,
+	}
+
+	add := func(m Match) {
+		d.Matches = append(d.Matches, m)
+		d.Action = Stronger(d.Action, m.Action)
+	}
+
+	now := time.Now()
+
+	// Paid donations can't be refused, only held
+	blockAction := ActionBlock
+	if stage == StageCompletion {
+		blockAction = ActionHold
+	}
+	for _, b := range blocks {
+		if b.IsActive(now) && b.Matches(&subject) {
+			id := b.ID
+			add(Match{BlockID: &id, Action: blockAction, Reason: fmt.Sprintf("blocked %s %s", b.Type, b.Value)})
+		}
+	}
+
+	if subject.HeldAtCreation {
+		add(Match{Action: ActionHold, Reason: "held when the donation was created"})
+	}
+
+	for _, r := range rules {
+		if !r.Enabled || r.Stage != stage {
+			continue
+		}
+
+		id := r.ID
+		matched, reason, err := r.evaluate(ctx, &subject, facts, now)
+		if err != nil {
+			add(Match{RuleID: &id, Action: ActionFlag, Reason: fmt.Sprintf("rule %q could not be evaluated: %v", r.Name, err)})
+			continue
+		}
+		if matched {
+			add(Match{RuleID: &id, Action: r.Action, Reason: fmt.Sprintf("%s: %s", r.Name, reason)})
+		}
+	}
+
+	if d.Action != ActionAllow {
+		d.ReviewStatus = ReviewPending
+	}
+
+	return d
+}
+
+// evaluate checks the rule's condition and describes why it matched
+func (r *Rule) evaluate(ctx context.Context, s *Subject, facts Facts, now time.Time) (bool, string, error) {
+	since := now.Add(-r.window())
+
+	switch r.Condition {
+	case ConditionPendingPerIP:
+		if s.IP == "" {
+			return false, "", nil
+		}
+		n, err := facts.PendingFromIP(ctx, s.IP, since)
+		if err != nil {
+			return false, "", err
+		}
+		return n > r.Params.Threshold, fmt.Sprintf("%d pending donations from %s in %s", n, s.IP, r.Params.Window), nil
+
+	case ConditionLargeAmount:
+		if s.Amount < r.Params.MinAmount {
+			return false, "", nil
+		}
+		if !r.Params.NewDonorOnly {
+			return true, fmt.Sprintf("amount %d is at least %d", s.Amount, r.Params.MinAmount), nil
+		}
+		isNew, err := facts.IsNewDonor(ctx, s)
+		if err != nil {
+			return false, "", err
+		}
+		return isNew, fmt.Sprintf("amount %d is at least %d from a new donor", s.Amount, r.Params.MinAmount), nil
+
+	case ConditionDuplicateMessage:
+		if strings.TrimSpace(s.Message) == "" {
+			return false, "", nil
+		}
+		n, err := facts.OtherDonorsWithMessage(ctx, s, since)
+		if err != nil {
+			return false, "", err
+		}
+		return n > r.Params.Threshold, fmt.Sprintf("%d other donors sent the same message in %s", n, r.Params.Window), nil
+
+	case ConditionDonorVelocity:
+		n, err := facts.DonationsFromDonor(ctx, s, since)
+		if err != nil {
+			return false, "", err
+		}
+		return n > r.Params.Threshold, fmt.Sprintf("%d donations from this donor in %s", n, r.Params.Window), nil
+	}
+
+	return false, "", nil
+}
@@ -11,11 +11,12 @@ import (
 type Status string
 
 const (
-	StatusPending  Status = "pending"
-	StatusPaid     Status = "paid"
-	StatusExpired  Status = "expired"
-	StatusFailed   Status = "failed"
-	StatusRefunded Status = "refunded"
+	StatusPending   Status = "pending"
+	StatusPaid      Status = "paid"
+	StatusExpired   Status = "expired"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+	StatusRefunded  Status = "refunded"
 )
 
 // Method represents the payment method
@@ -41,6 +42,8 @@ type Provider string
 const (
 	ProviderMidtrans Provider = "midtrans"
 	ProviderXendit   Provider = "xendit"
+	ProviderTripay   Provider = "tripay"
+	ProviderDuitku   Provider = "duitku"
 )
 
 // Payment represents a payment entity
@@ -52,6 +55,11 @@ type Payment struct {
 	PaymentMethod Method                 `json:"payment_method"`
 	Method        Method                 `json:"method"` // Alias for PaymentMethod
 	Amount        int64                  `json:"amount"`
+	FeeAmount     int64                  `json:"fee_amount"`
+	NetAmount     int64                  `json:"net_amount"`
+	FeeSource     FeeSource              `json:"fee_source"`
+	FeeCovered    bool                   `json:"fee_covered"`   // Donor added the fee on top of the donation
+	RefundNeeded  bool                   `json:"refund_needed"` // Paid after another attempt already completed the donation
 	Status        Status                 `json:"status"`
 	QRCodeURL     string                 `json:"qr_code_url,omitempty"`
 	VANumber      string                 `json:"va_number,omitempty"`
@@ -74,6 +82,8 @@ func NewPayment(donationID uuid.UUID, provider Provider, method Method, amount i
 		PaymentMethod: method,
 		Method:        method, // Set both fields
 		Amount:        amount,
+		NetAmount:     amount,
+		FeeSource:     FeeSourceEstimated,
 		Status:        StatusPending,
 		ExpiresAt:     expiresAt,
 		Metadata:      make(map[string]interface{}),
@@ -96,6 +106,14 @@ func (p *Payment) SetPaymentDetails(qrCodeURL, vaNumber, deepLink string) {
 	p.UpdatedAt = time.Now()
 }
 
+// ApplyFee sets the fee charged on the payment and recalculates the net amount
+func (p *Payment) ApplyFee(fee int64, source FeeSource) {
+	p.FeeAmount = fee
+	p.NetAmount = p.Amount - fee
+	p.FeeSource = source
+	p.UpdatedAt = time.Now()
+}
+
 // MarkAsPaid marks the payment as paid
 func (p *Payment) MarkAsPaid() {
 	now := time.Now()
@@ -116,6 +134,19 @@ func (p *Payment) MarkAsFailed() {
 	p.UpdatedAt = time.Now()
 }
 
+// MarkAsCancelled marks the payment as superseded by another attempt
+func (p *Payment) MarkAsCancelled() {
+	p.Status = StatusCancelled
+	p.UpdatedAt = time.Now()
+}
+
+// FlagForRefund marks a paid payment as needing a refund
+func (p *Payment) FlagForRefund(reason string) {
+	p.RefundNeeded = true
+	p.Metadata["refund_reason"] = reason
+	p.UpdatedAt = time.Now()
+}
+
 // IsExpired checks if the payment has expired
 func (p *Payment) IsExpired() bool {
 	return time.Now().After(p.ExpiresAt) && p.Status == StatusPending
@@ -139,8 +170,10 @@ func (p *Payment) GenerateOrderID() string {
 // Repository defines the payment repository interface
 type Repository interface {
 	Create(ctx context.Context, payment *Payment) error
+	CreateAttempt(ctx context.Context, payment *Payment, maxAttempts int) (bool, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*Payment, error)
 	GetByDonationID(ctx context.Context, donationID uuid.UUID) (*Payment, error)
+	ListByDonationID(ctx context.Context, donationID uuid.UUID) ([]*Payment, error)
 	GetByExternalID(ctx context.Context, provider Provider, externalID string) (*Payment, error)
 	Update(ctx context.Context, payment *Payment) error
 	UpdateStatus(ctx context.Context, id uuid.UUID, status Status) error
@@ -150,12 +183,13 @@ type Repository interface {
 
 // ListPaymentsParams holds parameters for listing payments
 type ListPaymentsParams struct {
-	Status    *Status
-	Provider  *Provider
-	StartDate *time.Time
-	EndDate   *time.Time
-	Page      int
-	Limit     int
+	Status       *Status
+	Provider     *Provider
+	RefundNeeded *bool
+	StartDate    *time.Time
+	EndDate      *time.Time
+	Page         int
+	Limit        int
 }
 
 // ListPaymentsResult holds the result of listing payments
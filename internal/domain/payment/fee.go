@@ -0,0 +1,198 @@
+package payment
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// FeeType represents how a provider charges for a payment method
+type FeeType string
+
+const (
+	FeeTypeFlat          FeeType = "flat"
+	FeeTypePercentage    FeeType = "percentage"
+	FeeTypePercentageMin FeeType = "percentage_min"
+)
+
+// FeeSource describes where the fee stored on a payment came from
+type FeeSource string
+
+const (
+	FeeSourceEstimated FeeSource = "estimated" // Calculated from the fee schedule
+	FeeSourceProvider  FeeSource = "provider"  // Reported by the provider in a webhook
+)
+
+// FeeRule describes the fee charged by a provider for a single payment method.
+// Percent is expressed in percent (0.7 means 0.7%). Flat is added on top of the
+// percentage for providers that charge both, and Minimum is the lowest fee charged.
+type FeeRule struct {
+	Type    FeeType `json:"type"`
+	Flat    int64   `json:"flat,omitempty"`
+	Percent float64 `json:"percent,omitempty"`
+	Minimum int64   `json:"minimum,omitempty"`
+}
+
+// Validate checks that the rule is consistent with its type
+func (r FeeRule) Validate() error {
+	if r.Flat < 0 || r.Percent < 0 || r.Minimum < 0 {
+		return fmt.Errorf("fee values must not be negative")
+	}
+	if r.Percent >= 100 {
+		return fmt.Errorf("fee percent must be below 100")
+	}
+
+	switch r.Type {
+	case FeeTypeFlat:
+		if r.Percent != 0 {
+			return fmt.Errorf("flat fee must not have a percent")
+		}
+	case FeeTypePercentage:
+		if r.Percent == 0 {
+			return fmt.Errorf("percentage fee requires a percent")
+		}
+	case FeeTypePercentageMin:
+		if r.Percent == 0 || r.Minimum == 0 {
+			return fmt.Errorf("percentage_min fee requires a percent and a minimum")
+		}
+	default:
+		return fmt.Errorf("unknown fee type: %s", r.Type)
+	}
+
+	return nil
+}
+
+// Calculate returns the fee charged for a gross amount
+func (r FeeRule) Calculate(amount int64) int64 {
+	if r.Type == "" || amount <= 0 {
+		return 0
+	}
+
+	fee := r.Flat
+	if r.Percent > 0 {
+		fee += int64(math.Ceil(float64(amount) * r.Percent / 100))
+	}
+	if fee < r.Minimum {
+		fee = r.Minimum
+	}
+
+	return fee
+}
+
+// GrossUp returns the smallest gross amount whose net amount after fees is at
+// least the given net amount. Used when the donor chooses to cover the fees.
+func (r FeeRule) GrossUp(net int64) int64 {
+	if r.Type == "" || net <= 0 {
+		return net
+	}
+
+	gross := net + r.Flat
+	if r.Percent > 0 {
+		gross = int64(math.Ceil(float64(net+r.Flat) * 100 / (100 - r.Percent)))
+	}
+	if gross < net+r.Minimum {
+		gross = net + r.Minimum
+	}
+
+	// Correct for rounding of the percentage part
+	for gross-r.Calculate(gross) < net {
+		gross++
+	}
+
+	return gross
+}
+
+// FeeSchedule maps provider and payment method to the fee rule that applies
+type FeeSchedule map[Provider]map[Method]FeeRule
+
+// Rule returns the fee rule for a provider and method. Methods without a rule
+// are treated as free.
+func (s FeeSchedule) Rule(provider Provider, method Method) FeeRule {
+	if methods, ok := s[provider]; ok {
+		return methods[method]
+	}
+	return FeeRule{}
+}
+
+// DefaultFeeSchedule returns the published standard rates of the supported providers
+func DefaultFeeSchedule() FeeSchedule {
+	va := FeeRule{Type: FeeTypeFlat, Flat: 4000}
+	ewallet := FeeRule{Type: FeeTypePercentage, Percent: 2}
+
+	return FeeSchedule{
+		ProviderMidtrans: {
+			MethodQRIS:      {Type: FeeTypePercentage, Percent: 0.7},
+			MethodGoPay:     ewallet,
+			MethodShopeePay: ewallet,
+			MethodVABCA:     va,
+			MethodVABNI:     va,
+			MethodVABRI:     va,
+			MethodVAPermata: va,
+			MethodVAMandiri: va,
+		},
+		ProviderXendit: {
+			MethodQRIS:      {Type: FeeTypePercentage, Percent: 0.7},
+			MethodOVO:       {Type: FeeTypePercentage, Percent: 1.5},
+			MethodDANA:      {Type: FeeTypePercentage, Percent: 1.5},
+			MethodShopeePay: {Type: FeeTypePercentage, Percent: 1.5},
+			MethodLinkAja:   {Type: FeeTypePercentage, Percent: 1.5},
+			MethodVABCA:     va,
+			MethodVABNI:     va,
+			MethodVABRI:     va,
+			MethodVAPermata: va,
+			MethodVAMandiri: va,
+		},
+		ProviderTripay: {
+			MethodQRIS:      {Type: FeeTypePercentage, Flat: 750, Percent: 0.7},
+			MethodOVO:       {Type: FeeTypePercentageMin, Percent: 3, Minimum: 1000},
+			MethodDANA:      {Type: FeeTypePercentageMin, Percent: 3, Minimum: 1000},
+			MethodShopeePay: {Type: FeeTypePercentageMin, Percent: 3, Minimum: 1000},
+			MethodVABCA:     {Type: FeeTypeFlat, Flat: 5500},
+			MethodVABNI:     va,
+			MethodVABRI:     va,
+			MethodVAPermata: va,
+			MethodVAMandiri: va,
+		},
+		ProviderDuitku: {
+			MethodQRIS:      {Type: FeeTypePercentage, Percent: 0.7},
+			MethodOVO:       {Type: FeeTypePercentage, Percent: 1.67},
+			MethodDANA:      {Type: FeeTypePercentage, Percent: 1.67},
+			MethodShopeePay: {Type: FeeTypePercentage, Percent: 2},
+			MethodLinkAja:   {Type: FeeTypePercentage, Percent: 1.67},
+			MethodVABCA:     {Type: FeeTypeFlat, Flat: 5000},
+			MethodVABNI:     {Type: FeeTypeFlat, Flat: 3000},
+			MethodVABRI:     {Type: FeeTypeFlat, Flat: 3000},
+			MethodVAPermata: {Type: FeeTypeFlat, Flat: 3000},
+			MethodVAMandiri: {Type: FeeTypeFlat, Flat: 3000},
+		},
+	}
+}
+
+// ParseFeeSchedule parses a JSON fee schedule of the form
+// {"provider": {"method": {"type": "percentage", "percent": 0.7}}} and merges it
+// over the default schedule. An empty string returns the default schedule.
+func ParseFeeSchedule(raw string) (FeeSchedule, error) {
+	schedule := DefaultFeeSchedule()
+	if raw == "" {
+		return schedule, nil
+	}
+
+	var overrides map[Provider]map[Method]FeeRule
+	if err := json.Unmarshal([]byte(raw), &overrides); err != nil {
+		return nil, fmt.Errorf("invalid fee schedule: %w", err)
+	}
+
+	for prov, methods := range overrides {
+		if schedule[prov] == nil {
+			schedule[prov] = make(map[Method]FeeRule)
+		}
+		for method, rule := range methods {
+			if err := rule.Validate(); err != nil {
+				return nil, fmt.Errorf("invalid fee rule for %s/%s: %w", prov, method, err)
+			}
+			schedule[prov][method] = rule
+		}
+	}
+
+	return schedule, nil
+}
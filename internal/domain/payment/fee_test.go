@@ -0,0 +1,109 @@
+package payment
+
+import "testing"
+
+var (
+	testFlat       = FeeRule{Type: FeeTypeFlat, Flat: 4000}
+	testPercent    = FeeRule{Type: FeeTypePercentage, Percent: 0.7}
+	testPercentOdd = FeeRule{Type: FeeTypePercentage, Percent: 1.67}
+	testFlatAndPct = FeeRule{Type: FeeTypePercentage, Flat: 750, Percent: 0.7}
+	testPercentMin = FeeRule{Type: FeeTypePercentageMin, Percent: 3, Minimum: 1000}
+)
+
+func TestFeeRuleCalculate(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   FeeRule
+		amount int64
+		want   int64
+	}{
+		{"flat", testFlat, 50000, 4000},
+		{"flat on a tiny amount", testFlat, 1, 4000},
+		{"percentage exact", testPercent, 10000, 70},
+		{"percentage rounds up", testPercent, 10001, 71},
+		{"percentage of one rupiah", testPercent, 1, 1},
+		{"fractional percent", testPercentOdd, 50000, 835},
+		{"flat plus percentage", testFlatAndPct, 10000, 820},
+		{"minimum applies", testPercentMin, 20000, 1000},
+		{"percentage above minimum", testPercentMin, 50000, 1500},
+		{"percentage above minimum rounds up", testPercentMin, 123457, 3704},
+		{"zero amount", testFlat, 0, 0},
+		{"negative amount", testPercentMin, -500, 0},
+		{"no rule", FeeRule{}, 50000, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Calculate(tt.amount); got != tt.want {
+				t.Errorf("Calculate(%d) = %d, want %d", tt.amount, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFeeRuleGrossUp(t *testing.T) {
+	tests := []struct {
+		name string
+		rule FeeRule
+		net  int64
+		want int64
+	}{
+		{"flat", testFlat, 10000, 14000},
+		{"percentage", testPercent, 10000, 10071},
+		{"percentage of one rupiah", testPercent, 1, 2},
+		{"fractional percent", testPercentOdd, 123457, 125554},
+		{"flat plus percentage", testFlatAndPct, 50000, 51108},
+		{"minimum applies", testPercentMin, 20000, 21000},
+		{"percentage above minimum", testPercentMin, 50000, 51547},
+		{"zero amount", testPercent, 0, 0},
+		{"negative amount", testPercentMin, -500, -500},
+		{"no rule", FeeRule{}, 50000, 50000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.rule.GrossUp(tt.net)
+			if got != tt.want {
+				t.Errorf("GrossUp(%d) = %d, want %d", tt.net, got, tt.want)
+			}
+
+			if tt.net <= 0 || tt.rule.Type == "" {
+				return
+			}
+			// The donor must cover the whole fee, and not a rupiah more
+			if net := got - tt.rule.Calculate(got); net < tt.net {
+				t.Errorf("GrossUp(%d) = %d nets only %d", tt.net, got, net)
+			}
+			if less := got - 1; less-tt.rule.Calculate(less) >= tt.net {
+				t.Errorf("GrossUp(%d) = %d is not the smallest, %d nets enough", tt.net, got, less)
+			}
+		})
+	}
+}
+
+func TestFeeRuleValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    FeeRule
+		wantErr bool
+	}{
+		{"flat", testFlat, false},
+		{"percentage", testPercent, false},
+		{"percentage with minimum", testPercentMin, false},
+		{"negative flat", FeeRule{Type: FeeTypeFlat, Flat: -1}, true},
+		{"negative percent", FeeRule{Type: FeeTypePercentage, Percent: -0.5}, true},
+		{"percent of 100", FeeRule{Type: FeeTypePercentage, Percent: 100}, true},
+		{"flat with percent", FeeRule{Type: FeeTypeFlat, Flat: 1000, Percent: 1}, true},
+		{"percentage without percent", FeeRule{Type: FeeTypePercentage}, true},
+		{"minimum missing", FeeRule{Type: FeeTypePercentageMin, Percent: 3}, true},
+		{"unknown type", FeeRule{Type: "tiered", Percent: 1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rule.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
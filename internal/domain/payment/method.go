@@ -0,0 +1,78 @@
+package payment
+
+import (
+	"context"
+	"time"
+)
+
+// MethodCategory groups payment methods for display
+type MethodCategory string
+
+const (
+	CategoryQRIS           MethodCategory = "qris"
+	CategoryEWallet        MethodCategory = "ewallet"
+	CategoryVirtualAccount MethodCategory = "virtual_account"
+)
+
+// MaintenanceWindow is a period during which a payment method cannot be used
+type MaintenanceWindow struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+// Contains checks if the given time falls inside the window
+func (w MaintenanceWindow) Contains(t time.Time) bool {
+	return !t.Before(w.StartsAt) && t.Before(w.EndsAt)
+}
+
+// MethodConfig holds the availability rules for a payment method
+type MethodConfig struct {
+	Method       Method              `json:"method"`
+	DisplayName  string              `json:"display_name"`
+	Category     MethodCategory      `json:"category"`
+	MinAmount    int64               `json:"min_amount"`
+	MaxAmount    int64               `json:"max_amount"`
+	Enabled      bool                `json:"enabled"`
+	DisplayOrder int                 `json:"display_order"`
+	Maintenance  []MaintenanceWindow `json:"maintenance_windows"`
+	UpdatedAt    time.Time           `json:"updated_at"`
+}
+
+// ActiveMaintenance returns the maintenance window covering the given time, if any
+func (m *MethodConfig) ActiveMaintenance(t time.Time) *MaintenanceWindow {
+	for i := range m.Maintenance {
+		if m.Maintenance[i].Contains(t) {
+			return &m.Maintenance[i]
+		}
+	}
+	return nil
+}
+
+// IsAvailable checks if the method is enabled and not under maintenance
+func (m *MethodConfig) IsAvailable(t time.Time) bool {
+	return m.Enabled && m.ActiveMaintenance(t) == nil
+}
+
+// AllowsAmount checks if the amount is within the method limits
+func (m *MethodConfig) AllowsAmount(amount int64) bool {
+	return amount >= m.MinAmount && amount <= m.MaxAmount
+}
+
+// PruneMaintenance drops maintenance windows that ended before the given time
+func (m *MethodConfig) PruneMaintenance(t time.Time) {
+	windows := m.Maintenance[:0]
+	for _, w := range m.Maintenance {
+		if w.EndsAt.After(t) {
+			windows = append(windows, w)
+		}
+	}
+	m.Maintenance = windows
+}
+
+// MethodRepository defines the payment method catalogue repository interface
+type MethodRepository interface {
+	List(ctx context.Context) ([]*MethodConfig, error)
+	GetByMethod(ctx context.Context, method Method) (*MethodConfig, error)
+	Update(ctx context.Context, config *MethodConfig) error
+}
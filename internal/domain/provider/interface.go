@@ -2,11 +2,15 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/reveegate/reveegate/internal/domain/payment"
 )
 
+// ErrCancelNotSupported is returned when a pending payment cannot be cancelled at the provider
+var ErrCancelNotSupported = errors.New("payment cancellation not supported")
+
 // PaymentRequest holds the request to create a payment
 type PaymentRequest struct {
 	OrderID       string
@@ -31,6 +35,13 @@ type PaymentResponse struct {
 	RawResponse   map[string]interface{}
 }
 
+// StatusRequest identifies a payment when querying its status from provider
+type StatusRequest struct {
+	OrderID       string
+	TransactionID string
+	PaymentMethod payment.Method
+}
+
 // PaymentStatus holds the payment status from provider
 type PaymentStatus struct {
 	OrderID         string
@@ -45,12 +56,14 @@ type PaymentStatus struct {
 
 // WebhookData holds parsed webhook data
 type WebhookData struct {
+	EventType       string
 	OrderID         string
 	TransactionID   string
 	TransactionTime time.Time
 	Status          payment.Status
 	Amount          int64
 	PaymentMethod   payment.Method
+	Fee             *int64 // Fee reported by the provider, nil when the callback carries none
 	RawPayload      map[string]interface{}
 }
 
@@ -69,7 +82,15 @@ type Provider interface {
 	ParseWebhook(payload []byte) (*WebhookData, error)
 
 	// GetPaymentStatus gets the payment status from provider
-	GetPaymentStatus(ctx context.Context, externalID string) (*PaymentStatus, error)
+	GetPaymentStatus(ctx context.Context, req StatusRequest) (*PaymentStatus, error)
+
+	// CancelPayment stops a pending payment from being paid. Returns
+	// ErrCancelNotSupported when the provider has no API for the method.
+	CancelPayment(ctx context.Context, req StatusRequest) error
+
+	// Ping checks the provider API can be reached. Any HTTP response counts
+	// as reachable; network errors and 5xx responses do not.
+	Ping(ctx context.Context) error
 
 	// GetSupportedMethods returns the supported payment methods
 	GetSupportedMethods() []payment.Method
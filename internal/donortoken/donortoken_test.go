@@ -0,0 +1,70 @@
+package donortoken
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestIssue(t *testing.T) {
+	s := NewSigner("donor-secret", 2*time.Hour)
+	now := time.Date(2026, 3, 14, 9, 30, 0, 750, time.UTC)
+	s.now = func() time.Time { return now }
+
+	token, expiresAt := s.Issue(uuid.New())
+
+	if want := time.Date(2026, 3, 14, 11, 30, 0, 0, time.UTC); !expiresAt.Equal(want) {
+		t.Errorf("expiresAt = %v, want %v", expiresAt, want)
+	}
+	if parts := strings.Split(token, "."); len(parts) != 3 || parts[0] != tokenVersion || parts[1] != strconv.FormatInt(expiresAt.Unix(), 10) {
+		t.Errorf("token = %q", token)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	donationID := uuid.MustParse("6f1c2a4e-8c55-4a5e-9a43-2a8f0c1b7d10")
+	issuedAt := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+
+	s := NewSigner("donor-secret", time.Hour)
+	s.now = func() time.Time { return issuedAt }
+	token, expiresAt := s.Issue(donationID)
+	parts := strings.Split(token, ".")
+
+	other := NewSigner("another-secret", time.Hour)
+	other.now = s.now
+	otherToken, _ := other.Issue(donationID)
+
+	tests := []struct {
+		name       string
+		token      string
+		donationID uuid.UUID
+		at         time.Time
+		want       error
+	}{
+		{"valid", token, donationID, issuedAt, nil},
+		{"valid until expiry", token, donationID, expiresAt, nil},
+		{"expired", token, donationID, expiresAt.Add(time.Second), ErrExpired},
+		{"wrong donation", token, uuid.MustParse("0b3e5c7a-1d2f-4e6a-8b9c-0d1e2f3a4b5c"), issuedAt, ErrInvalid},
+		{"missing", "", donationID, issuedAt, ErrMissing},
+		{"expiry extended", parts[0] + "." + strconv.FormatInt(expiresAt.Add(24*time.Hour).Unix(), 10) + "." + parts[2], donationID, expiresAt.Add(time.Hour), ErrInvalid},
+		{"tampered mac", parts[0] + "." + parts[1] + "." + strings.ToUpper(parts[2]), donationID, issuedAt, ErrInvalid},
+		{"truncated mac", parts[0] + "." + parts[1] + "." + parts[2][:10], donationID, issuedAt, ErrInvalid},
+		{"unknown version", "d2." + parts[1] + "." + parts[2], donationID, issuedAt, ErrInvalid},
+		{"extra segment", token + ".x", donationID, issuedAt, ErrInvalid},
+		{"signed with another secret", otherToken, donationID, issuedAt, ErrInvalid},
+		{"not a token", "not-a-token", donationID, issuedAt, ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.now = func() time.Time { return tt.at }
+			if err := s.Verify(tt.token, tt.donationID); !errors.Is(err, tt.want) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
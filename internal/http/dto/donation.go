@@ -13,6 +13,72 @@ type CreateDonationRequest struct {
 	Message       string `json:"message,omitempty" validate:"max=500"`
 	Amount        int64  `json:"amount" validate:"required,min=5000,max=100000000"`
 	PaymentMethod string `json:"payment_method" validate:"required,oneof=qris gopay dana ovo shopeepay linkaja va_bca va_bni va_mandiri va_bri va_permata"`
+	CoverFees     bool   `json:"cover_fees,omitempty"`
+}
+
+// CreatePaymentAttemptRequest represents a request to pay a donation with another method
+type CreatePaymentAttemptRequest struct {
+	PaymentMethod string `json:"payment_method" validate:"required,oneof=qris gopay dana ovo shopeepay linkaja va_bca va_bni va_mandiri va_bri va_permata"`
+	CoverFees     bool   `json:"cover_fees,omitempty"`
+}
+
+// FeeQuoteRequest represents the query for a fee quote
+type FeeQuoteRequest struct {
+	Amount        int64  `query:"amount" validate:"required,min=5000,max=100000000"`
+	PaymentMethod string `query:"payment_method" validate:"required,oneof=qris gopay dana ovo shopeepay linkaja va_bca va_bni va_mandiri va_bri va_permata"`
+	CoverFees     bool   `query:"cover_fees"`
+}
+
+// FeeQuoteResponse represents the fee breakdown shown before paying
+type FeeQuoteResponse struct {
+	Amount    int64 `json:"amount"`
+	Fee       int64 `json:"fee"`
+	Total     int64 `json:"total"`
+	NetAmount int64 `json:"net_amount"`
+	CoverFees bool  `json:"cover_fees"`
+}
+
+// ChallengeResponse describes the anti-abuse challenge to solve before creating a donation
+type ChallengeResponse struct {
+	Required   bool       `json:"required"`
+	Kind       string     `json:"kind,omitempty"` // pow, hcaptcha or turnstile
+	Token      string     `json:"token,omitempty"`
+	Difficulty int        `json:"difficulty,omitempty"`
+	SiteKey    string     `json:"site_key,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+// ChallengeRequiredResponse is returned when a donation needs a challenge solved first
+type ChallengeRequiredResponse struct {
+	Error     string            `json:"error"`
+	Message   string            `json:"message"`
+	Challenge ChallengeResponse `json:"challenge"`
+}
+
+// PaymentMethodResponse represents a payment method available to donors
+type PaymentMethodResponse struct {
+	Method      string `json:"method"`
+	DisplayName string `json:"display_name"`
+	Category    string `json:"category"`
+	MinAmount   int64  `json:"min_amount"`
+	MaxAmount   int64  `json:"max_amount"`
+}
+
+// MaintenanceWindowRequest represents a maintenance window in an update request
+type MaintenanceWindowRequest struct {
+	StartsAt time.Time `json:"starts_at" validate:"required"`
+	EndsAt   time.Time `json:"ends_at" validate:"required"`
+	Reason   string    `json:"reason,omitempty" validate:"max=255"`
+}
+
+// UpdatePaymentMethodRequest represents an admin update to a payment method
+type UpdatePaymentMethodRequest struct {
+	DisplayName  *string                     `json:"display_name,omitempty" validate:"omitempty,min=1,max=100"`
+	MinAmount    *int64                      `json:"min_amount,omitempty" validate:"omitempty,min=5000,max=100000000"`
+	MaxAmount    *int64                      `json:"max_amount,omitempty" validate:"omitempty,min=5000,max=100000000"`
+	Enabled      *bool                       `json:"enabled,omitempty"`
+	DisplayOrder *int                        `json:"display_order,omitempty" validate:"omitempty,min=0"`
+	Maintenance  *[]MaintenanceWindowRequest `json:"maintenance_windows,omitempty" validate:"omitempty,dive"`
 }
 
 // DonationResponse represents the response after creating a donation
@@ -27,6 +93,9 @@ type DonationResponse struct {
 	CreatedAt   time.Time    `json:"created_at"`
 	PaidAt      *time.Time   `json:"paid_at,omitempty"`
 	PaymentInfo *PaymentInfo `json:"payment_info,omitempty"`
+	// Lets the donor follow this donation; only returned when paying
+	AccessToken          string     `json:"access_token,omitempty"`
+	AccessTokenExpiresAt *time.Time `json:"access_token_expires_at,omitempty"`
 }
 
 // PaymentInfo represents payment details in donation response
@@ -35,6 +104,9 @@ type PaymentInfo struct {
 	Provider       string    `json:"provider"`
 	Method         string    `json:"method"`
 	Status         string    `json:"status"`
+	Amount         int64     `json:"amount"`
+	FeeAmount      int64     `json:"fee_amount"`
+	FeeCovered     bool      `json:"fee_covered"`
 	QRCodeURL      string    `json:"qr_code_url,omitempty"`
 	VANumber       string    `json:"va_number,omitempty"`
 	DeepLink       string    `json:"deep_link,omitempty"`
@@ -84,6 +156,9 @@ type DonationStatsResponse struct {
 	CompletedCount int64   `json:"completed_count"`
 	PendingCount   int64   `json:"pending_count"`
 	FailedCount    int64   `json:"failed_count"`
+	GrossAmount    int64   `json:"gross_amount"`
+	TotalFees      int64   `json:"total_fees"`
+	NetAmount      int64   `json:"net_amount"`
 }
 
 // MidtransWebhook represents the webhook payload from Midtrans
@@ -100,19 +175,6 @@ type MidtransWebhook struct {
 	FraudStatus       string `json:"fraud_status,omitempty"`
 }
 
-// XenditWebhook represents the webhook payload from Xendit
-type XenditWebhook struct {
-	ID             string `json:"id"`
-	ExternalID     string `json:"external_id"`
-	PayerEmail     string `json:"payer_email,omitempty"`
-	Description    string `json:"description,omitempty"`
-	Amount         int64  `json:"amount"`
-	Status         string `json:"status"`
-	PaidAt         string `json:"paid_at,omitempty"`
-	PaymentMethod  string `json:"payment_method"`
-	PaymentChannel string `json:"payment_channel,omitempty"`
-}
-
 // ReconcilePaymentRequest represents the request to manually reconcile a payment
 type ReconcilePaymentRequest struct {
 	Action string `json:"action" validate:"required,oneof=mark_as_paid mark_as_failed"`
@@ -127,7 +189,7 @@ type ReconcilePaymentResponse struct {
 
 // ListWebhookLogsRequest represents the request to list webhook logs
 type ListWebhookLogsRequest struct {
-	Provider  string     `query:"provider" validate:"omitempty,oneof=midtrans xendit"`
+	Provider  string     `query:"provider" validate:"omitempty,oneof=midtrans xendit tripay duitku"`
 	Processed *bool      `query:"processed"`
 	StartDate *time.Time `query:"start_date"`
 	EndDate   *time.Time `query:"end_date"`
@@ -154,6 +216,68 @@ type ListWebhookLogsResponse struct {
 	Pagination PaginationResponse   `json:"pagination"`
 }
 
+// CreateAPIKeyRequest represents a request to create an API key
+type CreateAPIKeyRequest struct {
+	Name               string     `json:"name" validate:"required,min=3,max=100"`
+	Scopes             []string   `json:"scopes" validate:"required,min=1,dive,oneof=donations:read stats:read overlay:control"`
+	ExpiresAt          *time.Time `json:"expires_at"`
+	RateLimitPerMinute *int       `json:"rate_limit_per_minute" validate:"omitempty,min=1,max=100000"`
+}
+
+// APIKeyResponse represents an API key without the key itself
+type APIKeyResponse struct {
+	ID                 uuid.UUID  `json:"id"`
+	Name               string     `json:"name"`
+	Prefix             string     `json:"prefix"`
+	Scopes             []string   `json:"scopes"`
+	RateLimitPerMinute int        `json:"rate_limit_per_minute"`
+	CreatedBy          *uuid.UUID `json:"created_by,omitempty"`
+	ExpiresAt          *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt         *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP         string     `json:"last_used_ip,omitempty"`
+	RevokedAt          *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+// CreateAPIKeyResponse is returned once when an API key is created
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+// AuditLogResponse represents an audit trail entry
+type AuditLogResponse struct {
+	ID           uuid.UUID              `json:"id"`
+	Sequence     int64                  `json:"sequence"`
+	ActorID      *uuid.UUID             `json:"actor_id,omitempty"`
+	ActorName    string                 `json:"actor_name,omitempty"`
+	Action       string                 `json:"action"`
+	ResourceType string                 `json:"resource_type,omitempty"`
+	ResourceID   *uuid.UUID             `json:"resource_id,omitempty"`
+	Changes      map[string]interface{} `json:"changes,omitempty"`
+	IPAddress    string                 `json:"ip_address,omitempty"`
+	UserAgent    string                 `json:"user_agent,omitempty"`
+	Hash         string                 `json:"hash,omitempty"`
+	CreatedAt    time.Time              `json:"created_at"`
+}
+
+// ListAuditLogsResponse represents the response for listing audit logs
+type ListAuditLogsResponse struct {
+	Logs       []AuditLogResponse `json:"logs"`
+	Pagination PaginationResponse `json:"pagination"`
+}
+
+// AuditChainResponse represents the result of verifying the audit hash chain
+type AuditChainResponse struct {
+	Valid        bool   `json:"valid"`
+	Checked      int64  `json:"checked"`
+	Unhashed     int64  `json:"unhashed"`
+	BrokenAt     *int64 `json:"broken_at,omitempty"`
+	Reason       string `json:"reason,omitempty"`
+	HeadSequence int64  `json:"head_sequence"`
+	HeadHash     string `json:"head_hash,omitempty"`
+}
+
 // AdminLoginRequest represents admin login request
 type AdminLoginRequest struct {
 	Email    string `json:"email" validate:"omitempty,email,max=100"`
@@ -163,11 +287,62 @@ type AdminLoginRequest struct {
 
 // AdminLoginResponse represents admin login response
 type AdminLoginResponse struct {
-	AccessToken  string    `json:"access_token"`
-	RefreshToken string    `json:"refresh_token"`
-	ExpiresIn    int       `json:"expires_in"`
-	TokenType    string    `json:"token_type"`
-	ExpiresAt    time.Time `json:"expires_at,omitempty"`
+	AccessToken   string    `json:"access_token"`
+	RefreshToken  string    `json:"refresh_token"`
+	ExpiresIn     int       `json:"expires_in"`
+	TokenType     string    `json:"token_type"`
+	ExpiresAt     time.Time `json:"expires_at,omitempty"`
+	Role          string    `json:"role,omitempty"`
+	Permissions   []string  `json:"permissions,omitempty"`
+	RecoveryCodes []string  `json:"recovery_codes,omitempty"`
+}
+
+// MFAChallengeResponse is returned by login when a second factor is required
+type MFAChallengeResponse struct {
+	MFARequired        bool   `json:"mfa_required"`
+	EnrollmentRequired bool   `json:"enrollment_required"`
+	MFAToken           string `json:"mfa_token"`
+	ExpiresIn          int    `json:"expires_in"`
+}
+
+// MFALoginRequest completes a login with a TOTP or recovery code
+type MFALoginRequest struct {
+	MFAToken     string `json:"mfa_token" validate:"required"`
+	Code         string `json:"code" validate:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+	RecoveryCode string `json:"recovery_code" validate:"omitempty,max=32"`
+}
+
+// MFAChallengeRequest starts enrollment during login
+type MFAChallengeRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+}
+
+// MFACodeRequest carries a TOTP code
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// MFAEnrollmentResponse contains the secret to add to an authenticator app
+type MFAEnrollmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// MFAStatusResponse represents the 2FA state of the current admin
+type MFAStatusResponse struct {
+	Enabled                bool `json:"enabled"`
+	Required               bool `json:"required"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+// MFAPolicyRequest turns 2FA enforcement on or off
+type MFAPolicyRequest struct {
+	Required *bool `json:"required" validate:"required"`
+}
+
+// RecoveryCodesResponse contains newly issued recovery codes
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
 }
 
 // RefreshTokenRequest represents a refresh token request
@@ -175,6 +350,76 @@ type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
+// SessionResponse represents an active admin session
+type SessionResponse struct {
+	ID              string    `json:"id"`
+	IPAddress       string    `json:"ip_address,omitempty"`
+	UserAgent       string    `json:"user_agent,omitempty"`
+	AuthenticatedAt time.Time `json:"authenticated_at"`
+	LastUsedAt      time.Time `json:"last_used_at"`
+	ExpiresAt       time.Time `json:"expires_at"`
+	Current         bool      `json:"current"`
+}
+
+// AdminUserResponse represents an admin account
+type AdminUserResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Username    string     `json:"username"`
+	Email       string     `json:"email"`
+	Role        string     `json:"role"`
+	IsActive    bool       `json:"is_active"`
+	MFAEnabled  bool       `json:"mfa_enabled"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// CreateAdminUserRequest creates an admin directly with a password
+type CreateAdminUserRequest struct {
+	Username string `json:"username" validate:"required,min=3,max=100"`
+	Email    string `json:"email" validate:"required,email,max=100"`
+	Password string `json:"password" validate:"required,min=8,max=100"`
+	Role     string `json:"role" validate:"required,oneof=owner moderator finance viewer"`
+}
+
+// InviteAdminRequest invites a new admin by email
+type InviteAdminRequest struct {
+	Email string `json:"email" validate:"required,email,max=100"`
+	Role  string `json:"role" validate:"required,oneof=owner moderator finance viewer"`
+}
+
+// InviteAdminResponse contains the single-use invitation link
+type InviteAdminResponse struct {
+	ID          uuid.UUID `json:"id"`
+	Email       string    `json:"email"`
+	Role        string    `json:"role"`
+	InviteToken string    `json:"invite_token"`
+	InviteURL   string    `json:"invite_url"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// AcceptInvitationRequest completes an invitation
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Username string `json:"username" validate:"required,min=3,max=100"`
+	Password string `json:"password" validate:"required,min=8,max=100"`
+}
+
+// UpdateAdminUserRequest changes the role of an admin
+type UpdateAdminUserRequest struct {
+	Role string `json:"role" validate:"required,oneof=owner moderator finance viewer"`
+}
+
+// ResetAdminPasswordRequest sets a new password for an admin
+type ResetAdminPasswordRequest struct {
+	Password string `json:"password" validate:"required,min=8,max=100"`
+	ResetMFA bool   `json:"reset_mfa"`
+}
+
+// UnlockIPRequest lifts a login lockout on an IP address
+type UnlockIPRequest struct {
+	IP string `json:"ip" validate:"required,ip"`
+}
+
 // ErrorResponse represents an error response
 type ErrorResponse struct {
 	Error   string `json:"error"`
@@ -191,10 +436,21 @@ type SuccessResponse struct {
 
 // HealthResponse represents a health check response
 type HealthResponse struct {
-	Status    string            `json:"status"`
-	Version   string            `json:"version,omitempty"`
-	Timestamp string            `json:"timestamp"`
-	Services  map[string]string `json:"services,omitempty"`
+	Status     string                             `json:"status"`
+	Version    string                             `json:"version,omitempty"`
+	Timestamp  string                             `json:"timestamp"`
+	Services   map[string]string                  `json:"services,omitempty"`
+	Components map[string]ComponentHealthResponse `json:"components,omitempty"`
+}
+
+// ComponentHealthResponse represents the detailed health of one dependency
+type ComponentHealthResponse struct {
+	Status    string                 `json:"status"`
+	Critical  bool                   `json:"critical"`
+	LatencyMs float64                `json:"latency_ms"`
+	Error     string                 `json:"error,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	CheckedAt time.Time              `json:"checked_at"`
 }
 
 // OverlayTokenRequest represents a request to create an overlay token
@@ -211,3 +467,110 @@ type OverlayTokenResponse struct {
 	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
 }
+
+// RealtimePresenceResponse lists realtime clients across all instances
+type RealtimePresenceResponse struct {
+	InstanceID    string                     `json:"instance_id"` // Instance that served the request
+	TotalClients  int                        `json:"total_clients"`
+	TotalOverlays int                        `json:"total_overlays"`
+	Instances     []InstancePresenceResponse `json:"instances"`
+}
+
+// InstancePresenceResponse lists the realtime clients of one instance
+type InstancePresenceResponse struct {
+	InstanceID string                   `json:"instance_id"`
+	LastSeen   time.Time                `json:"last_seen"`
+	Clients    []RealtimeClientResponse `json:"clients"`
+}
+
+// RealtimeClientResponse represents a connected overlay or admin client
+type RealtimeClientResponse struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	Channel     string    `json:"channel"`
+	UserID      string    `json:"user_id,omitempty"`
+	ConnectedAt time.Time `json:"connected_at"`
+}
+
+// CreateDonorBlockRequest represents a request to block a donor
+type CreateDonorBlockRequest struct {
+	Type      string     `json:"type" validate:"required,oneof=email ip name_pattern fingerprint"`
+	Value     string     `json:"value" validate:"required,max=255"`
+	Reason    string     `json:"reason,omitempty" validate:"max=500"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// DonorBlockResponse represents a donor block
+type DonorBlockResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	Type      string     `json:"type"`
+	Value     string     `json:"value"`
+	Reason    string     `json:"reason,omitempty"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// FraudRuleParams holds the thresholds of a fraud rule
+type FraudRuleParams struct {
+	Threshold    int    `json:"threshold,omitempty" validate:"min=0"`
+	Window       string `json:"window,omitempty" validate:"max=20"`
+	MinAmount    int64  `json:"min_amount,omitempty" validate:"min=0"`
+	NewDonorOnly bool   `json:"new_donor_only,omitempty"`
+}
+
+// FraudRuleRequest represents a request to create or replace a fraud rule
+type FraudRuleRequest struct {
+	Name      string          `json:"name" validate:"required,min=3,max=100"`
+	Stage     string          `json:"stage" validate:"required,oneof=create completion"`
+	Condition string          `json:"condition" validate:"required,oneof=pending_per_ip large_amount duplicate_message donor_velocity"`
+	Params    FraudRuleParams `json:"params"`
+	Action    string          `json:"action" validate:"required,oneof=flag hold challenge block"`
+	Enabled   *bool           `json:"enabled"`
+}
+
+// FraudRuleResponse represents a fraud rule
+type FraudRuleResponse struct {
+	ID        uuid.UUID       `json:"id"`
+	Name      string          `json:"name"`
+	Stage     string          `json:"stage"`
+	Condition string          `json:"condition"`
+	Params    FraudRuleParams `json:"params"`
+	Action    string          `json:"action"`
+	Enabled   bool            `json:"enabled"`
+	CreatedBy *uuid.UUID      `json:"created_by,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// FraudMatchResponse represents a block or rule that applied to a donation
+type FraudMatchResponse struct {
+	BlockID *uuid.UUID `json:"block_id,omitempty"`
+	RuleID  *uuid.UUID `json:"rule_id,omitempty"`
+	Action  string     `json:"action"`
+	Reason  string     `json:"reason"`
+}
+
+// FraudDecisionResponse represents a logged fraud decision
+type FraudDecisionResponse struct {
+	ID           uuid.UUID            `json:"id"`
+	Stage        string               `json:"stage"`
+	Action       string               `json:"action"`
+	DonationID   *uuid.UUID           `json:"donation_id,omitempty"`
+	PaymentID    *uuid.UUID           `json:"payment_id,omitempty"`
+	DonorName    string               `json:"donor_name,omitempty"`
+	DonorEmail   string               `json:"donor_email,omitempty"`
+	ClientIP     string               `json:"client_ip,omitempty"`
+	Amount       int64                `json:"amount"`
+	Matches      []FraudMatchResponse `json:"matches"`
+	ReviewStatus string               `json:"review_status,omitempty"`
+	ReviewedBy   *uuid.UUID           `json:"reviewed_by,omitempty"`
+	ReviewedAt   *time.Time           `json:"reviewed_at,omitempty"`
+	CreatedAt    time.Time            `json:"created_at"`
+}
+
+// ListFraudDecisionsResponse represents the response for listing fraud decisions
+type ListFraudDecisionsResponse struct {
+	Decisions  []FraudDecisionResponse `json:"decisions"`
+	Pagination PaginationResponse      `json:"pagination"`
+}
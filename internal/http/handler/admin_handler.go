@@ -1,14 +1,22 @@
 package handler
 
 import (
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"log/slog"
+	"math"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 
+	"github.com/reveegate/reveegate/internal/domain/donation"
 	"github.com/reveegate/reveegate/internal/domain/payment"
 	"github.com/reveegate/reveegate/internal/http/dto"
 	"github.com/reveegate/reveegate/internal/http/middleware"
@@ -19,6 +27,11 @@ import (
 // AdminHandler handles admin HTTP requests
 type AdminHandler struct {
 	donationService *service.DonationService
+	sessionService  *service.SessionService
+	mfaService      *service.MFAService
+	adminUsers      *service.AdminUserService
+	loginGuard      *service.LoginGuard
+	audit           *service.AuditService
 	adminRepo       *postgres.AdminRepository
 	authMiddleware  *middleware.Auth
 	validator       *validator.Validate
@@ -28,6 +41,11 @@ type AdminHandler struct {
 // NewAdminHandler creates a new admin handler
 func NewAdminHandler(
 	donationService *service.DonationService,
+	sessionService *service.SessionService,
+	mfaService *service.MFAService,
+	adminUsers *service.AdminUserService,
+	loginGuard *service.LoginGuard,
+	audit *service.AuditService,
 	adminRepo *postgres.AdminRepository,
 	authMiddleware *middleware.Auth,
 	validator *validator.Validate,
@@ -35,6 +53,11 @@ func NewAdminHandler(
 ) *AdminHandler {
 	return &AdminHandler{
 		donationService: donationService,
+		sessionService:  sessionService,
+		mfaService:      mfaService,
+		adminUsers:      adminUsers,
+		loginGuard:      loginGuard,
+		audit:           audit,
 		adminRepo:       adminRepo,
 		authMiddleware:  authMiddleware,
 		validator:       validator,
@@ -61,33 +84,43 @@ func (h *AdminHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var admin *postgres.AdminUser
 	var err error
 
+	attempt := service.LoginAttempt{
+		IP:        clientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+
 	if req.Email != "" {
+		attempt.Identifier = req.Email
 		admin, err = h.adminRepo.FindByEmail(r.Context(), req.Email)
 	} else if req.Username != "" {
+		attempt.Identifier = req.Username
 		admin, err = h.adminRepo.FindByUsername(r.Context(), req.Username)
 	} else {
 		h.respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Email or username is required")
 		return
 	}
 
-	if err != nil {
-		if err == postgres.ErrAdminNotFound {
-			h.respondError(w, http.StatusUnauthorized, "INVALID_CREDENTIALS", "Invalid email/username or password")
-			return
-		}
+	if err != nil && err != postgres.ErrAdminNotFound {
 		h.logger.Error("failed to find admin user", "error", err)
 		h.respondError(w, http.StatusInternalServerError, "SERVER_ERROR", "Internal server error")
 		return
 	}
+	if admin != nil {
+		attempt.AdminID = &admin.ID
+	}
 
-	// Check if admin is active
-	if !admin.IsActive {
-		h.respondError(w, http.StatusForbidden, "ACCOUNT_INACTIVE", "Your account has been deactivated")
+	// Count the attempt before checking the password, rejecting locked out
+	// or throttled ones
+	reservation, wait, err := h.loginGuard.Reserve(r.Context(), attempt)
+	if err != nil {
+		respondLoginBlocked(w, wait, err, h.respondError)
 		return
 	}
 
-	// Verify password using pgcrypto
-	matches, err := h.adminRepo.VerifyPassword(r.Context(), admin.PasswordHash, req.Password)
+	// Verify password, upgrading legacy hashes. Unknown accounts are checked
+	// against a dummy hash, and every failure gets the same response, so
+	// neither reveals which accounts exist.
+	matches, err := h.adminUsers.VerifyPassword(r.Context(), admin, req.Password)
 	if err != nil {
 		h.logger.Error("failed to verify password", "error", err)
 		h.respondError(w, http.StatusInternalServerError, "SERVER_ERROR", "Internal server error")
@@ -95,20 +128,56 @@ func (h *AdminHandler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if !matches {
+		h.loginGuard.RecordFailure(r.Context(), reservation)
+		if admin != nil {
+			h.audit.Record(r.Context(), loginFailureEntry(r, admin.ID, "invalid_password"))
+		}
 		h.respondError(w, http.StatusUnauthorized, "INVALID_CREDENTIALS", "Invalid email/username or password")
 		return
 	}
 
+	// Only report a deactivated account to someone who knows its password
+	if !admin.IsActive {
+		h.loginGuard.Release(r.Context(), reservation)
+		h.audit.Record(r.Context(), loginFailureEntry(r, admin.ID, "account_inactive"))
+		h.respondError(w, http.StatusForbidden, "ACCOUNT_INACTIVE", "Your account has been deactivated")
+		return
+	}
+
+	// Ask for the second factor before issuing tokens
+	challenge, err := h.mfaService.BeginLogin(r.Context(), admin)
+	if err != nil {
+		h.logger.Error("failed to start MFA challenge", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "SERVER_ERROR", "Internal server error")
+		return
+	}
+
+	if challenge != nil {
+		// The password was right; wrong codes are counted by the MFA step
+		h.loginGuard.Release(r.Context(), reservation)
+		h.respondJSON(w, http.StatusOK, dto.MFAChallengeResponse{
+			MFARequired:        true,
+			EnrollmentRequired: challenge.EnrollmentRequired,
+			MFAToken:           challenge.Token,
+			ExpiresIn:          int(time.Until(challenge.ExpiresAt).Seconds()),
+		})
+		return
+	}
+
+	// The account counter is only reset once the login is complete, so a
+	// known password cannot be used to reset failed 2FA attempts
+	h.loginGuard.RecordSuccess(r.Context(), reservation)
+
 	// Update last login timestamp
 	if err := h.adminRepo.UpdateLastLogin(r.Context(), admin.ID); err != nil {
 		h.logger.Error("failed to update last login", "error", err)
 		// Non-critical error, continue
 	}
 
-	// Generate tokens
-	accessToken, refreshToken, err := h.authMiddleware.GenerateTokens(admin.Email, "admin")
+	// Start a server-side session
+	tokens, err := h.sessionService.Start(r.Context(), admin, clientIP(r), r.UserAgent())
 	if err != nil {
-		h.logger.Error("failed to generate tokens", "error", err)
+		h.logger.Error("failed to start session", "error", err)
 		h.respondError(w, http.StatusInternalServerError, "TOKEN_ERROR", "Failed to generate tokens")
 		return
 	}
@@ -117,90 +186,218 @@ func (h *AdminHandler) Login(w http.ResponseWriter, r *http.Request) {
 		"admin_id", admin.ID,
 		"username", admin.Username,
 		"email", admin.Email,
+		"role", admin.Role,
+		"session_id", tokens.SessionID,
 	)
 
-	h.respondJSON(w, http.StatusOK, dto.AdminLoginResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    900, // 15 minutes
-		TokenType:    "Bearer",
-	})
+	h.audit.Record(r.Context(), loginSuccessEntry(r, admin.ID, tokens.SessionID, false))
+
+	h.respondJSON(w, http.StatusOK, buildLoginResponse(tokens))
+}
+
+// loginSuccessEntry records a completed login by an admin
+func loginSuccessEntry(r *http.Request, adminID, sessionID uuid.UUID, mfa bool) service.AuditEntry {
+	entry := auditEntry(r, service.AuditActionLogin, service.AuditResourceAdminUser, &adminID)
+	entry.ActorID = &adminID
+	entry.Details = map[string]interface{}{
+		"session_id": sessionID,
+		"mfa":        mfa,
+	}
+	return entry
+}
+
+// loginFailureEntry records a failed login against an existing admin. The
+// actor is unknown, so only the targeted account is stored.
+func loginFailureEntry(r *http.Request, adminID uuid.UUID, reason string) service.AuditEntry {
+	entry := auditEntry(r, service.AuditActionLoginFailed, service.AuditResourceAdminUser, &adminID)
+	entry.Details = map[string]interface{}{
+		"reason": reason,
+	}
+	return entry
 }
 
 // RefreshToken handles POST /api/v1/admin/refresh
 func (h *AdminHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		RefreshToken string `json:"refresh_token" validate:"required"`
-	}
+	var req dto.RefreshTokenRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.respondError(w, http.StatusBadRequest, "INVALID_JSON", "Invalid request body")
 		return
 	}
 
-	// Validate refresh token
-	claims, err := h.authMiddleware.ValidateToken(req.RefreshToken)
+	if err := h.validator.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		h.respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", formatValidationErrors(validationErrors))
+		return
+	}
+
+	tokens, err := h.sessionService.Refresh(r.Context(), req.RefreshToken, clientIP(r), r.UserAgent())
 	if err != nil {
-		h.respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Invalid or expired refresh token")
+		switch {
+		case errors.Is(err, service.ErrInvalidRefreshToken):
+			h.respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Invalid or expired refresh token")
+		case errors.Is(err, service.ErrRefreshTokenReused):
+			h.respondError(w, http.StatusUnauthorized, "TOKEN_REUSED", "Refresh token was already used, please log in again")
+		case errors.Is(err, service.ErrAccountInactive):
+			h.respondError(w, http.StatusForbidden, "ACCOUNT_INACTIVE", "Your account has been deactivated")
+		default:
+			h.logger.Error("failed to refresh session", "error", err)
+			h.respondError(w, http.StatusInternalServerError, "TOKEN_ERROR", "Failed to generate tokens")
+		}
 		return
 	}
 
-	// Generate new tokens
-	accessToken, refreshToken, err := h.authMiddleware.GenerateTokens(claims.Subject, claims.Role)
+	h.respondJSON(w, http.StatusOK, buildLoginResponse(tokens))
+}
+
+// Logout handles POST /api/v1/admin/logout
+func (h *AdminHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r.Context())
+	sessionID, err := uuid.Parse(claims.SessionID)
 	if err != nil {
-		h.logger.Error("failed to generate tokens", "error", err)
-		h.respondError(w, http.StatusInternalServerError, "TOKEN_ERROR", "Failed to generate tokens")
+		h.respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Token is not bound to a session")
 		return
 	}
 
-	h.respondJSON(w, http.StatusOK, dto.AdminLoginResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    900,
-		TokenType:    "Bearer",
-	})
+	if err := h.sessionService.Logout(r.Context(), sessionID); err != nil {
+		h.logger.Error("failed to logout", "session_id", sessionID, "error", err)
+		h.respondError(w, http.StatusInternalServerError, "LOGOUT_FAILED", "Failed to logout")
+		return
+	}
+
+	h.logger.Info("admin logged out", "admin", claims.Subject, "session_id", sessionID)
+
+	w.WriteHeader(http.StatusNoContent)
 }
 
-// GetDashboard handles GET /api/v1/admin/dashboard
-func (h *AdminHandler) GetDashboard(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+// LogoutAll handles POST /api/v1/admin/logout-all
+func (h *AdminHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r.Context())
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		h.respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Invalid user in token")
+		return
+	}
+
+	revoked, err := h.sessionService.LogoutAll(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to logout everywhere", "user_id", userID, "error", err)
+		h.respondError(w, http.StatusInternalServerError, "LOGOUT_FAILED", "Failed to logout")
+		return
+	}
+
+	entry := auditEntry(r, service.AuditActionLogoutAll, service.AuditResourceAdminUser, &userID)
+	entry.Details = map[string]interface{}{"sessions_revoked": revoked}
+	h.audit.Record(r.Context(), entry)
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"sessions_revoked": revoked,
+	})
+}
 
-	// Get today's stats
-	now := time.Now()
-	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+// ListSessions handles GET /api/v1/admin/sessions
+func (h *AdminHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r.Context())
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		h.respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Invalid user in token")
+		return
+	}
 
-	todayStats, err := h.donationService.GetDonationStats(ctx, todayStart, now)
+	sessions, err := h.sessionService.ListActive(r.Context(), userID)
 	if err != nil {
-		h.logger.Error("failed to get today stats", "error", err)
+		h.logger.Error("failed to list sessions", "user_id", userID, "error", err)
+		h.respondError(w, http.StatusInternalServerError, "LIST_FAILED", "Failed to list sessions")
+		return
 	}
 
-	// Get this month's stats
-	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	response := make([]dto.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		response[i] = dto.SessionResponse{
+			ID:              session.ID.String(),
+			IPAddress:       session.IPAddress,
+			UserAgent:       session.UserAgent,
+			AuthenticatedAt: session.AuthenticatedAt,
+			LastUsedAt:      session.LastUsedAt,
+			ExpiresAt:       session.ExpiresAt,
+			Current:         session.ID.String() == claims.SessionID,
+		}
+	}
 
-	monthStats, err := h.donationService.GetDonationStats(ctx, monthStart, now)
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"sessions": response,
+	})
+}
+
+// RevokeSession handles DELETE /api/v1/admin/sessions/{id}
+func (h *AdminHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r.Context())
+	userID, err := uuid.Parse(claims.UserID)
 	if err != nil {
-		h.logger.Error("failed to get month stats", "error", err)
+		h.respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Invalid user in token")
+		return
 	}
 
-	response := map[string]interface{}{
-		"today": map[string]interface{}{
-			"total_donations":     todayStats.TotalDonations,
-			"total_amount":        todayStats.TotalAmount,
-			"completed_donations": todayStats.CompletedDonations,
-			"completed_amount":    todayStats.CompletedAmount,
-			"average_amount":      todayStats.AverageAmount,
-		},
-		"month": map[string]interface{}{
-			"total_donations":     monthStats.TotalDonations,
-			"total_amount":        monthStats.TotalAmount,
-			"completed_donations": monthStats.CompletedDonations,
-			"completed_amount":    monthStats.CompletedAmount,
-			"average_amount":      monthStats.AverageAmount,
-		},
-		"timestamp": now.Format(time.RFC3339),
+	sessionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "INVALID_ID", "Invalid session ID")
+		return
 	}
 
-	h.respondJSON(w, http.StatusOK, response)
+	if err := h.sessionService.Revoke(r.Context(), userID, sessionID); err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			h.respondError(w, http.StatusNotFound, "NOT_FOUND", "Session not found")
+			return
+		}
+		h.logger.Error("failed to revoke session", "session_id", sessionID, "error", err)
+		h.respondError(w, http.StatusInternalServerError, "REVOKE_FAILED", "Failed to revoke session")
+		return
+	}
+
+	h.audit.Record(r.Context(), auditEntry(r, service.AuditActionSessionRevoke, service.AuditResourceSession, &sessionID))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// buildLoginResponse builds the token response for login and refresh
+func buildLoginResponse(tokens *service.TokenPair) dto.AdminLoginResponse {
+	return dto.AdminLoginResponse{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    int(time.Until(tokens.ExpiresAt).Seconds()),
+		TokenType:    "Bearer",
+		ExpiresAt:    tokens.ExpiresAt,
+		Role:         tokens.Role,
+		Permissions:  permissionNames(middleware.Role(tokens.Role)),
+	}
+}
+
+// permissionNames lists the permissions of a role for the admin UI
+func permissionNames(role middleware.Role) []string {
+	perms := middleware.Permissions(role)
+	names := make([]string, len(perms))
+	for i, p := range perms {
+		names[i] = string(p)
+	}
+	return names
+}
+
+// JWKS handles GET /.well-known/jwks.json
+func (h *AdminHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	h.respondJSON(w, http.StatusOK, h.authMiddleware.JWKS())
+}
+
+// GetDashboard handles GET /api/v1/admin/dashboard
+func (h *AdminHandler) GetDashboard(w http.ResponseWriter, r *http.Request) {
+	dashboard, err := h.donationService.GetDashboard(r.Context())
+	if err != nil {
+		h.logger.Error("failed to get dashboard", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "DASHBOARD_FAILED", "Failed to get dashboard")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, dashboard)
 }
 
 // ReconcilePayment handles POST /api/v1/admin/reconcile
@@ -238,7 +435,7 @@ func (h *AdminHandler) ReconcilePayment(w http.ResponseWriter, r *http.Request)
 
 	reason := req.Reason + " (by " + claims.Subject + ")"
 
-	err = h.donationService.ManualReconcile(r.Context(), paymentID, status, reason)
+	result, err := h.donationService.ManualReconcile(r.Context(), paymentID, status, reason)
 	if err != nil {
 		h.logger.Error("failed to reconcile payment",
 			"payment_id", paymentID,
@@ -254,12 +451,157 @@ func (h *AdminHandler) ReconcilePayment(w http.ResponseWriter, r *http.Request)
 		"admin", claims.Subject,
 	)
 
+	entry := auditEntry(r, service.AuditActionPaymentReconcile, service.AuditResourcePayment, &paymentID)
+	entry.Before = map[string]interface{}{
+		"payment_status":  result.PaymentStatusBefore,
+		"donation_status": result.DonationStatusBefore,
+	}
+	entry.After = map[string]interface{}{
+		"payment_status":  result.PaymentStatusAfter,
+		"donation_status": result.DonationStatusAfter,
+		"refund_needed":   result.RefundNeeded,
+	}
+	entry.Details = map[string]interface{}{
+		"donation_id": result.DonationID,
+		"reason":      req.Reason,
+	}
+	h.audit.Record(r.Context(), entry)
+
 	h.respondJSON(w, http.StatusOK, map[string]string{
 		"status":  "ok",
 		"message": "Payment reconciled successfully",
 	})
 }
 
+// ExportDonations handles GET /api/v1/admin/donations/export. It streams the
+// donations matching status, start_date and end_date as CSV. Donor emails are
+// only included for roles allowed to see personal data.
+func (h *AdminHandler) ExportDonations(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	params := donation.ListDonationsParams{}
+
+	if status := query.Get("status"); status != "" {
+		s := donation.Status(status)
+		params.Status = &s
+	}
+
+	if startStr := query.Get("start_date"); startStr != "" {
+		startDate, err := time.Parse("2006-01-02", startStr)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "INVALID_DATE", "Invalid start_date format (use YYYY-MM-DD)")
+			return
+		}
+		params.StartDate = &startDate
+	}
+
+	if endStr := query.Get("end_date"); endStr != "" {
+		endDate, err := time.Parse("2006-01-02", endStr)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "INVALID_DATE", "Invalid end_date format (use YYYY-MM-DD)")
+			return
+		}
+		// Include the entire end day
+		endDate = endDate.Add(24*time.Hour - time.Second)
+		params.EndDate = &endDate
+	}
+
+	showPII := middleware.Can(r.Context(), middleware.PermDonorPII)
+
+	entry := auditEntry(r, service.AuditActionDonationsExport, service.AuditResourceDonation, nil)
+	entry.Details = map[string]interface{}{
+		"status":      query.Get("status"),
+		"start_date":  query.Get("start_date"),
+		"end_date":    query.Get("end_date"),
+		"donor_email": showPII,
+	}
+	h.audit.Record(r.Context(), entry)
+
+	header := []string{"id", "created_at", "paid_at", "status", "donor_name", "donor_email", "amount", "message"}
+	if !showPII {
+		header = slices.Delete(header, 5, 6)
+	}
+
+	filename := "donations-" + time.Now().Format("20060102-150405") + ".csv"
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	out := csv.NewWriter(w)
+	out.Write(header)
+
+	err := h.donationService.ExportDonations(r.Context(), params, func(don *donation.Donation) error {
+		paidAt := ""
+		if don.PaidAt != nil {
+			paidAt = don.PaidAt.Format(time.RFC3339)
+		}
+
+		record := []string{
+			don.ID.String(),
+			don.CreatedAt.Format(time.RFC3339),
+			paidAt,
+			string(don.Status),
+			csvSafe(don.DonorName),
+		}
+		if showPII {
+			record = append(record, csvSafe(don.DonorEmail))
+		}
+		record = append(record, strconv.FormatInt(don.Amount, 10), csvSafe(don.Message))
+
+		return out.Write(record)
+	})
+	out.Flush()
+
+	// The status line is already sent, so a failure can only cut the file short
+	if err == nil {
+		err = out.Error()
+	}
+	if err != nil {
+		h.logger.Error("failed to export donations", "error", err)
+	}
+}
+
+// csvSafe keeps donor supplied text from being run as a formula when the
+// export is opened in a spreadsheet
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// ApproveMessage handles POST /api/v1/admin/donations/{id}/approve-message.
+// It releases a donation whose alert a fraud rule held, sending its message
+// to the overlays if the donation is paid.
+func (h *AdminHandler) ApproveMessage(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "INVALID_ID", "Invalid donation ID")
+		return
+	}
+
+	if _, err := h.donationService.GetDonation(r.Context(), id); err != nil {
+		h.respondError(w, http.StatusNotFound, "NOT_FOUND", "Donation not found")
+		return
+	}
+
+	released, err := h.donationService.ReleaseHeldAlert(r.Context(), id)
+	if err != nil {
+		h.logger.Error("failed to approve message", "donation_id", id, "error", err)
+		h.respondError(w, http.StatusInternalServerError, "APPROVE_FAILED", "Failed to approve message")
+		return
+	}
+	if !released {
+		h.respondError(w, http.StatusConflict, "NOT_HELD", "Donation message is not held for review")
+		return
+	}
+
+	h.audit.Record(r.Context(), auditEntry(r, service.AuditActionMessageApprove, service.AuditResourceDonation, &id))
+
+	h.respondJSON(w, http.StatusOK, map[string]string{
+		"status":  "ok",
+		"message": "Message approved",
+	})
+}
+
 // GenerateOverlayToken handles POST /api/v1/admin/overlay-token
 func (h *AdminHandler) GenerateOverlayToken(w http.ResponseWriter, r *http.Request) {
 	var req struct {
@@ -298,9 +640,51 @@ func (h *AdminHandler) GenerateOverlayToken(w http.ResponseWriter, r *http.Reque
 		"expires_in_days", req.ExpiresIn,
 	)
 
+	// The token itself is a credential and stays out of the audit trail
+	entry := auditEntry(r, service.AuditActionOverlayTokenCreate, service.AuditResourceOverlayToken, nil)
+	entry.After = map[string]interface{}{
+		"name":       req.Name,
+		"expires_at": response["expires_at"],
+	}
+	h.audit.Record(r.Context(), entry)
+
 	h.respondJSON(w, http.StatusCreated, response)
 }
 
+// ListPayments handles GET /api/v1/admin/payments
+func (h *AdminHandler) ListPayments(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	params := payment.ListPaymentsParams{
+		Page:  parseInt(query.Get("page"), 1),
+		Limit: parseInt(query.Get("limit"), 20),
+	}
+
+	if status := query.Get("status"); status != "" {
+		s := payment.Status(status)
+		params.Status = &s
+	}
+
+	if provider := query.Get("provider"); provider != "" {
+		p := payment.Provider(provider)
+		params.Provider = &p
+	}
+
+	if refund := query.Get("refund_needed"); refund != "" {
+		needed := refund == "true"
+		params.RefundNeeded = &needed
+	}
+
+	result, err := h.donationService.ListPayments(r.Context(), params)
+	if err != nil {
+		h.logger.Error("failed to list payments", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "LIST_FAILED", "Failed to list payments")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, result)
+}
+
 // GetWebhookLogs handles GET /api/v1/admin/webhook-logs
 func (h *AdminHandler) GetWebhookLogs(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameters
@@ -329,23 +713,6 @@ func (h *AdminHandler) GetWebhookLogs(w http.ResponseWriter, r *http.Request) {
 	h.respondJSON(w, http.StatusOK, response)
 }
 
-// GetSystemHealth handles GET /api/v1/admin/health
-func (h *AdminHandler) GetSystemHealth(w http.ResponseWriter, r *http.Request) {
-	// TODO: Implement actual health checks for DB, Redis, etc.
-
-	response := dto.HealthResponse{
-		Status: "healthy",
-		Services: map[string]string{
-			"database":  "ok",
-			"redis":     "ok",
-			"websocket": "ok",
-		},
-		Timestamp: time.Now().Format(time.RFC3339),
-	}
-
-	h.respondJSON(w, http.StatusOK, response)
-}
-
 // respondJSON sends JSON response
 func (h *AdminHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
@@ -360,3 +727,24 @@ func (h *AdminHandler) respondError(w http.ResponseWriter, status int, code, mes
 		Message: message,
 	})
 }
+
+// clientIP returns the client IP resolved by the ClientIP middleware, which
+// only believes proxy headers from trusted proxies
+func clientIP(r *http.Request) string {
+	return middleware.RequestIP(r)
+}
+
+// respondLoginBlocked rejects a login stopped by brute-force protection
+func respondLoginBlocked(w http.ResponseWriter, wait time.Duration, err error, respondError func(http.ResponseWriter, int, string, string)) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+
+	if errors.Is(err, service.ErrLoginLocked) {
+		respondError(w, http.StatusTooManyRequests, "LOGIN_LOCKED", "Too many failed login attempts, try again later")
+		return
+	}
+	respondError(w, http.StatusTooManyRequests, "LOGIN_THROTTLED", "Too many failed login attempts, wait before trying again")
+}
@@ -0,0 +1,393 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+
+	"github.com/reveegate/reveegate/internal/http/dto"
+	"github.com/reveegate/reveegate/internal/http/middleware"
+	"github.com/reveegate/reveegate/internal/password"
+	"github.com/reveegate/reveegate/internal/repository/postgres"
+	"github.com/reveegate/reveegate/internal/service"
+)
+
+// AdminUserHandler handles admin account management HTTP requests
+type AdminUserHandler struct {
+	adminUserService *service.AdminUserService
+	loginGuard       *service.LoginGuard
+	audit            *service.AuditService
+	appURL           string
+	validator        *validator.Validate
+	logger           *slog.Logger
+}
+
+// NewAdminUserHandler creates a new admin user handler
+func NewAdminUserHandler(
+	adminUserService *service.AdminUserService,
+	loginGuard *service.LoginGuard,
+	audit *service.AuditService,
+	appURL string,
+	validator *validator.Validate,
+	logger *slog.Logger,
+) *AdminUserHandler {
+	return &AdminUserHandler{
+		adminUserService: adminUserService,
+		loginGuard:       loginGuard,
+		audit:            audit,
+		appURL:           strings.TrimRight(appURL, "/"),
+		validator:        validator,
+		logger:           logger,
+	}
+}
+
+// List handles GET /api/v1/admin/users
+func (h *AdminUserHandler) List(w http.ResponseWriter, r *http.Request) {
+	admins, err := h.adminUserService.List(r.Context())
+	if err != nil {
+		h.respondAdminUserError(w, err)
+		return
+	}
+
+	response := make([]dto.AdminUserResponse, 0, len(admins))
+	for _, admin := range admins {
+		response = append(response, toAdminUserResponse(admin))
+	}
+
+	h.respondJSON(w, http.StatusOK, response)
+}
+
+// Create handles POST /api/v1/admin/users
+func (h *AdminUserHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateAdminUserRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	admin, err := h.adminUserService.Create(r.Context(), service.CreateAdminParams{
+		Username: req.Username,
+		Email:    req.Email,
+		Password: req.Password,
+		Role:     req.Role,
+	})
+	if err != nil {
+		h.respondAdminUserError(w, err)
+		return
+	}
+
+	entry := auditEntry(r, service.AuditActionAdminCreate, service.AuditResourceAdminUser, &admin.ID)
+	entry.After = toAdminUserResponse(admin)
+	h.audit.Record(r.Context(), entry)
+
+	h.respondJSON(w, http.StatusCreated, toAdminUserResponse(admin))
+}
+
+// Invite handles POST /api/v1/admin/users/invite
+func (h *AdminUserHandler) Invite(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.InviteAdminRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	inv, err := h.adminUserService.Invite(r.Context(), actorID, req.Email, req.Role)
+	if err != nil {
+		h.respondAdminUserError(w, err)
+		return
+	}
+
+	entry := auditEntry(r, service.AuditActionAdminInvite, service.AuditResourceInvitation, &inv.ID)
+	entry.After = map[string]interface{}{
+		"email":      inv.Email,
+		"role":       inv.Role,
+		"expires_at": inv.ExpiresAt,
+	}
+	h.audit.Record(r.Context(), entry)
+
+	h.respondJSON(w, http.StatusCreated, dto.InviteAdminResponse{
+		ID:          inv.ID,
+		Email:       inv.Email,
+		Role:        inv.Role,
+		InviteToken: inv.Token,
+		InviteURL:   h.appURL + "/admin?invite=" + url.QueryEscape(inv.Token),
+		ExpiresAt:   inv.ExpiresAt,
+	})
+}
+
+// AcceptInvitation handles POST /api/v1/admin/invitations/accept
+func (h *AdminUserHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	var req dto.AcceptInvitationRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	admin, err := h.adminUserService.AcceptInvitation(r.Context(), req.Token, req.Username, req.Password)
+	if err != nil {
+		h.respondAdminUserError(w, err)
+		return
+	}
+
+	// Nobody is logged in yet, so the new admin is the actor
+	entry := auditEntry(r, service.AuditActionInvitationAccept, service.AuditResourceAdminUser, &admin.ID)
+	entry.ActorID = &admin.ID
+	entry.After = toAdminUserResponse(admin)
+	h.audit.Record(r.Context(), entry)
+
+	h.respondJSON(w, http.StatusCreated, toAdminUserResponse(admin))
+}
+
+// Update handles PATCH /api/v1/admin/users/{id}
+func (h *AdminUserHandler) Update(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	userID, ok := h.pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateAdminUserRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	before, err := h.adminUserService.Get(r.Context(), userID)
+	if err != nil {
+		h.respondAdminUserError(w, err)
+		return
+	}
+
+	admin, err := h.adminUserService.UpdateRole(r.Context(), actorID, userID, req.Role)
+	if err != nil {
+		h.respondAdminUserError(w, err)
+		return
+	}
+
+	entry := auditEntry(r, service.AuditActionAdminUpdate, service.AuditResourceAdminUser, &userID)
+	entry.Before = toAdminUserResponse(before)
+	entry.After = toAdminUserResponse(admin)
+	h.audit.Record(r.Context(), entry)
+
+	h.respondJSON(w, http.StatusOK, toAdminUserResponse(admin))
+}
+
+// Deactivate handles POST /api/v1/admin/users/{id}/deactivate
+func (h *AdminUserHandler) Deactivate(w http.ResponseWriter, r *http.Request) {
+	h.setActive(w, r, false)
+}
+
+// Activate handles POST /api/v1/admin/users/{id}/activate
+func (h *AdminUserHandler) Activate(w http.ResponseWriter, r *http.Request) {
+	h.setActive(w, r, true)
+}
+
+// ResetPassword handles POST /api/v1/admin/users/{id}/reset-password
+func (h *AdminUserHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.ResetAdminPasswordRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	if err := h.adminUserService.ResetPassword(r.Context(), userID, req.Password, req.ResetMFA); err != nil {
+		h.respondAdminUserError(w, err)
+		return
+	}
+
+	entry := auditEntry(r, service.AuditActionPasswordReset, service.AuditResourceAdminUser, &userID)
+	entry.Details = map[string]interface{}{"reset_mfa": req.ResetMFA}
+	h.audit.Record(r.Context(), entry)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Unlock handles POST /api/v1/admin/users/{id}/unlock
+func (h *AdminUserHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	userID, ok := h.pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := h.adminUserService.Get(r.Context(), userID); err != nil {
+		h.respondAdminUserError(w, err)
+		return
+	}
+
+	if err := h.loginGuard.UnlockAccount(r.Context(), actorID, userID, clientIP(r), r.UserAgent()); err != nil {
+		h.respondAdminUserError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnlockIP handles POST /api/v1/admin/login-lockouts/unlock-ip
+func (h *AdminUserHandler) UnlockIP(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.UnlockIPRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	if err := h.loginGuard.UnlockIP(r.Context(), actorID, req.IP, clientIP(r), r.UserAgent()); err != nil {
+		h.respondAdminUserError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// setActive activates or deactivates the admin in the path
+func (h *AdminUserHandler) setActive(w http.ResponseWriter, r *http.Request, active bool) {
+	actorID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	userID, ok := h.pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	before, err := h.adminUserService.Get(r.Context(), userID)
+	if err != nil {
+		h.respondAdminUserError(w, err)
+		return
+	}
+
+	admin, err := h.adminUserService.SetActive(r.Context(), actorID, userID, active)
+	if err != nil {
+		h.respondAdminUserError(w, err)
+		return
+	}
+
+	action := service.AuditActionAdminDeactivate
+	if active {
+		action = service.AuditActionAdminActivate
+	}
+	entry := auditEntry(r, action, service.AuditResourceAdminUser, &userID)
+	entry.Before = toAdminUserResponse(before)
+	entry.After = toAdminUserResponse(admin)
+	h.audit.Record(r.Context(), entry)
+
+	h.respondJSON(w, http.StatusOK, toAdminUserResponse(admin))
+}
+
+// toAdminUserResponse converts an admin user to its response
+func toAdminUserResponse(admin *postgres.AdminUser) dto.AdminUserResponse {
+	return dto.AdminUserResponse{
+		ID:          admin.ID,
+		Username:    admin.Username,
+		Email:       admin.Email,
+		Role:        admin.Role,
+		IsActive:    admin.IsActive,
+		MFAEnabled:  admin.TOTPEnabled,
+		LastLoginAt: admin.LastLoginAt,
+		CreatedAt:   admin.CreatedAt,
+	}
+}
+
+// currentUserID returns the authenticated admin's ID
+func (h *AdminUserHandler) currentUserID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	claims := middleware.GetClaims(r.Context())
+	if claims == nil {
+		h.respondError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Not authenticated")
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		h.respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Invalid user in token")
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+// pathUserID parses the admin ID from the URL
+func (h *AdminUserHandler) pathUserID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "INVALID_ID", "Invalid admin user ID")
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// decode decodes and validates a JSON request body
+func (h *AdminUserHandler) decode(w http.ResponseWriter, r *http.Request, req interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "INVALID_JSON", "Invalid request body")
+		return false
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		h.respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", formatValidationErrors(validationErrors))
+		return false
+	}
+
+	return true
+}
+
+// respondAdminUserError maps admin user service errors to responses
+func (h *AdminUserHandler) respondAdminUserError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, postgres.ErrAdminNotFound):
+		h.respondError(w, http.StatusNotFound, "NOT_FOUND", "Admin user not found")
+	case errors.Is(err, postgres.ErrAdminExists):
+		h.respondError(w, http.StatusConflict, "ADMIN_EXISTS", "An admin with this username or email already exists")
+	case errors.Is(err, service.ErrCannotModifySelf):
+		h.respondError(w, http.StatusForbidden, "CANNOT_MODIFY_SELF", "You cannot change your own role or status")
+	case errors.Is(err, service.ErrLastOwner):
+		h.respondError(w, http.StatusConflict, "LAST_OWNER", "At least one active owner is required")
+	case errors.Is(err, password.ErrWeakPassword):
+		h.respondError(w, http.StatusBadRequest, "WEAK_PASSWORD", err.Error())
+	case errors.Is(err, service.ErrInvitationInvalid):
+		h.respondError(w, http.StatusBadRequest, "INVALID_INVITATION", "Invitation is invalid or expired")
+	default:
+		h.logger.Error("admin user request failed", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "SERVER_ERROR", "Internal server error")
+	}
+}
+
+// respondJSON sends JSON response
+func (h *AdminUserHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+// respondError sends error response
+func (h *AdminUserHandler) respondError(w http.ResponseWriter, status int, code, message string) {
+	h.respondJSON(w, status, dto.ErrorResponse{
+		Error:   code,
+		Message: message,
+	})
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+
+	"github.com/reveegate/reveegate/internal/http/dto"
+	"github.com/reveegate/reveegate/internal/http/middleware"
+	"github.com/reveegate/reveegate/internal/repository/postgres"
+	"github.com/reveegate/reveegate/internal/service"
+)
+
+// APIKeyHandler handles API key management HTTP requests
+type APIKeyHandler struct {
+	apiKeyService *service.APIKeyService
+	audit         *service.AuditService
+	validator     *validator.Validate
+	logger        *slog.Logger
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(
+	apiKeyService *service.APIKeyService,
+	audit *service.AuditService,
+	validator *validator.Validate,
+	logger *slog.Logger,
+) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+		audit:         audit,
+		validator:     validator,
+		logger:        logger,
+	}
+}
+
+// List handles GET /api/v1/admin/api-keys
+func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.apiKeyService.List(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list api keys", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "LIST_FAILED", "Failed to list API keys")
+		return
+	}
+
+	response := make([]dto.APIKeyResponse, len(keys))
+	for i, key := range keys {
+		response[i] = h.toAPIKeyResponse(key)
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"api_keys": response,
+	})
+}
+
+// Create handles POST /api/v1/admin/api-keys
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r.Context())
+	actorID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		h.respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Invalid user in token")
+		return
+	}
+
+	var req dto.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "INVALID_JSON", "Invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		h.respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", formatValidationErrors(validationErrors))
+		return
+	}
+
+	created, err := h.apiKeyService.Create(r.Context(), service.CreateAPIKeyParams{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+		RateLimit: req.RateLimitPerMinute,
+		CreatedBy: actorID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAPIKeyScope), errors.Is(err, service.ErrAPIKeyExpiry):
+			h.respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		default:
+			h.logger.Error("failed to create api key", "error", err)
+			h.respondError(w, http.StatusInternalServerError, "CREATE_FAILED", "Failed to create API key")
+		}
+		return
+	}
+
+	response := h.toAPIKeyResponse(created.APIKey)
+
+	entry := auditEntry(r, service.AuditActionAPIKeyCreate, service.AuditResourceAPIKey, &created.ID)
+	entry.After = response
+	h.audit.Record(r.Context(), entry)
+
+	h.respondJSON(w, http.StatusCreated, dto.CreateAPIKeyResponse{
+		APIKeyResponse: response,
+		Key:            created.Key,
+	})
+}
+
+// Revoke handles DELETE /api/v1/admin/api-keys/{id}
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "INVALID_ID", "Invalid API key ID")
+		return
+	}
+
+	before, err := h.apiKeyService.Revoke(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrAPIKeyNotFound) {
+			h.respondError(w, http.StatusNotFound, "NOT_FOUND", "API key not found")
+			return
+		}
+		h.logger.Error("failed to revoke api key", "api_key_id", id, "error", err)
+		h.respondError(w, http.StatusInternalServerError, "REVOKE_FAILED", "Failed to revoke API key")
+		return
+	}
+
+	if before.RevokedAt == nil {
+		entry := auditEntry(r, service.AuditActionAPIKeyRevoke, service.AuditResourceAPIKey, &id)
+		entry.Details = map[string]interface{}{"name": before.Name}
+		h.audit.Record(r.Context(), entry)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// toAPIKeyResponse converts an API key to its response
+func (h *APIKeyHandler) toAPIKeyResponse(key *postgres.APIKey) dto.APIKeyResponse {
+	return dto.APIKeyResponse{
+		ID:                 key.ID,
+		Name:               key.Name,
+		Prefix:             key.Prefix,
+		Scopes:             key.Scopes,
+		RateLimitPerMinute: h.apiKeyService.RateLimitFor(key),
+		CreatedBy:          key.CreatedBy,
+		ExpiresAt:          key.ExpiresAt,
+		LastUsedAt:         key.LastUsedAt,
+		LastUsedIP:         key.LastUsedIP,
+		RevokedAt:          key.RevokedAt,
+		CreatedAt:          key.CreatedAt,
+	}
+}
+
+// respondJSON sends JSON response
+func (h *APIKeyHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+// respondError sends error response
+func (h *APIKeyHandler) respondError(w http.ResponseWriter, status int, code, message string) {
+	h.respondJSON(w, status, dto.ErrorResponse{
+		Error:   code,
+		Message: message,
+	})
+}
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/reveegate/reveegate/internal/http/dto"
+	"github.com/reveegate/reveegate/internal/http/middleware"
+	"github.com/reveegate/reveegate/internal/repository/postgres"
+	"github.com/reveegate/reveegate/internal/service"
+)
+
+// AuditHandler handles audit trail HTTP requests
+type AuditHandler struct {
+	auditService *service.AuditService
+	logger       *slog.Logger
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(auditService *service.AuditService, logger *slog.Logger) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// List handles GET /api/v1/admin/audit-logs
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := postgres.AuditLogFilter{
+		Action:       query.Get("action"),
+		ResourceType: query.Get("resource_type"),
+		Page:         parseInt(query.Get("page"), 1),
+		Limit:        parseInt(query.Get("limit"), 50),
+	}
+
+	var ok bool
+	if filter.UserID, ok = h.parseUUIDParam(w, query.Get("actor_id"), "actor_id"); !ok {
+		return
+	}
+	if filter.ResourceID, ok = h.parseUUIDParam(w, query.Get("resource_id"), "resource_id"); !ok {
+		return
+	}
+	if filter.StartDate, ok = h.parseTimeParam(w, query.Get("from"), "from", false); !ok {
+		return
+	}
+	if filter.EndDate, ok = h.parseTimeParam(w, query.Get("to"), "to", true); !ok {
+		return
+	}
+
+	result, err := h.auditService.List(r.Context(), filter)
+	if err != nil {
+		h.logger.Error("failed to list audit logs", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "LIST_FAILED", "Failed to list audit logs")
+		return
+	}
+
+	logs := make([]dto.AuditLogResponse, len(result.Logs))
+	for i, log := range result.Logs {
+		logs[i] = dto.AuditLogResponse{
+			ID:           log.ID,
+			Sequence:     log.Sequence,
+			ActorID:      log.UserID,
+			ActorName:    log.Username,
+			Action:       log.Action,
+			ResourceType: log.ResourceType,
+			ResourceID:   log.ResourceID,
+			Changes:      log.Changes,
+			IPAddress:    log.IPAddress,
+			UserAgent:    log.UserAgent,
+			Hash:         log.Hash,
+			CreatedAt:    log.CreatedAt,
+		}
+	}
+
+	h.respondJSON(w, http.StatusOK, dto.ListAuditLogsResponse{
+		Logs: logs,
+		Pagination: dto.PaginationResponse{
+			Page:       result.Page,
+			Limit:      result.Limit,
+			Total:      result.Total,
+			TotalPages: result.TotalPages,
+		},
+	})
+}
+
+// Verify handles GET /api/v1/admin/audit-logs/verify
+func (h *AuditHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	report, err := h.auditService.Verify(r.Context())
+	if err != nil {
+		h.logger.Error("failed to verify audit log chain", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "VERIFY_FAILED", "Failed to verify audit logs")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, dto.AuditChainResponse{
+		Valid:        report.Valid,
+		Checked:      report.Checked,
+		Unhashed:     report.Unhashed,
+		BrokenAt:     report.BrokenAt,
+		Reason:       report.Reason,
+		HeadSequence: report.HeadSequence,
+		HeadHash:     report.HeadHash,
+	})
+}
+
+// parseUUIDParam parses an optional UUID query parameter
+func (h *AuditHandler) parseUUIDParam(w http.ResponseWriter, value, name string) (*uuid.UUID, bool) {
+	if value == "" {
+		return nil, true
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "INVALID_PARAMETER", name+" must be a UUID")
+		return nil, false
+	}
+	return &id, true
+}
+
+// parseTimeParam parses an optional RFC 3339 timestamp or YYYY-MM-DD date.
+// A bare date used as an upper bound covers the whole day.
+func (h *AuditHandler) parseTimeParam(w http.ResponseWriter, value, name string, endOfDay bool) (*time.Time, bool) {
+	if value == "" {
+		return nil, true
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, true
+	}
+
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		if endOfDay {
+			t = t.Add(24*time.Hour - time.Nanosecond)
+		}
+		return &t, true
+	}
+
+	h.respondError(w, http.StatusBadRequest, "INVALID_PARAMETER", name+" must be an RFC 3339 timestamp or YYYY-MM-DD date")
+	return nil, false
+}
+
+// respondJSON sends JSON response
+func (h *AuditHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+// respondError sends error response
+func (h *AuditHandler) respondError(w http.ResponseWriter, status int, code, message string) {
+	h.respondJSON(w, status, dto.ErrorResponse{
+		Error:   code,
+		Message: message,
+	})
+}
+
+// auditEntry starts an audit entry for the admin making the request
+func auditEntry(r *http.Request, action, resourceType string, resourceID *uuid.UUID) service.AuditEntry {
+	entry := service.AuditEntry{
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		IP:           clientIP(r),
+		UserAgent:    r.UserAgent(),
+	}
+
+	if claims := middleware.GetClaims(r.Context()); claims != nil {
+		if claims.IsAPIKey() {
+			entry.APIKeyID = claims.APIKeyID
+		} else if actorID, err := uuid.Parse(claims.UserID); err == nil {
+			entry.ActorID = &actorID
+		}
+	}
+
+	return entry
+}
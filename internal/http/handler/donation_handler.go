@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -11,15 +12,21 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 
+	"github.com/reveegate/reveegate/internal/challenge"
 	"github.com/reveegate/reveegate/internal/domain/donation"
 	"github.com/reveegate/reveegate/internal/domain/payment"
+	"github.com/reveegate/reveegate/internal/donortoken"
 	"github.com/reveegate/reveegate/internal/http/dto"
+	"github.com/reveegate/reveegate/internal/http/middleware"
 	"github.com/reveegate/reveegate/internal/service"
 )
 
 // DonationHandler handles donation HTTP requests
 type DonationHandler struct {
 	donationService *service.DonationService
+	idempotency     *service.IdempotencyService
+	abuseGuard      *service.AbuseGuard
+	donorTokens     *donortoken.Signer
 	validator       *validator.Validate
 	logger          *slog.Logger
 }
@@ -27,11 +34,17 @@ type DonationHandler struct {
 // NewDonationHandler creates a new donation handler
 func NewDonationHandler(
 	donationService *service.DonationService,
+	idempotency *service.IdempotencyService,
+	abuseGuard *service.AbuseGuard,
+	donorTokens *donortoken.Signer,
 	validator *validator.Validate,
 	logger *slog.Logger,
 ) *DonationHandler {
 	return &DonationHandler{
 		donationService: donationService,
+		idempotency:     idempotency,
+		abuseGuard:      abuseGuard,
+		donorTokens:     donorTokens,
 		validator:       validator,
 		logger:          logger,
 	}
@@ -52,20 +65,48 @@ func (h *DonationHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A retried request with the same Idempotency-Key gets the first response
+	var claim *service.IdempotencyClaim
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		var done bool
+		if claim, done = h.beginIdempotent(w, r, key, req); done {
+			return
+		}
+	}
+
 	// Map payment method string to payment.Method
 	paymentMethod := payment.Method(req.PaymentMethod)
 
-	// Create donation
+	// Create donation. Risky requests must solve a challenge before a charge is created.
 	result, err := h.donationService.CreateDonation(r.Context(), service.CreateDonationParams{
 		DonorName:     req.DonorName,
 		DonorEmail:    req.DonorEmail,
 		Message:       req.Message,
 		Amount:        req.Amount,
 		PaymentMethod: paymentMethod,
+		CoverFees:     req.CoverFees,
+		ClientIP:      clientIP(r),
+		Fingerprint:   deviceFingerprint(r),
+		Challenge:     challengeSolution(r),
 	})
 	if err != nil {
-		h.logger.Error("failed to create donation", "error", err)
-		h.respondError(w, http.StatusInternalServerError, "CREATE_FAILED", err.Error())
+		if claim != nil {
+			h.idempotency.Release(r.Context(), claim)
+		}
+
+		switch {
+		case errors.Is(err, service.ErrChallengeRequired), errors.Is(err, service.ErrChallengeUnavailable):
+			h.respondChallengeError(w, err)
+		case errors.Is(err, service.ErrDonorBlocked):
+			h.respondError(w, http.StatusForbidden, "DONOR_BLOCKED", err.Error())
+		case errors.Is(err, service.ErrMethodUnavailable):
+			h.respondError(w, http.StatusBadRequest, "METHOD_UNAVAILABLE", err.Error())
+		case errors.Is(err, service.ErrAmountOutOfRange):
+			h.respondError(w, http.StatusBadRequest, "AMOUNT_OUT_OF_RANGE", err.Error())
+		default:
+			h.logger.Error("failed to create donation", "error", err)
+			h.respondError(w, http.StatusInternalServerError, "CREATE_FAILED", err.Error())
+		}
 		return
 	}
 
@@ -80,10 +121,223 @@ func (h *DonationHandler) Create(w http.ResponseWriter, r *http.Request) {
 		CreatedAt:   result.Donation.CreatedAt,
 		PaymentInfo: h.buildPaymentInfo(result.Payment),
 	}
+	h.grantAccess(&response)
+
+	if claim != nil {
+		body, err := json.Marshal(response)
+		if err != nil {
+			h.idempotency.Release(r.Context(), claim)
+			h.logger.Error("failed to encode donation response", "donation_id", result.Donation.ID, "error", err)
+			h.respondError(w, http.StatusInternalServerError, "CREATE_FAILED", "Failed to encode response")
+			return
+		}
+
+		h.idempotency.Complete(r.Context(), claim, http.StatusCreated, body, &result.Donation.ID)
+		h.respondRaw(w, http.StatusCreated, body)
+		return
+	}
 
 	h.respondJSON(w, http.StatusCreated, response)
 }
 
+// GetChallenge handles GET /api/v1/donations/challenge
+func (h *DonationHandler) GetChallenge(w http.ResponseWriter, r *http.Request) {
+	c, err := h.abuseGuard.Issue(r.Context(), clientIP(r))
+	if err != nil {
+		h.logger.Error("failed to issue challenge", "error", err)
+		h.respondError(w, http.StatusServiceUnavailable, "CHALLENGE_UNAVAILABLE", "Failed to issue challenge")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.respondJSON(w, http.StatusOK, toChallengeResponse(c))
+}
+
+// challengeSolution reads a challenge solution from the request headers
+func challengeSolution(r *http.Request) challenge.Solution {
+	return challenge.Solution{
+		Token: r.Header.Get("X-Challenge-Token"),
+		Nonce: r.Header.Get("X-Challenge-Nonce"),
+	}
+}
+
+// deviceFingerprint returns the device ID sent by the donor page, or a hash
+// of the request headers when there is none
+func deviceFingerprint(r *http.Request) string {
+	if id := r.Header.Get("X-Device-Fingerprint"); id != "" && len(id) <= 128 {
+		return id
+	}
+	return middleware.KeyByDonorFingerprint(r)
+}
+
+// respondChallengeError responds to a failed anti-abuse check
+func (h *DonationHandler) respondChallengeError(w http.ResponseWriter, err error) {
+	var challengeErr *service.ChallengeError
+	if errors.As(err, &challengeErr) {
+		h.respondJSON(w, http.StatusForbidden, dto.ChallengeRequiredResponse{
+			Error:     "CHALLENGE_REQUIRED",
+			Message:   service.ErrChallengeRequired.Error(),
+			Challenge: toChallengeResponse(challengeErr.Challenge),
+		})
+		return
+	}
+
+	h.respondError(w, http.StatusServiceUnavailable, "CHALLENGE_UNAVAILABLE", err.Error())
+}
+
+// toChallengeResponse converts a challenge, nil meaning none is required
+func toChallengeResponse(c *challenge.Challenge) dto.ChallengeResponse {
+	if c == nil {
+		return dto.ChallengeResponse{Required: false}
+	}
+
+	return dto.ChallengeResponse{
+		Required:   true,
+		Kind:       c.Kind,
+		Token:      c.Token,
+		Difficulty: c.Difficulty,
+		SiteKey:    c.SiteKey,
+		ExpiresAt:  c.ExpiresAt,
+	}
+}
+
+// beginIdempotent claims an Idempotency-Key for a request. It returns done
+// when a response has already been written, either a replay of the first
+// request's response or an error.
+func (h *DonationHandler) beginIdempotent(w http.ResponseWriter, r *http.Request, key string, req interface{}) (*service.IdempotencyClaim, bool) {
+	if !validIdempotencyKey(key) {
+		h.respondError(w, http.StatusBadRequest, "INVALID_IDEMPOTENCY_KEY", "Idempotency-Key must be 1-255 printable ASCII characters")
+		return nil, true
+	}
+
+	fingerprint, err := service.IdempotencyFingerprint(r.Method, r.URL.Path, req)
+	if err != nil {
+		h.logger.Error("failed to fingerprint request", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "CREATE_FAILED", "Failed to process request")
+		return nil, true
+	}
+
+	claim, replay, err := h.idempotency.Begin(r.Context(), "donations.create", key, fingerprint)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrIdempotencyKeyReused):
+			h.respondError(w, http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED", err.Error())
+		case errors.Is(err, service.ErrIdempotencyInProgress):
+			w.Header().Set("Retry-After", "1")
+			h.respondError(w, http.StatusConflict, "IDEMPOTENCY_IN_PROGRESS", err.Error())
+		default:
+			h.logger.Error("failed to check idempotency key", "error", err)
+			h.respondError(w, http.StatusInternalServerError, "CREATE_FAILED", "Failed to process request")
+		}
+		return nil, true
+	}
+
+	if replay != nil {
+		w.Header().Set("Idempotent-Replayed", "true")
+		h.respondRaw(w, replay.Status, replay.Body)
+		return nil, true
+	}
+
+	return claim, false
+}
+
+// validIdempotencyKey checks a key is 1-255 printable ASCII characters
+func validIdempotencyKey(key string) bool {
+	if len(key) == 0 || len(key) > 255 {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// CreatePaymentAttempt handles POST /api/v1/donations/{id}/payments
+func (h *DonationHandler) CreatePaymentAttempt(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "INVALID_ID", "Invalid donation ID")
+		return
+	}
+
+	var req dto.CreatePaymentAttemptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "INVALID_JSON", "Invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		h.respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", formatValidationErrors(validationErrors))
+		return
+	}
+
+	result, err := h.donationService.CreatePaymentAttempt(r.Context(), service.CreatePaymentAttemptParams{
+		DonationID:    id,
+		PaymentMethod: payment.Method(req.PaymentMethod),
+		CoverFees:     req.CoverFees,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrDonationNotPayable):
+			h.respondError(w, http.StatusConflict, "DONATION_NOT_PAYABLE", err.Error())
+		case errors.Is(err, service.ErrTooManyAttempts):
+			h.respondError(w, http.StatusConflict, "TOO_MANY_ATTEMPTS", err.Error())
+		case errors.Is(err, service.ErrMethodUnavailable):
+			h.respondError(w, http.StatusBadRequest, "METHOD_UNAVAILABLE", err.Error())
+		case errors.Is(err, service.ErrAmountOutOfRange):
+			h.respondError(w, http.StatusBadRequest, "AMOUNT_OUT_OF_RANGE", err.Error())
+		case err.Error() == "donation not found":
+			h.respondError(w, http.StatusNotFound, "NOT_FOUND", "Donation not found")
+		default:
+			h.logger.Error("failed to create payment attempt", "donation_id", id, "error", err)
+			h.respondError(w, http.StatusInternalServerError, "CREATE_FAILED", err.Error())
+		}
+		return
+	}
+
+	response := dto.DonationResponse{
+		ID:          result.Donation.ID,
+		DonorName:   result.Donation.DonorName,
+		DonorEmail:  result.Donation.DonorEmail,
+		Message:     result.Donation.Message,
+		Amount:      result.Donation.Amount,
+		Status:      string(result.Donation.Status),
+		CreatedAt:   result.Donation.CreatedAt,
+		PaymentInfo: h.buildPaymentInfo(result.Payment),
+	}
+	h.grantAccess(&response)
+
+	h.respondJSON(w, http.StatusCreated, response)
+}
+
+// QuoteFee handles GET /api/v1/donations/fee-quote
+func (h *DonationHandler) QuoteFee(w http.ResponseWriter, r *http.Request) {
+	req := dto.FeeQuoteRequest{
+		Amount:        int64(parseInt(r.URL.Query().Get("amount"), 0)),
+		PaymentMethod: r.URL.Query().Get("payment_method"),
+		CoverFees:     r.URL.Query().Get("cover_fees") == "true",
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		h.respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", formatValidationErrors(validationErrors))
+		return
+	}
+
+	quote := h.donationService.QuoteFee(req.Amount, payment.Method(req.PaymentMethod), req.CoverFees)
+
+	h.respondJSON(w, http.StatusOK, dto.FeeQuoteResponse{
+		Amount:    quote.Amount,
+		Fee:       quote.Fee,
+		Total:     quote.Total,
+		NetAmount: quote.NetAmount,
+		CoverFees: req.CoverFees,
+	})
+}
+
 // GetByID handles GET /api/v1/donations/{id}
 func (h *DonationHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
@@ -172,18 +426,23 @@ func (h *DonationHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Donor emails are only shown to roles allowed to see personal data
+	showPII := middleware.Can(r.Context(), middleware.PermDonorPII)
+
 	// Build response
 	donations := make([]dto.DonationResponse, len(result.Donations))
 	for i, don := range result.Donations {
 		donations[i] = dto.DonationResponse{
-			ID:         don.ID,
-			DonorName:  don.DonorName,
-			DonorEmail: don.DonorEmail,
-			Message:    don.Message,
-			Amount:     don.Amount,
-			Status:     string(don.Status),
-			CreatedAt:  don.CreatedAt,
-			PaidAt:     don.PaidAt,
+			ID:        don.ID,
+			DonorName: don.DonorName,
+			Message:   don.Message,
+			Amount:    don.Amount,
+			Status:    string(don.Status),
+			CreatedAt: don.CreatedAt,
+			PaidAt:    don.PaidAt,
+		}
+		if showPII {
+			donations[i].DonorEmail = don.DonorEmail
 		}
 	}
 
@@ -244,6 +503,9 @@ func (h *DonationHandler) GetStats(w http.ResponseWriter, r *http.Request) {
 		"completed_donations": stats.CompletedDonations,
 		"completed_amount":    stats.CompletedAmount,
 		"average_amount":      stats.AverageAmount,
+		"gross_amount":        stats.GrossAmount,
+		"total_fees":          stats.TotalFees,
+		"net_amount":          stats.NetAmount,
 		"start_date":          startDate.Format("2006-01-02"),
 		"end_date":            endDate.Format("2006-01-02"),
 	}
@@ -251,6 +513,14 @@ func (h *DonationHandler) GetStats(w http.ResponseWriter, r *http.Request) {
 	h.respondJSON(w, http.StatusOK, response)
 }
 
+// grantAccess adds a donor access token for the donation to a response, so
+// the donor can follow its status
+func (h *DonationHandler) grantAccess(response *dto.DonationResponse) {
+	token, expiresAt := h.donorTokens.Issue(response.ID)
+	response.AccessToken = token
+	response.AccessTokenExpiresAt = &expiresAt
+}
+
 // buildPaymentInfo builds payment info for response
 func (h *DonationHandler) buildPaymentInfo(pay *payment.Payment) *dto.PaymentInfo {
 	if pay == nil {
@@ -258,14 +528,17 @@ func (h *DonationHandler) buildPaymentInfo(pay *payment.Payment) *dto.PaymentInf
 	}
 
 	return &dto.PaymentInfo{
-		PaymentID:     pay.ID,
-		Provider:      string(pay.Provider),
-		Method:        string(pay.Method),
-		Status:        string(pay.Status),
-		QRCodeURL:     pay.QRCodeURL,
-		VANumber:      pay.VANumber,
-		DeepLink:      pay.DeepLink,
-		ExpiresAt:     pay.ExpiresAt,
+		PaymentID:      pay.ID,
+		Provider:       string(pay.Provider),
+		Method:         string(pay.Method),
+		Status:         string(pay.Status),
+		Amount:         pay.Amount,
+		FeeAmount:      pay.FeeAmount,
+		FeeCovered:     pay.FeeCovered,
+		QRCodeURL:      pay.QRCodeURL,
+		VANumber:       pay.VANumber,
+		DeepLink:       pay.DeepLink,
+		ExpiresAt:      pay.ExpiresAt,
 		PaymentPageURL: buildPaymentPageURL(pay),
 	}
 }
@@ -288,6 +561,13 @@ func (h *DonationHandler) respondJSON(w http.ResponseWriter, status int, data in
 	json.NewEncoder(w).Encode(data)
 }
 
+// respondRaw sends an already encoded JSON response
+func (h *DonationHandler) respondRaw(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
 // respondError sends error response
 func (h *DonationHandler) respondError(w http.ResponseWriter, status int, code, message string) {
 	h.respondJSON(w, status, dto.ErrorResponse{
@@ -0,0 +1,516 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+
+	"github.com/reveegate/reveegate/internal/domain/fraud"
+	"github.com/reveegate/reveegate/internal/http/dto"
+	"github.com/reveegate/reveegate/internal/http/middleware"
+	"github.com/reveegate/reveegate/internal/repository/postgres"
+	"github.com/reveegate/reveegate/internal/service"
+)
+
+// FraudHandler handles donor block, fraud rule and decision review HTTP requests
+type FraudHandler struct {
+	fraudService    *service.FraudService
+	donationService *service.DonationService
+	audit           *service.AuditService
+	validator       *validator.Validate
+	logger          *slog.Logger
+}
+
+// NewFraudHandler creates a new fraud handler
+func NewFraudHandler(
+	fraudService *service.FraudService,
+	donationService *service.DonationService,
+	audit *service.AuditService,
+	validator *validator.Validate,
+	logger *slog.Logger,
+) *FraudHandler {
+	return &FraudHandler{
+		fraudService:    fraudService,
+		donationService: donationService,
+		audit:           audit,
+		validator:       validator,
+		logger:          logger,
+	}
+}
+
+// ListBlocks handles GET /api/v1/admin/fraud/blocks
+func (h *FraudHandler) ListBlocks(w http.ResponseWriter, r *http.Request) {
+	blocks, err := h.fraudService.ListBlocks(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list donor blocks", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "LIST_FAILED", "Failed to list blocks")
+		return
+	}
+
+	response := make([]dto.DonorBlockResponse, len(blocks))
+	for i, b := range blocks {
+		response[i] = toDonorBlockResponse(b)
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"blocks": response,
+	})
+}
+
+// CreateBlock handles POST /api/v1/admin/fraud/blocks
+func (h *FraudHandler) CreateBlock(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.actorID(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateDonorBlockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "INVALID_JSON", "Invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		h.respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", formatValidationErrors(validationErrors))
+		return
+	}
+
+	block := &fraud.Block{
+		Type:      fraud.BlockType(req.Type),
+		Value:     req.Value,
+		Reason:    req.Reason,
+		CreatedBy: &actorID,
+		ExpiresAt: req.ExpiresAt,
+	}
+
+	if err := h.fraudService.CreateBlock(r.Context(), block); err != nil {
+		switch {
+		case errors.Is(err, fraud.ErrInvalidBlock), errors.Is(err, service.ErrBlockExpiry):
+			h.respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		case errors.Is(err, service.ErrBlockExists):
+			h.respondError(w, http.StatusConflict, "BLOCK_EXISTS", err.Error())
+		default:
+			h.logger.Error("failed to create donor block", "error", err)
+			h.respondError(w, http.StatusInternalServerError, "CREATE_FAILED", "Failed to create block")
+		}
+		return
+	}
+
+	response := toDonorBlockResponse(block)
+
+	entry := auditEntry(r, service.AuditActionBlockCreate, service.AuditResourceDonorBlock, &block.ID)
+	entry.After = response
+	h.audit.Record(r.Context(), entry)
+
+	h.respondJSON(w, http.StatusCreated, response)
+}
+
+// DeleteBlock handles DELETE /api/v1/admin/fraud/blocks/{id}
+func (h *FraudHandler) DeleteBlock(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "INVALID_ID", "Invalid block ID")
+		return
+	}
+
+	block, err := h.fraudService.DeleteBlock(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrBlockNotFound) {
+			h.respondError(w, http.StatusNotFound, "NOT_FOUND", "Block not found")
+			return
+		}
+		h.logger.Error("failed to delete donor block", "block_id", id, "error", err)
+		h.respondError(w, http.StatusInternalServerError, "DELETE_FAILED", "Failed to delete block")
+		return
+	}
+
+	entry := auditEntry(r, service.AuditActionBlockDelete, service.AuditResourceDonorBlock, &id)
+	entry.Before = toDonorBlockResponse(block)
+	h.audit.Record(r.Context(), entry)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListRules handles GET /api/v1/admin/fraud/rules
+func (h *FraudHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.fraudService.ListRules(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list fraud rules", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "LIST_FAILED", "Failed to list rules")
+		return
+	}
+
+	response := make([]dto.FraudRuleResponse, len(rules))
+	for i, rule := range rules {
+		response[i] = toFraudRuleResponse(rule)
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"rules": response,
+	})
+}
+
+// CreateRule handles POST /api/v1/admin/fraud/rules
+func (h *FraudHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.actorID(w, r)
+	if !ok {
+		return
+	}
+
+	req, ok := h.decodeRule(w, r)
+	if !ok {
+		return
+	}
+
+	rule := &fraud.Rule{CreatedBy: &actorID, Enabled: true}
+	applyRuleRequest(rule, req)
+
+	if err := h.fraudService.CreateRule(r.Context(), rule); err != nil {
+		if errors.Is(err, fraud.ErrInvalidRule) {
+			h.respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+			return
+		}
+		h.logger.Error("failed to create fraud rule", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "CREATE_FAILED", "Failed to create rule")
+		return
+	}
+
+	response := toFraudRuleResponse(rule)
+
+	entry := auditEntry(r, service.AuditActionFraudRuleCreate, service.AuditResourceFraudRule, &rule.ID)
+	entry.After = response
+	h.audit.Record(r.Context(), entry)
+
+	h.respondJSON(w, http.StatusCreated, response)
+}
+
+// UpdateRule handles PUT /api/v1/admin/fraud/rules/{id}
+func (h *FraudHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "INVALID_ID", "Invalid rule ID")
+		return
+	}
+
+	req, ok := h.decodeRule(w, r)
+	if !ok {
+		return
+	}
+
+	rule, err := h.fraudService.GetRule(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrFraudRuleNotFound) {
+			h.respondError(w, http.StatusNotFound, "NOT_FOUND", "Rule not found")
+			return
+		}
+		h.logger.Error("failed to get fraud rule", "rule_id", id, "error", err)
+		h.respondError(w, http.StatusInternalServerError, "UPDATE_FAILED", "Failed to update rule")
+		return
+	}
+
+	before := toFraudRuleResponse(rule)
+	applyRuleRequest(rule, req)
+
+	if err := h.fraudService.UpdateRule(r.Context(), rule); err != nil {
+		switch {
+		case errors.Is(err, fraud.ErrInvalidRule):
+			h.respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		case errors.Is(err, service.ErrFraudRuleNotFound):
+			h.respondError(w, http.StatusNotFound, "NOT_FOUND", "Rule not found")
+		default:
+			h.logger.Error("failed to update fraud rule", "rule_id", id, "error", err)
+			h.respondError(w, http.StatusInternalServerError, "UPDATE_FAILED", "Failed to update rule")
+		}
+		return
+	}
+
+	response := toFraudRuleResponse(rule)
+
+	entry := auditEntry(r, service.AuditActionFraudRuleUpdate, service.AuditResourceFraudRule, &id)
+	entry.Before = before
+	entry.After = response
+	h.audit.Record(r.Context(), entry)
+
+	h.respondJSON(w, http.StatusOK, response)
+}
+
+// DeleteRule handles DELETE /api/v1/admin/fraud/rules/{id}
+func (h *FraudHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "INVALID_ID", "Invalid rule ID")
+		return
+	}
+
+	rule, err := h.fraudService.GetRule(r.Context(), id)
+	if err == nil {
+		err = h.fraudService.DeleteRule(r.Context(), id)
+	}
+	if err != nil {
+		if errors.Is(err, service.ErrFraudRuleNotFound) {
+			h.respondError(w, http.StatusNotFound, "NOT_FOUND", "Rule not found")
+			return
+		}
+		h.logger.Error("failed to delete fraud rule", "rule_id", id, "error", err)
+		h.respondError(w, http.StatusInternalServerError, "DELETE_FAILED", "Failed to delete rule")
+		return
+	}
+
+	entry := auditEntry(r, service.AuditActionFraudRuleDelete, service.AuditResourceFraudRule, &id)
+	entry.Before = toFraudRuleResponse(rule)
+	h.audit.Record(r.Context(), entry)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDecisions handles GET /api/v1/admin/fraud/decisions
+func (h *FraudHandler) ListDecisions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := postgres.FraudDecisionFilter{
+		Stage:        query.Get("stage"),
+		Action:       query.Get("action"),
+		ReviewStatus: query.Get("review_status"),
+		Page:         parseInt(query.Get("page"), 1),
+		Limit:        parseInt(query.Get("limit"), 50),
+	}
+
+	if v := query.Get("donation_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "INVALID_ID", "Invalid donation ID")
+			return
+		}
+		filter.DonationID = &id
+	}
+
+	result, err := h.fraudService.ListDecisions(r.Context(), filter)
+	if err != nil {
+		h.logger.Error("failed to list fraud decisions", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "LIST_FAILED", "Failed to list decisions")
+		return
+	}
+
+	// Donor emails are only shown to roles allowed to see personal data
+	showPII := middleware.Can(r.Context(), middleware.PermDonorPII)
+
+	decisions := make([]dto.FraudDecisionResponse, len(result.Decisions))
+	for i, d := range result.Decisions {
+		decisions[i] = toFraudDecisionResponse(d, showPII)
+	}
+
+	h.respondJSON(w, http.StatusOK, dto.ListFraudDecisionsResponse{
+		Decisions: decisions,
+		Pagination: dto.PaginationResponse{
+			Page:       result.Page,
+			Limit:      result.Limit,
+			Total:      result.Total,
+			TotalPages: result.TotalPages,
+		},
+	})
+}
+
+// ApproveDecision handles POST /api/v1/admin/fraud/decisions/{id}/approve.
+// A held overlay alert is released.
+func (h *FraudHandler) ApproveDecision(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, true)
+}
+
+// RejectDecision handles POST /api/v1/admin/fraud/decisions/{id}/reject.
+// A held overlay alert stays held.
+func (h *FraudHandler) RejectDecision(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, false)
+}
+
+// review records the outcome of reviewing a decision
+func (h *FraudHandler) review(w http.ResponseWriter, r *http.Request, approve bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "INVALID_ID", "Invalid decision ID")
+		return
+	}
+
+	actorID, ok := h.actorID(w, r)
+	if !ok {
+		return
+	}
+
+	decision, err := h.fraudService.Review(r.Context(), id, approve, actorID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrFraudDecisionNotFound):
+			h.respondError(w, http.StatusNotFound, "NOT_FOUND", "Decision not found")
+		case errors.Is(err, service.ErrDecisionNotPending):
+			h.respondError(w, http.StatusConflict, "ALREADY_REVIEWED", err.Error())
+		default:
+			h.logger.Error("failed to review fraud decision", "decision_id", id, "error", err)
+			h.respondError(w, http.StatusInternalServerError, "REVIEW_FAILED", "Failed to review decision")
+		}
+		return
+	}
+
+	released := false
+	if approve && decision.Action == fraud.ActionHold && decision.Subject.DonationID != nil {
+		released, err = h.donationService.ReleaseHeldAlert(r.Context(), *decision.Subject.DonationID)
+		if err != nil {
+			h.logger.Error("failed to release held alert", "decision_id", id, "donation_id", decision.Subject.DonationID, "error", err)
+			released = false
+		}
+	}
+
+	action := service.AuditActionDecisionReject
+	status := fraud.ReviewRejected
+	if approve {
+		action = service.AuditActionDecisionApprove
+		status = fraud.ReviewApproved
+	}
+
+	entry := auditEntry(r, action, service.AuditResourceFraudDecision, &id)
+	entry.Before = map[string]interface{}{"review_status": decision.ReviewStatus}
+	entry.After = map[string]interface{}{"review_status": status}
+	entry.Details = map[string]interface{}{
+		"action":         decision.Action,
+		"donation_id":    decision.Subject.DonationID,
+		"alert_released": released,
+	}
+	h.audit.Record(r.Context(), entry)
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"review_status":  status,
+		"alert_released": released,
+	})
+}
+
+// actorID returns the admin making the request, responding when there is none
+func (h *FraudHandler) actorID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	claims := middleware.GetClaims(r.Context())
+	actorID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		h.respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Invalid user in token")
+		return uuid.Nil, false
+	}
+	return actorID, true
+}
+
+// decodeRule reads and validates a rule request
+func (h *FraudHandler) decodeRule(w http.ResponseWriter, r *http.Request) (*dto.FraudRuleRequest, bool) {
+	var req dto.FraudRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "INVALID_JSON", "Invalid request body")
+		return nil, false
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		h.respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", formatValidationErrors(validationErrors))
+		return nil, false
+	}
+
+	return &req, true
+}
+
+// applyRuleRequest copies a rule request onto a rule
+func applyRuleRequest(rule *fraud.Rule, req *dto.FraudRuleRequest) {
+	rule.Name = req.Name
+	rule.Stage = fraud.Stage(req.Stage)
+	rule.Condition = fraud.Condition(req.Condition)
+	rule.Action = fraud.Action(req.Action)
+	rule.Params = fraud.RuleParams{
+		Threshold:    req.Params.Threshold,
+		Window:       req.Params.Window,
+		MinAmount:    req.Params.MinAmount,
+		NewDonorOnly: req.Params.NewDonorOnly,
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+}
+
+// toDonorBlockResponse converts a block to its response
+func toDonorBlockResponse(b *fraud.Block) dto.DonorBlockResponse {
+	return dto.DonorBlockResponse{
+		ID:        b.ID,
+		Type:      string(b.Type),
+		Value:     b.Value,
+		Reason:    b.Reason,
+		CreatedBy: b.CreatedBy,
+		ExpiresAt: b.ExpiresAt,
+		CreatedAt: b.CreatedAt,
+	}
+}
+
+// toFraudRuleResponse converts a rule to its response
+func toFraudRuleResponse(rule *fraud.Rule) dto.FraudRuleResponse {
+	return dto.FraudRuleResponse{
+		ID:        rule.ID,
+		Name:      rule.Name,
+		Stage:     string(rule.Stage),
+		Condition: string(rule.Condition),
+		Params: dto.FraudRuleParams{
+			Threshold:    rule.Params.Threshold,
+			Window:       rule.Params.Window,
+			MinAmount:    rule.Params.MinAmount,
+			NewDonorOnly: rule.Params.NewDonorOnly,
+		},
+		Action:    string(rule.Action),
+		Enabled:   rule.Enabled,
+		CreatedBy: rule.CreatedBy,
+		CreatedAt: rule.CreatedAt,
+		UpdatedAt: rule.UpdatedAt,
+	}
+}
+
+// toFraudDecisionResponse converts a decision to its response
+func toFraudDecisionResponse(d *fraud.Decision, showPII bool) dto.FraudDecisionResponse {
+	matches := make([]dto.FraudMatchResponse, len(d.Matches))
+	for i, m := range d.Matches {
+		matches[i] = dto.FraudMatchResponse{
+			BlockID: m.BlockID,
+			RuleID:  m.RuleID,
+			Action:  string(m.Action),
+			Reason:  m.Reason,
+		}
+	}
+
+	response := dto.FraudDecisionResponse{
+		ID:           d.ID,
+		Stage:        string(d.Stage),
+		Action:       string(d.Action),
+		DonationID:   d.Subject.DonationID,
+		PaymentID:    d.Subject.PaymentID,
+		DonorName:    d.Subject.Name,
+		ClientIP:     d.Subject.IP,
+		Amount:       d.Subject.Amount,
+		Matches:      matches,
+		ReviewStatus: d.ReviewStatus,
+		ReviewedBy:   d.ReviewedBy,
+		ReviewedAt:   d.ReviewedAt,
+		CreatedAt:    d.CreatedAt,
+	}
+	if showPII {
+		response.DonorEmail = d.Subject.Email
+	}
+
+	return response
+}
+
+// respondJSON sends JSON response
+func (h *FraudHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+// respondError sends error response
+func (h *FraudHandler) respondError(w http.ResponseWriter, status int, code, message string) {
+	h.respondJSON(w, status, dto.ErrorResponse{
+		Error:   code,
+		Message: message,
+	})
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/reveegate/reveegate/internal/http/dto"
+	"github.com/reveegate/reveegate/internal/service"
+)
+
+// HealthHandler handles liveness, readiness and detailed health requests
+type HealthHandler struct {
+	health  *service.HealthService
+	version string
+	logger  *slog.Logger
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(health *service.HealthService, version string, logger *slog.Logger) *HealthHandler {
+	return &HealthHandler{
+		health:  health,
+		version: version,
+		logger:  logger,
+	}
+}
+
+// Live handles GET /health. It only shows the process is serving requests, so
+// a database or Redis outage does not get the server restarted.
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	h.respondJSON(w, http.StatusOK, dto.HealthResponse{
+		Status:    "ok",
+		Timestamp: time.Now().Format(time.RFC3339),
+	})
+}
+
+// Ready handles GET /ready. It fails with 503 when the database, Redis or the
+// pub/sub subscriptions are down, and while the server is shutting down.
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	report := h.health.Readiness(r.Context())
+
+	response := h.summarize(report)
+	status := http.StatusOK
+	response.Status = "ready"
+	if !report.Ready {
+		status = http.StatusServiceUnavailable
+		response.Status = "not_ready"
+	}
+
+	h.respondJSON(w, status, response)
+}
+
+// Detailed handles GET /api/v1/admin/health
+func (h *HealthHandler) Detailed(w http.ResponseWriter, r *http.Request) {
+	report := h.health.Check(r.Context())
+
+	response := h.summarize(report)
+	response.Version = h.version
+	response.Components = make(map[string]dto.ComponentHealthResponse, len(report.Components))
+	for name, c := range report.Components {
+		response.Components[name] = dto.ComponentHealthResponse{
+			Status:    c.Status,
+			Critical:  c.Critical,
+			LatencyMs: float64(c.Latency.Microseconds()) / 1000,
+			Error:     c.Error,
+			Details:   c.Details,
+			CheckedAt: c.CheckedAt,
+		}
+	}
+
+	h.respondJSON(w, http.StatusOK, response)
+}
+
+// summarize converts a report to a response with one status per component
+func (h *HealthHandler) summarize(report *service.HealthReport) dto.HealthResponse {
+	response := dto.HealthResponse{
+		Status:    report.Status,
+		Services:  make(map[string]string, len(report.Components)),
+		Timestamp: report.CheckedAt.Format(time.RFC3339),
+	}
+	for name, c := range report.Components {
+		response.Services[name] = c.Status
+	}
+	return response
+}
+
+// respondJSON sends JSON response. Health results must not be cached.
+func (h *HealthHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
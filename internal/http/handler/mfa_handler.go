@@ -0,0 +1,371 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+
+	"github.com/reveegate/reveegate/internal/http/dto"
+	"github.com/reveegate/reveegate/internal/http/middleware"
+	"github.com/reveegate/reveegate/internal/repository/postgres"
+	"github.com/reveegate/reveegate/internal/service"
+)
+
+// MFAHandler handles two-factor authentication HTTP requests
+type MFAHandler struct {
+	mfaService     *service.MFAService
+	sessionService *service.SessionService
+	loginGuard     *service.LoginGuard
+	audit          *service.AuditService
+	adminRepo      *postgres.AdminRepository
+	validator      *validator.Validate
+	logger         *slog.Logger
+}
+
+// NewMFAHandler creates a new MFA handler
+func NewMFAHandler(
+	mfaService *service.MFAService,
+	sessionService *service.SessionService,
+	loginGuard *service.LoginGuard,
+	audit *service.AuditService,
+	adminRepo *postgres.AdminRepository,
+	validator *validator.Validate,
+	logger *slog.Logger,
+) *MFAHandler {
+	return &MFAHandler{
+		mfaService:     mfaService,
+		sessionService: sessionService,
+		loginGuard:     loginGuard,
+		audit:          audit,
+		adminRepo:      adminRepo,
+		validator:      validator,
+		logger:         logger,
+	}
+}
+
+// VerifyLogin handles POST /api/v1/admin/login/mfa
+func (h *MFAHandler) VerifyLogin(w http.ResponseWriter, r *http.Request) {
+	var req dto.MFALoginRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	userID, err := h.mfaService.ChallengeSubject(req.MFAToken)
+	if err != nil {
+		h.respondMFAError(w, err)
+		return
+	}
+
+	// Wrong codes count against the same limits as wrong passwords
+	attempt := service.LoginAttempt{
+		IP:        clientIP(r),
+		AdminID:   &userID,
+		UserAgent: r.UserAgent(),
+	}
+	reservation, wait, err := h.loginGuard.Reserve(r.Context(), attempt)
+	if err != nil {
+		respondLoginBlocked(w, wait, err, h.respondError)
+		return
+	}
+
+	admin, recoveryCodes, err := h.mfaService.CompleteLogin(r.Context(), req.MFAToken, req.Code, req.RecoveryCode)
+	if err != nil {
+		if errors.Is(err, service.ErrMFAInvalidCode) {
+			h.loginGuard.RecordFailure(r.Context(), reservation)
+			h.audit.Record(r.Context(), loginFailureEntry(r, userID, "invalid_mfa_code"))
+		} else {
+			h.loginGuard.Release(r.Context(), reservation)
+		}
+		h.respondMFAError(w, err)
+		return
+	}
+
+	h.loginGuard.RecordSuccess(r.Context(), reservation)
+
+	if err := h.adminRepo.UpdateLastLogin(r.Context(), admin.ID); err != nil {
+		h.logger.Error("failed to update last login", "error", err)
+	}
+
+	tokens, err := h.sessionService.Start(r.Context(), admin, clientIP(r), r.UserAgent())
+	if err != nil {
+		h.logger.Error("failed to start session", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "TOKEN_ERROR", "Failed to generate tokens")
+		return
+	}
+
+	h.logger.Info("admin login successful",
+		"admin_id", admin.ID,
+		"username", admin.Username,
+		"role", admin.Role,
+		"session_id", tokens.SessionID,
+		"mfa", true,
+	)
+
+	// Recovery codes are only returned when enrollment finished during login
+	if len(recoveryCodes) > 0 {
+		h.recordMFAChange(r, service.AuditActionMFAEnable, admin.ID, true)
+	}
+	h.audit.Record(r.Context(), loginSuccessEntry(r, admin.ID, tokens.SessionID, true))
+
+	response := buildLoginResponse(tokens)
+	response.RecoveryCodes = recoveryCodes
+	h.respondJSON(w, http.StatusOK, response)
+}
+
+// EnrollWithChallenge handles POST /api/v1/admin/login/mfa/enroll
+func (h *MFAHandler) EnrollWithChallenge(w http.ResponseWriter, r *http.Request) {
+	var req dto.MFAChallengeRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	enrollment, err := h.mfaService.EnrollWithChallenge(r.Context(), req.MFAToken)
+	if err != nil {
+		h.respondMFAError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, dto.MFAEnrollmentResponse{
+		Secret:          enrollment.Secret,
+		ProvisioningURI: enrollment.ProvisioningURI,
+	})
+}
+
+// GetStatus handles GET /api/v1/admin/mfa
+func (h *MFAHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := h.mfaService.Status(r.Context(), userID)
+	if err != nil {
+		h.respondMFAError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, dto.MFAStatusResponse{
+		Enabled:                status.Enabled,
+		Required:               status.Required,
+		RecoveryCodesRemaining: status.RecoveryCodesRemaining,
+	})
+}
+
+// Enroll handles POST /api/v1/admin/mfa/enroll
+func (h *MFAHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.mfaService.Enroll(r.Context(), userID)
+	if err != nil {
+		h.respondMFAError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, dto.MFAEnrollmentResponse{
+		Secret:          enrollment.Secret,
+		ProvisioningURI: enrollment.ProvisioningURI,
+	})
+}
+
+// Confirm handles POST /api/v1/admin/mfa/confirm
+func (h *MFAHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.MFACodeRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	codes, err := h.mfaService.Confirm(r.Context(), userID, req.Code)
+	if err != nil {
+		h.respondMFAError(w, err)
+		return
+	}
+
+	h.recordMFAChange(r, service.AuditActionMFAEnable, userID, true)
+
+	h.respondJSON(w, http.StatusOK, dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Disable handles POST /api/v1/admin/mfa/disable
+func (h *MFAHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.MFACodeRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	if err := h.mfaService.Disable(r.Context(), userID, req.Code); err != nil {
+		h.respondMFAError(w, err)
+		return
+	}
+
+	h.recordMFAChange(r, service.AuditActionMFADisable, userID, false)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes handles POST /api/v1/admin/mfa/recovery-codes
+func (h *MFAHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.MFACodeRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(r.Context(), userID, req.Code)
+	if err != nil {
+		h.respondMFAError(w, err)
+		return
+	}
+
+	entry := auditEntry(r, service.AuditActionMFARecoveryCodes, service.AuditResourceAdminUser, &userID)
+	entry.Details = map[string]interface{}{"recovery_codes": len(codes)}
+	h.audit.Record(r.Context(), entry)
+
+	h.respondJSON(w, http.StatusOK, dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// GetPolicy handles GET /api/v1/admin/settings/mfa
+func (h *MFAHandler) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	required, err := h.mfaService.IsRequired(r.Context())
+	if err != nil {
+		h.respondMFAError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]bool{"required": required})
+}
+
+// UpdatePolicy handles PUT /api/v1/admin/settings/mfa
+func (h *MFAHandler) UpdatePolicy(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.MFAPolicyRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	wasRequired, err := h.mfaService.IsRequired(r.Context())
+	if err != nil {
+		h.respondMFAError(w, err)
+		return
+	}
+
+	if err := h.mfaService.SetRequired(r.Context(), userID, *req.Required); err != nil {
+		if errors.Is(err, service.ErrMFANotEnrolled) {
+			h.respondError(w, http.StatusConflict, "MFA_NOT_ENROLLED", "Enable two-factor authentication on your own account before enforcing it")
+			return
+		}
+		h.respondMFAError(w, err)
+		return
+	}
+
+	entry := auditEntry(r, service.AuditActionMFAPolicyUpdate, service.AuditResourceSettings, nil)
+	entry.Before = map[string]bool{"mfa_required": wasRequired}
+	entry.After = map[string]bool{"mfa_required": *req.Required}
+	h.audit.Record(r.Context(), entry)
+
+	h.respondJSON(w, http.StatusOK, map[string]bool{"required": *req.Required})
+}
+
+// recordMFAChange records an admin turning two-factor authentication on or off
+func (h *MFAHandler) recordMFAChange(r *http.Request, action string, userID uuid.UUID, enabled bool) {
+	entry := auditEntry(r, action, service.AuditResourceAdminUser, &userID)
+	entry.ActorID = &userID
+	entry.Before = map[string]bool{"mfa_enabled": !enabled}
+	entry.After = map[string]bool{"mfa_enabled": enabled}
+	h.audit.Record(r.Context(), entry)
+}
+
+// currentUserID returns the authenticated admin's ID
+func (h *MFAHandler) currentUserID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	claims := middleware.GetClaims(r.Context())
+	if claims == nil {
+		h.respondError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Not authenticated")
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		h.respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Invalid user in token")
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+// decode decodes and validates a JSON request body
+func (h *MFAHandler) decode(w http.ResponseWriter, r *http.Request, req interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "INVALID_JSON", "Invalid request body")
+		return false
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		h.respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", formatValidationErrors(validationErrors))
+		return false
+	}
+
+	return true
+}
+
+// respondMFAError maps MFA service errors to responses
+func (h *MFAHandler) respondMFAError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrMFAInvalidCode):
+		h.respondError(w, http.StatusUnauthorized, "INVALID_MFA_CODE", "Invalid or already used code")
+	case errors.Is(err, service.ErrMFAChallengeInvalid):
+		h.respondError(w, http.StatusUnauthorized, "INVALID_MFA_TOKEN", "Login challenge is invalid or expired, please log in again")
+	case errors.Is(err, service.ErrAccountInactive):
+		h.respondError(w, http.StatusForbidden, "ACCOUNT_INACTIVE", "Your account has been deactivated")
+	case errors.Is(err, service.ErrMFANotEnrolled):
+		h.respondError(w, http.StatusConflict, "MFA_NOT_ENROLLED", "Two-factor authentication is not set up")
+	case errors.Is(err, service.ErrMFAAlreadyEnabled):
+		h.respondError(w, http.StatusConflict, "MFA_ALREADY_ENABLED", "Two-factor authentication is already enabled")
+	case errors.Is(err, service.ErrMFARequired):
+		h.respondError(w, http.StatusConflict, "MFA_REQUIRED", "Two-factor authentication is required for all admins")
+	case errors.Is(err, service.ErrMFAUnavailable):
+		h.respondError(w, http.StatusServiceUnavailable, "MFA_UNAVAILABLE", "Two-factor authentication is not configured on this server")
+	default:
+		h.logger.Error("MFA request failed", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "SERVER_ERROR", "Internal server error")
+	}
+}
+
+// respondJSON sends JSON response
+func (h *MFAHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+// respondError sends error response
+func (h *MFAHandler) respondError(w http.ResponseWriter, status int, code, message string) {
+	h.respondJSON(w, status, dto.ErrorResponse{
+		Error:   code,
+		Message: message,
+	})
+}
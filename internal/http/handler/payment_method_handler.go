@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/reveegate/reveegate/internal/domain/payment"
+	"github.com/reveegate/reveegate/internal/http/dto"
+	"github.com/reveegate/reveegate/internal/service"
+)
+
+// PaymentMethodHandler handles payment method catalogue HTTP requests
+type PaymentMethodHandler struct {
+	methodService *service.PaymentMethodService
+	audit         *service.AuditService
+	validator     *validator.Validate
+	logger        *slog.Logger
+}
+
+// NewPaymentMethodHandler creates a new payment method handler
+func NewPaymentMethodHandler(
+	methodService *service.PaymentMethodService,
+	audit *service.AuditService,
+	validator *validator.Validate,
+	logger *slog.Logger,
+) *PaymentMethodHandler {
+	return &PaymentMethodHandler{
+		methodService: methodService,
+		audit:         audit,
+		validator:     validator,
+		logger:        logger,
+	}
+}
+
+// ListAvailable handles GET /api/v1/payment-methods
+func (h *PaymentMethodHandler) ListAvailable(w http.ResponseWriter, r *http.Request) {
+	methods, err := h.methodService.ListAvailable(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list payment methods", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "LIST_FAILED", "Failed to list payment methods")
+		return
+	}
+
+	response := make([]dto.PaymentMethodResponse, len(methods))
+	for i, m := range methods {
+		response[i] = dto.PaymentMethodResponse{
+			Method:      string(m.Method),
+			DisplayName: m.DisplayName,
+			Category:    string(m.Category),
+			MinAmount:   m.MinAmount,
+			MaxAmount:   m.MaxAmount,
+		}
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"payment_methods": response,
+	})
+}
+
+// List handles GET /api/v1/admin/payment-methods
+func (h *PaymentMethodHandler) List(w http.ResponseWriter, r *http.Request) {
+	methods, err := h.methodService.List(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list payment methods", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "LIST_FAILED", "Failed to list payment methods")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"payment_methods": methods,
+	})
+}
+
+// Update handles PATCH /api/v1/admin/payment-methods/{method}
+func (h *PaymentMethodHandler) Update(w http.ResponseWriter, r *http.Request) {
+	method := payment.Method(chi.URLParam(r, "method"))
+
+	var req dto.UpdatePaymentMethodRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "INVALID_JSON", "Invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		h.respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", formatValidationErrors(validationErrors))
+		return
+	}
+
+	params := service.UpdateMethodParams{
+		DisplayName:  req.DisplayName,
+		MinAmount:    req.MinAmount,
+		MaxAmount:    req.MaxAmount,
+		Enabled:      req.Enabled,
+		DisplayOrder: req.DisplayOrder,
+	}
+
+	if req.Maintenance != nil {
+		windows := make([]payment.MaintenanceWindow, len(*req.Maintenance))
+		for i, w := range *req.Maintenance {
+			windows[i] = payment.MaintenanceWindow{
+				StartsAt: w.StartsAt,
+				EndsAt:   w.EndsAt,
+				Reason:   w.Reason,
+			}
+		}
+		params.Maintenance = &windows
+	}
+
+	before, err := h.methodService.Get(r.Context(), method)
+	if err != nil {
+		h.respondError(w, http.StatusNotFound, "NOT_FOUND", "Payment method not found")
+		return
+	}
+
+	updated, err := h.methodService.Update(r.Context(), method, params)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrPaymentMethodMissing):
+			h.respondError(w, http.StatusNotFound, "NOT_FOUND", "Payment method not found")
+		case errors.Is(err, service.ErrInvalidMethodConfig):
+			h.respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		default:
+			h.logger.Error("failed to update payment method", "method", method, "error", err)
+			h.respondError(w, http.StatusInternalServerError, "UPDATE_FAILED", "Failed to update payment method")
+		}
+		return
+	}
+
+	// Methods are keyed by name rather than ID, so the name goes in the details
+	entry := auditEntry(r, service.AuditActionPaymentMethodUpdate, service.AuditResourcePaymentMethod, nil)
+	entry.Before = before
+	entry.After = updated
+	entry.Details = map[string]interface{}{"method": method}
+	h.audit.Record(r.Context(), entry)
+
+	h.respondJSON(w, http.StatusOK, updated)
+}
+
+// respondJSON sends JSON response
+func (h *PaymentMethodHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+// respondError sends error response
+func (h *PaymentMethodHandler) respondError(w http.ResponseWriter, status int, code, message string) {
+	h.respondJSON(w, status, dto.ErrorResponse{
+		Error:   code,
+		Message: message,
+	})
+}
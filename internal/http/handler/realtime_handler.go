@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/reveegate/reveegate/internal/http/dto"
+	redisRepo "github.com/reveegate/reveegate/internal/repository/redis"
+)
+
+// realtimeClientOverlay is the presence type of overlay connections
+const realtimeClientOverlay = "overlay"
+
+// ClusterPresence lists realtime clients across all server instances
+type ClusterPresence interface {
+	ClusterPresence(ctx context.Context) ([]redisRepo.InstancePresence, error)
+	InstanceID() string
+}
+
+// RealtimeHandler handles realtime connection management requests
+type RealtimeHandler struct {
+	presence ClusterPresence
+	logger   *slog.Logger
+}
+
+// NewRealtimeHandler creates a new realtime handler
+func NewRealtimeHandler(presence ClusterPresence, logger *slog.Logger) *RealtimeHandler {
+	return &RealtimeHandler{
+		presence: presence,
+		logger:   logger,
+	}
+}
+
+// Presence handles GET /api/v1/admin/realtime/presence
+func (h *RealtimeHandler) Presence(w http.ResponseWriter, r *http.Request) {
+	instances, err := h.presence.ClusterPresence(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list realtime presence", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "PRESENCE_FAILED", "Failed to list realtime presence")
+		return
+	}
+
+	response := dto.RealtimePresenceResponse{
+		InstanceID: h.presence.InstanceID(),
+		Instances:  make([]dto.InstancePresenceResponse, len(instances)),
+	}
+	for i, instance := range instances {
+		clients := make([]dto.RealtimeClientResponse, len(instance.Clients))
+		overlays := 0
+		for j, client := range instance.Clients {
+			clients[j] = dto.RealtimeClientResponse{
+				ID:          client.ID,
+				Type:        client.Type,
+				Channel:     client.Channel,
+				UserID:      client.UserID,
+				ConnectedAt: client.ConnectedAt,
+			}
+			if client.Type == realtimeClientOverlay {
+				overlays++
+			}
+		}
+
+		response.Instances[i] = dto.InstancePresenceResponse{
+			InstanceID: instance.InstanceID,
+			LastSeen:   instance.LastSeen,
+			Clients:    clients,
+		}
+		response.TotalClients += len(clients)
+		response.TotalOverlays += overlays
+	}
+
+	h.respondJSON(w, http.StatusOK, response)
+}
+
+// respondJSON sends JSON response
+func (h *RealtimeHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+// respondError sends error response
+func (h *RealtimeHandler) respondError(w http.ResponseWriter, status int, code, message string) {
+	h.respondJSON(w, status, dto.ErrorResponse{
+		Error:   code,
+		Message: message,
+	})
+}
@@ -11,9 +11,13 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/reveegate/reveegate/internal/config"
 	"github.com/reveegate/reveegate/internal/domain/payment"
+	"github.com/reveegate/reveegate/internal/domain/provider"
 	"github.com/reveegate/reveegate/internal/http/dto"
+	"github.com/reveegate/reveegate/internal/metrics"
 	"github.com/reveegate/reveegate/internal/service"
 )
 
@@ -21,6 +25,7 @@ import (
 type WebhookHandler struct {
 	donationService *service.DonationService
 	webhookLogRepo  payment.WebhookLogRepository
+	providers       provider.ProviderFactory
 	config          *config.Config
 	logger          *slog.Logger
 }
@@ -29,12 +34,14 @@ type WebhookHandler struct {
 func NewWebhookHandler(
 	donationService *service.DonationService,
 	webhookLogRepo payment.WebhookLogRepository,
+	providers provider.ProviderFactory,
 	cfg *config.Config,
 	logger *slog.Logger,
 ) *WebhookHandler {
 	return &WebhookHandler{
 		donationService: donationService,
 		webhookLogRepo:  webhookLogRepo,
+		providers:       providers,
 		config:          cfg,
 		logger:          logger,
 	}
@@ -42,6 +49,8 @@ func NewWebhookHandler(
 
 // HandleMidtrans handles Midtrans webhook POST /api/v1/webhooks/midtrans
 func (h *WebhookHandler) HandleMidtrans(w http.ResponseWriter, r *http.Request) {
+	receivedAt := time.Now()
+
 	// Read body
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -63,6 +72,8 @@ func (h *WebhookHandler) HandleMidtrans(w http.ResponseWriter, r *http.Request)
 
 	// Verify signature
 	if !h.verifyMidtransSignature(webhook) {
+		metrics.WebhookVerificationFailuresTotal.WithLabelValues(string(payment.ProviderMidtrans)).Inc()
+		h.donationService.WebhookRejected(r.Context(), payment.ProviderMidtrans, "invalid_signature", h.getClientIP(r))
 		h.logger.Warn("invalid midtrans signature",
 			"order_id", webhook.OrderID,
 			"signature", webhook.SignatureKey,
@@ -88,6 +99,7 @@ func (h *WebhookHandler) HandleMidtrans(w http.ResponseWriter, r *http.Request)
 		Status:        status,
 		PaidAt:        paidAt,
 		RawPayload:    body,
+		ReceivedAt:    receivedAt,
 	})
 	if err != nil {
 		h.logger.Error("failed to process midtrans webhook",
@@ -113,11 +125,36 @@ func (h *WebhookHandler) HandleMidtrans(w http.ResponseWriter, r *http.Request)
 
 // HandleXendit handles Xendit webhook POST /api/v1/webhooks/xendit
 func (h *WebhookHandler) HandleXendit(w http.ResponseWriter, r *http.Request) {
-	// Verify callback token
-	callbackToken := r.Header.Get("x-callback-token")
-	if callbackToken != h.config.Xendit.WebhookToken {
-		h.logger.Warn("invalid xendit callback token")
-		h.respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Invalid callback token")
+	// Xendit authenticates callbacks with a static callback token
+	h.handleProviderWebhook(w, r, payment.ProviderXendit, r.Header.Get("x-callback-token"))
+}
+
+// HandleTripay handles Tripay webhook POST /api/v1/webhooks/tripay
+func (h *WebhookHandler) HandleTripay(w http.ResponseWriter, r *http.Request) {
+	// Only payment status callbacks are sent to this endpoint
+	if event := r.Header.Get("X-Callback-Event"); event != "payment_status" {
+		h.respondError(w, http.StatusBadRequest, "INVALID_EVENT", "Unrecognized callback event")
+		return
+	}
+
+	h.handleProviderWebhook(w, r, payment.ProviderTripay, r.Header.Get("X-Callback-Signature"))
+}
+
+// HandleDuitku handles Duitku webhook POST /api/v1/webhooks/duitku
+func (h *WebhookHandler) HandleDuitku(w http.ResponseWriter, r *http.Request) {
+	// Duitku sends the signature inside the form-encoded body
+	h.handleProviderWebhook(w, r, payment.ProviderDuitku, "")
+}
+
+// handleProviderWebhook verifies, parses and processes a webhook using the
+// provider's own VerifyWebhook and ParseWebhook implementations
+func (h *WebhookHandler) handleProviderWebhook(w http.ResponseWriter, r *http.Request, name payment.Provider, signature string) {
+	receivedAt := time.Now()
+
+	prov, err := h.providers.GetProvider(name)
+	if err != nil {
+		h.logger.Error("webhook for unconfigured provider", "provider", name, "error", err)
+		h.respondError(w, http.StatusNotFound, "PROVIDER_NOT_CONFIGURED", "Payment provider is not configured")
 		return
 	}
 
@@ -129,40 +166,59 @@ func (h *WebhookHandler) HandleXendit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Verify signature
+	if err := prov.VerifyWebhook(body, signature); err != nil {
+		metrics.WebhookVerificationFailuresTotal.WithLabelValues(string(name)).Inc()
+		h.donationService.WebhookRejected(r.Context(), name, "invalid_signature", h.getClientIP(r))
+		h.logger.Warn("invalid webhook signature",
+			"provider", name,
+			"error", err,
+		)
+		h.respondError(w, http.StatusUnauthorized, "INVALID_SIGNATURE", "Invalid webhook signature")
+		return
+	}
+
 	// Log webhook
-	h.logWebhook(r, payment.ProviderXendit, body)
+	h.logWebhook(r, name, body)
 
 	// Parse webhook
-	var webhook dto.XenditWebhook
-	if err := json.Unmarshal(body, &webhook); err != nil {
-		h.logger.Error("failed to parse webhook", "error", err)
-		h.respondError(w, http.StatusBadRequest, "INVALID_JSON", "Invalid webhook payload")
+	webhook, err := prov.ParseWebhook(body)
+	if err != nil {
+		h.logger.Error("failed to parse webhook", "provider", name, "error", err)
+		h.respondError(w, http.StatusBadRequest, "INVALID_PAYLOAD", "Invalid webhook payload")
 		return
 	}
 
-	// Map Xendit status to internal status
-	status := h.mapXenditStatus(webhook.Status)
-
-	// Parse paid time
-	var paidAt time.Time
-	if webhook.PaidAt != "" {
-		paidAt, _ = time.Parse(time.RFC3339, webhook.PaidAt)
+	// Lifecycle callbacks (e.g. VA created) don't change the payment
+	if webhook.Status == payment.StatusPending {
+		h.logger.Debug("webhook ignored",
+			"provider", name,
+			"event", webhook.EventType,
+			"order_id", webhook.OrderID,
+		)
+		h.respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+		return
 	}
 
 	// Process webhook
 	err = h.donationService.ProcessWebhook(r.Context(), service.ProcessWebhookParams{
-		Provider:      payment.ProviderXendit,
-		OrderID:       webhook.ExternalID,
-		TransactionID: webhook.ID,
-		Status:        status,
-		PaidAt:        paidAt,
+		Provider:      name,
+		OrderID:       webhook.OrderID,
+		TransactionID: webhook.TransactionID,
+		Status:        webhook.Status,
+		PaidAt:        webhook.TransactionTime,
+		Fee:           webhook.Fee,
 		RawPayload:    body,
+		ReceivedAt:    receivedAt,
 	})
 	if err != nil {
-		h.logger.Error("failed to process xendit webhook",
-			"external_id", webhook.ExternalID,
+		h.logger.Error("failed to process webhook",
+			"provider", name,
+			"event", webhook.EventType,
+			"order_id", webhook.OrderID,
 			"error", err,
 		)
+		// Return 200 to prevent retries for non-retriable errors
 		h.respondJSON(w, http.StatusOK, map[string]string{
 			"status":  "error",
 			"message": err.Error(),
@@ -170,10 +226,12 @@ func (h *WebhookHandler) HandleXendit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.logger.Info("xendit webhook processed",
-		"external_id", webhook.ExternalID,
-		"id", webhook.ID,
-		"status", status,
+	h.logger.Info("webhook processed",
+		"provider", name,
+		"event", webhook.EventType,
+		"order_id", webhook.OrderID,
+		"transaction_id", webhook.TransactionID,
+		"status", webhook.Status,
 	)
 
 	h.respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
@@ -212,25 +270,10 @@ func (h *WebhookHandler) mapMidtransStatus(transactionStatus, fraudStatus string
 	}
 }
 
-// mapXenditStatus maps Xendit status to internal status
-func (h *WebhookHandler) mapXenditStatus(status string) payment.Status {
-	switch status {
-	case "PAID", "SETTLED", "COMPLETED":
-		return payment.StatusPaid
-	case "PENDING", "ACTIVE":
-		return payment.StatusPending
-	case "EXPIRED":
-		return payment.StatusExpired
-	case "FAILED":
-		return payment.StatusFailed
-	default:
-		return payment.StatusPending
-	}
-}
-
 // logWebhook logs webhook to database
 func (h *WebhookHandler) logWebhook(r *http.Request, provider payment.Provider, body []byte) {
 	log := &payment.WebhookLog{
+		ID:        uuid.New(),
 		Provider:  provider,
 		EventType: r.Header.Get("x-event-type"),
 		Payload:   body,
@@ -254,13 +297,15 @@ func (h *WebhookHandler) extractHeaders(r *http.Request) map[string]string {
 		"User-Agent",
 		"X-Request-Id",
 		"X-Callback-Token",
+		"X-Callback-Signature",
+		"X-Callback-Event",
 		"X-Event-Type",
 	}
 
 	for _, name := range relevantHeaders {
 		if val := r.Header.Get(name); val != "" {
 			// Mask sensitive headers
-			if name == "X-Callback-Token" {
+			if name == "X-Callback-Token" || name == "X-Callback-Signature" {
 				val = maskString(val)
 			}
 			headers[name] = val
@@ -272,20 +317,7 @@ func (h *WebhookHandler) extractHeaders(r *http.Request) map[string]string {
 
 // getClientIP gets client IP from request
 func (h *WebhookHandler) getClientIP(r *http.Request) string {
-	// Check X-Forwarded-For header
-	forwarded := r.Header.Get("X-Forwarded-For")
-	if forwarded != "" {
-		// Return first IP in the chain
-		return forwarded
-	}
-
-	// Check X-Real-IP header
-	realIP := r.Header.Get("X-Real-IP")
-	if realIP != "" {
-		return realIP
-	}
-
-	return r.RemoteAddr
+	return clientIP(r)
 }
 
 // maskString masks a string for logging
@@ -345,6 +377,7 @@ func (h *WebhookHandler) SimulatePaidWebhook(w http.ResponseWriter, r *http.Requ
 		Status:        payment.StatusPaid,
 		PaidAt:        time.Now(),
 		RawPayload:    []byte(`{"simulated": true}`),
+		ReceivedAt:    time.Now(),
 	})
 	if err != nil {
 		h.respondError(w, http.StatusInternalServerError, "PROCESS_FAILED", err.Error())
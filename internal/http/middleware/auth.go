@@ -21,94 +21,186 @@ type ClaimsContextKey struct{}
 
 // Claims represents JWT claims
 type Claims struct {
-	UserID  string `json:"user_id"`
-	Subject string `json:"sub"`
-	Role    string `json:"role"`
+	UserID    string `json:"user_id"`
+	Subject   string `json:"sub"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid"`
+	jwt.RegisteredClaims
+
+	// Set instead of UserID and Role when the request used an API key
+	APIKeyID  string       `json:"-"`
+	Scopes    []Permission `json:"-"`
+	RateLimit int          `json:"-"` // Requests per minute allowed to the key
+}
+
+// IsAPIKey reports whether the request was authenticated with an API key
+func (c *Claims) IsAPIKey() bool {
+	return c.APIKeyID != ""
+}
+
+// ErrAPIKeyInvalid is returned for unknown, revoked or expired API keys
+var ErrAPIKeyInvalid = errors.New("invalid api key")
+
+// APIKeyPrincipal is a machine client authenticated by an API key
+type APIKeyPrincipal struct {
+	ID        string
+	Name      string
+	Scopes    []Permission
+	RateLimit int // Requests per minute
+}
+
+// APIKeyAuthenticator resolves keys sent as "Authorization: ApiKey <key>"
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key, ip string) (*APIKeyPrincipal, error)
+}
+
+// SessionRevocationChecker looks up session revocations in the database
+type SessionRevocationChecker interface {
+	SessionRevoked(ctx context.Context, sessionID string) (bool, error)
+}
+
+// mfaChallengeAudience marks tokens that only prove the password step of login
+const mfaChallengeAudience = "reveegate-mfa"
+
+// MFAChallengeClaims are carried by the token issued between the password and
+// the second factor. They have no session, so they are never accepted as access tokens.
+type MFAChallengeClaims struct {
 	jwt.RegisteredClaims
 }
 
 // Auth middleware provides JWT authentication
 type Auth struct {
-	config config.JWTConfig
-	cache  *redisRepo.Cache
-	logger *slog.Logger
+	config  config.JWTConfig
+	keyring *Keyring
+	cache   *redisRepo.Cache
+	apiKeys APIKeyAuthenticator
+	revoked SessionRevocationChecker
+	logger  *slog.Logger
 }
 
 // NewAuth creates a new Auth middleware
-func NewAuth(cfg config.JWTConfig, cache *redisRepo.Cache, logger *slog.Logger) *Auth {
-	return &Auth{
-		config: cfg,
-		cache:  cache,
-		logger: logger,
+func NewAuth(cfg config.JWTConfig, cache *redisRepo.Cache, logger *slog.Logger) (*Auth, error) {
+	keyring, err := NewKeyring(cfg)
+	if err != nil {
+		return nil, err
 	}
+
+	return &Auth{
+		config:  cfg,
+		keyring: keyring,
+		cache:   cache,
+		logger:  logger,
+	}, nil
+}
+
+// SetAPIKeyAuthenticator enables API key authentication. Without it only
+// bearer tokens are accepted.
+func (a *Auth) SetAPIKeyAuthenticator(apiKeys APIKeyAuthenticator) {
+	a.apiKeys = apiKeys
+}
+
+// SetSessionRevocationChecker sets where revocations are looked up when
+// Redis is unavailable. Without it such requests are rejected.
+func (a *Auth) SetSessionRevocationChecker(revoked SessionRevocationChecker) {
+	a.revoked = revoked
 }
 
-// GenerateTokens generates access and refresh tokens
-func (a *Auth) GenerateTokens(userID, role string) (accessToken, refreshToken string, err error) {
+// GenerateAccessToken generates a short-lived access token bound to a session
+func (a *Auth) GenerateAccessToken(userID, subject, role, sessionID string) (string, time.Time, error) {
 	now := time.Now()
+	expiresAt := now.Add(a.config.AccessTokenTTL)
 
-	// Generate access token
-	accessClaims := &Claims{
-		UserID:  userID,
-		Subject: userID,
-		Role:    role,
+	claims := &Claims{
+		UserID:    userID,
+		Subject:   subject,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(a.config.AccessTokenTTL)),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "reveegate",
-			Subject:   userID,
+			Subject:   subject,
 		},
 	}
 
-	accessTokenObj := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	accessToken, err = accessTokenObj.SignedString([]byte(a.config.Secret))
+	token, err := a.keyring.Sign(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
+
+// ValidateToken validates a JWT token and returns the claims
+func (a *Auth) ValidateToken(tokenString string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, a.keyring.Keyfunc,
+		jwt.WithValidMethods(a.keyring.ValidMethods()),
+		jwt.WithIssuer("reveegate"),
+	)
+
 	if err != nil {
-		return "", "", err
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	if claims.SessionID == "" {
+		return nil, errors.New("token is not bound to a session")
 	}
 
-	// Generate refresh token
-	refreshClaims := &Claims{
-		UserID:  userID,
-		Subject: userID,
-		Role:    role,
+	return claims, nil
+}
+
+// GenerateMFAChallenge generates a short-lived token for the second login step
+func (a *Auth) GenerateMFAChallenge(userID string, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	claims := &MFAChallengeClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(a.config.RefreshTokenTTL)),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "reveegate",
 			Subject:   userID,
+			Audience:  jwt.ClaimStrings{mfaChallengeAudience},
 		},
 	}
 
-	refreshTokenObj := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshToken, err = refreshTokenObj.SignedString([]byte(a.config.Secret))
+	token, err := a.keyring.Sign(claims)
 	if err != nil {
-		return "", "", err
+		return "", time.Time{}, err
 	}
 
-	return accessToken, refreshToken, nil
+	return token, expiresAt, nil
 }
 
-// ValidateToken validates a JWT token and returns the claims
-func (a *Auth) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(a.config.Secret), nil
-	})
-
+// ValidateMFAChallenge validates an MFA challenge token and returns the user ID
+func (a *Auth) ValidateMFAChallenge(tokenString string) (string, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &MFAChallengeClaims{}, a.keyring.Keyfunc,
+		jwt.WithValidMethods(a.keyring.ValidMethods()),
+		jwt.WithIssuer("reveegate"),
+		jwt.WithAudience(mfaChallengeAudience),
+	)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	claims, ok := token.Claims.(*Claims)
-	if !ok || !token.Valid {
-		return nil, errors.New("invalid token")
+	claims, ok := token.Claims.(*MFAChallengeClaims)
+	if !ok || !token.Valid || claims.Subject == "" {
+		return "", errors.New("invalid challenge token")
 	}
 
-	return claims, nil
+	return claims.Subject, nil
+}
+
+// JWKS returns the public keys other services can use to verify admin tokens
+func (a *Auth) JWKS() JWKSet {
+	return a.keyring.JWKS()
 }
 
 // ValidateOverlayToken validates an overlay token
@@ -121,6 +213,23 @@ func (a *Auth) ValidateOverlayToken(ctx context.Context, token string) (bool, er
 	return false, errors.New("invalid overlay token")
 }
 
+// IsSessionRevoked checks if the session an access token belongs to has been
+// logged out. Revocations are kept in Redis for the lifetime of an access
+// token; when Redis fails the database is asked instead, and an error is
+// returned if that fails too, so revoked tokens are never let through.
+func (a *Auth) IsSessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	revoked, err := a.cache.ExistsStrict(ctx, redisRepo.RevokedSessionKey(sessionID))
+	if err == nil {
+		return revoked, nil
+	}
+	if a.revoked == nil {
+		return false, err
+	}
+
+	a.logger.Warn("session revocation cache unavailable, checking database", "error", err)
+	return a.revoked.SessionRevoked(ctx, sessionID)
+}
+
 // HashToken hashes a token for storage
 func HashToken(token string) string {
 	hash := sha256.Sum256([]byte(token))
@@ -138,8 +247,13 @@ func (a *Auth) Middleware() func(http.Handler) http.Handler {
 				return
 			}
 
-			// Check Bearer prefix
 			parts := strings.SplitN(authHeader, " ", 2)
+			if len(parts) == 2 && parts[0] == "ApiKey" && a.apiKeys != nil {
+				a.serveAPIKey(w, r, next, parts[1])
+				return
+			}
+
+			// Check Bearer prefix
 			if len(parts) != 2 || parts[0] != "Bearer" {
 				http.Error(w, "Invalid authorization header format", http.StatusUnauthorized)
 				return
@@ -154,6 +268,17 @@ func (a *Auth) Middleware() func(http.Handler) http.Handler {
 				return
 			}
 
+			revoked, err := a.IsSessionRevoked(r.Context(), claims.SessionID)
+			if err != nil {
+				a.logger.Error("failed to check session revocation", "error", err)
+				http.Error(w, "Unable to verify session", http.StatusServiceUnavailable)
+				return
+			}
+			if revoked {
+				http.Error(w, "Session has been revoked", http.StatusUnauthorized)
+				return
+			}
+
 			// Add claims to context
 			ctx := context.WithValue(r.Context(), ClaimsContextKey{}, claims)
 
@@ -162,6 +287,29 @@ func (a *Auth) Middleware() func(http.Handler) http.Handler {
 	}
 }
 
+// serveAPIKey authenticates an API key and passes the request on with claims
+// that carry the key's scopes and rate limit instead of a role
+func (a *Auth) serveAPIKey(w http.ResponseWriter, r *http.Request, next http.Handler, key string) {
+	principal, err := a.apiKeys.AuthenticateAPIKey(r.Context(), strings.TrimSpace(key), RequestIP(r))
+	if err != nil {
+		if !errors.Is(err, ErrAPIKeyInvalid) {
+			a.logger.Error("failed to authenticate api key", "error", err)
+		}
+		http.Error(w, "Invalid or expired API key", http.StatusUnauthorized)
+		return
+	}
+
+	claims := &Claims{
+		Subject:   "api_key:" + principal.Name,
+		APIKeyID:  principal.ID,
+		Scopes:    principal.Scopes,
+		RateLimit: principal.RateLimit,
+	}
+
+	ctx := context.WithValue(r.Context(), ClaimsContextKey{}, claims)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
 // GetClaims gets the claims from context
 func GetClaims(ctx context.Context) *Claims {
 	if claims, ok := ctx.Value(ClaimsContextKey{}).(*Claims); ok {
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/reveegate/reveegate/internal/config"
+	redisRepo "github.com/reveegate/reveegate/internal/repository/redis"
+)
+
+type fakeRevocationChecker struct {
+	revoked bool
+	err     error
+}
+
+func (f fakeRevocationChecker) SessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	return f.revoked, f.err
+}
+
+func TestSessionRevocationWithRedisDown(t *testing.T) {
+	// Nothing listens on port 1, so every Redis command fails
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	defer client.Close()
+
+	tests := []struct {
+		name    string
+		checker SessionRevocationChecker
+		want    int
+	}{
+		{"no fallback", nil, http.StatusServiceUnavailable},
+		{"database unavailable", fakeRevocationChecker{err: errors.New("connection refused")}, http.StatusServiceUnavailable},
+		{"revoked in database", fakeRevocationChecker{revoked: true}, http.StatusUnauthorized},
+		{"active in database", fakeRevocationChecker{}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth, err := NewAuth(config.JWTConfig{
+				Secret:         "test-secret-that-is-at-least-32-bytes",
+				AccessTokenTTL: time.Minute,
+			}, redisRepo.NewCache(client), slog.New(slog.NewTextHandler(io.Discard, nil)))
+			if err != nil {
+				t.Fatalf("NewAuth() error = %v", err)
+			}
+			if tt.checker != nil {
+				auth.SetSessionRevocationChecker(tt.checker)
+			}
+
+			token, _, err := auth.GenerateAccessToken("user-1", "admin", "owner", "6f1c2a4e-8c55-4a5e-9a43-2a8f0c1b7d10")
+			if err != nil {
+				t.Fatalf("GenerateAccessToken() error = %v", err)
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/admin/me", nil)
+			r.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			auth.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})).ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
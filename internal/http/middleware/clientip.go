@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// clientIPContextKey holds the client address resolved by ClientIP
+type clientIPContextKey struct{}
+
+// ClientIP resolves the address of the client behind trusted proxies. Proxy
+// headers are only believed when the request comes from a trusted proxy,
+// and X-Forwarded-For is read from the right up to the first address that
+// isn't a trusted proxy itself, so a client can't choose its own address by
+// sending the header. Without trusted proxies the peer address is used.
+// Trusted proxies must set X-Forwarded-For or X-Real-IP.
+func ClientIP(trustedProxies []string) func(http.Handler) http.Handler {
+	trusted := make([]netip.Prefix, 0, len(trustedProxies))
+	for _, entry := range trustedProxies {
+		if prefix, err := parseIPOrPrefix(entry); err == nil {
+			trusted = append(trusted, prefix)
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := resolveClientIP(r, trusted)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientIPContextKey{}, ip)))
+		})
+	}
+}
+
+// RequestIP returns the client address resolved by ClientIP, or the peer
+// address when the middleware didn't run
+func RequestIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPContextKey{}).(string); ok {
+		return ip
+	}
+	return peerIP(r)
+}
+
+// resolveClientIP walks the proxy chain from the peer towards the client
+func resolveClientIP(r *http.Request, trusted []netip.Prefix) string {
+	peer := peerIP(r)
+	if !isTrusted(peer, trusted) {
+		return peer
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(header, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+	if len(hops) == 0 {
+		if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); realIP != "" {
+			hops = append(hops, realIP)
+		}
+	}
+
+	client := peer
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(hops[i])
+		if err != nil {
+			// Whatever comes before a malformed hop can't be trusted
+			break
+		}
+		client = addr.Unmap().String()
+		if !isTrusted(client, trusted) {
+			break
+		}
+	}
+	return client
+}
+
+// isTrusted reports whether an address belongs to a trusted proxy
+func isTrusted(ip string, trusted []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// peerIP returns the address of the connection's peer without the port
+func peerIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return addr.Unmap().String()
+	}
+	return host
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted := []string{"10.0.0.0/8", "127.0.0.1"}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		xff        []string
+		realIP     string
+		want       string
+	}{
+		{"no proxy", "203.0.113.7:5000", nil, "", "203.0.113.7"},
+		{"untrusted peer ignores headers", "203.0.113.7:5000", []string{"127.0.0.1"}, "127.0.0.1", "203.0.113.7"},
+		{"trusted proxy", "10.0.0.2:80", []string{"198.51.100.4"}, "", "198.51.100.4"},
+		{"spoofed hop left of client", "10.0.0.2:80", []string{"127.0.0.1, 198.51.100.4"}, "", "198.51.100.4"},
+		{"chain of trusted proxies", "10.0.0.2:80", []string{"198.51.100.4, 10.1.1.1", "10.2.2.2"}, "", "198.51.100.4"},
+		{"malformed hop", "10.0.0.2:80", []string{"garbage, 10.1.1.1"}, "", "10.1.1.1"},
+		{"x-real-ip from trusted proxy", "127.0.0.1:80", nil, "198.51.100.9", "198.51.100.9"},
+		{"trusted proxy without headers", "127.0.0.1:80", nil, "", "127.0.0.1"},
+		{"ipv4-mapped peer", "[::ffff:203.0.113.7]:5000", nil, "", "203.0.113.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, xff := range tt.xff {
+				r.Header.Add("X-Forwarded-For", xff)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			var got string
+			ClientIP(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = RequestIP(r)
+			})).ServeHTTP(httptest.NewRecorder(), r)
+
+			if got != tt.want {
+				t.Errorf("RequestIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetricsAccessIgnoresSpoofedHeaders(t *testing.T) {
+	handler := ClientIP(nil)(MetricsAccess("", []string{"127.0.0.1", "::1"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		header     string
+		want       int
+	}{
+		{"local scraper", "127.0.0.1:9000", "", http.StatusOK},
+		{"remote with True-Client-IP", "203.0.113.7:5000", "True-Client-IP", http.StatusForbidden},
+		{"remote with X-Real-IP", "203.0.113.7:5000", "X-Real-IP", http.StatusForbidden},
+		{"remote with X-Forwarded-For", "203.0.113.7:5000", "X-Forwarded-For", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.header != "" {
+				r.Header.Set(tt.header, "127.0.0.1")
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/reveegate/reveegate/internal/donortoken"
+)
+
+// DonorTokenVerifier checks donor access tokens
+type DonorTokenVerifier interface {
+	Verify(token string, donationID uuid.UUID) error
+}
+
+// DonorAccess only lets the donor of the donation in the {id} route
+// parameter through. The token returned when the donation was created is
+// sent as "Authorization: Bearer <token>", or as the access_token query
+// parameter by EventSource and WebSocket clients, which can't set headers.
+// Malformed IDs are left to the handler to reject.
+func DonorAccess(verifier DonorTokenVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id, err := uuid.Parse(chi.URLParam(r, "id"))
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token := r.URL.Query().Get("access_token")
+			if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+				token = bearer
+			}
+
+			switch err := verifier.Verify(token, id); {
+			case err == nil:
+				next.ServeHTTP(w, r)
+			case errors.Is(err, donortoken.ErrMissing):
+				http.Error(w, "Missing donor access token", http.StatusUnauthorized)
+			case errors.Is(err, donortoken.ErrExpired):
+				http.Error(w, "Donor access token expired", http.StatusUnauthorized)
+			default:
+				http.Error(w, "Not allowed to access this donation", http.StatusForbidden)
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/reveegate/reveegate/internal/config"
+)
+
+// DefaultKeyID is the key ID of the HMAC key built from JWT_SECRET
+const DefaultKeyID = "default"
+
+// Supported JWT signing algorithms
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// KeyConfig describes one entry of JWT_KEYS. PEM material can be given inline
+// or as a file path. Keys without private material can only verify tokens.
+type KeyConfig struct {
+	ID             string `json:"kid"`
+	Algorithm      string `json:"alg"`
+	Secret         string `json:"secret,omitempty"`
+	PrivateKey     string `json:"private_key,omitempty"`
+	PrivateKeyFile string `json:"private_key_file,omitempty"`
+	PublicKey      string `json:"public_key,omitempty"`
+	PublicKeyFile  string `json:"public_key_file,omitempty"`
+}
+
+// jwtKey is a parsed key of the keyring
+type jwtKey struct {
+	id        string
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// Keyring holds every key accepted for verification and the key used for signing
+type Keyring struct {
+	keys    map[string]*jwtKey
+	signing *jwtKey
+}
+
+// NewKeyring builds a keyring from the JWT configuration. JWT_SECRET, when set,
+// is always available as the HS256 key "default" so tokens signed before a
+// rotation keep verifying until they expire.
+func NewKeyring(cfg config.JWTConfig) (*Keyring, error) {
+	k := &Keyring{keys: make(map[string]*jwtKey)}
+
+	if cfg.Secret != "" {
+		k.keys[DefaultKeyID] = &jwtKey{
+			id:        DefaultKeyID,
+			method:    jwt.SigningMethodHS256,
+			signKey:   []byte(cfg.Secret),
+			verifyKey: []byte(cfg.Secret),
+		}
+	}
+
+	if cfg.Keys != "" {
+		var entries []KeyConfig
+		if err := json.Unmarshal([]byte(cfg.Keys), &entries); err != nil {
+			return nil, fmt.Errorf("invalid JWT_KEYS: %w", err)
+		}
+
+		for _, entry := range entries {
+			key, err := parseKey(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JWT key %q: %w", entry.ID, err)
+			}
+			if _, exists := k.keys[key.id]; exists {
+				return nil, fmt.Errorf("duplicate JWT key id %q", key.id)
+			}
+			k.keys[key.id] = key
+		}
+	}
+
+	if len(k.keys) == 0 {
+		return nil, errors.New("no JWT keys configured")
+	}
+
+	signingID := cfg.SigningKeyID
+	if signingID == "" {
+		signingID = DefaultKeyID
+	}
+
+	signing, ok := k.keys[signingID]
+	if !ok {
+		return nil, fmt.Errorf("JWT signing key %q is not configured", signingID)
+	}
+	if signing.signKey == nil {
+		return nil, fmt.Errorf("JWT signing key %q has no private key", signingID)
+	}
+	k.signing = signing
+
+	return k, nil
+}
+
+// SigningKeyID returns the ID of the key new tokens are signed with
+func (k *Keyring) SigningKeyID() string {
+	return k.signing.id
+}
+
+// Sign signs claims with the signing key and sets the kid header
+func (k *Keyring) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.signing.method, claims)
+	token.Header["kid"] = k.signing.id
+	return token.SignedString(k.signing.signKey)
+}
+
+// Keyfunc resolves the verification key from the kid header. The algorithm in
+// the token must match the key, which prevents algorithm confusion attacks.
+func (k *Keyring) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("missing kid header")
+	}
+
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+
+	return key.verifyKey, nil
+}
+
+// ValidMethods lists the algorithms of all configured keys
+func (k *Keyring) ValidMethods() []string {
+	seen := make(map[string]bool)
+	methods := make([]string, 0, len(k.keys))
+	for _, key := range k.keys {
+		if alg := key.method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// JWK is a public key in JSON Web Key format
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKSet is a JSON Web Key Set
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public asymmetric keys. HMAC keys are never published.
+func (k *Keyring) JWKS() JWKSet {
+	set := JWKSet{Keys: make([]JWK, 0)}
+
+	for _, key := range k.keys {
+		switch pub := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KeyType:   "RSA",
+				KeyID:     key.id,
+				Algorithm: key.method.Alg(),
+				Use:       "sig",
+				N:         base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KeyType:   "OKP",
+				KeyID:     key.id,
+				Algorithm: key.method.Alg(),
+				Use:       "sig",
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].KeyID < set.Keys[j].KeyID })
+
+	return set
+}
+
+// parseKey parses a JWT_KEYS entry
+func parseKey(cfg KeyConfig) (*jwtKey, error) {
+	if cfg.ID == "" {
+		return nil, errors.New("kid is required")
+	}
+
+	privatePEM, err := readKeyMaterial(cfg.PrivateKey, cfg.PrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	publicPEM, err := readKeyMaterial(cfg.PublicKey, cfg.PublicKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &jwtKey{id: cfg.ID}
+
+	switch cfg.Algorithm {
+	case AlgHS256:
+		if len(cfg.Secret) < 32 {
+			return nil, errors.New("HS256 secret must be at least 32 characters")
+		}
+		key.method = jwt.SigningMethodHS256
+		key.signKey = []byte(cfg.Secret)
+		key.verifyKey = []byte(cfg.Secret)
+
+	case AlgRS256:
+		key.method = jwt.SigningMethodRS256
+		if privatePEM != nil {
+			priv, err := jwt.ParseRSAPrivateKeyFromPEM(privatePEM)
+			if err != nil {
+				return nil, err
+			}
+			if priv.N.BitLen() < 2048 {
+				return nil, errors.New("RSA key must be at least 2048 bits")
+			}
+			key.signKey = priv
+			key.verifyKey = &priv.PublicKey
+		} else if publicPEM != nil {
+			pub, err := jwt.ParseRSAPublicKeyFromPEM(publicPEM)
+			if err != nil {
+				return nil, err
+			}
+			key.verifyKey = pub
+		}
+
+	case AlgEdDSA:
+		key.method = jwt.SigningMethodEdDSA
+		if privatePEM != nil {
+			priv, err := jwt.ParseEdPrivateKeyFromPEM(privatePEM)
+			if err != nil {
+				return nil, err
+			}
+			key.signKey = priv
+			key.verifyKey = priv.(crypto.Signer).Public()
+		} else if publicPEM != nil {
+			pub, err := jwt.ParseEdPublicKeyFromPEM(publicPEM)
+			if err != nil {
+				return nil, err
+			}
+			key.verifyKey = pub
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", cfg.Algorithm)
+	}
+
+	if key.verifyKey == nil {
+		return nil, errors.New("private_key or public_key is required")
+	}
+
+	return key, nil
+}
+
+// readKeyMaterial returns inline PEM or reads it from a file
+func readKeyMaterial(inline, file string) ([]byte, error) {
+	if inline != "" {
+		return []byte(inline), nil
+	}
+	if file == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	return data, nil
+}
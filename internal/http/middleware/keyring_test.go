@@ -0,0 +1,224 @@
+package middleware
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/reveegate/reveegate/internal/config"
+)
+
+const testJWTSecret = "test-secret-that-is-at-least-32-bytes"
+
+type testKeys struct {
+	rsa      *rsa.PrivateKey
+	ed       ed25519.PrivateKey
+	rsaPEM   string
+	edPEM    string
+	edPubPEM string
+}
+
+func newTestKeys(t *testing.T) testKeys {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate Ed25519 key: %v", err)
+	}
+
+	edDER, err := x509.MarshalPKCS8PrivateKey(edKey)
+	if err != nil {
+		t.Fatalf("failed to marshal Ed25519 key: %v", err)
+	}
+	edPubDER, err := x509.MarshalPKIXPublicKey(edPub)
+	if err != nil {
+		t.Fatalf("failed to marshal Ed25519 public key: %v", err)
+	}
+
+	return testKeys{
+		rsa:      rsaKey,
+		ed:       edKey,
+		rsaPEM:   encodePEM("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)),
+		edPEM:    encodePEM("PRIVATE KEY", edDER),
+		edPubPEM: encodePEM("PUBLIC KEY", edPubDER),
+	}
+}
+
+func encodePEM(blockType string, der []byte) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}))
+}
+
+func newTestKeyring(t *testing.T, keys testKeys) *Keyring {
+	t.Helper()
+
+	entries, err := json.Marshal([]KeyConfig{
+		{ID: "rsa-2026", Algorithm: AlgRS256, PrivateKey: keys.rsaPEM},
+		{ID: "ed-2026", Algorithm: AlgEdDSA, PrivateKey: keys.edPEM},
+		{ID: "ed-verify", Algorithm: AlgEdDSA, PublicKey: keys.edPubPEM},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal keys: %v", err)
+	}
+
+	keyring, err := NewKeyring(config.JWTConfig{
+		Secret:       testJWTSecret,
+		Keys:         string(entries),
+		SigningKeyID: "ed-2026",
+	})
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+	return keyring
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, key interface{}) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, jwt.RegisteredClaims{
+		Subject:   "user-1",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func TestKeyringKeyfunc(t *testing.T) {
+	keys := newTestKeys(t)
+	keyring := newTestKeyring(t, keys)
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"default hmac key", signToken(t, jwt.SigningMethodHS256, DefaultKeyID, []byte(testJWTSecret)), false},
+		{"rsa key", signToken(t, jwt.SigningMethodRS256, "rsa-2026", keys.rsa), false},
+		{"ed25519 key", signToken(t, jwt.SigningMethodEdDSA, "ed-2026", keys.ed), false},
+		{"verify-only key", signToken(t, jwt.SigningMethodEdDSA, "ed-verify", keys.ed), false},
+		{"missing kid", signToken(t, jwt.SigningMethodHS256, "", []byte(testJWTSecret)), true},
+		{"unknown kid", signToken(t, jwt.SigningMethodHS256, "retired", []byte(testJWTSecret)), true},
+		{"hmac with rsa kid", signToken(t, jwt.SigningMethodHS256, "rsa-2026", []byte(keys.rsaPEM)), true},
+		{"rsa with hmac kid", signToken(t, jwt.SigningMethodRS256, DefaultKeyID, keys.rsa), true},
+		{"ed25519 with rsa kid", signToken(t, jwt.SigningMethodEdDSA, "rsa-2026", keys.ed), true},
+		{"wrong secret", signToken(t, jwt.SigningMethodHS256, DefaultKeyID, []byte("another-secret-that-is-32-bytes-long")), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jwt.Parse(tt.token, keyring.Keyfunc, jwt.WithValidMethods(keyring.ValidMethods()))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeyringSign(t *testing.T) {
+	keyring := newTestKeyring(t, newTestKeys(t))
+
+	signed, err := keyring.Sign(jwt.RegisteredClaims{Subject: "user-1"})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	token, err := jwt.Parse(signed, keyring.Keyfunc)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if kid := token.Header["kid"]; kid != "ed-2026" {
+		t.Errorf("kid = %v, want ed-2026", kid)
+	}
+	if alg := token.Method.Alg(); alg != AlgEdDSA {
+		t.Errorf("alg = %s, want %s", alg, AlgEdDSA)
+	}
+}
+
+func TestKeyringJWKS(t *testing.T) {
+	keys := newTestKeys(t)
+	set := newTestKeyring(t, keys).JWKS()
+
+	if len(set.Keys) != 3 {
+		t.Fatalf("JWKS() has %d keys, want 3", len(set.Keys))
+	}
+
+	edX := base64.RawURLEncoding.EncodeToString(keys.ed.Public().(ed25519.PublicKey))
+	want := map[string]JWK{
+		"ed-2026":   {KeyType: "OKP", KeyID: "ed-2026", Algorithm: AlgEdDSA, Use: "sig", Curve: "Ed25519", X: edX},
+		"ed-verify": {KeyType: "OKP", KeyID: "ed-verify", Algorithm: AlgEdDSA, Use: "sig", Curve: "Ed25519", X: edX},
+		"rsa-2026": {
+			KeyType:   "RSA",
+			KeyID:     "rsa-2026",
+			Algorithm: AlgRS256,
+			Use:       "sig",
+			N:         base64.RawURLEncoding.EncodeToString(keys.rsa.N.Bytes()),
+			E:         "AQAB",
+		},
+	}
+
+	for i, key := range set.Keys {
+		if i > 0 && set.Keys[i-1].KeyID >= key.KeyID {
+			t.Errorf("JWKS() is not sorted by kid: %q before %q", set.Keys[i-1].KeyID, key.KeyID)
+		}
+		if key.KeyID == DefaultKeyID {
+			t.Error("JWKS() publishes the HMAC key")
+			continue
+		}
+		if key != want[key.KeyID] {
+			t.Errorf("JWKS() key = %+v, want %+v", key, want[key.KeyID])
+		}
+	}
+
+	// The published modulus and exponent must rebuild the signing key
+	for _, key := range set.Keys {
+		if key.KeyType != "RSA" {
+			continue
+		}
+		n, _ := base64.RawURLEncoding.DecodeString(key.N)
+		e, _ := base64.RawURLEncoding.DecodeString(key.E)
+		pub := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		if !pub.Equal(&keys.rsa.PublicKey) {
+			t.Error("JWKS() RSA key does not match the signing key")
+		}
+	}
+}
+
+func TestKeyringJWKSWithoutAsymmetricKeys(t *testing.T) {
+	keyring, err := NewKeyring(config.JWTConfig{Secret: testJWTSecret})
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	set := keyring.JWKS()
+	if set.Keys == nil || len(set.Keys) != 0 {
+		t.Errorf("JWKS() = %+v, want an empty key list", set)
+	}
+
+	// An empty set must still serialize as a list, not null
+	body, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("failed to marshal JWKS: %v", err)
+	}
+	if string(body) != `{"keys":[]}` {
+		t.Errorf("JWKS() = %s, want {\"keys\":[]}", body)
+	}
+}
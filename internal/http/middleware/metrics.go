@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/reveegate/reveegate/internal/metrics"
+)
+
+// Metrics records request latency per route pattern. It has to run before
+// routing; the pattern is read once the router has matched the request.
+func Metrics() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ww := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+			next.ServeHTTP(ww, r)
+
+			// Unmatched paths share one series so scanners can't create new ones
+			route := "unmatched"
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				if pattern := rctx.RoutePattern(); pattern != "" {
+					route = pattern
+				}
+			}
+
+			metrics.HTTPRequestDuration.
+				WithLabelValues(r.Method, route, strconv.Itoa(ww.statusCode)).
+				Observe(time.Since(start).Seconds())
+		})
+	}
+}
+
+// MetricsAccess protects the metrics endpoint. Scrapers either send the
+// bearer token or connect from an allowed address or CIDR range; entries
+// that don't parse are ignored. The address is the one ClientIP resolved,
+// which proxy headers only change when they come from a trusted proxy.
+func MetricsAccess(token string, allowedIPs []string) func(http.Handler) http.Handler {
+	allowed := make([]netip.Prefix, 0, len(allowedIPs))
+	for _, entry := range allowedIPs {
+		if prefix, err := parseIPOrPrefix(entry); err == nil {
+			allowed = append(allowed, prefix)
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token != "" {
+				bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+				if ok && subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) == 1 {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			if addr, err := netip.ParseAddr(RequestIP(r)); err == nil {
+				addr = addr.Unmap()
+				for _, prefix := range allowed {
+					if prefix.Contains(addr) {
+						next.ServeHTTP(w, r)
+						return
+					}
+				}
+			}
+
+			if token != "" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			}
+			http.Error(w, "Forbidden", http.StatusForbidden)
+		})
+	}
+}
+
+// parseIPOrPrefix parses an address or CIDR range; an address becomes a
+// single-address range
+func parseIPOrPrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
@@ -2,52 +2,106 @@ package middleware
 
 import (
 	"context"
-	"fmt"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/reveegate/reveegate/internal/config"
 	"github.com/reveegate/reveegate/internal/repository/redis"
 )
 
-// RateLimiter middleware handles rate limiting
+// rateLimitPeriod is the period the configured per-minute limits refill over
+const rateLimitPeriod = time.Minute
+
+// RateLimitPolicy is the request budget of a route group. Each client gets a
+// token bucket of Limit tokens that refills evenly over Period, so a client
+// can burst up to Limit requests but never more than Limit per Period.
+type RateLimitPolicy struct {
+	Name   string // Route group, part of the bucket key
+	Limit  int
+	Period time.Duration
+}
+
+// RateLimitKeyFunc returns the client a request is counted against. An empty
+// key leaves the request unlimited.
+type RateLimitKeyFunc func(r *http.Request) string
+
+// RateLimiter takes tokens from buckets in Redis so limits hold across
+// instances. While Redis is unreachable it falls back to buckets in this
+// process, which keeps limits in force per instance.
 type RateLimiter struct {
-	cache         *redis.Cache
-	requestsLimit int
-	windowSize    time.Duration
+	cache  *redis.Cache
+	local  *localBuckets
+	logger *slog.Logger
+
+	lastFallbackLog atomic.Int64 // Unix seconds
 }
 
-// NewRateLimiter creates a new rate limiter middleware
-func NewRateLimiter(cache *redis.Cache, requestsLimit int, windowSize time.Duration) *RateLimiter {
+// NewRateLimiter creates a new rate limiter
+func NewRateLimiter(cache *redis.Cache, logger *slog.Logger) *RateLimiter {
 	return &RateLimiter{
-		cache:         cache,
-		requestsLimit: requestsLimit,
-		windowSize:    windowSize,
+		cache:  cache,
+		local:  newLocalBuckets(),
+		logger: logger,
 	}
 }
 
-// Limit returns a middleware that rate limits requests
-func (rl *RateLimiter) Limit(endpoint string) func(http.Handler) http.Handler {
+// Allow takes a token for a client from the policy's bucket
+func (rl *RateLimiter) Allow(ctx context.Context, policy RateLimitPolicy, client string) *redis.TokenBucketResult {
+	key := redis.RateLimitKey(policy.Name, client)
+
+	result, err := rl.cache.TakeToken(ctx, key, policy.Limit, policy.Period)
+	if err == nil {
+		return result
+	}
+
+	// Warn at most once a minute so an outage doesn't flood the log
+	now := time.Now()
+	if last := rl.lastFallbackLog.Load(); now.Unix()-last >= 60 && rl.lastFallbackLog.CompareAndSwap(last, now.Unix()) {
+		rl.logger.Warn("rate limiter using in-process buckets, redis unavailable", "error", err)
+	}
+
+	return rl.local.take(key, policy.Limit, policy.Period, now)
+}
+
+// Limit returns a middleware that applies a policy to the client chosen by keyFunc
+func (rl *RateLimiter) Limit(policy RateLimitPolicy, keyFunc RateLimitKeyFunc) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ip := getClientIP(r)
-			key := redis.RateLimitKey(ip, endpoint)
-
-			// Increment counter with TTL
-			count, err := rl.cache.IncrementWithTTL(r.Context(), key, rl.windowSize)
-			if err != nil {
-				// If Redis fails, allow the request but log error
+			client := keyFunc(r)
+			if client == "" || policy.Limit <= 0 {
 				next.ServeHTTP(w, r)
 				return
 			}
 
-			// Set rate limit headers
-			w.Header().Set("X-RateLimit-Limit", fmt.Sprintf("%d", rl.requestsLimit))
-			w.Header().Set("X-RateLimit-Remaining", fmt.Sprintf("%d", max(0, int64(rl.requestsLimit)-count)))
+			if !rl.serve(w, r, policy, client) {
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
 
-			// Check if rate limit exceeded
-			if count > int64(rl.requestsLimit) {
-				w.Header().Set("Retry-After", fmt.Sprintf("%d", int(rl.windowSize.Seconds())))
-				http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+// LimitAPIKeys returns a middleware that applies each API key's own
+// per-minute limit. Requests not made with an API key pass through.
+func (rl *RateLimiter) LimitAPIKeys(name string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims := GetClaims(r.Context())
+			if claims == nil || !claims.IsAPIKey() || claims.RateLimit <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			policy := RateLimitPolicy{Name: name, Limit: claims.RateLimit, Period: rateLimitPeriod}
+			if !rl.serve(w, r, policy, claims.APIKeyID) {
 				return
 			}
 
@@ -56,62 +110,156 @@ func (rl *RateLimiter) Limit(endpoint string) func(http.Handler) http.Handler {
 	}
 }
 
-// RateLimitConfig holds rate limit configuration for different endpoints
-type RateLimitConfig struct {
-	DonationLimit int           // requests per minute for donation endpoints
-	APILimit      int           // requests per minute for general API
-	WebhookLimit  int           // requests per minute for webhook endpoints
-	AdminLimit    int           // requests per minute for admin endpoints
-	WindowSize    time.Duration // time window for rate limiting
+// serve takes a token and writes the rate limit headers. It rejects the
+// request and returns false when the bucket is empty.
+func (rl *RateLimiter) serve(w http.ResponseWriter, r *http.Request, policy RateLimitPolicy, client string) bool {
+	result := rl.Allow(r.Context(), policy, client)
+
+	// Headers from draft-ietf-httpapi-ratelimit-headers
+	h := w.Header()
+	h.Set("RateLimit-Limit", strconv.Itoa(policy.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+	h.Set("RateLimit-Policy", strconv.Itoa(policy.Limit)+";w="+strconv.Itoa(ceilSeconds(policy.Period)))
+
+	if !result.Allowed {
+		h.Set("Retry-After", strconv.Itoa(max(1, ceilSeconds(result.RetryAfter))))
+		rl.logger.Info("rate limit exceeded",
+			"group", policy.Name,
+			"client", client,
+			"path", r.URL.Path,
+		)
+		http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+		return false
+	}
+
+	return true
 }
 
-// DefaultRateLimitConfig returns default rate limit configuration
-func DefaultRateLimitConfig() RateLimitConfig {
-	return RateLimitConfig{
-		DonationLimit: 10,
-		APILimit:      100,
-		WebhookLimit:  1000,
-		AdminLimit:    300,
-		WindowSize:    time.Minute,
+// ceilSeconds rounds a duration up to whole seconds
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// KeyByIP counts requests against the client IP
+func KeyByIP(r *http.Request) string {
+	return RequestIP(r)
+}
+
+// KeyByDonorFingerprint counts requests against a hash of the client IP and
+// browser headers. Donors sharing an address, such as viewers behind a mobile
+// carrier's NAT, get separate budgets. Since the headers are client
+// controlled, routes limited this way should also be limited by IP.
+func KeyByDonorFingerprint(r *http.Request) string {
+	sum := sha256.Sum256([]byte(RequestIP(r) + "\n" + r.UserAgent() + "\n" + r.Header.Get("Accept-Language")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// localBuckets are in-process token buckets used while Redis is unavailable
+type localBuckets struct {
+	mu        sync.Mutex
+	buckets   map[string]*localBucket
+	lastSweep time.Time
+}
+
+type localBucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Time // When the bucket will be full again
+}
+
+func newLocalBuckets() *localBuckets {
+	return &localBuckets{
+		buckets:   make(map[string]*localBucket),
+		lastSweep: time.Now(),
 	}
 }
 
-// RateLimitMiddleware creates rate limiters for different endpoints
+// take mirrors the Redis token bucket script
+func (lb *localBuckets) take(key string, capacity int, period time.Duration, now time.Time) *redis.TokenBucketResult {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	// Full buckets are the same as absent ones, so drop them now and then
+	if now.Sub(lb.lastSweep) >= time.Minute {
+		for k, b := range lb.buckets {
+			if now.After(b.full) {
+				delete(lb.buckets, k)
+			}
+		}
+		lb.lastSweep = now
+	}
+
+	rate := float64(capacity) / float64(period) // Tokens per nanosecond
+
+	b, ok := lb.buckets[key]
+	if !ok {
+		b = &localBucket{tokens: float64(capacity), last: now}
+		lb.buckets[key] = b
+	}
+	if now.After(b.last) {
+		b.tokens = math.Min(float64(capacity), b.tokens+float64(now.Sub(b.last))*rate)
+		b.last = now
+	}
+
+	result := &redis.TokenBucketResult{}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration(math.Ceil((1 - b.tokens) / rate))
+	}
+
+	result.Remaining = int(b.tokens)
+	result.Reset = time.Duration(math.Ceil((float64(capacity) - b.tokens) / rate))
+	b.full = now.Add(result.Reset)
+
+	return result
+}
+
+// RateLimitMiddleware holds the rate limits of each route group
 type RateLimitMiddleware struct {
-	donation *RateLimiter
-	api      *RateLimiter
-	webhook  *RateLimiter
-	admin    *RateLimiter
+	limiter  *RateLimiter
+	donation RateLimitPolicy
+	api      RateLimitPolicy
+	webhook  RateLimitPolicy
+	admin    RateLimitPolicy
 }
 
-// NewRateLimitMiddleware creates a new rate limit middleware set
-func NewRateLimitMiddleware(cache *redis.Cache, config RateLimitConfig) *RateLimitMiddleware {
+// NewRateLimitMiddleware creates the route group rate limits from configuration
+func NewRateLimitMiddleware(limiter *RateLimiter, cfg config.RateLimitConfig) *RateLimitMiddleware {
 	return &RateLimitMiddleware{
-		donation: NewRateLimiter(cache, config.DonationLimit, config.WindowSize),
-		api:      NewRateLimiter(cache, config.APILimit, config.WindowSize),
-		webhook:  NewRateLimiter(cache, config.WebhookLimit, config.WindowSize),
-		admin:    NewRateLimiter(cache, config.AdminLimit, config.WindowSize),
+		limiter:  limiter,
+		donation: RateLimitPolicy{Name: "donation", Limit: cfg.DonationPerMinute, Period: rateLimitPeriod},
+		api:      RateLimitPolicy{Name: "api", Limit: cfg.APIPerMinute, Period: rateLimitPeriod},
+		webhook:  RateLimitPolicy{Name: "webhook", Limit: cfg.WebhookPerMinute, Period: rateLimitPeriod},
+		admin:    RateLimitPolicy{Name: "admin", Limit: cfg.AdminPerMinute, Period: rateLimitPeriod},
 	}
 }
 
-// Donation returns the donation rate limiter middleware
+// Donation limits donation creation per donor fingerprint
 func (rlm *RateLimitMiddleware) Donation() func(http.Handler) http.Handler {
-	return rlm.donation.Limit("donation")
+	return rlm.limiter.Limit(rlm.donation, KeyByDonorFingerprint)
 }
 
-// API returns the general API rate limiter middleware
+// API limits the public API per IP
 func (rlm *RateLimitMiddleware) API() func(http.Handler) http.Handler {
-	return rlm.api.Limit("api")
+	return rlm.limiter.Limit(rlm.api, KeyByIP)
 }
 
-// Webhook returns the webhook rate limiter middleware
+// Webhook limits payment provider callbacks per IP
 func (rlm *RateLimitMiddleware) Webhook() func(http.Handler) http.Handler {
-	return rlm.webhook.Limit("webhook")
+	return rlm.limiter.Limit(rlm.webhook, KeyByIP)
 }
 
-// Admin returns the admin rate limiter middleware
+// Admin limits the admin API per IP
 func (rlm *RateLimitMiddleware) Admin() func(http.Handler) http.Handler {
-	return rlm.admin.Limit("admin")
+	return rlm.limiter.Limit(rlm.admin, KeyByIP)
+}
+
+// APIKey applies each API key's own limit. It must run after authentication.
+func (rlm *RateLimitMiddleware) APIKey() func(http.Handler) http.Handler {
+	return rlm.limiter.LimitAPIKeys("api_key")
 }
 
 // IPWhitelistMiddleware checks if the request IP is whitelisted
@@ -124,7 +272,7 @@ func IPWhitelistMiddleware(whitelist []string) func(http.Handler) http.Handler {
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			clientIP := getClientIP(r)
+			clientIP := RequestIP(r)
 
 			// Check if IP is whitelisted
 			if !isIPWhitelisted(clientIP, whitelist) {
@@ -168,10 +316,3 @@ func TimeoutMiddleware(timeout time.Duration) func(http.Handler) http.Handler {
 		})
 	}
 }
-
-func max(a, b int64) int64 {
-	if a > b {
-		return a
-	}
-	return b
-}
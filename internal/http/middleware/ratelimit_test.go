@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	redisRepo "github.com/reveegate/reveegate/internal/repository/redis"
+)
+
+func TestRateLimitKeysIgnoreUntrustedProxyHeaders(t *testing.T) {
+	keys := map[string]func(*http.Request) string{
+		"KeyByIP":               KeyByIP,
+		"KeyByDonorFingerprint": KeyByDonorFingerprint,
+	}
+
+	for name, key := range keys {
+		t.Run(name, func(t *testing.T) {
+			seen := make(map[string]bool)
+			for _, spoofed := range []string{"", "198.51.100.1", "198.51.100.2", "127.0.0.1"} {
+				r := httptest.NewRequest(http.MethodPost, "/api/v1/donations", nil)
+				r.RemoteAddr = "203.0.113.7:5000"
+				if spoofed != "" {
+					r.Header.Set("X-Forwarded-For", spoofed)
+					r.Header.Set("X-Real-IP", spoofed)
+				}
+
+				ClientIP([]string{"10.0.0.0/8"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					seen[key(r)] = true
+				})).ServeHTTP(httptest.NewRecorder(), r)
+			}
+
+			if len(seen) != 1 {
+				t.Errorf("spoofed headers produced %d different keys, want 1", len(seen))
+			}
+		})
+	}
+}
+
+func TestLocalBucketsTake(t *testing.T) {
+	lb := newLocalBuckets()
+	start := time.Now()
+
+	// Three tokens refilling over three seconds, one token a second
+	steps := []struct {
+		name       string
+		key        string
+		at         time.Duration
+		allowed    bool
+		remaining  int
+		retryAfter time.Duration
+		reset      time.Duration
+	}{
+		{"first request", "a", 0, true, 2, 0, time.Second},
+		{"second request", "a", 0, true, 1, 0, 2 * time.Second},
+		{"third request", "a", 0, true, 0, 0, 3 * time.Second},
+		{"bucket empty", "a", 0, false, 0, time.Second, 3 * time.Second},
+		{"other client", "b", 0, true, 2, 0, time.Second},
+		{"partly refilled", "a", 500 * time.Millisecond, false, 0, 500 * time.Millisecond, 2500 * time.Millisecond},
+		{"one token refilled", "a", time.Second, true, 0, 0, 3 * time.Second},
+		{"clock going backwards", "a", 900 * time.Millisecond, false, 0, time.Second, 3 * time.Second},
+		{"refilled to capacity", "a", time.Hour, true, 2, 0, time.Second},
+	}
+
+	for _, step := range steps {
+		got := lb.take(step.key, 3, 3*time.Second, start.Add(step.at))
+		if got.Allowed != step.allowed || got.Remaining != step.remaining {
+			t.Errorf("%s: allowed/remaining = %v/%d, want %v/%d", step.name, got.Allowed, got.Remaining, step.allowed, step.remaining)
+		}
+		if got.RetryAfter != step.retryAfter || got.Reset != step.reset {
+			t.Errorf("%s: retry after/reset = %v/%v, want %v/%v", step.name, got.RetryAfter, got.Reset, step.retryAfter, step.reset)
+		}
+	}
+
+	// The sweep at "refilled to capacity" drops the full bucket of client b
+	if _, ok := lb.buckets["b"]; ok {
+		t.Error("sweep kept a full bucket")
+	}
+}
+
+func TestRateLimiterFallsBackWhenRedisDown(t *testing.T) {
+	// Nothing listens on port 1, so every Redis command fails
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	defer client.Close()
+
+	limiter := NewRateLimiter(redisRepo.NewCache(client), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	policy := RateLimitPolicy{Name: "donation", Limit: 2, Period: time.Minute}
+	handler := ClientIP(nil)(limiter.Limit(policy, KeyByIP)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	requests := []struct {
+		remoteAddr string
+		want       int
+		remaining  string
+	}{
+		{"203.0.113.7:5000", http.StatusOK, "1"},
+		{"203.0.113.7:5001", http.StatusOK, "0"},
+		{"203.0.113.7:5002", http.StatusTooManyRequests, "0"},
+		{"203.0.113.8:5000", http.StatusOK, "1"},
+	}
+
+	for _, req := range requests {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/donations", nil)
+		r.RemoteAddr = req.remoteAddr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if w.Code != req.want {
+			t.Errorf("%s: status = %d, want %d", req.remoteAddr, w.Code, req.want)
+		}
+		if got := w.Header().Get("RateLimit-Remaining"); got != req.remaining {
+			t.Errorf("%s: RateLimit-Remaining = %q, want %q", req.remoteAddr, got, req.remaining)
+		}
+		if req.want == http.StatusTooManyRequests && w.Header().Get("Retry-After") != "30" {
+			t.Errorf("%s: Retry-After = %q, want 30", req.remoteAddr, w.Header().Get("Retry-After"))
+		}
+	}
+}
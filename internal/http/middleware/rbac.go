@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+)
+
+// Role is an admin user role
+type Role string
+
+const (
+	RoleOwner     Role = "owner"
+	RoleModerator Role = "moderator"
+	RoleFinance   Role = "finance"
+	RoleViewer    Role = "viewer"
+)
+
+// IsValid checks if the role is known
+func (r Role) IsValid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Permission is an action an admin can be allowed to perform
+type Permission string
+
+const (
+	PermDashboardRead      Permission = "dashboard:read"
+	PermDonationsRead      Permission = "donations:read"
+	PermDonorPII           Permission = "donations:read_pii"
+	PermDonationsExport    Permission = "donations:export"
+	PermStatsRead          Permission = "stats:read"
+	PermMessagesModerate   Permission = "messages:moderate"
+	PermOverlayControl     Permission = "overlay:control"
+	PermPaymentsRead       Permission = "payments:read"
+	PermPaymentsReconcile  Permission = "payments:reconcile"
+	PermPaymentMethodsRead Permission = "payment_methods:read"
+	PermPaymentMethodsEdit Permission = "payment_methods:manage"
+	PermWebhookLogsRead    Permission = "webhook_logs:read"
+	PermSystemHealth       Permission = "system:health"
+	PermAdminsManage       Permission = "admins:manage"
+	PermAuditLogsRead      Permission = "audit_logs:read"
+	PermAPIKeysManage      Permission = "api_keys:manage"
+	PermFraudManage        Permission = "fraud:manage"
+)
+
+// APIKeyScopes are the permissions an API key can be granted
+var APIKeyScopes = []Permission{
+	PermDonationsRead,
+	PermStatsRead,
+	PermOverlayControl,
+}
+
+// IsAPIKeyScope checks if a permission can be granted to an API key
+func IsAPIKeyScope(perm Permission) bool {
+	for _, scope := range APIKeyScopes {
+		if scope == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// rolePermissions is the permission matrix. Owners can do everything; every
+// other role only gets what it needs for its job.
+var rolePermissions = map[Role][]Permission{
+	RoleOwner: {
+		PermDashboardRead,
+		PermDonationsRead,
+		PermDonorPII,
+		PermDonationsExport,
+		PermStatsRead,
+		PermMessagesModerate,
+		PermOverlayControl,
+		PermPaymentsRead,
+		PermPaymentsReconcile,
+		PermPaymentMethodsRead,
+		PermPaymentMethodsEdit,
+		PermWebhookLogsRead,
+		PermSystemHealth,
+		PermAdminsManage,
+		PermAuditLogsRead,
+		PermAPIKeysManage,
+		PermFraudManage,
+	},
+	RoleModerator: {
+		PermDashboardRead,
+		PermDonationsRead,
+		PermStatsRead,
+		PermMessagesModerate,
+		PermOverlayControl,
+		PermFraudManage,
+	},
+	RoleFinance: {
+		PermDashboardRead,
+		PermDonationsRead,
+		PermDonorPII,
+		PermDonationsExport,
+		PermStatsRead,
+		PermPaymentsRead,
+		PermPaymentsReconcile,
+		PermPaymentMethodsRead,
+		PermWebhookLogsRead,
+	},
+	RoleViewer: {
+		PermDashboardRead,
+		PermDonationsRead,
+		PermStatsRead,
+		PermPaymentsRead,
+		PermPaymentMethodsRead,
+	},
+}
+
+// HasPermission checks if a role grants a permission
+func HasPermission(role Role, perm Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions returns the permissions granted to a role
+func Permissions(role Role) []Permission {
+	return append([]Permission(nil), rolePermissions[role]...)
+}
+
+// Can checks if the authenticated admin or API key in the context has a
+// permission. API keys are limited to their scopes.
+func Can(ctx context.Context, perm Permission) bool {
+	claims := GetClaims(ctx)
+	if claims == nil {
+		return false
+	}
+
+	if claims.IsAPIKey() {
+		for _, scope := range claims.Scopes {
+			if scope == perm {
+				return true
+			}
+		}
+		return false
+	}
+
+	return HasPermission(Role(claims.Role), perm)
+}
+
+// RequirePermission returns middleware that rejects admins whose role does
+// not grant the permission. It must run after the auth middleware.
+func RequirePermission(perm Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !Can(r.Context(), perm) {
+				http.Error(w, "Your role does not allow this action", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireSession returns middleware that rejects API keys on routes that act
+// on the logged-in admin's own account. It must run after the auth middleware.
+func RequireSession() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if claims := GetClaims(r.Context()); claims == nil || claims.IsAPIKey() {
+				http.Error(w, "This action requires an admin session", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
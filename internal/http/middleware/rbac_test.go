@@ -0,0 +1,31 @@
+package middleware
+
+import "testing"
+
+func TestRolePermissions(t *testing.T) {
+	tests := []struct {
+		role Role
+		perm Permission
+		want bool
+	}{
+		{RoleModerator, PermMessagesModerate, true},
+		{RoleModerator, PermOverlayControl, true},
+		{RoleModerator, PermPaymentsReconcile, false},
+		{RoleModerator, PermDonorPII, false},
+		{RoleModerator, PermDonationsExport, false},
+		{RoleFinance, PermDonationsExport, true},
+		{RoleFinance, PermPaymentsReconcile, true},
+		{RoleFinance, PermMessagesModerate, false},
+		{RoleViewer, PermDonationsExport, false},
+		{RoleViewer, PermMessagesModerate, false},
+		{RoleOwner, PermDonationsExport, true},
+		{RoleOwner, PermMessagesModerate, true},
+		{Role("admin"), PermDonationsRead, false},
+	}
+
+	for _, tt := range tests {
+		if got := HasPermission(tt.role, tt.perm); got != tt.want {
+			t.Errorf("HasPermission(%s, %s) = %v, want %v", tt.role, tt.perm, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing starts a server span for every request, continuing the trace of
+// the caller when it sent a traceparent header. The span starts out named
+// after the method; once the router has matched the request it is renamed
+// after the route pattern, like the metrics.
+func Tracing() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return otelhttp.NewHandler(nameSpanAfterRoute(next), "HTTP request",
+			// otelhttp renames the span with this too when chi has set
+			// r.Pattern, which only holds the innermost router's part of
+			// the route, so always name it after the full chi pattern
+			otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+				return routeSpanName(r)
+			}),
+			// Probes and scrapes would drown out real traffic
+			otelhttp.WithFilter(func(r *http.Request) bool {
+				switch r.URL.Path {
+				case "/health", "/ready", "/metrics":
+					return false
+				}
+				return true
+			}),
+		)
+	}
+}
+
+// nameSpanAfterRoute renames the request span once the router has matched
+// the request. The span is started before routing, when no pattern is known.
+func nameSpanAfterRoute(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+		trace.SpanFromContext(r.Context()).SetName(routeSpanName(r))
+	})
+}
+
+// routeSpanName names a request span "<method> <route pattern>", or just the
+// method before routing and for unmatched requests
+func routeSpanName(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return r.Method + " " + pattern
+		}
+	}
+	return r.Method
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracingNamesSpansAfterRoute(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+	router := chi.NewRouter()
+	router.Use(Tracing())
+	router.Get("/health", ok)
+	router.Route("/api/v1", func(r chi.Router) {
+		r.Get("/donations/{id}", ok)
+		r.Route("/admin", func(r chi.Router) {
+			r.Post("/fraud/decisions/{id}/approve", ok)
+		})
+	})
+
+	tests := []struct {
+		method string
+		path   string
+		want   string // Empty when no span should be recorded
+	}{
+		{http.MethodGet, "/api/v1/donations/6f1c2a4e-8c55-4a5e-9a43-2a8f0c1b7d10", "GET /api/v1/donations/{id}"},
+		{http.MethodPost, "/api/v1/admin/fraud/decisions/42/approve", "POST /api/v1/admin/fraud/decisions/{id}/approve"},
+		{http.MethodGet, "/no-such-route", "GET"},
+		{http.MethodGet, "/health", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			before := len(recorder.Ended())
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+
+			spans := recorder.Ended()[before:]
+			if tt.want == "" {
+				if len(spans) != 0 {
+					t.Errorf("recorded %d spans, want none", len(spans))
+				}
+				return
+			}
+			if len(spans) != 1 {
+				t.Fatalf("recorded %d spans, want 1", len(spans))
+			}
+			if got := spans[0].Name(); got != tt.want {
+				t.Errorf("span name = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
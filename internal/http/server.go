@@ -8,8 +8,12 @@ import (
 	"github.com/go-chi/chi/v5"
 	chimiddleware "github.com/go-chi/chi/v5/middleware"
 	"github.com/go-playground/validator/v10"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/reveegate/reveegate/internal/config"
+	"github.com/reveegate/reveegate/internal/domain/payment"
+	"github.com/reveegate/reveegate/internal/domain/provider"
+	"github.com/reveegate/reveegate/internal/donortoken"
 	"github.com/reveegate/reveegate/internal/http/handler"
 	"github.com/reveegate/reveegate/internal/http/middleware"
 	"github.com/reveegate/reveegate/internal/realtime/websocket"
@@ -18,6 +22,9 @@ import (
 	"github.com/reveegate/reveegate/internal/service"
 )
 
+// requestTimeout bounds how long a handler may run
+const requestTimeout = 30 * time.Second
+
 // Server represents the HTTP server
 type Server struct {
 	router *chi.Mux
@@ -30,6 +37,19 @@ type Server struct {
 func NewServer(
 	cfg *config.Config,
 	donationService *service.DonationService,
+	idempotencyService *service.IdempotencyService,
+	abuseGuard *service.AbuseGuard,
+	fraudService *service.FraudService,
+	sessionService *service.SessionService,
+	mfaService *service.MFAService,
+	adminUserService *service.AdminUserService,
+	loginGuard *service.LoginGuard,
+	auditService *service.AuditService,
+	apiKeyService *service.APIKeyService,
+	healthService *service.HealthService,
+	paymentMethodService *service.PaymentMethodService,
+	webhookLogRepo payment.WebhookLogRepository,
+	providers provider.ProviderFactory,
 	adminRepo *postgresRepo.AdminRepository,
 	authMiddleware *middleware.Auth,
 	cache *redisRepo.Cache,
@@ -46,72 +66,131 @@ func NewServer(
 		wsHub:  wsHub,
 	}
 
+	// Donors follow their donation with a token returned when they pay
+	donorTokens := donortoken.NewSigner(cfg.Donor.TokenSecret, cfg.Donor.TokenTTL)
+
 	// Create handlers
-	donationHandler := handler.NewDonationHandler(donationService, validator, logger)
-	webhookHandler := handler.NewWebhookHandler(donationService, nil, cfg, logger)
-	adminHandler := handler.NewAdminHandler(donationService, adminRepo, authMiddleware, validator, logger)
-	wsHandler := websocket.NewHandler(wsHub, authMiddleware, logger)
+	donationHandler := handler.NewDonationHandler(donationService, idempotencyService, abuseGuard, donorTokens, validator, logger)
+	paymentMethodHandler := handler.NewPaymentMethodHandler(paymentMethodService, auditService, validator, logger)
+	webhookHandler := handler.NewWebhookHandler(donationService, webhookLogRepo, providers, cfg, logger)
+	adminHandler := handler.NewAdminHandler(donationService, sessionService, mfaService, adminUserService, loginGuard, auditService, adminRepo, authMiddleware, validator, logger)
+	mfaHandler := handler.NewMFAHandler(mfaService, sessionService, loginGuard, auditService, adminRepo, validator, logger)
+	adminUserHandler := handler.NewAdminUserHandler(adminUserService, loginGuard, auditService, cfg.App.URL, validator, logger)
+	auditHandler := handler.NewAuditHandler(auditService, logger)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService, auditService, validator, logger)
+	healthHandler := handler.NewHealthHandler(healthService, cfg.App.Version, logger)
+	fraudHandler := handler.NewFraudHandler(fraudService, donationService, auditService, validator, logger)
+	realtimeHandler := handler.NewRealtimeHandler(wsHub, logger)
+	wsHandler := websocket.NewHandler(wsHub, authMiddleware, donationService, donationService, logger)
+	sseHandler := websocket.NewSSEHandler(wsHub, authMiddleware, donationService, logger)
+
+	// Rate limits per route group
+	rateLimits := middleware.NewRateLimitMiddleware(middleware.NewRateLimiter(cache, logger), cfg.RateLimit)
 
 	// Setup middleware
 	server.setupMiddleware(cfg, cache, logger)
 
 	// Setup routes
-	server.setupRoutes(donationHandler, webhookHandler, adminHandler, wsHandler, authMiddleware)
+	server.setupRoutes(donationHandler, paymentMethodHandler, webhookHandler, adminHandler, mfaHandler, adminUserHandler, auditHandler, apiKeyHandler, fraudHandler, healthHandler, realtimeHandler, wsHandler, sseHandler, authMiddleware, donorTokens, rateLimits)
 
 	return server
 }
 
 // setupMiddleware configures global middleware
 func (s *Server) setupMiddleware(cfg *config.Config, cache *redisRepo.Cache, logger *slog.Logger) {
+	// Tracing comes first so the span covers the whole request
+	s.router.Use(middleware.Tracing())
+
 	// Built-in chi middleware
 	s.router.Use(chimiddleware.RequestID)
-	s.router.Use(chimiddleware.RealIP)
+	s.router.Use(middleware.ClientIP(cfg.App.TrustedProxies))
 	s.router.Use(chimiddleware.Recoverer)
-	s.router.Use(chimiddleware.Timeout(30 * time.Second))
 
 	// Custom middleware
 	s.router.Use(middleware.Logger(logger))
+	s.router.Use(middleware.Metrics())
 
 	// Convert config.CORSConfig to middleware.CORSConfig
 	corsConfig := middleware.CORSConfig{
 		AllowedOrigins:   cfg.CORS.AllowedOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Request-ID"},
-		ExposedHeaders:   []string{"Link", "X-Request-ID"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Request-ID", "Idempotency-Key", "X-Challenge-Token", "X-Challenge-Nonce", "X-Device-Fingerprint"},
+		ExposedHeaders:   []string{"Link", "X-Request-ID", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After", "Idempotent-Replayed"},
 		AllowCredentials: true,
 		MaxAge:           86400,
 	}
 	s.router.Use(middleware.CORS(corsConfig))
 	s.router.Use(middleware.Security())
 
-	// Rate limiting is applied per-route, not globally
+	// Rate limiting is applied per route group, not globally
 }
 
 // setupRoutes configures all routes
 func (s *Server) setupRoutes(
 	donationHandler *handler.DonationHandler,
+	paymentMethodHandler *handler.PaymentMethodHandler,
 	webhookHandler *handler.WebhookHandler,
 	adminHandler *handler.AdminHandler,
+	mfaHandler *handler.MFAHandler,
+	adminUserHandler *handler.AdminUserHandler,
+	auditHandler *handler.AuditHandler,
+	apiKeyHandler *handler.APIKeyHandler,
+	fraudHandler *handler.FraudHandler,
+	healthHandler *handler.HealthHandler,
+	realtimeHandler *handler.RealtimeHandler,
 	wsHandler *websocket.Handler,
+	sseHandler *websocket.SSEHandler,
 	authMiddleware *middleware.Auth,
+	donorTokens *donortoken.Signer,
+	rateLimits *middleware.RateLimitMiddleware,
 ) {
-	// Health check (no rate limit)
-	s.router.Get("/health", s.healthCheck)
-	s.router.Get("/ready", s.readinessCheck)
+	// Every route gets a request timeout except the Server-Sent Events
+	// streams, which stay open far longer
+	timeout := chimiddleware.Timeout(requestTimeout)
+
+	// Liveness and readiness probes (no rate limit)
+	s.router.With(timeout).Get("/health", healthHandler.Live)
+	s.router.With(timeout).Get("/ready", healthHandler.Ready)
+
+	// Prometheus metrics (bearer token or IP allowlist)
+	if s.config.Metrics.Enabled {
+		s.router.With(timeout, middleware.MetricsAccess(s.config.Metrics.Token, s.config.Metrics.AllowedIPs)).Handle("/metrics", promhttp.Handler())
+	}
+
+	// Public keys for verifying admin tokens
+	s.router.With(timeout).Get("/.well-known/jwks.json", adminHandler.JWKS)
 
 	// API v1 routes
 	s.router.Route("/api/v1", func(r chi.Router) {
 		// Public donation routes
 		r.Route("/donations", func(r chi.Router) {
-			r.Post("/", donationHandler.Create)
-			r.Get("/{id}", donationHandler.GetByID)
-			r.Get("/{id}/status", donationHandler.GetStatus)
+			r.Use(rateLimits.API())
+			r.With(timeout, rateLimits.Donation()).Post("/", donationHandler.Create)
+			r.With(timeout).Get("/fee-quote", donationHandler.QuoteFee)
+			r.With(timeout).Get("/challenge", donationHandler.GetChallenge)
+
+			// A donation is only visible to its donor
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.DonorAccess(donorTokens))
+				r.With(timeout).Get("/{id}", donationHandler.GetByID)
+				r.With(timeout).Get("/{id}/status", donationHandler.GetStatus)
+				r.Get("/{id}/events", sseHandler.HandleDonationEvents)
+				r.With(timeout, rateLimits.Donation()).Post("/{id}/payments", donationHandler.CreatePaymentAttempt)
+			})
 		})
 
-		// Webhook routes (no rate limit, signature verified)
+		// Public payment method catalogue
+		r.With(timeout, rateLimits.API()).Get("/payment-methods", paymentMethodHandler.ListAvailable)
+
+		// Webhook routes (signature verified)
 		r.Route("/webhooks", func(r chi.Router) {
+			r.Use(timeout)
+			r.Use(rateLimits.Webhook())
+
 			r.Post("/midtrans", webhookHandler.HandleMidtrans)
 			r.Post("/xendit", webhookHandler.HandleXendit)
+			r.Post("/tripay", webhookHandler.HandleTripay)
+			r.Post("/duitku", webhookHandler.HandleDuitku)
 			r.Post("/verify", webhookHandler.VerifyWebhook)
 
 			// Development only
@@ -122,39 +201,130 @@ func (s *Server) setupRoutes(
 
 		// Admin routes (protected)
 		r.Route("/admin", func(r chi.Router) {
+			r.Use(timeout)
+			r.Use(rateLimits.Admin())
+
 			// Public admin routes
 			r.Post("/login", adminHandler.Login)
+			r.Post("/login/mfa", mfaHandler.VerifyLogin)
+			r.Post("/login/mfa/enroll", mfaHandler.EnrollWithChallenge)
 			r.Post("/refresh", adminHandler.RefreshToken)
+			r.Post("/invitations/accept", adminUserHandler.AcceptInvitation)
 
 			// Protected admin routes
 			r.Group(func(r chi.Router) {
 				r.Use(authMiddleware.Middleware())
-
-				r.Get("/dashboard", adminHandler.GetDashboard)
-				r.Get("/donations", donationHandler.List)
-				r.Get("/donations/stats", donationHandler.GetStats)
-				r.Post("/reconcile", adminHandler.ReconcilePayment)
-				r.Post("/overlay-token", adminHandler.GenerateOverlayToken)
-				r.Get("/webhook-logs", adminHandler.GetWebhookLogs)
-				r.Get("/health", adminHandler.GetSystemHealth)
+				r.Use(rateLimits.APIKey())
+
+				// Own account routes are available to every role but not to API keys
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireSession())
+
+					// Session management
+					r.Post("/logout", adminHandler.Logout)
+					r.Post("/logout-all", adminHandler.LogoutAll)
+					r.Get("/sessions", adminHandler.ListSessions)
+					r.Delete("/sessions/{id}", adminHandler.RevokeSession)
+
+					// Two-factor authentication for the current admin
+					r.Get("/mfa", mfaHandler.GetStatus)
+					r.Post("/mfa/enroll", mfaHandler.Enroll)
+					r.Post("/mfa/confirm", mfaHandler.Confirm)
+					r.Post("/mfa/disable", mfaHandler.Disable)
+					r.Post("/mfa/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+				})
+				r.With(middleware.RequirePermission(middleware.PermAdminsManage)).Get("/settings/mfa", mfaHandler.GetPolicy)
+				r.With(middleware.RequirePermission(middleware.PermAdminsManage)).Put("/settings/mfa", mfaHandler.UpdatePolicy)
+
+				// Admin account management
+				r.Route("/users", func(r chi.Router) {
+					r.Use(middleware.RequirePermission(middleware.PermAdminsManage))
+					r.Get("/", adminUserHandler.List)
+					r.Post("/", adminUserHandler.Create)
+					r.Post("/invite", adminUserHandler.Invite)
+					r.Patch("/{id}", adminUserHandler.Update)
+					r.Post("/{id}/deactivate", adminUserHandler.Deactivate)
+					r.Post("/{id}/activate", adminUserHandler.Activate)
+					r.Post("/{id}/reset-password", adminUserHandler.ResetPassword)
+					r.Post("/{id}/unlock", adminUserHandler.Unlock)
+				})
+				r.With(middleware.RequirePermission(middleware.PermAdminsManage)).Post("/login-lockouts/unlock-ip", adminUserHandler.UnlockIP)
+
+				// Audit trail
+				r.Route("/audit-logs", func(r chi.Router) {
+					r.Use(middleware.RequirePermission(middleware.PermAuditLogsRead))
+					r.Get("/", auditHandler.List)
+					r.Get("/verify", auditHandler.Verify)
+				})
+
+				// API keys for machine clients
+				r.Route("/api-keys", func(r chi.Router) {
+					r.Use(middleware.RequirePermission(middleware.PermAPIKeysManage))
+					r.Get("/", apiKeyHandler.List)
+					r.Post("/", apiKeyHandler.Create)
+					r.Delete("/{id}", apiKeyHandler.Revoke)
+				})
+
+				// Donor blocklist, fraud rules and decision review
+				r.Route("/fraud", func(r chi.Router) {
+					r.Use(middleware.RequirePermission(middleware.PermFraudManage))
+					r.Get("/blocks", fraudHandler.ListBlocks)
+					r.Post("/blocks", fraudHandler.CreateBlock)
+					r.Delete("/blocks/{id}", fraudHandler.DeleteBlock)
+					r.Get("/rules", fraudHandler.ListRules)
+					r.Post("/rules", fraudHandler.CreateRule)
+					r.Put("/rules/{id}", fraudHandler.UpdateRule)
+					r.Delete("/rules/{id}", fraudHandler.DeleteRule)
+					r.Get("/decisions", fraudHandler.ListDecisions)
+					r.Post("/decisions/{id}/approve", fraudHandler.ApproveDecision)
+					r.Post("/decisions/{id}/reject", fraudHandler.RejectDecision)
+				})
+
+				r.With(middleware.RequirePermission(middleware.PermDashboardRead)).Get("/dashboard", adminHandler.GetDashboard)
+				r.With(middleware.RequirePermission(middleware.PermDonationsRead)).Get("/donations", donationHandler.List)
+				r.With(middleware.RequirePermission(middleware.PermStatsRead)).Get("/donations/stats", donationHandler.GetStats)
+				r.With(middleware.RequirePermission(middleware.PermDonationsExport)).Get("/donations/export", adminHandler.ExportDonations)
+				r.With(middleware.RequirePermission(middleware.PermMessagesModerate)).Post("/donations/{id}/approve-message", adminHandler.ApproveMessage)
+				r.With(middleware.RequirePermission(middleware.PermPaymentsRead)).Get("/payments", adminHandler.ListPayments)
+				r.With(middleware.RequirePermission(middleware.PermPaymentsReconcile)).Post("/reconcile", adminHandler.ReconcilePayment)
+				r.With(middleware.RequirePermission(middleware.PermOverlayControl)).Post("/overlay-token", adminHandler.GenerateOverlayToken)
+				r.With(middleware.RequirePermission(middleware.PermOverlayControl)).Get("/realtime/presence", realtimeHandler.Presence)
+				r.With(middleware.RequirePermission(middleware.PermWebhookLogsRead)).Get("/webhook-logs", adminHandler.GetWebhookLogs)
+				r.With(middleware.RequirePermission(middleware.PermSystemHealth)).Get("/health", healthHandler.Detailed)
+				r.With(middleware.RequirePermission(middleware.PermPaymentMethodsRead)).Get("/payment-methods", paymentMethodHandler.List)
+				r.With(middleware.RequirePermission(middleware.PermPaymentMethodsEdit)).Patch("/payment-methods/{method}", paymentMethodHandler.Update)
 			})
 		})
 	})
 
 	// WebSocket routes
 	s.router.Route("/ws", func(r chi.Router) {
+		r.Use(timeout)
+
 		// Overlay WebSocket (token auth via query param)
 		r.Get("/overlay", wsHandler.HandleOverlay)
 
+		// Donation status for its donor (donor access token via query param)
+		r.With(middleware.DonorAccess(donorTokens)).Get("/donations/{id}", wsHandler.HandleDonation)
+
 		// Admin WebSocket (JWT auth)
 		r.Group(func(r chi.Router) {
 			r.Use(authMiddleware.Middleware())
+			r.Use(middleware.RequirePermission(middleware.PermDashboardRead))
 			r.Get("/admin", wsHandler.HandleAdmin)
 		})
 	})
 
+	// Server-Sent Events fallback for clients that can't use WebSockets
+	s.router.Route("/sse", func(r chi.Router) {
+		// Overlay stream (token auth via query param)
+		r.Get("/overlay", sseHandler.HandleOverlay)
+	})
+
 	// Static file serving for web assets
 	s.router.Route("/", func(r chi.Router) {
+		r.Use(timeout)
+
 		// Redirect root to donor page
 		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 			http.Redirect(w, r, "/donate", http.StatusFound)
@@ -188,21 +358,6 @@ func (s *Server) Handler() http.Handler {
 	return s.router
 }
 
-// healthCheck handles GET /health
-func (s *Server) healthCheck(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"status":"ok"}`))
-}
-
-// readinessCheck handles GET /ready
-func (s *Server) readinessCheck(w http.ResponseWriter, r *http.Request) {
-	// TODO: Check database and redis connectivity
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"status":"ready"}`))
-}
-
 // serveDonorPage serves the donor page
 func (s *Server) serveDonorPage(w http.ResponseWriter, r *http.Request) {
 	http.ServeFile(w, r, "./web/donor/index.html")
@@ -0,0 +1,94 @@
+// Package metrics defines the Prometheus metrics served on /metrics
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/reveegate/reveegate/internal/domain/payment"
+)
+
+const namespace = "reveegate"
+
+// Donation lifecycle events counted by DonationsTotal
+const (
+	DonationCreated   = "created"
+	DonationCompleted = "completed"
+	DonationExpired   = "expired"
+	DonationFailed    = "failed"
+)
+
+var (
+	// HTTPRequestDuration is labelled with the chi route pattern rather than
+	// the path, so IDs and tokens don't create new series
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	DonationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "donations_total",
+		Help:      "Donations created, completed, expired and failed by payment method and provider.",
+	}, []string{"event", "method", "provider"})
+
+	ProviderRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "provider",
+		Name:      "request_duration_seconds",
+		Help:      "Payment provider API latency by operation.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10, 30},
+	}, []string{"provider", "operation"})
+
+	ProviderErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "provider",
+		Name:      "errors_total",
+		Help:      "Failed payment provider API calls by operation.",
+	}, []string{"provider", "operation"})
+
+	WebhookVerificationFailuresTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "webhook",
+		Name:      "verification_failures_total",
+		Help:      "Webhooks rejected because their signature or token did not verify.",
+	}, []string{"provider"})
+
+	WebSocketClients = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "clients",
+		Help:      "Connected realtime clients by type.",
+	}, []string{"type"})
+
+	WebSocketDroppedClientsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "dropped_clients_total",
+		Help:      "Realtime clients disconnected because their send buffer was full.",
+	}, []string{"type"})
+
+	HubQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "websocket",
+		Name:      "hub_queue_depth",
+		Help:      "Messages waiting in the hub broadcast queue.",
+	})
+
+	// WebhookToOverlayDuration runs from receiving the paid webhook to handing
+	// the alert to the overlay clients. The target is under a second.
+	WebhookToOverlayDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "webhook_to_overlay_seconds",
+		Help:      "Time from receiving a paid webhook to broadcasting the alert to overlays.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 0.75, 1, 1.5, 2.5, 5, 10},
+	})
+)
+
+// RecordDonation counts a donation lifecycle event
+func RecordDonation(event string, method payment.Method, provider payment.Provider) {
+	DonationsTotal.WithLabelValues(event, string(method), string(provider)).Inc()
+}
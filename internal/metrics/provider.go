@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/reveegate/reveegate/internal/domain/provider"
+)
+
+// instrumentedProvider records latency and errors of the calls a provider
+// makes to its API
+type instrumentedProvider struct {
+	provider.Provider
+}
+
+// InstrumentProvider wraps a provider so its API calls are measured
+func InstrumentProvider(p provider.Provider) provider.Provider {
+	return &instrumentedProvider{Provider: p}
+}
+
+// CreatePayment implements provider.Provider
+func (p *instrumentedProvider) CreatePayment(ctx context.Context, req provider.PaymentRequest) (*provider.PaymentResponse, error) {
+	start := time.Now()
+	resp, err := p.Provider.CreatePayment(ctx, req)
+	p.observe("create_payment", start, err)
+	return resp, err
+}
+
+// GetPaymentStatus implements provider.Provider
+func (p *instrumentedProvider) GetPaymentStatus(ctx context.Context, req provider.StatusRequest) (*provider.PaymentStatus, error) {
+	start := time.Now()
+	status, err := p.Provider.GetPaymentStatus(ctx, req)
+	p.observe("get_payment_status", start, err)
+	return status, err
+}
+
+// CancelPayment implements provider.Provider. Methods without a cancel API
+// never reach the provider and are not recorded.
+func (p *instrumentedProvider) CancelPayment(ctx context.Context, req provider.StatusRequest) error {
+	start := time.Now()
+	err := p.Provider.CancelPayment(ctx, req)
+	if !errors.Is(err, provider.ErrCancelNotSupported) {
+		p.observe("cancel_payment", start, err)
+	}
+	return err
+}
+
+// observe records the latency of a call and counts it when it failed
+func (p *instrumentedProvider) observe(operation string, start time.Time, err error) {
+	name := string(p.GetName())
+	ProviderRequestDuration.WithLabelValues(name, operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		ProviderErrorsTotal.WithLabelValues(name, operation).Inc()
+	}
+}
@@ -0,0 +1,156 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testParams keep argon2id cheap so the tests stay fast
+var testParams = Params{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func TestHashVerify(t *testing.T) {
+	h := NewHasher(testParams)
+
+	hash, err := h.Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if want := "$argon2id$v=19$m=1024,t=1,p=1$"; !strings.HasPrefix(hash, want) {
+		t.Errorf("Hash() = %s, want prefix %s", hash, want)
+	}
+
+	other, err := h.Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if hash == other {
+		t.Error("Hash() reused a salt")
+	}
+
+	tests := []struct {
+		name      string
+		password  string
+		wantMatch bool
+	}{
+		{"correct password", "correct horse battery staple", true},
+		{"wrong password", "correct horse battery stapler", false},
+		{"empty password", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, needsRehash, err := h.Verify(tt.password, hash)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if match != tt.wantMatch {
+				t.Errorf("Verify() match = %v, want %v", match, tt.wantMatch)
+			}
+			if needsRehash {
+				t.Error("Verify() asked to rehash a current hash")
+			}
+		})
+	}
+}
+
+func TestVerifyNeedsRehash(t *testing.T) {
+	weak := NewHasher(testParams)
+	hash, err := weak.Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		params Params
+		want   bool
+	}{
+		{"same parameters", testParams, false},
+		{"more memory", Params{Memory: 2048, Iterations: 1, Parallelism: 1}, true},
+		{"more iterations", Params{Memory: 1024, Iterations: 2, Parallelism: 1}, true},
+		{"more parallelism", Params{Memory: 1024, Iterations: 1, Parallelism: 2}, true},
+		{"longer salt", Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 32}, true},
+		{"longer key", Params{Memory: 1024, Iterations: 1, Parallelism: 1, KeyLength: 64}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, needsRehash, err := NewHasher(tt.params).Verify("correct horse battery staple", hash)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if !match {
+				t.Fatal("Verify() rejected the password")
+			}
+			if needsRehash != tt.want {
+				t.Errorf("Verify() needsRehash = %v, want %v", needsRehash, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyLegacyBcrypt(t *testing.T) {
+	h := NewHasher(testParams)
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte("correct horse battery staple"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to hash with bcrypt: %v", err)
+	}
+
+	// pgcrypto's crypt() writes $2a$, other implementations $2b$ or $2y$
+	for _, prefix := range []string{"$2a$", "$2b$", "$2y$"} {
+		t.Run(prefix, func(t *testing.T) {
+			hash := prefix + string(legacy[4:])
+
+			match, needsRehash, err := h.Verify("correct horse battery staple", hash)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if !match || !needsRehash {
+				t.Errorf("Verify() = %v, %v, want a match that needs a rehash", match, needsRehash)
+			}
+
+			match, needsRehash, err = h.Verify("wrong password", hash)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if match || needsRehash {
+				t.Errorf("Verify() = %v, %v for a wrong password, want false, false", match, needsRehash)
+			}
+		})
+	}
+}
+
+func TestVerifyUnsupportedHash(t *testing.T) {
+	h := NewHasher(testParams)
+
+	tests := []struct {
+		name string
+		hash string
+	}{
+		{"empty", ""},
+		{"plain text", "correct horse battery staple"},
+		{"md5 crypt", "$1$saltsalt$qjXMvbEw8oaL.CzflDugX/"},
+		{"argon2i", "$argon2i$v=19$m=1024,t=1,p=1$c29tZXNhbHQ$c29tZWtleQ"},
+		{"argon2id wrong version", "$argon2id$v=16$m=1024,t=1,p=1$c29tZXNhbHQ$c29tZWtleQ"},
+		{"argon2id missing key", "$argon2id$v=19$m=1024,t=1,p=1$c29tZXNhbHQ$"},
+		{"argon2id bad params", "$argon2id$v=19$memory=1024$c29tZXNhbHQ$c29tZWtleQ"},
+		{"argon2id bad salt", "$argon2id$v=19$m=1024,t=1,p=1$not base64!$c29tZWtleQ"},
+		{"truncated bcrypt", "$2a$04$short"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, _, err := h.Verify("correct horse battery staple", tt.hash)
+			if !errors.Is(err, ErrUnsupportedHash) {
+				t.Errorf("Verify() error = %v, want ErrUnsupportedHash", err)
+			}
+			if match {
+				t.Error("Verify() matched an unsupported hash")
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ErrWeakPassword is wrapped by every policy violation
+var ErrWeakPassword = errors.New("password does not meet the policy")
+
+// MaxLength caps password length so hashing cost stays bounded
+const MaxLength = 128
+
+// commonPasswords are rejected regardless of length or character classes
+var commonPasswords = map[string]bool{
+	"password":      true,
+	"password1":     true,
+	"password123":   true,
+	"passw0rd":      true,
+	"12345678":      true,
+	"123456789":     true,
+	"1234567890":    true,
+	"123123123":     true,
+	"qwerty123":     true,
+	"qwertyuiop":    true,
+	"iloveyou":      true,
+	"admin123":      true,
+	"administrator": true,
+	"letmein123":    true,
+	"welcome123":    true,
+	"changeme":      true,
+	"reveegate":     true,
+	"reveegate123":  true,
+}
+
+// Policy describes the requirements for new passwords
+type Policy struct {
+	MinLength  int
+	MinClasses int // Distinct character classes: lowercase, uppercase, digits, symbols
+}
+
+// Validate checks a new password. identifiers such as the username and email
+// must not appear in the password.
+func (p Policy) Validate(password string, identifiers ...string) error {
+	length := len([]rune(password))
+	if length < p.MinLength {
+		return fmt.Errorf("%w: must be at least %d characters", ErrWeakPassword, p.MinLength)
+	}
+	if length > MaxLength {
+		return fmt.Errorf("%w: must be at most %d characters", ErrWeakPassword, MaxLength)
+	}
+
+	if classes := countClasses(password); classes < p.MinClasses {
+		return fmt.Errorf("%w: must mix at least %d of lowercase letters, uppercase letters, digits and symbols", ErrWeakPassword, p.MinClasses)
+	}
+
+	lower := strings.ToLower(password)
+	if commonPasswords[lower] {
+		return fmt.Errorf("%w: too common", ErrWeakPassword)
+	}
+
+	for _, id := range identifiers {
+		// Compare against the local part of email addresses
+		id, _, _ = strings.Cut(strings.ToLower(id), "@")
+		if len(id) >= 3 && strings.Contains(lower, id) {
+			return fmt.Errorf("%w: must not contain the username or email", ErrWeakPassword)
+		}
+	}
+
+	return nil
+}
+
+// countClasses counts the character classes used in a password
+func countClasses(password string) int {
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	count := 0
+	for _, used := range []bool{lower, upper, digit, symbol} {
+		if used {
+			count++
+		}
+	}
+	return count
+}
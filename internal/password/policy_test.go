@@ -0,0 +1,79 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPolicyValidate(t *testing.T) {
+	policy := Policy{MinLength: 12, MinClasses: 3}
+
+	tests := []struct {
+		name        string
+		password    string
+		identifiers []string
+		wantErr     bool
+	}{
+		{"strong password", "Tr0pical-Mango", nil, false},
+		{"minimum length", "Abcdefghij1k", nil, false},
+		{"too short", "Abcdefghi1k", nil, true},
+		{"length counts runes", "Páßwörd-Ünï", nil, true},
+		{"maximum length", "Aa1" + strings.Repeat("x", MaxLength-3), nil, false},
+		{"too long", "Aa1" + strings.Repeat("x", MaxLength-2), nil, true},
+		{"two classes", "tropicalmango42", nil, true},
+		{"symbols count as a class", "tropical-mango42", nil, false},
+		{"contains username", "Budi-Santoso-2026", []string{"budisantoso", "santoso"}, true},
+		{"contains email local part", "xX-Santoso-99", []string{"santoso@example.com"}, true},
+		{"email domain is ignored", "Example-Dot-Com1", []string{"budi@example.com"}, false},
+		{"short identifiers are ignored", "Tr0pical-Mango", []string{"tr"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.password, tt.identifiers...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrWeakPassword) {
+				t.Errorf("Validate() error = %v, want ErrWeakPassword", err)
+			}
+		})
+	}
+}
+
+func TestPolicyValidateCommonPasswords(t *testing.T) {
+	// The blocklist applies even when the policy would otherwise allow it
+	policy := Policy{MinLength: 8, MinClasses: 1}
+
+	for _, password := range []string{"password", "12345678", "qwertyuiop", "CHANGEME", "Reveegate"} {
+		if err := policy.Validate(password); !errors.Is(err, ErrWeakPassword) {
+			t.Errorf("Validate(%q) error = %v, want ErrWeakPassword", password, err)
+		}
+	}
+}
+
+func TestCountClasses(t *testing.T) {
+	tests := []struct {
+		password string
+		want     int
+	}{
+		{"", 0},
+		{"abc", 1},
+		{"ABC", 1},
+		{"123", 1},
+		{"!@#", 1},
+		{"abcABC", 2},
+		{"abc123!", 3},
+		{"aB3$", 4},
+		{"éÉ٣ ", 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			if got := countClasses(tt.password); got != tt.want {
+				t.Errorf("countClasses(%q) = %d, want %d", tt.password, got, tt.want)
+			}
+		})
+	}
+}
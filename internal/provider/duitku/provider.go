@@ -0,0 +1,402 @@
+package duitku
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/reveegate/reveegate/internal/config"
+	"github.com/reveegate/reveegate/internal/domain/payment"
+	"github.com/reveegate/reveegate/internal/domain/provider"
+	"github.com/reveegate/reveegate/internal/telemetry"
+)
+
+const (
+	sandboxURL = "https://sandbox.duitku.com"
+)
+
+// Provider implements Duitku payment provider
+type Provider struct {
+	merchantCode string
+	apiKey       string
+	baseURL      string
+	callbackURL  string
+	returnURL    string
+	httpClient   *http.Client
+}
+
+// NewProvider creates a new Duitku provider
+func NewProvider(cfg config.DuitkuConfig) *Provider {
+	baseURL := cfg.APIURL
+	if baseURL == "" {
+		baseURL = sandboxURL
+	}
+
+	return &Provider{
+		merchantCode: cfg.MerchantCode,
+		apiKey:       cfg.APIKey,
+		baseURL:      baseURL,
+		callbackURL:  cfg.CallbackURL,
+		returnURL:    cfg.ReturnURL,
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: telemetry.HTTPTransport("duitku"),
+		},
+	}
+}
+
+// GetName returns the provider name
+func (p *Provider) GetName() payment.Provider {
+	return payment.ProviderDuitku
+}
+
+// GetSupportedMethods returns supported payment methods
+func (p *Provider) GetSupportedMethods() []payment.Method {
+	return []payment.Method{
+		payment.MethodQRIS,
+		payment.MethodVABCA,
+		payment.MethodVABNI,
+		payment.MethodVABRI,
+		payment.MethodVAMandiri,
+		payment.MethodVAPermata,
+		payment.MethodOVO,
+		payment.MethodDANA,
+		payment.MethodShopeePay,
+		payment.MethodLinkAja,
+	}
+}
+
+// IsMethodSupported checks if a payment method is supported
+func (p *Provider) IsMethodSupported(method payment.Method) bool {
+	for _, m := range p.GetSupportedMethods() {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// CreatePayment creates a new payment through the v2 inquiry API
+func (p *Provider) CreatePayment(ctx context.Context, req provider.PaymentRequest) (*provider.PaymentResponse, error) {
+	channel := mapMethodToChannel(req.PaymentMethod)
+	if channel == "" {
+		return nil, fmt.Errorf("unsupported payment method: %s", req.PaymentMethod)
+	}
+
+	callbackURL := req.CallbackURL
+	if callbackURL == "" {
+		callbackURL = p.callbackURL
+	}
+
+	expiryMinutes := int(math.Ceil(time.Until(req.ExpiryTime).Minutes()))
+	if expiryMinutes <= 0 {
+		expiryMinutes = 1440 // 24 hours in minutes
+	}
+
+	amount := strconv.FormatInt(req.Amount, 10)
+
+	body := map[string]interface{}{
+		"merchantCode":    p.merchantCode,
+		"paymentAmount":   req.Amount,
+		"paymentMethod":   channel,
+		"merchantOrderId": req.OrderID,
+		"productDetails":  req.Description,
+		"email":           req.CustomerEmail,
+		"customerVaName":  req.CustomerName,
+		"callbackUrl":     callbackURL,
+		"returnUrl":       p.returnURL,
+		"expiryPeriod":    expiryMinutes,
+		"signature":       md5Hex(p.merchantCode + req.OrderID + amount + p.apiKey),
+	}
+
+	respBody, err := p.doRequest(ctx, "/webapi/api/merchant/v2/inquiry", body)
+	if err != nil {
+		return nil, fmt.Errorf("duitku inquiry failed: %w", err)
+	}
+
+	var resp struct {
+		MerchantCode  string `json:"merchantCode"`
+		Reference     string `json:"reference"`
+		PaymentURL    string `json:"paymentUrl"`
+		VANumber      string `json:"vaNumber"`
+		QRString      string `json:"qrString"`
+		StatusCode    string `json:"statusCode"`
+		StatusMessage string `json:"statusMessage"`
+	}
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if resp.StatusCode != "00" {
+		return nil, fmt.Errorf("duitku error: %s", resp.StatusMessage)
+	}
+
+	result := &provider.PaymentResponse{
+		ExternalID:    req.OrderID,
+		TransactionID: resp.Reference,
+		PaymentMethod: req.PaymentMethod,
+		ExpiresAt:     req.ExpiryTime,
+	}
+
+	// Extract payment details based on method
+	switch {
+	case req.PaymentMethod == payment.MethodQRIS:
+		result.QRCodeURL = resp.QRString
+	case resp.VANumber != "":
+		result.VANumber = resp.VANumber
+	default:
+		result.DeepLink = resp.PaymentURL
+	}
+
+	json.Unmarshal(respBody, &result.RawResponse)
+
+	return result, nil
+}
+
+// GetPaymentStatus gets the status of a transaction by merchant order ID
+func (p *Provider) GetPaymentStatus(ctx context.Context, req provider.StatusRequest) (*provider.PaymentStatus, error) {
+	body := map[string]interface{}{
+		"merchantCode":    p.merchantCode,
+		"merchantOrderId": req.OrderID,
+		"signature":       md5Hex(p.merchantCode + req.OrderID + p.apiKey),
+	}
+
+	respBody, err := p.doRequest(ctx, "/webapi/api/merchant/transactionStatus", body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment status: %w", err)
+	}
+
+	var resp struct {
+		MerchantOrderID string `json:"merchantOrderId"`
+		Reference       string `json:"reference"`
+		Amount          string `json:"amount"`
+		StatusCode      string `json:"statusCode"`
+		StatusMessage   string `json:"statusMessage"`
+	}
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	amount, _ := strconv.ParseInt(resp.Amount, 10, 64)
+
+	return &provider.PaymentStatus{
+		OrderID:       resp.MerchantOrderID,
+		ExternalID:    resp.MerchantOrderID,
+		TransactionID: resp.Reference,
+		RawStatus:     resp.StatusCode,
+		Status:        mapTransactionStatus(resp.StatusCode),
+		Amount:        amount,
+		PaymentMethod: req.PaymentMethod,
+	}, nil
+}
+
+// CancelPayment is not supported; unpaid Duitku transactions simply expire
+func (p *Provider) CancelPayment(ctx context.Context, req provider.StatusRequest) error {
+	return provider.ErrCancelNotSupported
+}
+
+// Ping checks the Duitku API can be reached
+func (p *Provider) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach duitku: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("duitku returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// VerifyWebhook verifies the callback signature
+//
+// Duitku posts form-encoded callbacks with the signature inside the body:
+// MD5(merchantCode + amount + merchantOrderId + apiKey)
+func (p *Provider) VerifyWebhook(payload []byte, signature string) error {
+	form, err := url.ParseQuery(string(payload))
+	if err != nil {
+		return fmt.Errorf("failed to parse callback: %w", err)
+	}
+
+	if form.Get("merchantCode") != p.merchantCode {
+		return errors.New("duitku merchant code mismatch")
+	}
+
+	expected := md5Hex(form.Get("merchantCode") + form.Get("amount") + form.Get("merchantOrderId") + p.apiKey)
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(form.Get("signature"))) != 1 {
+		return errors.New("invalid duitku callback signature")
+	}
+
+	return nil
+}
+
+// ParseWebhook parses the form-encoded webhook payload
+func (p *Provider) ParseWebhook(payload []byte) (*provider.WebhookData, error) {
+	form, err := url.ParseQuery(string(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse webhook payload: %w", err)
+	}
+
+	amount, _ := strconv.ParseInt(form.Get("amount"), 10, 64)
+	settlementTime, _ := time.Parse("2006-01-02 15:04:05", form.Get("settlementDate"))
+
+	raw := make(map[string]interface{}, len(form))
+	for key := range form {
+		if key == "signature" {
+			continue
+		}
+		raw[key] = form.Get(key)
+	}
+
+	// resultCode 00 means success, 01 means failed
+	status := payment.StatusFailed
+	if form.Get("resultCode") == "00" {
+		status = payment.StatusPaid
+	}
+
+	return &provider.WebhookData{
+		EventType:       "callback",
+		OrderID:         form.Get("merchantOrderId"),
+		TransactionID:   form.Get("reference"),
+		TransactionTime: settlementTime,
+		Status:          status,
+		Amount:          amount,
+		PaymentMethod:   mapChannelToMethod(form.Get("paymentCode")),
+		RawPayload:      raw,
+	}, nil
+}
+
+// doRequest makes a POST request to Duitku API
+func (p *Provider) doRequest(ctx context.Context, endpoint string, body interface{}) ([]byte, error) {
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+endpoint, bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set headers
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	// Make request
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		var errResp struct {
+			Message string `json:"Message"`
+		}
+		json.Unmarshal(respBody, &errResp)
+		errMsg := errResp.Message
+		if errMsg == "" {
+			errMsg = "Unknown error"
+		}
+		return nil, fmt.Errorf("duitku error (%d): %s", resp.StatusCode, errMsg)
+	}
+
+	return respBody, nil
+}
+
+// Helper functions
+
+func md5Hex(data string) string {
+	hash := md5.Sum([]byte(data))
+	return hex.EncodeToString(hash[:])
+}
+
+func mapTransactionStatus(statusCode string) payment.Status {
+	switch statusCode {
+	case "00":
+		return payment.StatusPaid
+	case "02":
+		return payment.StatusFailed
+	default:
+		return payment.StatusPending
+	}
+}
+
+func mapMethodToChannel(method payment.Method) string {
+	switch method {
+	case payment.MethodQRIS:
+		return "SP"
+	case payment.MethodVABCA:
+		return "BC"
+	case payment.MethodVABNI:
+		return "I1"
+	case payment.MethodVABRI:
+		return "BR"
+	case payment.MethodVAMandiri:
+		return "M2"
+	case payment.MethodVAPermata:
+		return "BT"
+	case payment.MethodOVO:
+		return "OV"
+	case payment.MethodDANA:
+		return "DA"
+	case payment.MethodShopeePay:
+		return "SA"
+	case payment.MethodLinkAja:
+		return "LA"
+	default:
+		return ""
+	}
+}
+
+func mapChannelToMethod(channel string) payment.Method {
+	switch channel {
+	case "SP", "NQ", "GQ":
+		return payment.MethodQRIS
+	case "BC":
+		return payment.MethodVABCA
+	case "I1":
+		return payment.MethodVABNI
+	case "BR":
+		return payment.MethodVABRI
+	case "M2":
+		return payment.MethodVAMandiri
+	case "BT":
+		return payment.MethodVAPermata
+	case "OV":
+		return payment.MethodOVO
+	case "DA":
+		return payment.MethodDANA
+	case "SA":
+		return payment.MethodShopeePay
+	case "LA":
+		return payment.MethodLinkAja
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,302 @@
+package duitku
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/reveegate/reveegate/internal/config"
+	"github.com/reveegate/reveegate/internal/domain/payment"
+	"github.com/reveegate/reveegate/internal/domain/provider"
+)
+
+const (
+	testMerchantCode = "DS12345"
+	testAPIKey       = "test-api-key"
+)
+
+func newTestProvider(t *testing.T, handler http.HandlerFunc) *Provider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return NewProvider(config.DuitkuConfig{
+		MerchantCode: testMerchantCode,
+		APIKey:       testAPIKey,
+		APIURL:       server.URL,
+		CallbackURL:  "https://donate.example.com/webhooks/duitku",
+	})
+}
+
+// sign computes the MD5 signatures Duitku uses, independently of md5Hex
+func sign(data string) string {
+	sum := md5.Sum([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+func respond(t *testing.T, w http.ResponseWriter, status int, body interface{}) {
+	t.Helper()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		t.Errorf("failed to write response: %v", err)
+	}
+}
+
+func TestCreatePayment(t *testing.T) {
+	tests := []struct {
+		method    payment.Method
+		channel   string
+		qrCodeURL string
+		vaNumber  string
+		deepLink  string
+	}{
+		{method: payment.MethodQRIS, channel: "SP", qrCodeURL: "00020101021226660014ID.LINKAJA.WWW"},
+		{method: payment.MethodVABCA, channel: "BC", vaNumber: "7007014001234567"},
+		{method: payment.MethodVAPermata, channel: "BT", vaNumber: "8500001234567890"},
+		{method: payment.MethodDANA, channel: "DA", deepLink: "https://sandbox.duitku.com/topup/v2/TopUpCreditCardPayment.aspx?ref=DS1234"},
+		{method: payment.MethodLinkAja, channel: "LA", deepLink: "https://sandbox.duitku.com/topup/v2/TopUpCreditCardPayment.aspx?ref=DS1234"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/webapi/api/merchant/v2/inquiry" {
+					t.Errorf("request = %s %s, want POST /webapi/api/merchant/v2/inquiry", r.Method, r.URL.Path)
+				}
+
+				var body map[string]interface{}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("failed to decode request: %v", err)
+					return
+				}
+				if body["paymentMethod"] != tt.channel {
+					t.Errorf("paymentMethod = %v, want %s", body["paymentMethod"], tt.channel)
+				}
+				if body["merchantCode"] != testMerchantCode || body["merchantOrderId"] != "RG-ORDER-1" {
+					t.Errorf("merchant = %v/%v", body["merchantCode"], body["merchantOrderId"])
+				}
+				if body["callbackUrl"] != "https://donate.example.com/webhooks/duitku" {
+					t.Errorf("callbackUrl = %v", body["callbackUrl"])
+				}
+				if want := sign(testMerchantCode + "RG-ORDER-1" + "50000" + testAPIKey); body["signature"] != want {
+					t.Errorf("signature = %v, want %s", body["signature"], want)
+				}
+
+				respond(t, w, http.StatusOK, map[string]interface{}{
+					"merchantCode":  testMerchantCode,
+					"reference":     "DS1234",
+					"paymentUrl":    tt.deepLink,
+					"vaNumber":      tt.vaNumber,
+					"qrString":      tt.qrCodeURL,
+					"amount":        "50000",
+					"statusCode":    "00",
+					"statusMessage": "SUCCESS",
+				})
+			})
+
+			expiry := time.Now().Add(time.Hour)
+			resp, err := p.CreatePayment(context.Background(), provider.PaymentRequest{
+				OrderID:       "RG-ORDER-1",
+				Amount:        50000,
+				PaymentMethod: tt.method,
+				CustomerName:  "Budi",
+				CustomerEmail: "budi@example.com",
+				Description:   "Donation from Budi",
+				ExpiryTime:    expiry,
+			})
+			if err != nil {
+				t.Fatalf("CreatePayment() error = %v", err)
+			}
+
+			if resp.ExternalID != "RG-ORDER-1" || resp.TransactionID != "DS1234" {
+				t.Errorf("ids = %q/%q", resp.ExternalID, resp.TransactionID)
+			}
+			if !resp.ExpiresAt.Equal(expiry) {
+				t.Errorf("ExpiresAt = %v, want %v", resp.ExpiresAt, expiry)
+			}
+			if resp.QRCodeURL != tt.qrCodeURL || resp.VANumber != tt.vaNumber || resp.DeepLink != tt.deepLink {
+				t.Errorf("details = %q/%q/%q, want %q/%q/%q",
+					resp.QRCodeURL, resp.VANumber, resp.DeepLink, tt.qrCodeURL, tt.vaNumber, tt.deepLink)
+			}
+		})
+	}
+}
+
+func TestCreatePaymentErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		method payment.Method
+		status int
+		body   map[string]interface{}
+	}{
+		{"unsupported method", payment.MethodGoPay, http.StatusOK, nil},
+		{"http error", payment.MethodQRIS, http.StatusUnauthorized, map[string]interface{}{"Message": "Wrong signature"}},
+		{"rejected inquiry", payment.MethodQRIS, http.StatusOK, map[string]interface{}{"statusCode": "01", "statusMessage": "Minimum payment 10000 IDR"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+				respond(t, w, tt.status, tt.body)
+			})
+
+			_, err := p.CreatePayment(context.Background(), provider.PaymentRequest{
+				OrderID:       "RG-ORDER-1",
+				Amount:        5000,
+				PaymentMethod: tt.method,
+				ExpiryTime:    time.Now().Add(time.Hour),
+			})
+			if err == nil {
+				t.Error("CreatePayment() error = nil")
+			}
+		})
+	}
+}
+
+func TestGetPaymentStatus(t *testing.T) {
+	tests := []struct {
+		statusCode string
+		want       payment.Status
+	}{
+		{"00", payment.StatusPaid},
+		{"01", payment.StatusPending},
+		{"02", payment.StatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.statusCode, func(t *testing.T) {
+			p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/webapi/api/merchant/transactionStatus" {
+					t.Errorf("request = %s %s, want POST /webapi/api/merchant/transactionStatus", r.Method, r.URL.Path)
+				}
+
+				var body map[string]interface{}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("failed to decode request: %v", err)
+					return
+				}
+				if want := sign(testMerchantCode + "RG-ORDER-1" + testAPIKey); body["signature"] != want {
+					t.Errorf("signature = %v, want %s", body["signature"], want)
+				}
+
+				respond(t, w, http.StatusOK, map[string]interface{}{
+					"merchantOrderId": "RG-ORDER-1",
+					"reference":       "DS1234",
+					"amount":          "75000",
+					"fee":             "0.00",
+					"statusCode":      tt.statusCode,
+					"statusMessage":   "status message",
+				})
+			})
+
+			status, err := p.GetPaymentStatus(context.Background(), provider.StatusRequest{
+				OrderID:       "RG-ORDER-1",
+				PaymentMethod: payment.MethodVABRI,
+			})
+			if err != nil {
+				t.Fatalf("GetPaymentStatus() error = %v", err)
+			}
+
+			if status.Status != tt.want || status.RawStatus != tt.statusCode {
+				t.Errorf("status = %q (%q), want %q", status.Status, status.RawStatus, tt.want)
+			}
+			if status.OrderID != "RG-ORDER-1" || status.TransactionID != "DS1234" || status.Amount != 75000 {
+				t.Errorf("status = %+v", status)
+			}
+		})
+	}
+}
+
+func callbackForm(amount, signature string) url.Values {
+	return url.Values{
+		"merchantCode":    {testMerchantCode},
+		"amount":          {amount},
+		"merchantOrderId": {"RG-ORDER-1"},
+		"paymentCode":     {"BR"},
+		"resultCode":      {"00"},
+		"reference":       {"DS1234"},
+		"settlementDate":  {"2026-03-14 09:30:00"},
+		"signature":       {signature},
+	}
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	p := NewProvider(config.DuitkuConfig{MerchantCode: testMerchantCode, APIKey: testAPIKey})
+	signature := sign(testMerchantCode + "50000" + "RG-ORDER-1" + testAPIKey)
+
+	otherMerchant := callbackForm("50000", sign("DS99999"+"50000"+"RG-ORDER-1"+testAPIKey))
+	otherMerchant.Set("merchantCode", "DS99999")
+
+	tests := []struct {
+		name    string
+		form    url.Values
+		wantErr bool
+	}{
+		{"valid", callbackForm("50000", signature), false},
+		{"tampered amount", callbackForm("90000", signature), true},
+		{"signed with another key", callbackForm("50000", sign(testMerchantCode+"50000"+"RG-ORDER-1"+"another-key")), true},
+		{"other merchant", otherMerchant, true},
+		{"missing signature", callbackForm("50000", ""), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.VerifyWebhook([]byte(tt.form.Encode()), "")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyWebhook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseWebhook(t *testing.T) {
+	p := NewProvider(config.DuitkuConfig{MerchantCode: testMerchantCode, APIKey: testAPIKey})
+	form := callbackForm("50000", sign(testMerchantCode+"50000"+"RG-ORDER-1"+testAPIKey))
+
+	data, err := p.ParseWebhook([]byte(form.Encode()))
+	if err != nil {
+		t.Fatalf("ParseWebhook() error = %v", err)
+	}
+
+	if data.OrderID != "RG-ORDER-1" || data.TransactionID != "DS1234" {
+		t.Errorf("ids = %q/%q", data.OrderID, data.TransactionID)
+	}
+	if data.Status != payment.StatusPaid || data.Amount != 50000 || data.PaymentMethod != payment.MethodVABRI {
+		t.Errorf("data = %+v", data)
+	}
+	if want := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC); !data.TransactionTime.Equal(want) {
+		t.Errorf("TransactionTime = %v, want %v", data.TransactionTime, want)
+	}
+	if _, ok := data.RawPayload["signature"]; ok {
+		t.Error("RawPayload keeps the signature")
+	}
+
+	form.Set("resultCode", "01")
+	data, err = p.ParseWebhook([]byte(form.Encode()))
+	if err != nil {
+		t.Fatalf("ParseWebhook() error = %v", err)
+	}
+	if data.Status != payment.StatusFailed {
+		t.Errorf("Status = %q, want %q", data.Status, payment.StatusFailed)
+	}
+}
+
+func TestMethodMapping(t *testing.T) {
+	for _, method := range NewProvider(config.DuitkuConfig{}).GetSupportedMethods() {
+		channel := mapMethodToChannel(method)
+		if channel == "" {
+			t.Errorf("%s has no channel", method)
+			continue
+		}
+		if got := mapChannelToMethod(channel); got != method {
+			t.Errorf("channel %s maps back to %q, want %q", channel, got, method)
+		}
+	}
+}
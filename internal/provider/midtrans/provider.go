@@ -13,6 +13,7 @@ import (
 	"github.com/reveegate/reveegate/internal/config"
 	"github.com/reveegate/reveegate/internal/domain/payment"
 	"github.com/reveegate/reveegate/internal/domain/provider"
+	"github.com/reveegate/reveegate/internal/telemetry"
 )
 
 const (
@@ -44,7 +45,8 @@ func NewProvider(cfg config.MidtransConfig) *Provider {
 		baseURL:      baseURL,
 		isProduction: cfg.IsProduction,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: telemetry.HTTPTransport("midtrans"),
 		},
 	}
 }
@@ -86,8 +88,8 @@ func (p *Provider) CreatePayment(ctx context.Context, req provider.PaymentReques
 }
 
 // GetPaymentStatus gets the status of a payment
-func (p *Provider) GetPaymentStatus(ctx context.Context, orderID string) (*provider.PaymentStatus, error) {
-	endpoint := fmt.Sprintf("/v2/%s/status", orderID)
+func (p *Provider) GetPaymentStatus(ctx context.Context, req provider.StatusRequest) (*provider.PaymentStatus, error) {
+	endpoint := fmt.Sprintf("/v2/%s/status", req.OrderID)
 
 	resp, err := p.doRequest(ctx, endpoint, nil)
 	if err != nil {
@@ -117,6 +119,46 @@ func (p *Provider) GetPaymentStatus(ctx context.Context, orderID string) (*provi
 	return status, nil
 }
 
+// CancelPayment expires a pending transaction so it can no longer be paid
+func (p *Provider) CancelPayment(ctx context.Context, req provider.StatusRequest) error {
+	endpoint := fmt.Sprintf("/v2/%s/expire", req.OrderID)
+
+	resp, err := p.doRequest(ctx, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to cancel payment: %w", err)
+	}
+
+	// 407 means the transaction had already expired
+	statusCode, _ := resp["status_code"].(string)
+	if statusCode != "" && statusCode != "200" && statusCode != "407" {
+		message, _ := resp["status_message"].(string)
+		return fmt.Errorf("midtrans error (%s): %s", statusCode, message)
+	}
+
+	return nil
+}
+
+// Ping checks the Midtrans API can be reached
+func (p *Provider) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach midtrans: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("midtrans returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
 // buildRequest builds Midtrans charge request
 func (p *Provider) buildRequest(req provider.PaymentRequest) map[string]interface{} {
 	baseReq := map[string]interface{}{
@@ -0,0 +1,77 @@
+package registry
+
+import (
+	"fmt"
+
+	"github.com/reveegate/reveegate/internal/domain/payment"
+	"github.com/reveegate/reveegate/internal/domain/provider"
+)
+
+// Registry implements provider.ProviderFactory over a fixed set of providers
+type Registry struct {
+	providers       map[payment.Provider]provider.Provider
+	order           []payment.Provider
+	defaultProvider payment.Provider
+}
+
+// NewRegistry creates a new provider registry
+//
+// The default provider is preferred when resolving a provider for a method;
+// the others are tried in the order given
+func NewRegistry(defaultProvider payment.Provider, providers ...provider.Provider) *Registry {
+	r := &Registry{
+		providers:       make(map[payment.Provider]provider.Provider, len(providers)),
+		order:           make([]payment.Provider, 0, len(providers)),
+		defaultProvider: defaultProvider,
+	}
+
+	for _, p := range providers {
+		name := p.GetName()
+		if _, exists := r.providers[name]; exists {
+			continue
+		}
+		r.providers[name] = p
+		r.order = append(r.order, name)
+	}
+
+	return r
+}
+
+// GetProvider returns the provider with the given name
+func (r *Registry) GetProvider(name payment.Provider) (provider.Provider, error) {
+	p, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("payment provider not configured: %s", name)
+	}
+	return p, nil
+}
+
+// GetDefaultProvider returns the provider used for new donations
+func (r *Registry) GetDefaultProvider() (provider.Provider, error) {
+	return r.GetProvider(r.defaultProvider)
+}
+
+// GetProviderForMethod returns the first provider supporting the method,
+// starting with the default provider
+func (r *Registry) GetProviderForMethod(method payment.Method) (provider.Provider, error) {
+	if p, ok := r.providers[r.defaultProvider]; ok && p.IsMethodSupported(method) {
+		return p, nil
+	}
+
+	for _, name := range r.order {
+		if p := r.providers[name]; p.IsMethodSupported(method) {
+			return p, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no payment provider supports method: %s", method)
+}
+
+// GetAllProviders returns all registered providers
+func (r *Registry) GetAllProviders() []provider.Provider {
+	providers := make([]provider.Provider, 0, len(r.order))
+	for _, name := range r.order {
+		providers = append(providers, r.providers[name])
+	}
+	return providers
+}
@@ -0,0 +1,446 @@
+package tripay
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/reveegate/reveegate/internal/config"
+	"github.com/reveegate/reveegate/internal/domain/payment"
+	"github.com/reveegate/reveegate/internal/domain/provider"
+	"github.com/reveegate/reveegate/internal/telemetry"
+)
+
+const (
+	sandboxURL = "https://tripay.co.id/api-sandbox"
+
+	// defaultCustomerEmail is used when the donor leaves the email empty,
+	// since Tripay requires one on every transaction
+	defaultCustomerEmail = "donor@reveegate.com"
+)
+
+// Provider implements Tripay payment provider
+type Provider struct {
+	apiKey       string
+	privateKey   string
+	merchantCode string
+	baseURL      string
+	callbackURL  string
+	returnURL    string
+	httpClient   *http.Client
+}
+
+// NewProvider creates a new Tripay provider
+func NewProvider(cfg config.TripayConfig) *Provider {
+	baseURL := cfg.APIURL
+	if baseURL == "" {
+		baseURL = sandboxURL
+	}
+
+	return &Provider{
+		apiKey:       cfg.APIKey,
+		privateKey:   cfg.PrivateKey,
+		merchantCode: cfg.MerchantCode,
+		baseURL:      baseURL,
+		callbackURL:  cfg.CallbackURL,
+		returnURL:    cfg.ReturnURL,
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: telemetry.HTTPTransport("tripay"),
+		},
+	}
+}
+
+// GetName returns the provider name
+func (p *Provider) GetName() payment.Provider {
+	return payment.ProviderTripay
+}
+
+// GetSupportedMethods returns supported payment methods
+func (p *Provider) GetSupportedMethods() []payment.Method {
+	return []payment.Method{
+		payment.MethodQRIS,
+		payment.MethodVABCA,
+		payment.MethodVABNI,
+		payment.MethodVABRI,
+		payment.MethodVAMandiri,
+		payment.MethodVAPermata,
+		payment.MethodOVO,
+		payment.MethodDANA,
+		payment.MethodShopeePay,
+	}
+}
+
+// IsMethodSupported checks if a payment method is supported
+func (p *Provider) IsMethodSupported(method payment.Method) bool {
+	for _, m := range p.GetSupportedMethods() {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// CreatePayment creates a new closed payment transaction
+func (p *Provider) CreatePayment(ctx context.Context, req provider.PaymentRequest) (*provider.PaymentResponse, error) {
+	channel := mapMethodToChannel(req.PaymentMethod)
+	if channel == "" {
+		return nil, fmt.Errorf("unsupported payment method: %s", req.PaymentMethod)
+	}
+
+	email := req.CustomerEmail
+	if email == "" {
+		email = defaultCustomerEmail
+	}
+
+	callbackURL := req.CallbackURL
+	if callbackURL == "" {
+		callbackURL = p.callbackURL
+	}
+
+	body := map[string]interface{}{
+		"method":         channel,
+		"merchant_ref":   req.OrderID,
+		"amount":         req.Amount,
+		"customer_name":  req.CustomerName,
+		"customer_email": email,
+		"order_items": []map[string]interface{}{
+			{
+				"name":     req.Description,
+				"price":    req.Amount,
+				"quantity": 1,
+			},
+		},
+		"callback_url": callbackURL,
+		"return_url":   p.returnURL,
+		"expired_time": req.ExpiryTime.Unix(),
+		"signature":    p.transactionSignature(req.OrderID, req.Amount),
+	}
+
+	resp, err := p.doRequest(ctx, "POST", "/transaction/create", body)
+	if err != nil {
+		return nil, fmt.Errorf("tripay transaction failed: %w", err)
+	}
+
+	var data transactionData
+	if err := json.Unmarshal(resp, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse transaction: %w", err)
+	}
+
+	result := &provider.PaymentResponse{
+		ExternalID:    data.MerchantRef,
+		TransactionID: data.Reference,
+		PaymentMethod: req.PaymentMethod,
+		ExpiresAt:     time.Unix(data.ExpiredTime, 0),
+	}
+
+	// Extract payment details based on method
+	switch {
+	case req.PaymentMethod == payment.MethodQRIS:
+		result.QRCodeURL = data.QRString
+	case isVA(req.PaymentMethod):
+		result.VANumber = data.PayCode
+	default:
+		result.DeepLink = data.PayURL
+		if result.DeepLink == "" {
+			result.DeepLink = data.CheckoutURL
+		}
+	}
+
+	if data.ExpiredTime == 0 {
+		result.ExpiresAt = req.ExpiryTime
+	}
+
+	json.Unmarshal(resp, &result.RawResponse)
+
+	return result, nil
+}
+
+// GetPaymentStatus gets the status of a transaction by its Tripay reference
+func (p *Provider) GetPaymentStatus(ctx context.Context, req provider.StatusRequest) (*provider.PaymentStatus, error) {
+	if req.TransactionID == "" {
+		return nil, errors.New("tripay reference is required")
+	}
+
+	endpoint := "/transaction/detail?reference=" + url.QueryEscape(req.TransactionID)
+
+	resp, err := p.doRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment status: %w", err)
+	}
+
+	var data transactionData
+	if err := json.Unmarshal(resp, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse transaction: %w", err)
+	}
+
+	status := &provider.PaymentStatus{
+		OrderID:       data.MerchantRef,
+		ExternalID:    data.MerchantRef,
+		TransactionID: data.Reference,
+		RawStatus:     data.Status,
+		Status:        mapStatus(data.Status),
+		Amount:        data.Amount,
+		PaymentMethod: mapChannelToMethod(data.PaymentMethod),
+	}
+
+	if data.PaidAt > 0 {
+		status.TransactionTime = time.Unix(data.PaidAt, 0)
+	}
+
+	return status, nil
+}
+
+// CancelPayment is not supported; unpaid Tripay transactions simply expire
+func (p *Provider) CancelPayment(ctx context.Context, req provider.StatusRequest) error {
+	return provider.ErrCancelNotSupported
+}
+
+// Ping checks the Tripay API can be reached
+func (p *Provider) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach tripay: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("tripay returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// VerifyWebhook verifies the X-Callback-Signature header
+//
+// The signature is HMAC-SHA256 of the raw callback body keyed with the private key
+func (p *Provider) VerifyWebhook(payload []byte, signature string) error {
+	mac := hmac.New(sha256.New, []byte(p.privateKey))
+	mac.Write(payload)
+	expected := hex.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errors.New("invalid tripay callback signature")
+	}
+
+	return nil
+}
+
+// ParseWebhook parses the webhook payload
+func (p *Provider) ParseWebhook(payload []byte) (*provider.WebhookData, error) {
+	var cb callback
+	if err := json.Unmarshal(payload, &cb); err != nil {
+		return nil, fmt.Errorf("failed to parse webhook payload: %w", err)
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse webhook payload: %w", err)
+	}
+
+	data := &provider.WebhookData{
+		EventType:     "payment_status",
+		OrderID:       cb.MerchantRef,
+		TransactionID: cb.Reference,
+		Status:        mapStatus(cb.Status),
+		Amount:        cb.TotalAmount,
+		PaymentMethod: mapChannelToMethod(cb.PaymentMethodCode),
+		RawPayload:    raw,
+	}
+
+	if cb.PaidAt > 0 {
+		data.TransactionTime = time.Unix(cb.PaidAt, 0)
+	}
+
+	// Only the merchant share is deducted from what we receive; the customer
+	// share is charged on top of the order amount
+	if cb.TotalFee > 0 {
+		fee := cb.FeeMerchant
+		data.Fee = &fee
+	}
+
+	return data, nil
+}
+
+// transactionData holds the fields used from a Tripay transaction
+type transactionData struct {
+	Reference     string `json:"reference"`
+	MerchantRef   string `json:"merchant_ref"`
+	PaymentMethod string `json:"payment_method"`
+	Amount        int64  `json:"amount"`
+	PayCode       string `json:"pay_code"`
+	PayURL        string `json:"pay_url"`
+	CheckoutURL   string `json:"checkout_url"`
+	QRString      string `json:"qr_string"`
+	Status        string `json:"status"`
+	ExpiredTime   int64  `json:"expired_time"`
+	PaidAt        int64  `json:"paid_at"`
+}
+
+// callback represents a payment_status callback
+type callback struct {
+	Reference         string `json:"reference"`
+	MerchantRef       string `json:"merchant_ref"`
+	PaymentMethod     string `json:"payment_method"`
+	PaymentMethodCode string `json:"payment_method_code"`
+	TotalAmount       int64  `json:"total_amount"`
+	FeeMerchant       int64  `json:"fee_merchant"`
+	FeeCustomer       int64  `json:"fee_customer"`
+	TotalFee          int64  `json:"total_fee"`
+	AmountReceived    int64  `json:"amount_received"`
+	Status            string `json:"status"`
+	PaidAt            int64  `json:"paid_at"`
+}
+
+// transactionSignature signs a closed transaction request
+func (p *Provider) transactionSignature(merchantRef string, amount int64) string {
+	mac := hmac.New(sha256.New, []byte(p.privateKey))
+	mac.Write([]byte(p.merchantCode + merchantRef + strconv.FormatInt(amount, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// doRequest makes an HTTP request to Tripay API and returns the data field
+func (p *Provider) doRequest(ctx context.Context, method, endpoint string, body interface{}) (json.RawMessage, error) {
+	var reqBody io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reqBody = bytes.NewReader(jsonBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURL+endpoint, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set headers
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	// Make request
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var result struct {
+		Success bool            `json:"success"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 || !result.Success {
+		errMsg := result.Message
+		if errMsg == "" {
+			errMsg = "Unknown error"
+		}
+		return nil, fmt.Errorf("tripay error (%d): %s", resp.StatusCode, errMsg)
+	}
+
+	return result.Data, nil
+}
+
+// Helper functions
+
+func isVA(method payment.Method) bool {
+	switch method {
+	case payment.MethodVABCA, payment.MethodVABNI, payment.MethodVABRI,
+		payment.MethodVAMandiri, payment.MethodVAPermata:
+		return true
+	}
+	return false
+}
+
+func mapStatus(status string) payment.Status {
+	switch status {
+	case "PAID":
+		return payment.StatusPaid
+	case "EXPIRED":
+		return payment.StatusExpired
+	case "FAILED":
+		return payment.StatusFailed
+	case "REFUND":
+		return payment.StatusRefunded
+	default:
+		return payment.StatusPending
+	}
+}
+
+func mapMethodToChannel(method payment.Method) string {
+	switch method {
+	case payment.MethodQRIS:
+		return "QRIS"
+	case payment.MethodVABCA:
+		return "BCAVA"
+	case payment.MethodVABNI:
+		return "BNIVA"
+	case payment.MethodVABRI:
+		return "BRIVA"
+	case payment.MethodVAMandiri:
+		return "MANDIRIVA"
+	case payment.MethodVAPermata:
+		return "PERMATAVA"
+	case payment.MethodOVO:
+		return "OVO"
+	case payment.MethodDANA:
+		return "DANA"
+	case payment.MethodShopeePay:
+		return "SHOPEEPAY"
+	default:
+		return ""
+	}
+}
+
+func mapChannelToMethod(channel string) payment.Method {
+	switch channel {
+	case "QRIS", "QRISC", "QRIS2":
+		return payment.MethodQRIS
+	case "BCAVA":
+		return payment.MethodVABCA
+	case "BNIVA":
+		return payment.MethodVABNI
+	case "BRIVA":
+		return payment.MethodVABRI
+	case "MANDIRIVA":
+		return payment.MethodVAMandiri
+	case "PERMATAVA":
+		return payment.MethodVAPermata
+	case "OVO":
+		return payment.MethodOVO
+	case "DANA":
+		return payment.MethodDANA
+	case "SHOPEEPAY":
+		return payment.MethodShopeePay
+	default:
+		return ""
+	}
+}
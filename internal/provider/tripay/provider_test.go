@@ -0,0 +1,300 @@
+package tripay
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/reveegate/reveegate/internal/config"
+	"github.com/reveegate/reveegate/internal/domain/payment"
+	"github.com/reveegate/reveegate/internal/domain/provider"
+)
+
+const (
+	testAPIKey       = "DEV-test-api-key"
+	testPrivateKey   = "test-private-key"
+	testMerchantCode = "T12345"
+)
+
+func newTestProvider(t *testing.T, handler http.HandlerFunc) *Provider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return NewProvider(config.TripayConfig{
+		APIKey:       testAPIKey,
+		PrivateKey:   testPrivateKey,
+		MerchantCode: testMerchantCode,
+		APIURL:       server.URL,
+		CallbackURL:  "https://donate.example.com/webhooks/tripay",
+	})
+}
+
+func hmacHex(key, data string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(data))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func respond(t *testing.T, w http.ResponseWriter, status int, body interface{}) {
+	t.Helper()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		t.Errorf("failed to write response: %v", err)
+	}
+}
+
+func TestCreatePayment(t *testing.T) {
+	expiry := time.Date(2026, 3, 15, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		method    payment.Method
+		channel   string
+		qrCodeURL string
+		vaNumber  string
+		deepLink  string
+	}{
+		{method: payment.MethodQRIS, channel: "QRIS", qrCodeURL: "00020101021226610016ID.CO.SHOPEE.WWW"},
+		{method: payment.MethodVABNI, channel: "BNIVA", vaNumber: "8808123456789012"},
+		{method: payment.MethodVAMandiri, channel: "MANDIRIVA", vaNumber: "8808123456789012"},
+		{method: payment.MethodOVO, channel: "OVO", deepLink: "https://tripay.co.id/checkout/DEV-T1234"},
+		{method: payment.MethodShopeePay, channel: "SHOPEEPAY", deepLink: "https://tripay.co.id/checkout/DEV-T1234"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/transaction/create" {
+					t.Errorf("request = %s %s, want POST /transaction/create", r.Method, r.URL.Path)
+				}
+				if got := r.Header.Get("Authorization"); got != "Bearer "+testAPIKey {
+					t.Errorf("Authorization = %q", got)
+				}
+
+				var body map[string]interface{}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("failed to decode request: %v", err)
+					return
+				}
+				if body["method"] != tt.channel {
+					t.Errorf("method = %v, want %s", body["method"], tt.channel)
+				}
+				if body["merchant_ref"] != "RG-ORDER-1" {
+					t.Errorf("merchant_ref = %v", body["merchant_ref"])
+				}
+				if body["customer_email"] != defaultCustomerEmail {
+					t.Errorf("customer_email = %v, want the default", body["customer_email"])
+				}
+				if want := hmacHex(testPrivateKey, testMerchantCode+"RG-ORDER-1"+"50000"); body["signature"] != want {
+					t.Errorf("signature = %v, want %s", body["signature"], want)
+				}
+
+				respond(t, w, http.StatusOK, map[string]interface{}{
+					"success": true,
+					"data": map[string]interface{}{
+						"reference":      "DEV-T1234",
+						"merchant_ref":   "RG-ORDER-1",
+						"payment_method": tt.channel,
+						"amount":         50000,
+						"pay_code":       tt.vaNumber,
+						"checkout_url":   tt.deepLink,
+						"qr_string":      tt.qrCodeURL,
+						"status":         "UNPAID",
+						"expired_time":   expiry.Unix(),
+					},
+				})
+			})
+
+			resp, err := p.CreatePayment(context.Background(), provider.PaymentRequest{
+				OrderID:       "RG-ORDER-1",
+				Amount:        50000,
+				PaymentMethod: tt.method,
+				CustomerName:  "Budi",
+				Description:   "Donation from Budi",
+				ExpiryTime:    expiry,
+			})
+			if err != nil {
+				t.Fatalf("CreatePayment() error = %v", err)
+			}
+
+			if resp.ExternalID != "RG-ORDER-1" || resp.TransactionID != "DEV-T1234" {
+				t.Errorf("ids = %q/%q", resp.ExternalID, resp.TransactionID)
+			}
+			if !resp.ExpiresAt.Equal(expiry) {
+				t.Errorf("ExpiresAt = %v, want %v", resp.ExpiresAt, expiry)
+			}
+			if resp.QRCodeURL != tt.qrCodeURL || resp.VANumber != tt.vaNumber || resp.DeepLink != tt.deepLink {
+				t.Errorf("details = %q/%q/%q, want %q/%q/%q",
+					resp.QRCodeURL, resp.VANumber, resp.DeepLink, tt.qrCodeURL, tt.vaNumber, tt.deepLink)
+			}
+		})
+	}
+}
+
+func TestCreatePaymentErrors(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		respond(t, w, http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"message": "Invalid signature",
+		})
+	})
+
+	req := provider.PaymentRequest{OrderID: "RG-ORDER-1", Amount: 50000, ExpiryTime: time.Now().Add(time.Hour)}
+
+	req.PaymentMethod = payment.MethodGoPay
+	if _, err := p.CreatePayment(context.Background(), req); err == nil {
+		t.Error("CreatePayment() accepted an unsupported method")
+	}
+
+	req.PaymentMethod = payment.MethodQRIS
+	if _, err := p.CreatePayment(context.Background(), req); err == nil {
+		t.Error("CreatePayment() ignored an error response")
+	}
+}
+
+func TestGetPaymentStatus(t *testing.T) {
+	paidAt := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		rawStatus string
+		want      payment.Status
+	}{
+		{"UNPAID", payment.StatusPending},
+		{"PAID", payment.StatusPaid},
+		{"EXPIRED", payment.StatusExpired},
+		{"FAILED", payment.StatusFailed},
+		{"REFUND", payment.StatusRefunded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rawStatus, func(t *testing.T) {
+			p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet || r.URL.Path != "/transaction/detail" {
+					t.Errorf("request = %s %s, want GET /transaction/detail", r.Method, r.URL.Path)
+				}
+				if got := r.URL.Query().Get("reference"); got != "DEV-T1234" {
+					t.Errorf("reference = %q", got)
+				}
+
+				data := map[string]interface{}{
+					"reference":      "DEV-T1234",
+					"merchant_ref":   "RG-ORDER-1",
+					"payment_method": "BRIVA",
+					"amount":         75000,
+					"status":         tt.rawStatus,
+				}
+				if tt.want == payment.StatusPaid {
+					data["paid_at"] = paidAt.Unix()
+				}
+				respond(t, w, http.StatusOK, map[string]interface{}{"success": true, "data": data})
+			})
+
+			status, err := p.GetPaymentStatus(context.Background(), provider.StatusRequest{TransactionID: "DEV-T1234"})
+			if err != nil {
+				t.Fatalf("GetPaymentStatus() error = %v", err)
+			}
+
+			if status.Status != tt.want || status.RawStatus != tt.rawStatus {
+				t.Errorf("status = %q (%q), want %q", status.Status, status.RawStatus, tt.want)
+			}
+			if status.OrderID != "RG-ORDER-1" || status.Amount != 75000 || status.PaymentMethod != payment.MethodVABRI {
+				t.Errorf("status = %+v", status)
+			}
+			if tt.want == payment.StatusPaid && !status.TransactionTime.Equal(paidAt) {
+				t.Errorf("TransactionTime = %v, want %v", status.TransactionTime, paidAt)
+			}
+		})
+	}
+}
+
+func TestGetPaymentStatusRequiresReference(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected request")
+	})
+
+	if _, err := p.GetPaymentStatus(context.Background(), provider.StatusRequest{OrderID: "RG-ORDER-1"}); err == nil {
+		t.Error("GetPaymentStatus() accepted a request without a reference")
+	}
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	p := NewProvider(config.TripayConfig{PrivateKey: testPrivateKey})
+	payload := []byte(`{"reference":"DEV-T1234","merchant_ref":"RG-ORDER-1","status":"PAID","total_amount":50000}`)
+	signature := hmacHex(testPrivateKey, string(payload))
+
+	tests := []struct {
+		name      string
+		payload   []byte
+		signature string
+		wantErr   bool
+	}{
+		{"valid", payload, signature, false},
+		{"tampered payload", []byte(`{"reference":"DEV-T1234","merchant_ref":"RG-ORDER-1","status":"PAID","total_amount":90000}`), signature, true},
+		{"signed with another key", payload, hmacHex("another-key", string(payload)), true},
+		{"missing signature", payload, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.VerifyWebhook(tt.payload, tt.signature)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyWebhook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseWebhook(t *testing.T) {
+	p := NewProvider(config.TripayConfig{PrivateKey: testPrivateKey})
+	payload := []byte(`{
+		"reference": "DEV-T1234",
+		"merchant_ref": "RG-ORDER-1",
+		"payment_method": "QRIS by ShopeePay",
+		"payment_method_code": "QRIS2",
+		"total_amount": 50750,
+		"fee_merchant": 750,
+		"fee_customer": 0,
+		"total_fee": 750,
+		"amount_received": 50000,
+		"status": "PAID",
+		"paid_at": 1773480600
+	}`)
+
+	data, err := p.ParseWebhook(payload)
+	if err != nil {
+		t.Fatalf("ParseWebhook() error = %v", err)
+	}
+
+	if data.OrderID != "RG-ORDER-1" || data.TransactionID != "DEV-T1234" {
+		t.Errorf("ids = %q/%q", data.OrderID, data.TransactionID)
+	}
+	if data.Status != payment.StatusPaid || data.Amount != 50750 || data.PaymentMethod != payment.MethodQRIS {
+		t.Errorf("data = %+v", data)
+	}
+	if data.Fee == nil || *data.Fee != 750 {
+		t.Errorf("Fee = %v, want 750", data.Fee)
+	}
+	if !data.TransactionTime.Equal(time.Unix(1773480600, 0)) {
+		t.Errorf("TransactionTime = %v", data.TransactionTime)
+	}
+}
+
+func TestMethodMapping(t *testing.T) {
+	for _, method := range NewProvider(config.TripayConfig{}).GetSupportedMethods() {
+		channel := mapMethodToChannel(method)
+		if channel == "" {
+			t.Errorf("%s has no channel", method)
+			continue
+		}
+		if got := mapChannelToMethod(channel); got != method {
+			t.Errorf("channel %s maps back to %q, want %q", channel, got, method)
+		}
+	}
+}
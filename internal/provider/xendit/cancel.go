@@ -0,0 +1,41 @@
+package xendit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/reveegate/reveegate/internal/domain/provider"
+)
+
+// CancelPayment stops a pending payment from being paid
+//
+// Invoices are expired and fixed virtual accounts get their expiration moved
+// to now. QR codes and pending e-wallet charges have no cancel endpoint.
+func (p *Provider) CancelPayment(ctx context.Context, req provider.StatusRequest) error {
+	if req.TransactionID == "" {
+		return errors.New("xendit transaction ID is required")
+	}
+
+	switch {
+	case p.invoiceMode:
+		endpoint := fmt.Sprintf("/invoices/%s/expire!", url.PathEscape(req.TransactionID))
+		if _, err := p.doRequest(ctx, "POST", endpoint, nil, nil); err != nil {
+			return fmt.Errorf("failed to expire invoice: %w", err)
+		}
+	case isVA(req.PaymentMethod):
+		endpoint := "/callback_virtual_accounts/" + url.PathEscape(req.TransactionID)
+		body := map[string]interface{}{
+			"expiration_date": time.Now().UTC().Format(time.RFC3339),
+		}
+		if _, err := p.doRequest(ctx, "PATCH", endpoint, body, nil); err != nil {
+			return fmt.Errorf("failed to expire virtual account: %w", err)
+		}
+	default:
+		return provider.ErrCancelNotSupported
+	}
+
+	return nil
+}
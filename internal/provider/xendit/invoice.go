@@ -0,0 +1,162 @@
+package xendit
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/reveegate/reveegate/internal/domain/payment"
+	"github.com/reveegate/reveegate/internal/domain/provider"
+)
+
+// createInvoice creates a hosted checkout invoice restricted to the requested method
+func (p *Provider) createInvoice(ctx context.Context, req provider.PaymentRequest) (*provider.PaymentResponse, error) {
+	channel := mapMethodToInvoiceChannel(req.PaymentMethod)
+	if channel == "" {
+		return nil, fmt.Errorf("unsupported payment method: %s", req.PaymentMethod)
+	}
+
+	duration := int64(time.Until(req.ExpiryTime).Seconds())
+	if duration <= 0 {
+		duration = int64((24 * time.Hour).Seconds())
+	}
+
+	body := map[string]interface{}{
+		"external_id":          req.OrderID,
+		"amount":               req.Amount,
+		"currency":             "IDR",
+		"description":          req.Description,
+		"invoice_duration":     duration,
+		"payment_methods":      []string{channel},
+		"success_redirect_url": p.successRedirectURL,
+		"failure_redirect_url": p.failureRedirectURL,
+		"customer": map[string]interface{}{
+			"given_names": req.CustomerName,
+		},
+	}
+
+	if req.CustomerEmail != "" {
+		body["payer_email"] = req.CustomerEmail
+		body["customer"].(map[string]interface{})["email"] = req.CustomerEmail
+	}
+
+	respData, err := p.doRequest(ctx, "POST", "/v2/invoices", body, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, ok := respData.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid invoice response")
+	}
+
+	externalID, _ := resp["external_id"].(string)
+	id, _ := resp["id"].(string)
+	invoiceURL, _ := resp["invoice_url"].(string)
+	expiresAt := parseTime(resp["expiry_date"])
+	if expiresAt.IsZero() {
+		expiresAt = req.ExpiryTime
+	}
+
+	// The hosted checkout page is returned as the deep link so the donor
+	// page redirects to it like any other e-wallet checkout
+	return &provider.PaymentResponse{
+		ExternalID:    externalID,
+		TransactionID: id,
+		PaymentMethod: req.PaymentMethod,
+		DeepLink:      invoiceURL,
+		ExpiresAt:     expiresAt,
+		RawResponse:   resp,
+	}, nil
+}
+
+// getInvoiceStatus gets the status of an invoice by its external ID
+func (p *Provider) getInvoiceStatus(ctx context.Context, req provider.StatusRequest) (*provider.PaymentStatus, error) {
+	endpoint := "/v2/invoices?external_id=" + url.QueryEscape(req.OrderID)
+
+	resp, err := p.doRequest(ctx, "GET", endpoint, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice status: %w", err)
+	}
+
+	// Parse array response
+	invoices, ok := resp.([]interface{})
+	if !ok || len(invoices) == 0 {
+		return nil, fmt.Errorf("invoice not found")
+	}
+
+	invoice, ok := invoices[0].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid invoice response")
+	}
+
+	externalID, _ := invoice["external_id"].(string)
+	id, _ := invoice["id"].(string)
+	rawStatus, _ := invoice["status"].(string)
+	channel, _ := invoice["payment_channel"].(string)
+
+	method := mapInvoiceChannelToMethod(channel)
+	if method == "" {
+		method = req.PaymentMethod
+	}
+
+	return &provider.PaymentStatus{
+		OrderID:         externalID,
+		ExternalID:      externalID,
+		TransactionID:   id,
+		RawStatus:       rawStatus,
+		Status:          mapInvoiceStatus(rawStatus),
+		TransactionTime: parseTime(invoice["paid_at"]),
+		Amount:          parseAmount(invoice["amount"]),
+		PaymentMethod:   method,
+	}, nil
+}
+
+// mapInvoiceStatus maps an invoice status to internal status
+func mapInvoiceStatus(status string) payment.Status {
+	switch status {
+	case "PAID", "SETTLED":
+		return payment.StatusPaid
+	case "EXPIRED":
+		return payment.StatusExpired
+	default:
+		return payment.StatusPending
+	}
+}
+
+// mapMethodToInvoiceChannel maps a payment method to an invoice payment_methods entry
+func mapMethodToInvoiceChannel(method payment.Method) string {
+	switch method {
+	case payment.MethodQRIS:
+		return "QRIS"
+	case payment.MethodOVO:
+		return "OVO"
+	case payment.MethodDANA:
+		return "DANA"
+	case payment.MethodShopeePay:
+		return "SHOPEEPAY"
+	case payment.MethodLinkAja:
+		return "LINKAJA"
+	default:
+		return mapMethodToBank(method)
+	}
+}
+
+// mapInvoiceChannelToMethod maps an invoice payment_channel back to a payment method
+func mapInvoiceChannelToMethod(channel string) payment.Method {
+	switch channel {
+	case "QRIS":
+		return payment.MethodQRIS
+	case "OVO":
+		return payment.MethodOVO
+	case "DANA":
+		return payment.MethodDANA
+	case "SHOPEEPAY":
+		return payment.MethodShopeePay
+	case "LINKAJA":
+		return payment.MethodLinkAja
+	default:
+		return mapBankToMethod(channel)
+	}
+}
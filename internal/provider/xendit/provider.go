@@ -3,7 +3,9 @@ package xendit
 import (
 	"bytes"
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,26 +14,46 @@ import (
 	"github.com/reveegate/reveegate/internal/config"
 	"github.com/reveegate/reveegate/internal/domain/payment"
 	"github.com/reveegate/reveegate/internal/domain/provider"
+	"github.com/reveegate/reveegate/internal/telemetry"
 )
 
 const (
-	baseURL = "https://api.xendit.co"
+	defaultBaseURL = "https://api.xendit.co"
+
+	// qrAPIVersion is the QR Codes API version used for creation and status
+	qrAPIVersion = "2022-07-31"
 )
 
 // Provider implements Xendit payment provider
 type Provider struct {
-	secretKey  string
-	publicKey  string
-	httpClient *http.Client
+	secretKey          string
+	publicKey          string
+	webhookToken       string
+	baseURL            string
+	invoiceMode        bool
+	successRedirectURL string
+	failureRedirectURL string
+	httpClient         *http.Client
 }
 
 // NewProvider creates a new Xendit provider
 func NewProvider(cfg config.XenditConfig) *Provider {
+	baseURL := cfg.APIURL
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
 	return &Provider{
-		secretKey: cfg.SecretKey,
-		publicKey: cfg.PublicKey,
+		secretKey:          cfg.SecretKey,
+		publicKey:          cfg.PublicKey,
+		webhookToken:       cfg.WebhookToken,
+		baseURL:            baseURL,
+		invoiceMode:        cfg.InvoiceMode,
+		successRedirectURL: cfg.SuccessRedirectURL,
+		failureRedirectURL: cfg.FailureRedirectURL,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: telemetry.HTTPTransport("xendit"),
 		},
 	}
 }
@@ -58,6 +80,10 @@ func (p *Provider) GetSupportedMethods() []payment.Method {
 
 // CreatePayment creates a new payment
 func (p *Provider) CreatePayment(ctx context.Context, req provider.PaymentRequest) (*provider.PaymentResponse, error) {
+	if p.invoiceMode {
+		return p.createInvoice(ctx, req)
+	}
+
 	switch {
 	case isQRIS(req.PaymentMethod):
 		return p.createQRISPayment(ctx, req)
@@ -70,52 +96,12 @@ func (p *Provider) CreatePayment(ctx context.Context, req provider.PaymentReques
 	}
 }
 
-// GetPaymentStatus gets the status of a payment
-func (p *Provider) GetPaymentStatus(ctx context.Context, orderID string) (*provider.PaymentStatus, error) {
-	// Xendit uses different endpoints for different payment types
-	// For simplicity, we'll check invoice status
-	endpoint := fmt.Sprintf("/v2/invoices?external_id=%s", orderID)
-
-	resp, err := p.doRequest(ctx, "GET", endpoint, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get payment status: %w", err)
-	}
-
-	// Parse array response
-	invoices, ok := resp.([]interface{})
-	if !ok || len(invoices) == 0 {
-		return nil, fmt.Errorf("invoice not found")
-	}
-
-	invoice := invoices[0].(map[string]interface{})
-
-	status := &provider.PaymentStatus{
-		OrderID:       invoice["external_id"].(string),
-		TransactionID: invoice["id"].(string),
-		RawStatus:     invoice["status"].(string),
-	}
-
-	// Map status
-	switch invoice["status"].(string) {
-	case "PAID", "SETTLED":
-		status.Status = payment.StatusPaid
-	case "PENDING":
-		status.Status = payment.StatusPending
-	case "EXPIRED":
-		status.Status = payment.StatusExpired
-	default:
-		status.Status = payment.StatusPending
-	}
-
-	return status, nil
-}
-
 // createQRISPayment creates a QRIS payment
 func (p *Provider) createQRISPayment(ctx context.Context, req provider.PaymentRequest) (*provider.PaymentResponse, error) {
 	body := map[string]interface{}{
-		"external_id":  req.OrderID,
+		"reference_id": req.OrderID,
 		"type":         "DYNAMIC",
-		"channel_code": "QRIS",
+		"channel_code": "ID_DANA", // QRIS acquirer
 		"amount":       req.Amount,
 		"currency":     "IDR",
 		"expires_at":   req.ExpiryTime.Format(time.RFC3339),
@@ -125,20 +111,33 @@ func (p *Provider) createQRISPayment(ctx context.Context, req provider.PaymentRe
 		},
 	}
 
-	respData, err := p.doRequest(ctx, "POST", "/qr_codes", body)
+	respData, err := p.doRequest(ctx, "POST", "/qr_codes", body, map[string]string{
+		"api-version": qrAPIVersion,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	resp := respData.(map[string]interface{})
+	resp, ok := respData.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid QR code response")
+	}
 
-	expiresAt, _ := time.Parse(time.RFC3339, resp["expires_at"].(string))
+	referenceID, _ := resp["reference_id"].(string)
+	id, _ := resp["id"].(string)
+	qrString, _ := resp["qr_string"].(string)
+	expiresAt := parseTime(resp["expires_at"])
+	if expiresAt.IsZero() {
+		expiresAt = req.ExpiryTime
+	}
 
 	return &provider.PaymentResponse{
-		ExternalID:    resp["external_id"].(string),
-		TransactionID: resp["id"].(string),
-		QRCodeURL:     resp["qr_string"].(string),
+		ExternalID:    referenceID,
+		TransactionID: id,
+		PaymentMethod: req.PaymentMethod,
+		QRCodeURL:     qrString,
 		ExpiresAt:     expiresAt,
+		RawResponse:   resp,
 	}, nil
 }
 
@@ -156,20 +155,40 @@ func (p *Provider) createVAPayment(ctx context.Context, req provider.PaymentRequ
 		"expiration_date": req.ExpiryTime.Format(time.RFC3339),
 	}
 
-	respData, err := p.doRequest(ctx, "POST", "/callback_virtual_accounts", body)
+	respData, err := p.doRequest(ctx, "POST", "/callback_virtual_accounts", body, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	resp := respData.(map[string]interface{})
+	resp, ok := respData.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid virtual account response")
+	}
 
-	expiresAt, _ := time.Parse(time.RFC3339, resp["expiration_date"].(string))
+	externalID, err := requiredString(resp, "external_id")
+	if err != nil {
+		return nil, err
+	}
+	id, err := requiredString(resp, "id")
+	if err != nil {
+		return nil, err
+	}
+	accountNumber, err := requiredString(resp, "account_number")
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := parseTime(resp["expiration_date"])
+	if expiresAt.IsZero() {
+		expiresAt = req.ExpiryTime
+	}
 
 	return &provider.PaymentResponse{
-		ExternalID:    resp["external_id"].(string),
-		TransactionID: resp["id"].(string),
-		VANumber:      resp["account_number"].(string),
+		ExternalID:    externalID,
+		TransactionID: id,
+		PaymentMethod: req.PaymentMethod,
+		VANumber:      accountNumber,
 		ExpiresAt:     expiresAt,
+		RawResponse:   resp,
 	}, nil
 }
 
@@ -184,8 +203,8 @@ func (p *Provider) createEWalletPayment(ctx context.Context, req provider.Paymen
 		"checkout_method": "ONE_TIME_PAYMENT",
 		"channel_code":    channelCode,
 		"channel_properties": map[string]interface{}{
-			"success_redirect_url": "https://reveegate.com/donation/success",
-			"failure_redirect_url": "https://reveegate.com/donation/failed",
+			"success_redirect_url": p.successRedirectURL,
+			"failure_redirect_url": p.failureRedirectURL,
 		},
 		"metadata": map[string]interface{}{
 			"customer_name":  req.CustomerName,
@@ -193,12 +212,24 @@ func (p *Provider) createEWalletPayment(ctx context.Context, req provider.Paymen
 		},
 	}
 
-	respData, err := p.doRequest(ctx, "POST", "/ewallets/charges", body)
+	respData, err := p.doRequest(ctx, "POST", "/ewallets/charges", body, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	resp := respData.(map[string]interface{})
+	resp, ok := respData.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid e-wallet charge response")
+	}
+
+	referenceID, err := requiredString(resp, "reference_id")
+	if err != nil {
+		return nil, err
+	}
+	id, err := requiredString(resp, "id")
+	if err != nil {
+		return nil, err
+	}
 
 	var deepLink string
 	if actions, ok := resp["actions"].(map[string]interface{}); ok {
@@ -210,16 +241,18 @@ func (p *Provider) createEWalletPayment(ctx context.Context, req provider.Paymen
 	}
 
 	return &provider.PaymentResponse{
-		ExternalID:    resp["reference_id"].(string),
-		TransactionID: resp["id"].(string),
+		ExternalID:    referenceID,
+		TransactionID: id,
+		PaymentMethod: req.PaymentMethod,
 		DeepLink:      deepLink,
 		ExpiresAt:     req.ExpiryTime,
+		RawResponse:   resp,
 	}, nil
 }
 
 // doRequest makes an HTTP request to Xendit API
-func (p *Provider) doRequest(ctx context.Context, method, endpoint string, body interface{}) (interface{}, error) {
-	url := baseURL + endpoint
+func (p *Provider) doRequest(ctx context.Context, method, endpoint string, body interface{}, headers map[string]string) (interface{}, error) {
+	url := p.baseURL + endpoint
 
 	var reqBody io.Reader
 	if body != nil {
@@ -239,6 +272,9 @@ func (p *Provider) doRequest(ctx context.Context, method, endpoint string, body
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 	req.SetBasicAuth(p.secretKey, "")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
 
 	// Make request
 	resp, err := p.httpClient.Do(req)
@@ -314,6 +350,40 @@ func mapMethodToBank(method payment.Method) string {
 	}
 }
 
+func mapBankToMethod(bankCode string) payment.Method {
+	switch bankCode {
+	case "BCA":
+		return payment.MethodVABCA
+	case "BNI":
+		return payment.MethodVABNI
+	case "BRI":
+		return payment.MethodVABRI
+	case "MANDIRI":
+		return payment.MethodVAMandiri
+	case "PERMATA":
+		return payment.MethodVAPermata
+	default:
+		return ""
+	}
+}
+
+func mapChannelToMethod(channelCode string) payment.Method {
+	switch channelCode {
+	case "ID_OVO":
+		return payment.MethodOVO
+	case "ID_DANA":
+		return payment.MethodDANA
+	case "ID_SHOPEEPAY":
+		return payment.MethodShopeePay
+	case "ID_LINKAJA":
+		return payment.MethodLinkAja
+	case "ID_GOPAY":
+		return payment.MethodGoPay
+	default:
+		return ""
+	}
+}
+
 func mapMethodToChannel(method payment.Method) string {
 	switch method {
 	case payment.MethodOVO:
@@ -341,55 +411,67 @@ func (p *Provider) IsMethodSupported(method payment.Method) bool {
 	return false
 }
 
-// VerifyWebhook verifies the webhook signature
+// VerifyWebhook verifies the X-CALLBACK-TOKEN header sent with every Xendit callback
 func (p *Provider) VerifyWebhook(payload []byte, signature string) error {
-	// Xendit uses callback token for verification
-	// This should be checked against X-CALLBACK-TOKEN header
+	if p.webhookToken == "" {
+		return errors.New("xendit webhook token is not configured")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(signature), []byte(p.webhookToken)) != 1 {
+		return errors.New("invalid xendit callback token")
+	}
+
 	return nil
 }
 
-// ParseWebhook parses the webhook payload
-func (p *Provider) ParseWebhook(payload []byte) (*provider.WebhookData, error) {
-	var data map[string]interface{}
-	if err := json.Unmarshal(payload, &data); err != nil {
-		return nil, fmt.Errorf("failed to parse webhook payload: %w", err)
-	}
-
-	externalID, _ := data["external_id"].(string)
-	id, _ := data["id"].(string)
-	status, _ := data["status"].(string)
-	paidAt, _ := data["paid_at"].(string)
-
-	// Parse amount
-	var amount int64
-	if a, ok := data["amount"].(float64); ok {
-		amount = int64(a)
-	}
-
-	// Parse paid_at time
-	parsedTime, _ := time.Parse(time.RFC3339, paidAt)
-
-	// Map status
-	var paymentStatus payment.Status
-	switch status {
-	case "PAID", "SETTLED":
-		paymentStatus = payment.StatusPaid
-	case "PENDING", "ACTIVE":
-		paymentStatus = payment.StatusPending
-	case "EXPIRED":
-		paymentStatus = payment.StatusExpired
-	case "FAILED":
-		paymentStatus = payment.StatusFailed
-	default:
-		paymentStatus = payment.StatusPending
+// requiredString reads a string field that must be present in a response
+func requiredString(resp map[string]interface{}, field string) (string, error) {
+	value, ok := resp[field].(string)
+	if !ok || value == "" {
+		return "", fmt.Errorf("xendit response is missing %s", field)
 	}
+	return value, nil
+}
 
-	return &provider.WebhookData{
-		OrderID:         externalID,
-		TransactionID:   id,
-		TransactionTime: parsedTime,
-		Status:          paymentStatus,
-		Amount:          amount,
-		RawPayload:      data,
-	}, nil
+// parseTime parses an RFC3339 timestamp from a decoded JSON value
+func parseTime(value interface{}) time.Time {
+	str, _ := value.(string)
+	if str == "" {
+		return time.Time{}
+	}
+
+	t, err := time.Parse(time.RFC3339, str)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// parseAmount parses an amount from a decoded JSON number
+func parseAmount(value interface{}) int64 {
+	if a, ok := value.(float64); ok {
+		return int64(a)
+	}
+	return 0
+}
+
+// Ping checks the Xendit API can be reached
+func (p *Provider) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach xendit: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("xendit returned status %d", resp.StatusCode)
+	}
+
+	return nil
 }
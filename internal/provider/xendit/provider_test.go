@@ -0,0 +1,178 @@
+package xendit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/reveegate/reveegate/internal/config"
+	"github.com/reveegate/reveegate/internal/domain/payment"
+	"github.com/reveegate/reveegate/internal/domain/provider"
+)
+
+var testExpiry = time.Date(2026, 3, 15, 8, 0, 0, 0, time.UTC)
+
+func newTestProvider(t *testing.T, handler http.HandlerFunc) *Provider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return NewProvider(config.XenditConfig{
+		SecretKey: "xnd_development_test",
+		APIURL:    server.URL,
+	})
+}
+
+func respond(t *testing.T, w http.ResponseWriter, status int, body interface{}) {
+	t.Helper()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		t.Errorf("failed to write response: %v", err)
+	}
+}
+
+func vaResponse() map[string]interface{} {
+	return map[string]interface{}{
+		"id":              "5f2f6e1a9c2c3f0012345678",
+		"external_id":     "RG-ORDER-1",
+		"bank_code":       "BNI",
+		"account_number":  "8808999912345678",
+		"expiration_date": "2026-03-15T08:00:00Z",
+	}
+}
+
+func ewalletResponse() map[string]interface{} {
+	return map[string]interface{}{
+		"id":           "ewc_bb8c3po-c3po-r2d2-c3po-r2d2c3por2d2",
+		"reference_id": "RG-ORDER-1",
+		"channel_code": "ID_OVO",
+		"actions": map[string]interface{}{
+			"mobile_deeplink_checkout_url": "https://ewallet.xendit.co/checkout/ewc_bb8c3po",
+		},
+	}
+}
+
+func createPayment(p *Provider, method payment.Method) (*provider.PaymentResponse, error) {
+	return p.CreatePayment(context.Background(), provider.PaymentRequest{
+		OrderID:       "RG-ORDER-1",
+		Amount:        50000,
+		PaymentMethod: method,
+		CustomerName:  "Budi",
+		ExpiryTime:    testExpiry,
+	})
+}
+
+func TestCreatePayment(t *testing.T) {
+	tests := []struct {
+		method        payment.Method
+		path          string
+		response      map[string]interface{}
+		transactionID string
+		vaNumber      string
+		deepLink      string
+	}{
+		{
+			method:        payment.MethodVABNI,
+			path:          "/callback_virtual_accounts",
+			response:      vaResponse(),
+			transactionID: "5f2f6e1a9c2c3f0012345678",
+			vaNumber:      "8808999912345678",
+		},
+		{
+			method:        payment.MethodOVO,
+			path:          "/ewallets/charges",
+			response:      ewalletResponse(),
+			transactionID: "ewc_bb8c3po-c3po-r2d2-c3po-r2d2c3por2d2",
+			deepLink:      "https://ewallet.xendit.co/checkout/ewc_bb8c3po",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != tt.path {
+					t.Errorf("request = %s %s, want POST %s", r.Method, r.URL.Path, tt.path)
+				}
+				respond(t, w, http.StatusOK, tt.response)
+			})
+
+			resp, err := createPayment(p, tt.method)
+			if err != nil {
+				t.Fatalf("CreatePayment() error = %v", err)
+			}
+
+			if resp.ExternalID != "RG-ORDER-1" || resp.TransactionID != tt.transactionID {
+				t.Errorf("ids = %q/%q", resp.ExternalID, resp.TransactionID)
+			}
+			if !resp.ExpiresAt.Equal(testExpiry) {
+				t.Errorf("ExpiresAt = %v, want %v", resp.ExpiresAt, testExpiry)
+			}
+			if resp.VANumber != tt.vaNumber || resp.DeepLink != tt.deepLink {
+				t.Errorf("details = %q/%q, want %q/%q", resp.VANumber, resp.DeepLink, tt.vaNumber, tt.deepLink)
+			}
+		})
+	}
+}
+
+func TestCreatePaymentMissingFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   payment.Method
+		response map[string]interface{}
+		missing  string
+	}{
+		{"va without id", payment.MethodVABNI, vaResponse(), "id"},
+		{"va without external id", payment.MethodVABNI, vaResponse(), "external_id"},
+		{"va without account number", payment.MethodVABNI, vaResponse(), "account_number"},
+		{"ewallet without id", payment.MethodOVO, ewalletResponse(), "id"},
+		{"ewallet without reference id", payment.MethodOVO, ewalletResponse(), "reference_id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delete(tt.response, tt.missing)
+			p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+				respond(t, w, http.StatusOK, tt.response)
+			})
+
+			if _, err := createPayment(p, tt.method); err == nil {
+				t.Errorf("CreatePayment() accepted a response without %s", tt.missing)
+			}
+		})
+	}
+}
+
+func TestCreatePaymentOptionalFields(t *testing.T) {
+	// A missing or malformed expiry falls back to the requested one
+	response := vaResponse()
+	response["expiration_date"] = 1773561600
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		respond(t, w, http.StatusOK, response)
+	})
+
+	resp, err := createPayment(p, payment.MethodVABNI)
+	if err != nil {
+		t.Fatalf("CreatePayment() error = %v", err)
+	}
+	if !resp.ExpiresAt.Equal(testExpiry) {
+		t.Errorf("ExpiresAt = %v, want %v", resp.ExpiresAt, testExpiry)
+	}
+}
+
+func TestCreatePaymentUnexpectedResponse(t *testing.T) {
+	for _, method := range []payment.Method{payment.MethodQRIS, payment.MethodVABNI, payment.MethodOVO} {
+		t.Run(string(method), func(t *testing.T) {
+			p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+				respond(t, w, http.StatusOK, []interface{}{})
+			})
+
+			if _, err := createPayment(p, method); err == nil {
+				t.Error("CreatePayment() accepted a response that isn't an object")
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package xendit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/reveegate/reveegate/internal/domain/payment"
+	"github.com/reveegate/reveegate/internal/domain/provider"
+)
+
+// GetPaymentStatus gets the status of a payment
+//
+// Xendit exposes a different status endpoint per product, so the request
+// must carry the payment method and the Xendit object ID returned at creation
+func (p *Provider) GetPaymentStatus(ctx context.Context, req provider.StatusRequest) (*provider.PaymentStatus, error) {
+	if p.invoiceMode {
+		return p.getInvoiceStatus(ctx, req)
+	}
+
+	if req.TransactionID == "" {
+		return nil, errors.New("xendit transaction ID is required")
+	}
+
+	switch {
+	case isQRIS(req.PaymentMethod):
+		return p.getQRISStatus(ctx, req)
+	case isVA(req.PaymentMethod):
+		return p.getVAStatus(ctx, req)
+	case isEWallet(req.PaymentMethod):
+		return p.getEWalletStatus(ctx, req)
+	default:
+		return nil, fmt.Errorf("unsupported payment method: %s", req.PaymentMethod)
+	}
+}
+
+// getQRISStatus gets the status of a QRIS payment from the QR code payments list
+func (p *Provider) getQRISStatus(ctx context.Context, req provider.StatusRequest) (*provider.PaymentStatus, error) {
+	endpoint := fmt.Sprintf("/qr_codes/%s/payments", url.PathEscape(req.TransactionID))
+
+	respData, err := p.doRequest(ctx, "GET", endpoint, nil, map[string]string{
+		"api-version": qrAPIVersion,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment status: %w", err)
+	}
+
+	status := &provider.PaymentStatus{
+		OrderID:       req.OrderID,
+		ExternalID:    req.OrderID,
+		TransactionID: req.TransactionID,
+		RawStatus:     "ACTIVE",
+		Status:        payment.StatusPending,
+		PaymentMethod: payment.MethodQRIS,
+	}
+
+	resp, _ := respData.(map[string]interface{})
+	payments, _ := resp["data"].([]interface{})
+	for _, item := range payments {
+		qrPayment, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		rawStatus, _ := qrPayment["status"].(string)
+		if rawStatus != "SUCCEEDED" {
+			continue
+		}
+
+		status.RawStatus = rawStatus
+		status.Status = payment.StatusPaid
+		status.TransactionTime = parseTime(qrPayment["created"])
+		status.Amount = parseAmount(qrPayment["amount"])
+		break
+	}
+
+	return status, nil
+}
+
+// getVAStatus gets the status of a fixed virtual account
+func (p *Provider) getVAStatus(ctx context.Context, req provider.StatusRequest) (*provider.PaymentStatus, error) {
+	endpoint := "/callback_virtual_accounts/" + url.PathEscape(req.TransactionID)
+
+	respData, err := p.doRequest(ctx, "GET", endpoint, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment status: %w", err)
+	}
+
+	resp, ok := respData.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid virtual account response")
+	}
+
+	externalID, _ := resp["external_id"].(string)
+	rawStatus, _ := resp["status"].(string)
+	bankCode, _ := resp["bank_code"].(string)
+
+	status := &provider.PaymentStatus{
+		OrderID:       externalID,
+		ExternalID:    externalID,
+		TransactionID: req.TransactionID,
+		RawStatus:     rawStatus,
+		Amount:        parseAmount(resp["expected_amount"]),
+		PaymentMethod: mapBankToMethod(bankCode),
+	}
+
+	// A closed single-use VA turns INACTIVE once it is paid or once it
+	// expires, so the expiration date tells the two apart
+	switch rawStatus {
+	case "INACTIVE":
+		expiresAt := parseTime(resp["expiration_date"])
+		if !expiresAt.IsZero() && time.Now().After(expiresAt) {
+			status.Status = payment.StatusExpired
+		} else {
+			status.Status = payment.StatusPaid
+		}
+	default:
+		status.Status = payment.StatusPending
+	}
+
+	return status, nil
+}
+
+// getEWalletStatus gets the status of an e-wallet charge
+func (p *Provider) getEWalletStatus(ctx context.Context, req provider.StatusRequest) (*provider.PaymentStatus, error) {
+	endpoint := "/ewallets/charges/" + url.PathEscape(req.TransactionID)
+
+	respData, err := p.doRequest(ctx, "GET", endpoint, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment status: %w", err)
+	}
+
+	resp, ok := respData.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid e-wallet charge response")
+	}
+
+	referenceID, _ := resp["reference_id"].(string)
+	rawStatus, _ := resp["status"].(string)
+	channelCode, _ := resp["channel_code"].(string)
+
+	amount := parseAmount(resp["capture_amount"])
+	if amount == 0 {
+		amount = parseAmount(resp["charge_amount"])
+	}
+
+	return &provider.PaymentStatus{
+		OrderID:         referenceID,
+		ExternalID:      referenceID,
+		TransactionID:   req.TransactionID,
+		RawStatus:       rawStatus,
+		Status:          mapEWalletStatus(rawStatus),
+		TransactionTime: parseTime(resp["updated"]),
+		Amount:          amount,
+		PaymentMethod:   mapChannelToMethod(channelCode),
+	}, nil
+}
+
+// mapEWalletStatus maps an e-wallet charge status to internal status
+func mapEWalletStatus(status string) payment.Status {
+	switch status {
+	case "SUCCEEDED":
+		return payment.StatusPaid
+	case "FAILED", "VOIDED":
+		return payment.StatusFailed
+	case "REFUNDED":
+		return payment.StatusRefunded
+	default:
+		return payment.StatusPending
+	}
+}
@@ -0,0 +1,266 @@
+package xendit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/reveegate/reveegate/internal/domain/payment"
+	"github.com/reveegate/reveegate/internal/domain/provider"
+)
+
+// Callback event types reported in WebhookData.EventType
+const (
+	EventQRPayment      = "qr.payment"
+	EventEWalletCapture = "ewallet.capture"
+	EventVAPayment      = "fixed_va.payment"
+	EventVAUpdated      = "fixed_va.updated"
+	EventInvoice        = "invoice"
+)
+
+// callbackEnvelope holds the fields used to tell Xendit callback shapes apart
+type callbackEnvelope struct {
+	Event                    string `json:"event"`
+	CallbackVirtualAccountID string `json:"callback_virtual_account_id"`
+	PaymentID                string `json:"payment_id"`
+	AccountNumber            string `json:"account_number"`
+	ExternalID               string `json:"external_id"`
+}
+
+// qrPaymentCallback represents a qr.payment callback
+type qrPaymentCallback struct {
+	Event string `json:"event"`
+	Data  struct {
+		ID            string  `json:"id"`
+		QRID          string  `json:"qr_id"`
+		ReferenceID   string  `json:"reference_id"`
+		Amount        float64 `json:"amount"`
+		Currency      string  `json:"currency"`
+		Status        string  `json:"status"`
+		ChannelCode   string  `json:"channel_code"`
+		Created       string  `json:"created"`
+		PaymentDetail struct {
+			ReceiptID string `json:"receipt_id"`
+			Source    string `json:"source"`
+		} `json:"payment_detail"`
+	} `json:"data"`
+}
+
+// vaPaymentCallback represents a fixed virtual account payment callback
+type vaPaymentCallback struct {
+	ID                       string  `json:"id"`
+	PaymentID                string  `json:"payment_id"`
+	CallbackVirtualAccountID string  `json:"callback_virtual_account_id"`
+	ExternalID               string  `json:"external_id"`
+	AccountNumber            string  `json:"account_number"`
+	BankCode                 string  `json:"bank_code"`
+	Amount                   float64 `json:"amount"`
+	TransactionTimestamp     string  `json:"transaction_timestamp"`
+}
+
+// vaUpdatedCallback represents a fixed virtual account created/updated callback
+type vaUpdatedCallback struct {
+	ID             string  `json:"id"`
+	ExternalID     string  `json:"external_id"`
+	BankCode       string  `json:"bank_code"`
+	AccountNumber  string  `json:"account_number"`
+	ExpectedAmount float64 `json:"expected_amount"`
+	Status         string  `json:"status"`
+	Updated        string  `json:"updated"`
+}
+
+// eWalletCaptureCallback represents an ewallet.capture callback
+type eWalletCaptureCallback struct {
+	Event string `json:"event"`
+	Data  struct {
+		ID            string  `json:"id"`
+		ReferenceID   string  `json:"reference_id"`
+		Status        string  `json:"status"`
+		ChargeAmount  float64 `json:"charge_amount"`
+		CaptureAmount float64 `json:"capture_amount"`
+		ChannelCode   string  `json:"channel_code"`
+		FailureCode   string  `json:"failure_code"`
+		Updated       string  `json:"updated"`
+	} `json:"data"`
+}
+
+// invoiceCallback represents an invoice paid/expired callback
+type invoiceCallback struct {
+	ID             string  `json:"id"`
+	ExternalID     string  `json:"external_id"`
+	Status         string  `json:"status"`
+	Amount         float64 `json:"amount"`
+	PaidAmount     float64 `json:"paid_amount"`
+	AdjustedAmount float64 `json:"adjusted_received_amount"`
+	PaidAt         string  `json:"paid_at"`
+	Updated        string  `json:"updated"`
+	PaymentMethod  string  `json:"payment_method"`
+	PaymentChannel string  `json:"payment_channel"`
+}
+
+// ParseWebhook parses the webhook payload
+//
+// Xendit sends a different callback body per product, so the shape is
+// detected first and then decoded by the matching parser
+func (p *Provider) ParseWebhook(payload []byte) (*provider.WebhookData, error) {
+	var envelope callbackEnvelope
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to parse webhook payload: %w", err)
+	}
+
+	var (
+		data *provider.WebhookData
+		err  error
+	)
+
+	switch {
+	case envelope.Event == EventQRPayment:
+		data, err = parseQRPayment(payload)
+	case envelope.Event == EventEWalletCapture:
+		data, err = parseEWalletCapture(payload)
+	case envelope.CallbackVirtualAccountID != "" && envelope.PaymentID != "":
+		data, err = parseVAPayment(payload)
+	case envelope.AccountNumber != "":
+		data, err = parseVAUpdated(payload)
+	case envelope.ExternalID != "":
+		data, err = parseInvoice(payload)
+	default:
+		return nil, errors.New("unrecognized xendit callback")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse webhook payload: %w", err)
+	}
+	data.RawPayload = raw
+
+	return data, nil
+}
+
+// parseQRPayment parses a qr.payment callback
+func parseQRPayment(payload []byte) (*provider.WebhookData, error) {
+	var cb qrPaymentCallback
+	if err := json.Unmarshal(payload, &cb); err != nil {
+		return nil, fmt.Errorf("failed to parse qr.payment callback: %w", err)
+	}
+
+	status := payment.StatusPending
+	switch cb.Data.Status {
+	case "SUCCEEDED", "COMPLETED":
+		status = payment.StatusPaid
+	case "FAILED":
+		status = payment.StatusFailed
+	}
+
+	return &provider.WebhookData{
+		EventType:       EventQRPayment,
+		OrderID:         cb.Data.ReferenceID,
+		TransactionID:   cb.Data.ID,
+		TransactionTime: parseTime(cb.Data.Created),
+		Status:          status,
+		Amount:          int64(cb.Data.Amount),
+		PaymentMethod:   payment.MethodQRIS,
+	}, nil
+}
+
+// parseVAPayment parses a fixed virtual account payment callback
+//
+// The payment callback carries no status field; receiving it means the VA was paid
+func parseVAPayment(payload []byte) (*provider.WebhookData, error) {
+	var cb vaPaymentCallback
+	if err := json.Unmarshal(payload, &cb); err != nil {
+		return nil, fmt.Errorf("failed to parse fixed_va payment callback: %w", err)
+	}
+
+	return &provider.WebhookData{
+		EventType:       EventVAPayment,
+		OrderID:         cb.ExternalID,
+		TransactionID:   cb.PaymentID,
+		TransactionTime: parseTime(cb.TransactionTimestamp),
+		Status:          payment.StatusPaid,
+		Amount:          int64(cb.Amount),
+		PaymentMethod:   mapBankToMethod(cb.BankCode),
+	}, nil
+}
+
+// parseVAUpdated parses a fixed virtual account created/updated callback
+//
+// These callbacks only report the VA lifecycle, so they never complete a payment
+func parseVAUpdated(payload []byte) (*provider.WebhookData, error) {
+	var cb vaUpdatedCallback
+	if err := json.Unmarshal(payload, &cb); err != nil {
+		return nil, fmt.Errorf("failed to parse fixed_va callback: %w", err)
+	}
+
+	return &provider.WebhookData{
+		EventType:       EventVAUpdated,
+		OrderID:         cb.ExternalID,
+		TransactionID:   cb.ID,
+		TransactionTime: parseTime(cb.Updated),
+		Status:          payment.StatusPending,
+		Amount:          int64(cb.ExpectedAmount),
+		PaymentMethod:   mapBankToMethod(cb.BankCode),
+	}, nil
+}
+
+// parseEWalletCapture parses an ewallet.capture callback
+func parseEWalletCapture(payload []byte) (*provider.WebhookData, error) {
+	var cb eWalletCaptureCallback
+	if err := json.Unmarshal(payload, &cb); err != nil {
+		return nil, fmt.Errorf("failed to parse ewallet.capture callback: %w", err)
+	}
+
+	amount := int64(cb.Data.CaptureAmount)
+	if amount == 0 {
+		amount = int64(cb.Data.ChargeAmount)
+	}
+
+	return &provider.WebhookData{
+		EventType:       EventEWalletCapture,
+		OrderID:         cb.Data.ReferenceID,
+		TransactionID:   cb.Data.ID,
+		TransactionTime: parseTime(cb.Data.Updated),
+		Status:          mapEWalletStatus(cb.Data.Status),
+		Amount:          amount,
+		PaymentMethod:   mapChannelToMethod(cb.Data.ChannelCode),
+	}, nil
+}
+
+// parseInvoice parses an invoice callback
+func parseInvoice(payload []byte) (*provider.WebhookData, error) {
+	var cb invoiceCallback
+	if err := json.Unmarshal(payload, &cb); err != nil {
+		return nil, fmt.Errorf("failed to parse invoice callback: %w", err)
+	}
+
+	transactionTime := parseTime(cb.PaidAt)
+	if transactionTime.IsZero() {
+		transactionTime = parseTime(cb.Updated)
+	}
+
+	amount := int64(cb.PaidAmount)
+	if amount == 0 {
+		amount = int64(cb.Amount)
+	}
+
+	data := &provider.WebhookData{
+		EventType:       EventInvoice,
+		OrderID:         cb.ExternalID,
+		TransactionID:   cb.ID,
+		TransactionTime: transactionTime,
+		Status:          mapInvoiceStatus(cb.Status),
+		Amount:          amount,
+		PaymentMethod:   mapInvoiceChannelToMethod(cb.PaymentChannel),
+	}
+
+	// Paid invoices report what is credited to the balance after Xendit fees
+	if cb.AdjustedAmount > 0 && amount >= int64(cb.AdjustedAmount) {
+		fee := amount - int64(cb.AdjustedAmount)
+		data.Fee = &fee
+	}
+
+	return data, nil
+}
@@ -0,0 +1,163 @@
+package xendit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/reveegate/reveegate/internal/config"
+	"github.com/reveegate/reveegate/internal/domain/payment"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	payload, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	return payload
+}
+
+func TestParseWebhook(t *testing.T) {
+	p := NewProvider(config.XenditConfig{WebhookToken: "callback-token"})
+
+	tests := []struct {
+		fixture       string
+		eventType     string
+		orderID       string
+		transactionID string
+		status        payment.Status
+		amount        int64
+		method        payment.Method
+		fee           *int64
+	}{
+		{
+			fixture:       "qr_payment.json",
+			eventType:     EventQRPayment,
+			orderID:       "RG-20260314-9F2A61C4",
+			transactionID: "qrpy_8182837te-87st-49ing-8696-1239bd4d759c",
+			status:        payment.StatusPaid,
+			amount:        50000,
+			method:        payment.MethodQRIS,
+		},
+		{
+			fixture:       "fixed_va_payment.json",
+			eventType:     EventVAPayment,
+			orderID:       "RG-20260314-4C7D0E92",
+			transactionID: "1502450097845",
+			status:        payment.StatusPaid,
+			amount:        104000,
+			method:        payment.MethodVABNI,
+		},
+		{
+			fixture:       "ewallet_capture.json",
+			eventType:     EventEWalletCapture,
+			orderID:       "RG-20260314-7B13AA05",
+			transactionID: "ewc_bb8c3po-c3po-r2d2-c3po-r2d2c3por2d2",
+			status:        payment.StatusPaid,
+			amount:        25750,
+			method:        payment.MethodOVO,
+		},
+		{
+			fixture:       "invoice_paid.json",
+			eventType:     EventInvoice,
+			orderID:       "RG-20260314-E05F3B18",
+			transactionID: "65f2c1d4b3a2e10019d8e6f3",
+			status:        payment.StatusPaid,
+			amount:        100000,
+			method:        payment.MethodShopeePay,
+			fee:           int64Ptr(2000),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			payload := readFixture(t, tt.fixture)
+
+			if err := p.VerifyWebhook(payload, "callback-token"); err != nil {
+				t.Fatalf("VerifyWebhook() error = %v", err)
+			}
+
+			data, err := p.ParseWebhook(payload)
+			if err != nil {
+				t.Fatalf("ParseWebhook() error = %v", err)
+			}
+
+			if data.EventType != tt.eventType {
+				t.Errorf("EventType = %q, want %q", data.EventType, tt.eventType)
+			}
+			if data.OrderID != tt.orderID {
+				t.Errorf("OrderID = %q, want %q", data.OrderID, tt.orderID)
+			}
+			if data.TransactionID != tt.transactionID {
+				t.Errorf("TransactionID = %q, want %q", data.TransactionID, tt.transactionID)
+			}
+			if data.Status != tt.status {
+				t.Errorf("Status = %q, want %q", data.Status, tt.status)
+			}
+			if data.Amount != tt.amount {
+				t.Errorf("Amount = %d, want %d", data.Amount, tt.amount)
+			}
+			if data.PaymentMethod != tt.method {
+				t.Errorf("PaymentMethod = %q, want %q", data.PaymentMethod, tt.method)
+			}
+			if data.TransactionTime.IsZero() {
+				t.Error("TransactionTime is zero")
+			}
+			if data.RawPayload == nil {
+				t.Error("RawPayload is nil")
+			}
+
+			switch {
+			case tt.fee == nil && data.Fee != nil:
+				t.Errorf("Fee = %d, want none", *data.Fee)
+			case tt.fee != nil && data.Fee == nil:
+				t.Errorf("Fee = none, want %d", *tt.fee)
+			case tt.fee != nil && *data.Fee != *tt.fee:
+				t.Errorf("Fee = %d, want %d", *data.Fee, *tt.fee)
+			}
+		})
+	}
+}
+
+func TestParseWebhookRejectsUnknownEvent(t *testing.T) {
+	p := NewProvider(config.XenditConfig{WebhookToken: "callback-token"})
+
+	if _, err := p.ParseWebhook(readFixture(t, "unknown_event.json")); err == nil {
+		t.Error("ParseWebhook() accepted an unknown event")
+	}
+	if _, err := p.ParseWebhook([]byte("not json")); err == nil {
+		t.Error("ParseWebhook() accepted a malformed payload")
+	}
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	payload := readFixture(t, "qr_payment.json")
+
+	tests := []struct {
+		name         string
+		webhookToken string
+		header       string
+		wantErr      bool
+	}{
+		{"valid token", "callback-token", "callback-token", false},
+		{"wrong token", "callback-token", "callback-tokem", true},
+		{"missing token", "callback-token", "", true},
+		{"token not configured", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProvider(config.XenditConfig{WebhookToken: tt.webhookToken})
+
+			err := p.VerifyWebhook(payload, tt.header)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyWebhook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
@@ -0,0 +1,202 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/reveegate/reveegate/internal/domain/donation"
+	"github.com/reveegate/reveegate/internal/http/middleware"
+	"github.com/reveegate/reveegate/internal/service"
+)
+
+// Number of recent donations in an admin snapshot
+const snapshotDonations = 20
+
+// adminEventPermissions is the permission an admin needs to receive each
+// category of admin event. Events of other categories are not delivered.
+var adminEventPermissions = map[string]middleware.Permission{
+	"donation":   middleware.PermDonationsRead,
+	"webhook":    middleware.PermWebhookLogsRead,
+	"moderation": middleware.PermFraudManage,
+	"security":   middleware.PermAdminsManage,
+	"overlay":    middleware.PermOverlayControl,
+}
+
+// DashboardReader provides the dashboard figures and recent donations an
+// admin snapshot is built from
+type DashboardReader interface {
+	GetDashboard(ctx context.Context) (*service.Dashboard, error)
+	ListDonations(ctx context.Context, params donation.ListDonationsParams) (*donation.ListDonationsResult, error)
+}
+
+// adminSubscription is what an admin client receives: the event categories
+// its role may see, narrowed to the patterns it subscribed to. A pattern is
+// an event type, a category such as "donation.*", or "*".
+type adminSubscription struct {
+	categories map[string]bool
+
+	mu       sync.RWMutex
+	patterns []string
+}
+
+// newAdminSubscription subscribes to every event the claims in ctx allow
+func newAdminSubscription(ctx context.Context) *adminSubscription {
+	categories := make(map[string]bool)
+	for category, perm := range adminEventPermissions {
+		if middleware.Can(ctx, perm) {
+			categories[category] = true
+		}
+	}
+
+	return &adminSubscription{
+		categories: categories,
+		patterns:   []string{"*"},
+	}
+}
+
+// allows reports whether the client's role may see a category of events
+func (s *adminSubscription) allows(category string) bool {
+	return s.categories[category]
+}
+
+// wants reports whether an event goes to the client
+func (s *adminSubscription) wants(eventType string) bool {
+	if !s.allows(eventCategory(eventType)) {
+		return false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, pattern := range s.patterns {
+		if matchEvent(pattern, eventType) {
+			return true
+		}
+	}
+	return false
+}
+
+// subscribe replaces the subscribed patterns
+func (s *adminSubscription) subscribe(patterns []string) error {
+	if err := validatePatterns(patterns); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.patterns = patterns
+	s.mu.Unlock()
+	return nil
+}
+
+// unsubscribe removes patterns from the subscription
+func (s *adminSubscription) unsubscribe(patterns []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := make([]string, 0, len(s.patterns))
+	for _, current := range s.patterns {
+		remove := false
+		for _, pattern := range patterns {
+			if pattern == current {
+				remove = true
+				break
+			}
+		}
+		if !remove {
+			kept = append(kept, current)
+		}
+	}
+	s.patterns = kept
+}
+
+// current returns the subscribed patterns
+func (s *adminSubscription) current() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]string(nil), s.patterns...)
+}
+
+// available lists the event types the client's role may receive
+func (s *adminSubscription) available() []string {
+	types := make([]string, 0, len(service.AdminEventTypes))
+	for _, eventType := range service.AdminEventTypes {
+		if s.allows(eventCategory(eventType)) {
+			types = append(types, eventType)
+		}
+	}
+	return types
+}
+
+// eventCategory is the part of an event type before the dot
+func eventCategory(eventType string) string {
+	category, _, _ := strings.Cut(eventType, ".")
+	return category
+}
+
+// matchEvent reports whether a subscription pattern covers an event type
+func matchEvent(pattern, eventType string) bool {
+	if pattern == "*" || pattern == eventType {
+		return true
+	}
+	category, ok := strings.CutSuffix(pattern, ".*")
+	return ok && category == eventCategory(eventType)
+}
+
+// validatePatterns rejects patterns that can never match an event
+func validatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if pattern == "*" {
+			continue
+		}
+		if category, ok := strings.CutSuffix(pattern, ".*"); ok {
+			if _, known := adminEventPermissions[category]; known {
+				continue
+			}
+		}
+
+		known := false
+		for _, eventType := range service.AdminEventTypes {
+			if pattern == eventType {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown event type %q", pattern)
+		}
+	}
+	return nil
+}
+
+// splitPatterns parses a comma-separated list of patterns
+func splitPatterns(list string) []string {
+	patterns := make([]string, 0)
+	for _, pattern := range strings.Split(list, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// payloadPatterns reads the "events" list of a subscribe or unsubscribe
+// message
+func payloadPatterns(payload map[string]interface{}) ([]string, error) {
+	list, ok := payload["events"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("payload.events must be a list of event types")
+	}
+
+	patterns := make([]string, 0, len(list))
+	for _, item := range list {
+		pattern, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("payload.events must be a list of event types")
+		}
+		patterns = append(patterns, strings.TrimSpace(pattern))
+	}
+	return patterns, nil
+}
@@ -1,12 +1,19 @@
 package websocket
 
 import (
+	"context"
 	"encoding/json"
 	"log/slog"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/reveegate/reveegate/internal/telemetry"
 )
 
 const (
@@ -24,9 +31,20 @@ const (
 
 	// Size of client send channel buffer
 	sendBufferSize = 256
+
+	// Minimum time between snapshots an admin client asks for
+	snapshotInterval = 5 * time.Second
+)
+
+// Client types
+const (
+	clientTypeOverlay = "overlay"
+	clientTypeAdmin   = "admin"
+	clientTypeDonor   = "donor"
 )
 
-// Client represents a WebSocket client
+// Client represents a realtime client. WebSocket clients run ReadPump and
+// WritePump; SSE clients have no conn and are written by the SSE handler.
 type Client struct {
 	// Unique client ID
 	id string
@@ -40,8 +58,14 @@ type Client struct {
 	// Hub reference
 	hub *Hub
 
-	// Buffered channel of outbound messages
-	send chan []byte
+	// Buffered channel of outbound messages. It is never closed, so
+	// broadcasts racing a disconnect can't panic; done ends the client.
+	send chan outboundMessage
+
+	// Closed when the client is disconnected
+	done      chan struct{}
+	closeOnce sync.Once
+	closeCode int
 
 	// Logger
 	logger *slog.Logger
@@ -51,6 +75,22 @@ type Client struct {
 
 	// User identifier (token or user ID)
 	userID string
+
+	connectedAt time.Time
+
+	// Admin clients only: the events they receive and how to build their
+	// catch-up snapshot
+	subscription *adminSubscription
+	snapshot     func(context.Context) OutgoingMessage
+	lastSnapshot time.Time // Only used by ReadPump
+}
+
+// outboundMessage is a message queued for a client, with its history ID and
+// the span that broadcast it when it belongs to a trace
+type outboundMessage struct {
+	id   string
+	data []byte
+	span trace.SpanContext
 }
 
 // IncomingMessage represents a message from client
@@ -69,21 +109,23 @@ type OutgoingMessage struct {
 // NewClient creates a new WebSocket client
 func NewClient(conn *websocket.Conn, hub *Hub, channel, clientType, userID string, logger *slog.Logger) *Client {
 	return &Client{
-		id:         uuid.New().String(),
-		channel:    channel,
-		conn:       conn,
-		hub:        hub,
-		send:       make(chan []byte, sendBufferSize),
-		logger:     logger,
-		clientType: clientType,
-		userID:     userID,
+		id:          uuid.New().String(),
+		channel:     channel,
+		conn:        conn,
+		hub:         hub,
+		send:        make(chan outboundMessage, sendBufferSize),
+		done:        make(chan struct{}),
+		logger:      logger,
+		clientType:  clientType,
+		userID:      userID,
+		connectedAt: time.Now(),
 	}
 }
 
 // ReadPump pumps messages from the WebSocket connection to the hub
 func (c *Client) ReadPump() {
 	defer func() {
-		c.hub.unregister <- c
+		c.hub.Unregister(c, websocket.CloseNormalClosure)
 		c.conn.Close()
 	}()
 
@@ -116,32 +158,41 @@ func (c *Client) WritePump() {
 	defer func() {
 		ticker.Stop()
 		c.conn.Close()
+		c.hub.pumps.Done()
 	}()
 
 	for {
 		select {
-		case message, ok := <-c.send:
+		case <-c.done:
+			// Hub closed the client
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(c.closeCode, ""))
+			return
+
+		case message := <-c.send:
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
-			if !ok {
-				// Hub closed the channel
-				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
-				return
-			}
 
 			w, err := c.conn.NextWriter(websocket.TextMessage)
 			if err != nil {
 				return
 			}
-			w.Write(message)
+			spans := c.traceWrite(nil, message)
+			w.Write(message.data)
 
 			// Add queued messages to the current WebSocket message
 			n := len(c.send)
 			for i := 0; i < n; i++ {
+				queued := <-c.send
+				spans = c.traceWrite(spans, queued)
 				w.Write([]byte{'\n'})
-				w.Write(<-c.send)
+				w.Write(queued.data)
 			}
 
-			if err := w.Close(); err != nil {
+			err = w.Close()
+			for _, span := range spans {
+				telemetry.End(span, err)
+			}
+			if err != nil {
 				return
 			}
 
@@ -154,6 +205,65 @@ func (c *Client) WritePump() {
 	}
 }
 
+// trySend queues a message without blocking. It fails when the client's
+// buffer is full; messages for a closed client are discarded.
+func (c *Client) trySend(msg outboundMessage) bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+	}
+
+	select {
+	case c.send <- msg:
+		return true
+	default:
+		return false
+	}
+}
+
+// close disconnects the client with a close code. Only the first call has
+// an effect.
+func (c *Client) close(code int) {
+	c.closeOnce.Do(func() {
+		c.closeCode = code
+		close(c.done)
+	})
+}
+
+// presenceUserID identifies the client in cluster presence. Overlay tokens
+// are secrets and only shown masked.
+func (c *Client) presenceUserID() string {
+	if c.clientType == clientTypeOverlay {
+		return maskToken(c.userID)
+	}
+	return c.userID
+}
+
+// maskChannel masks the overlay token in a channel name
+func maskChannel(channel string) string {
+	if token, ok := strings.CutPrefix(channel, clientTypeOverlay+":"); ok {
+		return clientTypeOverlay + ":" + maskToken(token)
+	}
+	return channel
+}
+
+// traceWrite starts a span for writing a message that belongs to a trace
+func (c *Client) traceWrite(spans []trace.Span, msg outboundMessage) []trace.Span {
+	if !msg.span.IsValid() {
+		return spans
+	}
+
+	ctx := trace.ContextWithSpanContext(context.Background(), msg.span)
+	_, span := telemetry.Tracer().Start(ctx, "websocket.write",
+		trace.WithAttributes(
+			attribute.String("websocket.client_id", c.id),
+			attribute.String("websocket.client_type", c.clientType),
+		),
+	)
+	return append(spans, span)
+}
+
 // handleMessage processes incoming messages
 func (c *Client) handleMessage(data []byte) {
 	var msg IncomingMessage
@@ -169,9 +279,31 @@ func (c *Client) handleMessage(data []byte) {
 	switch msg.Type {
 	case "ping":
 		c.sendPong()
-	case "subscribe":
-		// Already subscribed on connect
-		c.sendAck("subscribed")
+	case "subscribe", "unsubscribe":
+		if c.subscription == nil {
+			// Overlays and donors are subscribed to their channel on connect
+			if msg.Type == "subscribe" {
+				c.sendAck("subscribed")
+			} else {
+				c.sendError("Only admin clients can change their subscription")
+			}
+			return
+		}
+		c.updateSubscription(msg)
+	case "snapshot":
+		if c.snapshot == nil {
+			c.sendError("Snapshots are only available to admin clients")
+			return
+		}
+		if time.Since(c.lastSnapshot) < snapshotInterval {
+			c.sendError("Snapshot requested too often")
+			return
+		}
+		c.lastSnapshot = time.Now()
+
+		ctx, cancel := context.WithTimeout(context.Background(), writeWait)
+		defer cancel()
+		c.sendJSON(c.snapshot(ctx))
 	default:
 		c.logger.Debug("unknown message type",
 			"client_id", c.id,
@@ -180,6 +312,44 @@ func (c *Client) handleMessage(data []byte) {
 	}
 }
 
+// updateSubscription changes the admin events the client receives and
+// acknowledges with the resulting subscription
+func (c *Client) updateSubscription(msg IncomingMessage) {
+	patterns, err := payloadPatterns(msg.Payload)
+	if err != nil {
+		c.sendError(err.Error())
+		return
+	}
+
+	action := "unsubscribed"
+	if msg.Type == "subscribe" {
+		if err := c.subscription.subscribe(patterns); err != nil {
+			c.sendError(err.Error())
+			return
+		}
+		action = "subscribed"
+	} else {
+		c.subscription.unsubscribe(patterns)
+	}
+
+	c.sendJSON(OutgoingMessage{
+		Type:      "ack",
+		Timestamp: time.Now().Format(time.RFC3339),
+		Data: map[string]interface{}{
+			"action": action,
+			"events": c.subscription.current(),
+		},
+	})
+}
+
+// wantsEvent reports whether an admin event goes to the client
+func (c *Client) wantsEvent(eventType string) bool {
+	if c.subscription == nil {
+		return false
+	}
+	return c.subscription.wants(eventType)
+}
+
 // sendPong sends a pong response
 func (c *Client) sendPong() {
 	msg := OutgoingMessage{
@@ -221,13 +391,19 @@ func (c *Client) sendJSON(msg OutgoingMessage) {
 		return
 	}
 
-	select {
-	case c.send <- data:
-	default:
+	if !c.trySend(outboundMessage{data: data}) {
 		c.logger.Warn("client send buffer full", "client_id", c.id)
 	}
 }
 
+// writeJSON writes a message straight to the connection, ahead of anything
+// queued. It must only be used before WritePump starts, since WritePump is
+// the connection's only writer afterwards.
+func (c *Client) writeJSON(msg OutgoingMessage) error {
+	c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return c.conn.WriteJSON(msg)
+}
+
 // SendWelcome sends a welcome message to the client
 func (c *Client) SendWelcome() {
 	msg := OutgoingMessage{
@@ -1,11 +1,16 @@
 package websocket
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
+	"time"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 
+	"github.com/reveegate/reveegate/internal/domain/donation"
 	"github.com/reveegate/reveegate/internal/http/middleware"
 )
 
@@ -22,14 +27,18 @@ var upgrader = websocket.Upgrader{
 type Handler struct {
 	hub            *Hub
 	authMiddleware *middleware.Auth
+	donations      DonationReader
+	dashboard      DashboardReader
 	logger         *slog.Logger
 }
 
 // NewHandler creates a new WebSocket handler
-func NewHandler(hub *Hub, authMiddleware *middleware.Auth, logger *slog.Logger) *Handler {
+func NewHandler(hub *Hub, authMiddleware *middleware.Auth, donations DonationReader, dashboard DashboardReader, logger *slog.Logger) *Handler {
 	return &Handler{
 		hub:            hub,
 		authMiddleware: authMiddleware,
+		donations:      donations,
+		dashboard:      dashboard,
 		logger:         logger,
 	}
 }
@@ -64,18 +73,21 @@ func (h *Handler) HandleOverlay(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create client
-	channel := "overlay:" + token
-	client := NewClient(conn, h.hub, channel, "overlay", token, h.logger)
+	channel := clientTypeOverlay + ":" + token
+	client := NewClient(conn, h.hub, channel, clientTypeOverlay, token, h.logger)
 
 	// Register client
-	h.hub.register <- client
+	if err := h.hub.Register(client); err != nil {
+		h.rejectClosed(conn)
+		return
+	}
 
 	// Send welcome message
 	client.SendWelcome()
 
 	h.logger.Info("overlay client connected",
 		"client_id", client.id,
-		"channel", channel,
+		"channel", maskChannel(channel),
 	)
 
 	// Start goroutines
@@ -83,7 +95,9 @@ func (h *Handler) HandleOverlay(w http.ResponseWriter, r *http.Request) {
 	go client.ReadPump()
 }
 
-// HandleAdmin handles GET /ws/admin
+// HandleAdmin handles GET /ws/admin. The first message is a snapshot of the
+// dashboard, followed by the admin events the client is subscribed to. The
+// optional events query parameter sets the initial subscription.
 func (h *Handler) HandleAdmin(w http.ResponseWriter, r *http.Request) {
 	// Get claims from context (set by auth middleware)
 	claims, ok := r.Context().Value(middleware.ClaimsContextKey{}).(*middleware.Claims)
@@ -92,6 +106,15 @@ func (h *Handler) HandleAdmin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The role decides which events can be received at all
+	subscription := newAdminSubscription(r.Context())
+	if events := r.URL.Query().Get("events"); events != "" {
+		if err := subscription.subscribe(splitPatterns(events)); err != nil {
+			http.Error(w, "Invalid events: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
 	// Upgrade connection
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
@@ -102,19 +125,32 @@ func (h *Handler) HandleAdmin(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create client
-	channel := "admin:" + claims.Subject
-	client := NewClient(conn, h.hub, channel, "admin", claims.Subject, h.logger)
+	channel := clientTypeAdmin + ":" + claims.Subject
+	client := NewClient(conn, h.hub, channel, clientTypeAdmin, claims.Subject, h.logger)
+	client.subscription = subscription
+	client.snapshot = func(ctx context.Context) OutgoingMessage {
+		return h.adminSnapshot(ctx, client)
+	}
 
 	// Register client
-	h.hub.register <- client
+	if err := h.hub.Register(client); err != nil {
+		h.rejectClosed(conn)
+		return
+	}
 
-	// Send welcome message
-	client.SendWelcome()
+	// Built after registering, so no event is missed, and written before the
+	// pumps start, so it comes before the events queued meanwhile. A failed
+	// write ends the pumps.
+	client.lastSnapshot = time.Now()
+	if err := client.writeJSON(h.adminSnapshot(r.Context(), client)); err != nil {
+		h.logger.Debug("failed to send admin snapshot", "client_id", client.id, "error", err)
+	}
 
 	h.logger.Info("admin client connected",
 		"client_id", client.id,
 		"channel", channel,
 		"user", claims.Subject,
+		"events", subscription.current(),
 	)
 
 	// Start goroutines
@@ -122,6 +158,127 @@ func (h *Handler) HandleAdmin(w http.ResponseWriter, r *http.Request) {
 	go client.ReadPump()
 }
 
+// HandleDonation handles GET /ws/donations/{id}. The donor receives the
+// current status, then every change. Access is checked by the DonorAccess
+// middleware.
+func (h *Handler) HandleDonation(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid donation ID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.donations.GetDonation(r.Context(), id); err != nil {
+		http.Error(w, "Donation not found", http.StatusNotFound)
+		return
+	}
+
+	// Upgrade connection
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		h.logger.Error("failed to upgrade websocket",
+			"error", err,
+		)
+		return
+	}
+
+	// Create client
+	client := NewClient(conn, h.hub, donationChannel(id.String()), clientTypeDonor, id.String(), h.logger)
+
+	// Register client
+	if err := h.hub.Register(client); err != nil {
+		h.rejectClosed(conn)
+		return
+	}
+
+	// Read after registering, so a change can't slip in between
+	if don, err := h.donations.GetDonation(r.Context(), id); err == nil {
+		client.sendJSON(donationStatusMessage(currentStatus(don)))
+	}
+
+	// Start goroutines
+	go client.WritePump()
+	go client.ReadPump()
+}
+
+// adminSnapshot describes what the dashboard shows now: the figures, the
+// latest donations and the overlays connected across the cluster. Parts the
+// client's role may not see are left out, as are parts that fail to load.
+func (h *Handler) adminSnapshot(ctx context.Context, client *Client) OutgoingMessage {
+	data := map[string]interface{}{
+		"client_id":   client.id,
+		"instance_id": h.hub.InstanceID(),
+		"events":      client.subscription.current(),
+		"event_types": client.subscription.available(),
+	}
+
+	if dashboard, err := h.dashboard.GetDashboard(ctx); err != nil {
+		h.logger.Warn("failed to load dashboard for snapshot", "client_id", client.id, "error", err)
+	} else {
+		data["dashboard"] = dashboard
+	}
+
+	if client.subscription.allows("donation") {
+		result, err := h.dashboard.ListDonations(ctx, donation.ListDonationsParams{Page: 1, Limit: snapshotDonations})
+		if err != nil {
+			h.logger.Warn("failed to load donations for snapshot", "client_id", client.id, "error", err)
+		} else {
+			recent := make([]map[string]interface{}, len(result.Donations))
+			for i, don := range result.Donations {
+				recent[i] = map[string]interface{}{
+					"id":         don.ID,
+					"donor_name": don.DonorName,
+					"message":    don.Message,
+					"amount":     don.Amount,
+					"status":     don.Status,
+					"created_at": don.CreatedAt,
+					"paid_at":    don.PaidAt,
+				}
+			}
+			data["recent_donations"] = recent
+		}
+	}
+
+	if client.subscription.allows("overlay") {
+		instances, err := h.hub.ClusterPresence(ctx)
+		if err != nil {
+			h.logger.Warn("failed to load presence for snapshot", "client_id", client.id, "error", err)
+		} else {
+			overlays := make([]map[string]interface{}, 0)
+			for _, instance := range instances {
+				for _, c := range instance.Clients {
+					if c.Type != clientTypeOverlay {
+						continue
+					}
+					overlays = append(overlays, map[string]interface{}{
+						"client_id":    c.ID,
+						"instance_id":  instance.InstanceID,
+						"channel":      c.Channel,
+						"connected_at": c.ConnectedAt,
+					})
+				}
+			}
+			data["overlays"] = overlays
+		}
+	}
+
+	return OutgoingMessage{
+		Type:      "snapshot",
+		Timestamp: time.Now().Format(time.RFC3339),
+		Data:      data,
+	}
+}
+
+// rejectClosed tells a client that connected during shutdown to reconnect,
+// which the load balancer routes to another instance
+func (h *Handler) rejectClosed(conn *websocket.Conn) {
+	conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"),
+		time.Now().Add(writeWait),
+	)
+	conn.Close()
+}
+
 // maskToken masks a token for logging
 func maskToken(token string) string {
 	if len(token) <= 8 {
@@ -3,181 +3,401 @@ package websocket
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"sync"
 	"time"
 
+	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/reveegate/reveegate/internal/domain/donation"
+	"github.com/reveegate/reveegate/internal/metrics"
 	redisRepo "github.com/reveegate/reveegate/internal/repository/redis"
+	"github.com/reveegate/reveegate/internal/service"
+	"github.com/reveegate/reveegate/internal/telemetry"
 )
 
-// Hub maintains the set of active clients and broadcasts messages
-type Hub struct {
-	// Registered clients by channel
-	clients map[string]map[*Client]bool
+const (
+	// Size of the queue between the Redis subscriptions and the fan-out
+	broadcastQueueSize = 256
 
-	// Register requests from clients
-	register chan *Client
+	// How often this instance refreshes its presence in Redis
+	presenceInterval = 15 * time.Second
 
-	// Unregister requests from clients
-	unregister chan *Client
+	// PresenceTTL is how long the presence of an instance that stopped
+	// refreshing it is kept
+	PresenceTTL = 3 * presenceInterval
+)
+
+// ErrHubClosed is returned when a client connects while the hub shuts down
+var ErrHubClosed = errors.New("realtime hub is shut down")
 
-	// Broadcast message to specific channel
+// Hub tracks the clients connected to this instance and fans out the events
+// every instance receives from Redis to them. Instances don't talk to each
+// other: each one delivers pub/sub events to its own clients and publishes
+// its presence so the cluster can tell where overlays are connected.
+//
+// Channel sends never happen under mu and never block on a client; a client
+// whose buffer is full is disconnected.
+type Hub struct {
+	instanceID string
+
+	// Registered clients by channel
+	mu      sync.RWMutex
+	clients map[string]map[*Client]struct{}
+	closed  bool
+
+	// Messages waiting to be fanned out
 	broadcast chan *BroadcastMessage
 
+	// Signals the presence loop that clients changed
+	presenceChanged chan struct{}
+
 	// Pubsub for receiving events from other services
-	pubsub *redisRepo.PubSub
+	pubsub   *redisRepo.PubSub
+	presence *redisRepo.Presence
 
 	// Logger
 	logger *slog.Logger
 
-	// Mutex for thread-safe client access
-	mu sync.RWMutex
+	// Running write pumps, waited for on shutdown
+	pumps sync.WaitGroup
 
 	// Context for shutdown
 	ctx    context.Context
 	cancel context.CancelFunc
+	done   chan struct{}
 }
 
-// BroadcastMessage represents a message to broadcast
+// BroadcastMessage represents a message to broadcast. It goes to the clients
+// of Channel, or to every client of ClientType when Channel is empty. Admin
+// events only go to the admin clients subscribed to EventType.
 type BroadcastMessage struct {
-	Channel string
-	Message []byte
+	Channel    string
+	ClientType string
+	EventType  string
+	Message    []byte
+	EventID    string            // History position, sent as the SSE event ID
+	Span       trace.SpanContext // Broadcast span the client writes are traced under
+	ReceivedAt time.Time         // When the webhook behind a donation alert arrived
 }
 
 // NewHub creates a new Hub
-func NewHub(pubsub *redisRepo.PubSub, logger *slog.Logger) *Hub {
+func NewHub(pubsub *redisRepo.PubSub, presence *redisRepo.Presence, instanceID string, logger *slog.Logger) *Hub {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &Hub{
-		clients:    make(map[string]map[*Client]bool),
-		register:   make(chan *Client, 256),
-		unregister: make(chan *Client, 256),
-		broadcast:  make(chan *BroadcastMessage, 256),
-		pubsub:     pubsub,
-		logger:     logger,
-		ctx:        ctx,
-		cancel:     cancel,
+		instanceID:      instanceID,
+		clients:         make(map[string]map[*Client]struct{}),
+		broadcast:       make(chan *BroadcastMessage, broadcastQueueSize),
+		presenceChanged: make(chan struct{}, 1),
+		pubsub:          pubsub,
+		presence:        presence,
+		logger:          logger.With("instance_id", instanceID),
+		ctx:             ctx,
+		cancel:          cancel,
+		done:            make(chan struct{}),
 	}
 }
 
-// Run starts the hub
+// Run subscribes to Redis and fans out messages until Shutdown is called
 func (h *Hub) Run() {
+	defer close(h.done)
+
 	// Start Redis subscription
-	go h.subscribeToEvents()
+	h.subscribeToEvents()
+
+	// Shutdown removes the presence once the loop can't publish it again
+	presenceStopped := make(chan struct{})
+	go func() {
+		defer close(presenceStopped)
+		h.presenceLoop()
+	}()
 
 	for {
 		select {
 		case <-h.ctx.Done():
 			h.logger.Info("hub shutting down")
+			<-presenceStopped
 			return
 
-		case client := <-h.register:
-			h.registerClient(client)
-
-		case client := <-h.unregister:
-			h.unregisterClient(client)
-
 		case msg := <-h.broadcast:
-			h.broadcastToChannel(msg)
+			h.fanOut(msg)
+			metrics.HubQueueDepth.Set(float64(len(h.broadcast)))
 		}
 	}
 }
 
-// Stop gracefully stops the hub
-func (h *Hub) Stop() {
+// Shutdown stops accepting clients, closes every connected client with a
+// going-away close frame so it reconnects to another instance, and removes
+// this instance's presence. It waits for the close frames to be written
+// until ctx is done.
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil
+	}
+	h.closed = true
+	clients := h.clients
+	h.clients = make(map[string]map[*Client]struct{})
+	h.mu.Unlock()
+
+	// Stops the subscriptions, the fan-out and the presence loop
 	h.cancel()
+
+	count := 0
+	for _, channelClients := range clients {
+		for client := range channelClients {
+			client.close(websocket.CloseGoingAway)
+			metrics.WebSocketClients.WithLabelValues(client.clientType).Dec()
+			count++
+		}
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		h.pumps.Wait()
+		<-h.done
+		close(stopped)
+	}()
+
+	var err error
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if rerr := h.presence.Remove(context.WithoutCancel(ctx), h.instanceID); rerr != nil && err == nil {
+		err = rerr
+	}
+
+	h.logger.Info("hub stopped", "clients_closed", count)
+	return err
 }
 
-// registerClient adds a client to a channel
-func (h *Hub) registerClient(client *Client) {
+// Register adds a client to its channel. The caller starts the client's
+// pumps only when it succeeds.
+func (h *Hub) Register(client *Client) error {
 	h.mu.Lock()
-	defer h.mu.Unlock()
+	if h.closed {
+		h.mu.Unlock()
+		return ErrHubClosed
+	}
 
 	if h.clients[client.channel] == nil {
-		h.clients[client.channel] = make(map[*Client]bool)
+		h.clients[client.channel] = make(map[*Client]struct{})
+	}
+	h.clients[client.channel][client] = struct{}{}
+	inChannel := len(h.clients[client.channel])
+
+	// Added under the lock so Shutdown never misses a pump started after it
+	h.pumps.Add(1)
+	h.mu.Unlock()
+
+	metrics.WebSocketClients.WithLabelValues(client.clientType).Inc()
+	h.notifyPresence()
+	if client.clientType == clientTypeOverlay {
+		go h.publishOverlayEvent(service.EventOverlayConnected, client)
 	}
-	h.clients[client.channel][client] = true
 
 	h.logger.Debug("client registered",
-		"channel", client.channel,
+		"channel", maskChannel(client.channel),
 		"client_id", client.id,
-		"clients_in_channel", len(h.clients[client.channel]),
+		"clients_in_channel", inChannel,
 	)
+
+	return nil
 }
 
-// unregisterClient removes a client from its channel
-func (h *Hub) unregisterClient(client *Client) {
+// Unregister removes a client and closes it with closeCode. It reports
+// whether the client was still registered.
+func (h *Hub) Unregister(client *Client, closeCode int) bool {
 	h.mu.Lock()
-	defer h.mu.Unlock()
+	clients, ok := h.clients[client.channel]
+	if ok {
+		_, ok = clients[client]
+	}
+	if ok {
+		delete(clients, client)
+		if len(clients) == 0 {
+			delete(h.clients, client.channel)
+		}
+	}
+	h.mu.Unlock()
 
-	if clients, ok := h.clients[client.channel]; ok {
-		if _, exists := clients[client]; exists {
-			delete(clients, client)
-			close(client.send)
+	client.close(closeCode)
+	if !ok {
+		return false
+	}
 
-			if len(clients) == 0 {
-				delete(h.clients, client.channel)
-			}
+	metrics.WebSocketClients.WithLabelValues(client.clientType).Dec()
+	h.notifyPresence()
+	if client.clientType == clientTypeOverlay {
+		go h.publishOverlayEvent(service.EventOverlayDisconnected, client)
+	}
 
-			h.logger.Debug("client unregistered",
-				"channel", client.channel,
-				"client_id", client.id,
-			)
-		}
+	h.logger.Debug("client unregistered",
+		"channel", maskChannel(client.channel),
+		"client_id", client.id,
+	)
+
+	return true
+}
+
+// enqueue queues a message for the fan-out, waiting while the queue is full
+func (h *Hub) enqueue(msg *BroadcastMessage) {
+	select {
+	case h.broadcast <- msg:
+	case <-h.ctx.Done():
 	}
 }
 
-// broadcastToChannel sends message to all clients in a channel
-func (h *Hub) broadcastToChannel(msg *BroadcastMessage) {
+// fanOut sends a message to its clients. Clients that can't keep up are
+// disconnected instead of slowing everyone else down.
+func (h *Hub) fanOut(msg *BroadcastMessage) {
 	h.mu.RLock()
-	clients, ok := h.clients[msg.Channel]
+	var targets []*Client
+	if msg.Channel != "" {
+		for client := range h.clients[msg.Channel] {
+			targets = append(targets, client)
+		}
+	} else {
+		for _, clients := range h.clients {
+			for client := range clients {
+				if client.clientType != msg.ClientType {
+					continue
+				}
+				if msg.EventType != "" && !client.wantsEvent(msg.EventType) {
+					continue
+				}
+				targets = append(targets, client)
+			}
+		}
+	}
 	h.mu.RUnlock()
 
-	if !ok {
-		return
-	}
+	out := outboundMessage{id: msg.EventID, data: msg.Message, span: msg.Span}
+	for _, client := range targets {
+		if client.trySend(out) {
+			continue
+		}
 
-	for client := range clients {
-		select {
-		case client.send <- msg.Message:
-		default:
-			// Client buffer full, unregister
-			h.unregister <- client
+		// Client buffer full, unregister
+		if h.Unregister(client, websocket.CloseTryAgainLater) {
+			metrics.WebSocketDroppedClientsTotal.WithLabelValues(client.clientType).Inc()
+			h.logger.Warn("slow client disconnected", "client_id", client.id, "type", client.clientType)
 		}
 	}
+
+	// Alerts completed by a webhook are timed from its arrival
+	if !msg.ReceivedAt.IsZero() {
+		metrics.WebhookToOverlayDuration.Observe(time.Since(msg.ReceivedAt).Seconds())
+	}
 }
 
 // BroadcastDonation broadcasts a donation event to overlay clients
 func (h *Hub) BroadcastDonation(event *redisRepo.DonationEvent) {
+	// Continue the trace of the webhook that completed the donation
+	_, span := telemetry.Tracer().Start(telemetry.Extract(h.ctx, event.TraceContext), "hub.BroadcastDonation",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("donation.id", event.ID),
+			attribute.String("realtime.instance_id", h.instanceID),
+		),
+	)
+	defer span.End()
+
+	data, err := json.Marshal(donationMessage(event))
+	if err != nil {
+		h.logger.Error("failed to marshal donation event", "error", err)
+		return
+	}
+
+	broadcast := &BroadcastMessage{
+		ClientType: clientTypeOverlay,
+		Message:    data,
+		EventID:    event.EventID,
+		Span:       span.SpanContext(),
+	}
+	if receivedAt, err := time.Parse(time.RFC3339Nano, event.ReceivedAt); err == nil {
+		broadcast.ReceivedAt = receivedAt
+	}
+
+	h.enqueue(broadcast)
+}
+
+// BroadcastDonationStatus sends a donation status change to the donor's
+// status streams
+func (h *Hub) BroadcastDonationStatus(event *redisRepo.DonationStatusEvent) {
+	data, err := json.Marshal(donationStatusMessage(event))
+	if err != nil {
+		h.logger.Error("failed to marshal donation status event", "error", err)
+		return
+	}
+
+	h.enqueue(&BroadcastMessage{
+		Channel: donationChannel(event.DonationID),
+		Message: data,
+		EventID: event.EventID,
+	})
+}
+
+// BroadcastAdminEvent broadcasts an event to the admin dashboard clients
+// subscribed to it
+func (h *Hub) BroadcastAdminEvent(event *redisRepo.AdminEvent) {
 	msg := OutgoingMessage{
-		Type:      "donation",
-		Timestamp: time.Now().Format(time.RFC3339),
-		Data: map[string]interface{}{
-			"id":         event.ID,
-			"donor_name": event.DonorName,
-			"message":    event.Message,
-			"amount":     event.Amount,
-			"paid_at":    event.PaidAt,
-		},
+		Type:      event.Type,
+		Timestamp: event.Timestamp,
+		Data:      event.Data,
 	}
 
 	data, err := json.Marshal(msg)
 	if err != nil {
-		h.logger.Error("failed to marshal donation event", "error", err)
+		h.logger.Error("failed to marshal admin event", "error", err)
 		return
 	}
 
-	// Broadcast to all overlay channels
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+	h.enqueue(&BroadcastMessage{
+		ClientType: clientTypeAdmin,
+		EventType:  event.Type,
+		Message:    data,
+	})
+}
 
-	for channel := range h.clients {
-		if len(channel) > 8 && channel[:8] == "overlay:" {
-			h.broadcast <- &BroadcastMessage{
-				Channel: channel,
-				Message: data,
-			}
-		}
+// publishOverlayEvent tells the admin dashboards of every instance that an
+// overlay connected or disconnected here. Overlays of an instance that shuts
+// down are not announced; they show up again where they reconnect.
+func (h *Hub) publishOverlayEvent(eventType string, client *Client) {
+	transport := "websocket"
+	if client.conn == nil {
+		transport = "sse"
+	}
+
+	data := map[string]interface{}{
+		"client_id":    client.id,
+		"instance_id":  h.instanceID,
+		"channel":      maskChannel(client.channel),
+		"transport":    transport,
+		"connected_at": client.connectedAt.Format(time.RFC3339),
+	}
+	if eventType == service.EventOverlayDisconnected {
+		data["connected_seconds"] = int64(time.Since(client.connectedAt).Seconds())
+	}
+
+	ctx, cancel := context.WithTimeout(h.ctx, 5*time.Second)
+	defer cancel()
+
+	event := &redisRepo.AdminEvent{
+		Type:      eventType,
+		Data:      data,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	if err := h.pubsub.PublishAdminEvent(ctx, event); err != nil && h.ctx.Err() == nil {
+		h.logger.Warn("failed to publish overlay event", "type", eventType, "error", err)
 	}
 }
 
@@ -190,9 +410,145 @@ func (h *Hub) subscribeToEvents() {
 	if err != nil {
 		h.logger.Error("failed to subscribe to donations", "error", err)
 	}
+
+	err = h.pubsub.SubscribeDonationStatus(h.ctx, func(event *redisRepo.DonationStatusEvent) {
+		h.BroadcastDonationStatus(event)
+	})
+
+	if err != nil {
+		h.logger.Error("failed to subscribe to donation status", "error", err)
+	}
+
+	err = h.pubsub.SubscribeAdminEvents(h.ctx, func(event *redisRepo.AdminEvent) {
+		h.BroadcastAdminEvent(event)
+	})
+
+	if err != nil {
+		h.logger.Error("failed to subscribe to admin events", "error", err)
+	}
+}
+
+// donationMessage is the overlay alert for a donation
+func donationMessage(event *redisRepo.DonationEvent) OutgoingMessage {
+	return OutgoingMessage{
+		Type:      "donation",
+		Timestamp: time.Now().Format(time.RFC3339),
+		Data: map[string]interface{}{
+			"id":         event.ID,
+			"donor_name": event.DonorName,
+			"message":    event.Message,
+			"amount":     event.Amount,
+			"paid_at":    event.PaidAt,
+		},
+	}
+}
+
+// donationStatusMessage is the status update sent to a donor. A completed
+// donation comes with what the thank-you screen shows.
+func donationStatusMessage(event *redisRepo.DonationStatusEvent) OutgoingMessage {
+	data := map[string]interface{}{
+		"donation_id": event.DonationID,
+		"status":      event.Status,
+	}
+	if event.PaidAt != "" {
+		data["paid_at"] = event.PaidAt
+	}
+	if event.Status == string(donation.StatusCompleted) {
+		data["thank_you"] = map[string]interface{}{
+			"donor_name": event.DonorName,
+			"amount":     event.Amount,
+			"message":    event.Message,
+		}
+	}
+
+	return OutgoingMessage{
+		Type:      "donation_status",
+		Timestamp: event.Timestamp,
+		Data:      data,
+	}
+}
+
+// currentStatus describes a donation as it is now, for clients that just
+// connected
+func currentStatus(don *donation.Donation) *redisRepo.DonationStatusEvent {
+	return redisRepo.NewDonationStatusEvent(
+		don.ID.String(),
+		string(don.Status),
+		don.DonorName,
+		don.Message,
+		don.Amount,
+		don.PaidAt,
+	)
+}
+
+// donationChannel is the channel of the status streams of one donation
+func donationChannel(donationID string) string {
+	return clientTypeDonor + ":" + donationID
+}
+
+// notifyPresence asks the presence loop to publish soon
+func (h *Hub) notifyPresence() {
+	select {
+	case h.presenceChanged <- struct{}{}:
+	default:
+	}
+}
+
+// presenceLoop publishes this instance's clients to Redis when they change
+// and at least every presenceInterval
+func (h *Hub) presenceLoop() {
+	ticker := time.NewTicker(presenceInterval)
+	defer ticker.Stop()
+
+	h.publishPresence()
+	for {
+		select {
+		case <-h.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-h.presenceChanged:
+		}
+		h.publishPresence()
+	}
+}
+
+// publishPresence writes the current clients to Redis
+func (h *Hub) publishPresence() {
+	h.mu.RLock()
+	clients := make([]redisRepo.PresenceClient, 0)
+	for channel, channelClients := range h.clients {
+		for client := range channelClients {
+			clients = append(clients, redisRepo.PresenceClient{
+				ID:          client.id,
+				Type:        client.clientType,
+				Channel:     maskChannel(channel),
+				UserID:      client.presenceUserID(),
+				ConnectedAt: client.connectedAt,
+			})
+		}
+	}
+	h.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(h.ctx, 5*time.Second)
+	defer cancel()
+
+	if err := h.presence.Publish(ctx, h.instanceID, clients, PresenceTTL); err != nil && h.ctx.Err() == nil {
+		h.logger.Warn("failed to publish presence", "error", err)
+	}
 }
 
-// GetStats returns hub statistics
+// ClusterPresence lists the clients connected to every live instance
+func (h *Hub) ClusterPresence(ctx context.Context) ([]redisRepo.InstancePresence, error) {
+	return h.presence.List(ctx, PresenceTTL)
+}
+
+// InstanceID returns the ID this instance publishes its presence under
+func (h *Hub) InstanceID() string {
+	return h.instanceID
+}
+
+// GetStats returns statistics of this instance's hub. Overlay tokens in
+// channel names are masked.
 func (h *Hub) GetStats() map[string]interface{} {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
@@ -202,14 +558,16 @@ func (h *Hub) GetStats() map[string]interface{} {
 
 	for channel, clients := range h.clients {
 		count := len(clients)
-		channelStats[channel] = count
+		channelStats[maskChannel(channel)] += count
 		totalClients += count
 	}
 
 	return map[string]interface{}{
+		"instance_id":    h.instanceID,
 		"total_clients":  totalClients,
 		"total_channels": len(h.clients),
 		"channels":       channelStats,
+		"queue_depth":    len(h.broadcast),
 	}
 }
 
@@ -218,8 +576,5 @@ func (h *Hub) GetClientCount(channel string) int {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
-	if clients, ok := h.clients[channel]; ok {
-		return len(clients)
-	}
-	return 0
+	return len(h.clients[channel])
 }
@@ -0,0 +1,301 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+
+	"github.com/reveegate/reveegate/internal/domain/donation"
+	"github.com/reveegate/reveegate/internal/http/dto"
+	"github.com/reveegate/reveegate/internal/http/middleware"
+	redisRepo "github.com/reveegate/reveegate/internal/repository/redis"
+)
+
+const (
+	// Heartbeats keep proxies from closing idle streams
+	sseHeartbeatInterval = 15 * time.Second
+
+	// How long EventSource waits before reconnecting (ms)
+	sseRetryMs = 3000
+)
+
+// DonationReader looks up the donation behind a status stream
+type DonationReader interface {
+	GetDonation(ctx context.Context, id uuid.UUID) (*donation.Donation, error)
+}
+
+// SSEHandler serves Server-Sent Events streams for clients that can't use
+// WebSockets. Stream clients are registered with the hub like WebSocket
+// clients and receive the same messages.
+type SSEHandler struct {
+	hub            *Hub
+	authMiddleware *middleware.Auth
+	donations      DonationReader
+	logger         *slog.Logger
+}
+
+// NewSSEHandler creates a new SSE handler
+func NewSSEHandler(hub *Hub, authMiddleware *middleware.Auth, donations DonationReader, logger *slog.Logger) *SSEHandler {
+	return &SSEHandler{
+		hub:            hub,
+		authMiddleware: authMiddleware,
+		donations:      donations,
+		logger:         logger,
+	}
+}
+
+// HandleOverlay handles GET /sse/overlay. A reconnecting client sends
+// Last-Event-ID and gets the donations it missed.
+func (h *SSEHandler) HandleOverlay(w http.ResponseWriter, r *http.Request) {
+	// Get overlay token from query parameter
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		http.Error(w, "Missing overlay token", http.StatusUnauthorized)
+		return
+	}
+
+	// Validate overlay token
+	valid, err := h.authMiddleware.ValidateOverlayToken(r.Context(), token)
+	if err != nil || !valid {
+		h.logger.Warn("invalid overlay token",
+			"token", maskToken(token),
+			"error", err,
+		)
+		http.Error(w, "Invalid overlay token", http.StatusUnauthorized)
+		return
+	}
+
+	channel := clientTypeOverlay + ":" + token
+	client := NewClient(nil, h.hub, channel, clientTypeOverlay, token, h.logger)
+	if err := h.hub.Register(client); err != nil {
+		h.rejectClosed(w)
+		return
+	}
+
+	initial := []OutgoingMessage{{
+		Type:      "welcome",
+		Timestamp: time.Now().Format(time.RFC3339),
+		Data: map[string]interface{}{
+			"client_id": client.id,
+			"channel":   maskChannel(channel),
+			"message":   "Connected to ReveeGate event stream",
+		},
+	}}
+
+	// Registered before reading the history, so nothing falls in between
+	var missed []*redisRepo.DonationEvent
+	lastEventID := requestLastEventID(r)
+	if lastEventID != "" {
+		missed, err = h.hub.pubsub.DonationEventsSince(r.Context(), lastEventID)
+		if err != nil {
+			h.logger.Warn("failed to replay overlay events", "last_event_id", lastEventID, "error", err)
+		}
+	}
+
+	replay := make([]outboundMessage, 0, len(missed))
+	for _, event := range missed {
+		if data, err := json.Marshal(donationMessage(event)); err == nil {
+			replay = append(replay, outboundMessage{id: event.EventID, data: data})
+		}
+	}
+
+	h.logger.Info("overlay stream connected",
+		"client_id", client.id,
+		"channel", maskChannel(channel),
+		"replayed", len(replay),
+	)
+
+	h.serve(w, r, client, lastEventID, initial, replay)
+}
+
+// HandleDonationEvents handles GET /api/v1/donations/{id}/events. A new
+// stream starts with the current status; a reconnecting one with the
+// changes it missed.
+func (h *SSEHandler) HandleDonationEvents(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "INVALID_ID", "Invalid donation ID")
+		return
+	}
+
+	// Check the donation exists before holding a connection for it
+	if _, err := h.donations.GetDonation(r.Context(), id); err != nil {
+		h.respondError(w, http.StatusNotFound, "NOT_FOUND", "Donation not found")
+		return
+	}
+
+	client := NewClient(nil, h.hub, donationChannel(id.String()), clientTypeDonor, id.String(), h.logger)
+	if err := h.hub.Register(client); err != nil {
+		h.rejectClosed(w)
+		return
+	}
+
+	var initial []OutgoingMessage
+	var replay []outboundMessage
+	lastEventID := requestLastEventID(r)
+	if lastEventID != "" {
+		missed, err := h.hub.pubsub.DonationStatusSince(r.Context(), id.String(), lastEventID)
+		if err != nil {
+			h.logger.Warn("failed to replay donation status", "donation_id", id, "last_event_id", lastEventID, "error", err)
+		}
+		for _, event := range missed {
+			if data, err := json.Marshal(donationStatusMessage(event)); err == nil {
+				replay = append(replay, outboundMessage{id: event.EventID, data: data})
+			}
+		}
+	} else {
+		// Read after registering, so a change can't slip in between
+		if don, err := h.donations.GetDonation(r.Context(), id); err == nil {
+			initial = append(initial, donationStatusMessage(currentStatus(don)))
+		}
+	}
+
+	h.serve(w, r, client, lastEventID, initial, replay)
+}
+
+// serve writes a registered client's messages as an event stream until the
+// request ends or the hub closes the client
+func (h *SSEHandler) serve(w http.ResponseWriter, r *http.Request, client *Client, lastEventID string, initial []OutgoingMessage, replay []outboundMessage) {
+	defer h.hub.pumps.Done()
+	defer h.hub.Unregister(client, websocket.CloseNormalClosure)
+
+	stream, err := newSSEStream(w)
+	if err != nil {
+		h.logger.Error("failed to start event stream", "client_id", client.id, "error", err)
+		return
+	}
+
+	for _, msg := range initial {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			continue
+		}
+		if err := stream.write("", "", data); err != nil {
+			return
+		}
+	}
+
+	// Live messages can repeat the end of the replay
+	for _, msg := range replay {
+		if err := stream.write(msg.id, "", msg.data); err != nil {
+			return
+		}
+		lastEventID = msg.id
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case <-client.done:
+			// Hub shut down or dropped the client; EventSource reconnects
+			return
+
+		case msg := <-client.send:
+			if msg.id != "" {
+				if !redisRepo.EventIDAfter(msg.id, lastEventID) {
+					continue
+				}
+				lastEventID = msg.id
+			}
+			if err := stream.write(msg.id, "", msg.data); err != nil {
+				return
+			}
+
+		case <-heartbeat.C:
+			data, _ := json.Marshal(OutgoingMessage{
+				Type:      "heartbeat",
+				Timestamp: time.Now().Format(time.RFC3339),
+			})
+			if err := stream.write("", "heartbeat", data); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// rejectClosed asks a client that connected during shutdown to retry, which
+// the load balancer routes to another instance
+func (h *SSEHandler) rejectClosed(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", "1")
+	http.Error(w, "Server shutting down", http.StatusServiceUnavailable)
+}
+
+// respondError sends error response
+func (h *SSEHandler) respondError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(dto.ErrorResponse{
+		Error:   code,
+		Message: message,
+	})
+}
+
+// requestLastEventID returns the ID a reconnecting EventSource last received.
+// Malformed IDs are ignored.
+func requestLastEventID(r *http.Request) string {
+	id := r.Header.Get("Last-Event-ID")
+	if !redisRepo.ValidEventID(id) {
+		return ""
+	}
+	return id
+}
+
+// sseStream writes Server-Sent Events to a response
+type sseStream struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+// newSSEStream sends the stream headers
+func newSSEStream(w http.ResponseWriter) (*sseStream, error) {
+	s := &sseStream{w: w, rc: http.NewResponseController(w)}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if err := s.writeRaw(fmt.Sprintf("retry: %d\n\n", sseRetryMs)); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// write sends one event. The data is a single line of JSON.
+func (s *sseStream) write(id, event string, data []byte) error {
+	msg := ""
+	if id != "" {
+		msg += "id: " + id + "\n"
+	}
+	if event != "" {
+		msg += "event: " + event + "\n"
+	}
+	msg += "data: " + string(data) + "\n\n"
+
+	return s.writeRaw(msg)
+}
+
+// writeRaw writes and flushes. The server's write timeout is pushed back
+// for every write, since the stream outlives it.
+func (s *sseStream) writeRaw(msg string) error {
+	if err := s.rc.SetWriteDeadline(time.Now().Add(writeWait)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	if _, err := s.w.Write([]byte(msg)); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
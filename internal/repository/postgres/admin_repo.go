@@ -3,10 +3,12 @@ package postgres
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -14,6 +16,7 @@ var (
 	ErrAdminNotFound      = errors.New("admin user not found")
 	ErrAdminInactive      = errors.New("admin user is inactive")
 	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrAdminExists        = errors.New("admin user with this username or email already exists")
 )
 
 // AdminUser represents an admin user entity
@@ -22,7 +25,10 @@ type AdminUser struct {
 	Username     string
 	PasswordHash string
 	Email        string
+	Role         string
 	IsActive     bool
+	TOTPSecret   string // Encrypted, empty until enrollment starts
+	TOTPEnabled  bool
 	LastLoginAt  *time.Time
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
@@ -33,69 +39,134 @@ type AdminRepository struct {
 	db *pgxpool.Pool
 }
 
+const adminColumns = `
+	id, username, password_hash, email, role, is_active,
+	COALESCE(totp_secret, ''), totp_enabled, last_login_at, created_at, updated_at
+`
+
 // NewAdminRepository creates a new admin repository
 func NewAdminRepository(db *pgxpool.Pool) *AdminRepository {
 	return &AdminRepository{db: db}
 }
 
+// FindByID finds an admin user by ID
+func (r *AdminRepository) FindByID(ctx context.Context, id uuid.UUID) (*AdminUser, error) {
+	query := `SELECT ` + adminColumns + ` FROM admin_users WHERE id = $1`
+	return scanAdmin(r.db.QueryRow(ctx, query, id))
+}
+
 // FindByEmail finds an admin user by email
 func (r *AdminRepository) FindByEmail(ctx context.Context, email string) (*AdminUser, error) {
-	query := `
-		SELECT id, username, password_hash, email, is_active, last_login_at, created_at, updated_at
-		FROM admin_users
-		WHERE email = $1
-	`
+	query := `SELECT ` + adminColumns + ` FROM admin_users WHERE email = $1`
+	return scanAdmin(r.db.QueryRow(ctx, query, email))
+}
 
-	var admin AdminUser
-	err := r.db.QueryRow(ctx, query, email).Scan(
-		&admin.ID,
-		&admin.Username,
-		&admin.PasswordHash,
-		&admin.Email,
-		&admin.IsActive,
-		&admin.LastLoginAt,
-		&admin.CreatedAt,
-		&admin.UpdatedAt,
-	)
+// FindByUsername finds an admin user by username
+func (r *AdminRepository) FindByUsername(ctx context.Context, username string) (*AdminUser, error) {
+	query := `SELECT ` + adminColumns + ` FROM admin_users WHERE username = $1`
+	return scanAdmin(r.db.QueryRow(ctx, query, username))
+}
+
+// List lists all admin users
+func (r *AdminRepository) List(ctx context.Context) ([]*AdminUser, error) {
+	query := `SELECT ` + adminColumns + ` FROM admin_users ORDER BY created_at`
 
+	rows, err := r.db.Query(ctx, query)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, ErrAdminNotFound
+		return nil, fmt.Errorf("failed to list admin users: %w", err)
+	}
+	defer rows.Close()
+
+	admins := make([]*AdminUser, 0)
+	for rows.Next() {
+		admin, err := scanAdmin(rows)
+		if err != nil {
+			return nil, err
 		}
-		return nil, err
+		admins = append(admins, admin)
 	}
 
-	return &admin, nil
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating admin users: %w", err)
+	}
+
+	return admins, nil
 }
 
-// FindByUsername finds an admin user by username
-func (r *AdminRepository) FindByUsername(ctx context.Context, username string) (*AdminUser, error) {
+// Create creates an admin user with admin.PasswordHash already set
+func (r *AdminRepository) Create(ctx context.Context, admin *AdminUser) error {
+	return insertAdmin(ctx, r.db, admin)
+}
+
+// UpdatePassword replaces the password hash of an admin user
+func (r *AdminRepository) UpdatePassword(ctx context.Context, adminID uuid.UUID, passwordHash string) error {
 	query := `
-		SELECT id, username, password_hash, email, is_active, last_login_at, created_at, updated_at
-		FROM admin_users
-		WHERE username = $1
+		UPDATE admin_users
+		SET password_hash = $2, updated_at = NOW()
+		WHERE id = $1
 	`
 
-	var admin AdminUser
-	err := r.db.QueryRow(ctx, query, username).Scan(
-		&admin.ID,
-		&admin.Username,
-		&admin.PasswordHash,
-		&admin.Email,
-		&admin.IsActive,
-		&admin.LastLoginAt,
-		&admin.CreatedAt,
-		&admin.UpdatedAt,
-	)
+	tag, err := r.db.Exec(ctx, query, adminID, passwordHash)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAdminNotFound
+	}
+
+	return nil
+}
 
+// UpgradePasswordHash replaces a legacy hash after a successful login. It does
+// nothing if the password was changed in the meantime.
+func (r *AdminRepository) UpgradePasswordHash(ctx context.Context, adminID uuid.UUID, oldHash, newHash string) error {
+	query := `
+		UPDATE admin_users
+		SET password_hash = $3, updated_at = NOW()
+		WHERE id = $1 AND password_hash = $2
+	`
+
+	_, err := r.db.Exec(ctx, query, adminID, oldHash, newHash)
+	return err
+}
+
+// SetActive activates or deactivates an admin user
+func (r *AdminRepository) SetActive(ctx context.Context, adminID uuid.UUID, active bool) error {
+	query := `UPDATE admin_users SET is_active = $2, updated_at = NOW() WHERE id = $1`
+
+	tag, err := r.db.Exec(ctx, query, adminID, active)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, ErrAdminNotFound
-		}
-		return nil, err
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAdminNotFound
 	}
 
-	return &admin, nil
+	return nil
+}
+
+// UpdateRole changes the role of an admin user
+func (r *AdminRepository) UpdateRole(ctx context.Context, adminID uuid.UUID, role string) error {
+	query := `UPDATE admin_users SET role = $2, updated_at = NOW() WHERE id = $1`
+
+	tag, err := r.db.Exec(ctx, query, adminID, role)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAdminNotFound
+	}
+
+	return nil
+}
+
+// CountActiveOwners counts active admin users with the owner role
+func (r *AdminRepository) CountActiveOwners(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx,
+		`SELECT COUNT(*) FROM admin_users WHERE role = 'owner' AND is_active = TRUE`,
+	).Scan(&count)
+	return count, err
 }
 
 // UpdateLastLogin updates the last login timestamp
@@ -110,15 +181,179 @@ func (r *AdminRepository) UpdateLastLogin(ctx context.Context, adminID uuid.UUID
 	return err
 }
 
-// VerifyPassword verifies if the provided password matches using pgcrypto
-func (r *AdminRepository) VerifyPassword(ctx context.Context, hashedPassword, plainPassword string) (bool, error) {
-	query := `SELECT $1 = crypt($2, $1)`
+// SetTOTPSecret stores a pending TOTP secret. 2FA stays disabled until the
+// first code is confirmed.
+func (r *AdminRepository) SetTOTPSecret(ctx context.Context, adminID uuid.UUID, encryptedSecret string) error {
+	query := `
+		UPDATE admin_users
+		SET totp_secret = $2, totp_enabled = FALSE, totp_confirmed_at = NULL,
+		    totp_last_step = NULL, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	_, err := r.db.Exec(ctx, query, adminID, encryptedSecret)
+	return err
+}
+
+// EnableTOTP marks the pending TOTP secret as confirmed
+func (r *AdminRepository) EnableTOTP(ctx context.Context, adminID uuid.UUID) error {
+	query := `
+		UPDATE admin_users
+		SET totp_enabled = TRUE, totp_confirmed_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND totp_secret IS NOT NULL
+	`
+
+	_, err := r.db.Exec(ctx, query, adminID)
+	return err
+}
+
+// DisableTOTP removes the TOTP secret and all recovery codes
+func (r *AdminRepository) DisableTOTP(ctx context.Context, adminID uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE admin_users
+		SET totp_secret = NULL, totp_enabled = FALSE, totp_confirmed_at = NULL,
+		    totp_last_step = NULL, updated_at = NOW()
+		WHERE id = $1
+	`, adminID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM admin_recovery_codes WHERE user_id = $1`, adminID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ClaimTOTPStep records the time step of an accepted code. It returns false if
+// the same or a later step was already used, which means the code is replayed.
+func (r *AdminRepository) ClaimTOTPStep(ctx context.Context, adminID uuid.UUID, step int64) (bool, error) {
+	query := `
+		UPDATE admin_users
+		SET totp_last_step = $2
+		WHERE id = $1 AND (totp_last_step IS NULL OR totp_last_step < $2)
+	`
+
+	tag, err := r.db.Exec(ctx, query, adminID, step)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// ReplaceRecoveryCodes deletes existing recovery codes and stores new hashes
+func (r *AdminRepository) ReplaceRecoveryCodes(ctx context.Context, adminID uuid.UUID, codeHashes []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM admin_recovery_codes WHERE user_id = $1`, adminID); err != nil {
+		return err
+	}
+
+	for _, hash := range codeHashes {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO admin_recovery_codes (user_id, code_hash) VALUES ($1, $2)`,
+			adminID, hash,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UseRecoveryCode marks an unused recovery code as used. It returns false if
+// the code does not exist or was already used.
+func (r *AdminRepository) UseRecoveryCode(ctx context.Context, adminID uuid.UUID, codeHash string) (bool, error) {
+	query := `
+		UPDATE admin_recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
 
-	var matches bool
-	err := r.db.QueryRow(ctx, query, hashedPassword, plainPassword).Scan(&matches)
+	tag, err := r.db.Exec(ctx, query, adminID, codeHash)
 	if err != nil {
 		return false, err
 	}
 
-	return matches, nil
+	return tag.RowsAffected() == 1, nil
+}
+
+// CountRecoveryCodes counts the unused recovery codes of an admin
+func (r *AdminRepository) CountRecoveryCodes(ctx context.Context, adminID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx,
+		`SELECT COUNT(*) FROM admin_recovery_codes WHERE user_id = $1 AND used_at IS NULL`,
+		adminID,
+	).Scan(&count)
+	return count, err
+}
+
+// insertAdmin inserts an admin user on the pool or inside a transaction
+func insertAdmin(ctx context.Context, db execer, admin *AdminUser) error {
+	if admin.ID == uuid.Nil {
+		admin.ID = uuid.New()
+	}
+
+	query := `
+		INSERT INTO admin_users (id, username, password_hash, email, role, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at, updated_at
+	`
+
+	err := db.QueryRow(ctx, query,
+		admin.ID,
+		admin.Username,
+		admin.PasswordHash,
+		admin.Email,
+		admin.Role,
+		admin.IsActive,
+	).Scan(&admin.CreatedAt, &admin.UpdatedAt)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrAdminExists
+		}
+		return fmt.Errorf("failed to create admin user: %w", err)
+	}
+
+	return nil
+}
+
+// Helper function to scan an admin user from a row
+func scanAdmin(row pgx.Row) (*AdminUser, error) {
+	var admin AdminUser
+	err := row.Scan(
+		&admin.ID,
+		&admin.Username,
+		&admin.PasswordHash,
+		&admin.Email,
+		&admin.Role,
+		&admin.IsActive,
+		&admin.TOTPSecret,
+		&admin.TOTPEnabled,
+		&admin.LastLoginAt,
+		&admin.CreatedAt,
+		&admin.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAdminNotFound
+		}
+		return nil, err
+	}
+
+	return &admin, nil
 }
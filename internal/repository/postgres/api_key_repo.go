@@ -0,0 +1,174 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrAPIKeyNotFound is returned when no API key matches
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// APIKey represents a scoped key for machine clients
+type APIKey struct {
+	ID         uuid.UUID
+	Name       string
+	Prefix     string // First characters of the key, shown to tell keys apart
+	KeyHash    string
+	Scopes     []string
+	RateLimit  *int // Requests per minute, nil uses the default
+	CreatedBy  *uuid.UUID
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	LastUsedIP string
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+// APIKeyRepository handles API key database operations
+type APIKeyRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db *pgxpool.Pool) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// Create stores a new API key
+func (r *APIKeyRepository) Create(ctx context.Context, key *APIKey) error {
+	if key.ID == uuid.Nil {
+		key.ID = uuid.New()
+	}
+
+	query := `
+		INSERT INTO api_keys (id, name, prefix, key_hash, scopes, rate_limit_per_minute, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		key.ID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		key.Scopes,
+		key.RateLimit,
+		key.CreatedBy,
+		key.ExpiresAt,
+	).Scan(&key.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return nil
+}
+
+// List lists all API keys, newest first
+func (r *APIKeyRepository) List(ctx context.Context) ([]*APIKey, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]*APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// FindByID finds an API key by ID
+func (r *APIKeyRepository) FindByID(ctx context.Context, id uuid.UUID) (*APIKey, error) {
+	return scanAPIKey(r.db.QueryRow(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = $1`, id))
+}
+
+// FindByHash finds an API key by the SHA-256 of the key
+func (r *APIKeyRepository) FindByHash(ctx context.Context, keyHash string) (*APIKey, error) {
+	return scanAPIKey(r.db.QueryRow(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`, keyHash))
+}
+
+// Revoke revokes an API key. Revoking an already revoked key is not an error.
+func (r *APIKeyRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, NOW()) WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// TouchLastUsed records when and from where a key was last used. Writes are
+// skipped while the stored time is less than a minute old.
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, ip string) error {
+	var addr *netip.Addr
+	if parsed, err := netip.ParseAddr(ip); err == nil {
+		addr = &parsed
+	}
+
+	_, err := r.db.Exec(ctx, `
+		UPDATE api_keys
+		SET last_used_at = NOW(), last_used_ip = $2
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+	`, id, addr)
+	if err != nil {
+		return fmt.Errorf("failed to update api key last used: %w", err)
+	}
+
+	return nil
+}
+
+// apiKeyColumns are the columns read by scanAPIKey
+const apiKeyColumns = `
+	id, name, prefix, key_hash, scopes, rate_limit_per_minute, created_by,
+	expires_at, last_used_at, host(last_used_ip), revoked_at, created_at
+`
+
+// Helper function to scan an API key from a row
+func scanAPIKey(row pgx.Row) (*APIKey, error) {
+	var key APIKey
+	var lastUsedIP *string
+
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&key.Scopes,
+		&key.RateLimit,
+		&key.CreatedBy,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&lastUsedIP,
+		&key.RevokedAt,
+		&key.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to scan api key: %w", err)
+	}
+
+	if lastUsedIP != nil {
+		key.LastUsedIP = *lastUsedIP
+	}
+
+	return &key, nil
+}
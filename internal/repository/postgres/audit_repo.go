@@ -0,0 +1,431 @@
+package postgres
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// auditChainLock is the advisory lock that serializes audit inserts so every
+// entry links to the one written before it
+const auditChainLock = 730_411_038
+
+// AuditLog represents an entry in the admin audit trail
+type AuditLog struct {
+	ID           uuid.UUID
+	UserID       *uuid.UUID // Acting admin, nil for system events
+	Username     string     // Acting admin's username, filled when listing
+	Action       string
+	ResourceType string
+	ResourceID   *uuid.UUID
+	Changes      map[string]interface{}
+	IPAddress    string
+	UserAgent    string
+	Sequence     int64
+	PrevHash     string
+	Hash         string // Empty for entries written before the hash chain
+	CreatedAt    time.Time
+	changesJSON  []byte // Changes as hashed
+}
+
+// AuditLogFilter narrows an audit log listing. Zero values are ignored.
+type AuditLogFilter struct {
+	UserID       *uuid.UUID
+	Action       string
+	ResourceType string
+	ResourceID   *uuid.UUID
+	StartDate    *time.Time
+	EndDate      *time.Time
+	Page         int
+	Limit        int
+}
+
+// AuditLogList is a page of audit log entries
+type AuditLogList struct {
+	Logs       []*AuditLog
+	Total      int64
+	Page       int
+	Limit      int
+	TotalPages int
+}
+
+// AuditChainReport is the result of verifying the audit hash chain
+type AuditChainReport struct {
+	Valid        bool
+	Checked      int64  // Hashed entries verified
+	Unhashed     int64  // Entries written before the chain existed
+	BrokenAt     *int64 // Sequence of the first entry that failed
+	Reason       string
+	HeadSequence int64
+	HeadHash     string
+}
+
+// AuditRepository handles audit log database operations
+type AuditRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db *pgxpool.Pool) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Create appends an audit log entry to the hash chain
+func (r *AuditRepository) Create(ctx context.Context, log *AuditLog) error {
+	if log.ID == uuid.Nil {
+		log.ID = uuid.New()
+	}
+
+	changes, err := canonicalChanges(log.Changes)
+	if err != nil {
+		return err
+	}
+	log.changesJSON = changes
+
+	var ip *netip.Addr
+	if addr, err := netip.ParseAddr(log.IPAddress); err == nil {
+		addr = addr.Unmap()
+		ip = &addr
+		log.IPAddress = addr.String()
+	} else {
+		log.IPAddress = ""
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, auditChainLock); err != nil {
+		return fmt.Errorf("failed to lock audit chain: %w", err)
+	}
+
+	var prevHash *string
+	err = tx.QueryRow(ctx, `SELECT sequence, hash FROM audit_logs ORDER BY sequence DESC LIMIT 1`).Scan(&log.Sequence, &prevHash)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to read audit chain head: %w", err)
+	}
+
+	log.Sequence++
+	log.PrevHash = ""
+	if prevHash != nil {
+		log.PrevHash = *prevHash
+	}
+	// Postgres keeps microseconds, so truncate before hashing
+	log.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	log.Hash = log.computeHash()
+
+	query := `
+		INSERT INTO audit_logs (
+			id, user_id, action, resource_type, resource_id, changes, ip_address, user_agent,
+			sequence, prev_hash, hash, created_at
+		) VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, NULLIF($8, ''), $9, NULLIF($10, ''), $11, $12)
+	`
+
+	_, err = tx.Exec(ctx, query,
+		log.ID,
+		log.UserID,
+		log.Action,
+		log.ResourceType,
+		log.ResourceID,
+		changes,
+		ip,
+		log.UserAgent,
+		log.Sequence,
+		log.PrevHash,
+		log.Hash,
+		log.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// auditColumns are the columns read by scanAuditLog
+const auditColumns = `
+	a.id, a.user_id, COALESCE(u.username, ''), a.action, COALESCE(a.resource_type, ''), a.resource_id,
+	a.changes, COALESCE(host(a.ip_address), ''), COALESCE(a.user_agent, ''),
+	a.sequence, COALESCE(a.prev_hash, ''), COALESCE(a.hash, ''), a.created_at
+`
+
+// List lists audit log entries, newest first
+func (r *AuditRepository) List(ctx context.Context, filter AuditLogFilter) (*AuditLogList, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 50
+	}
+
+	offset := (filter.Page - 1) * filter.Limit
+
+	where := ` WHERE 1=1`
+	args := []interface{}{}
+	argCount := 1
+
+	if filter.UserID != nil {
+		where += fmt.Sprintf(" AND a.user_id = $%d", argCount)
+		args = append(args, *filter.UserID)
+		argCount++
+	}
+
+	if filter.Action != "" {
+		where += fmt.Sprintf(" AND a.action = $%d", argCount)
+		args = append(args, filter.Action)
+		argCount++
+	}
+
+	if filter.ResourceType != "" {
+		where += fmt.Sprintf(" AND a.resource_type = $%d", argCount)
+		args = append(args, filter.ResourceType)
+		argCount++
+	}
+
+	if filter.ResourceID != nil {
+		where += fmt.Sprintf(" AND a.resource_id = $%d", argCount)
+		args = append(args, *filter.ResourceID)
+		argCount++
+	}
+
+	if filter.StartDate != nil {
+		where += fmt.Sprintf(" AND a.created_at >= $%d", argCount)
+		args = append(args, *filter.StartDate)
+		argCount++
+	}
+
+	if filter.EndDate != nil {
+		where += fmt.Sprintf(" AND a.created_at <= $%d", argCount)
+		args = append(args, *filter.EndDate)
+		argCount++
+	}
+
+	var total int64
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM audit_logs a`+where, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count audit logs: %w", err)
+	}
+
+	query := `SELECT ` + auditColumns + ` FROM audit_logs a LEFT JOIN admin_users u ON u.id = a.user_id` + where
+	query += fmt.Sprintf(" ORDER BY a.sequence DESC LIMIT $%d OFFSET $%d", argCount, argCount+1)
+	args = append(args, filter.Limit, offset)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	logs := make([]*AuditLog, 0)
+	for rows.Next() {
+		log, err := scanAuditLog(rows)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, log)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit logs: %w", err)
+	}
+
+	totalPages := int(total) / filter.Limit
+	if int(total)%filter.Limit > 0 {
+		totalPages++
+	}
+
+	return &AuditLogList{
+		Logs:       logs,
+		Total:      total,
+		Page:       filter.Page,
+		Limit:      filter.Limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// VerifyChain walks the whole audit trail in order and recomputes every hash.
+// A gap in the sequence means an entry was deleted; a hash mismatch means an
+// entry was edited. Removing entries from the end of the chain can only be
+// caught by comparing HeadSequence and HeadHash with a previously saved report.
+func (r *AuditRepository) VerifyChain(ctx context.Context) (*AuditChainReport, error) {
+	query := `SELECT ` + auditColumns + ` FROM audit_logs a LEFT JOIN admin_users u ON u.id = a.user_id ORDER BY a.sequence`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit chain: %w", err)
+	}
+	defer rows.Close()
+
+	report := &AuditChainReport{Valid: true}
+	var prev *AuditLog
+
+	for rows.Next() {
+		log, err := scanAuditLog(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		if reason := verifyLink(prev, log); reason != "" {
+			report.Valid = false
+			report.BrokenAt = &log.Sequence
+			report.Reason = reason
+			break
+		}
+
+		if log.Hash == "" {
+			report.Unhashed++
+		} else {
+			report.Checked++
+		}
+		report.HeadSequence = log.Sequence
+		report.HeadHash = log.Hash
+		prev = log
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit chain: %w", err)
+	}
+
+	return report, nil
+}
+
+// verifyLink checks an entry against the one before it and returns why it
+// does not belong in the chain, or an empty string
+func verifyLink(prev, log *AuditLog) string {
+	expectedSequence := int64(1)
+	if prev != nil {
+		expectedSequence = prev.Sequence + 1
+	}
+	if log.Sequence != expectedSequence {
+		return fmt.Sprintf("expected sequence %d, found %d: entries were deleted", expectedSequence, log.Sequence)
+	}
+
+	if log.Hash == "" {
+		if prev != nil && prev.Hash != "" {
+			return "entry has no hash after the chain started"
+		}
+		return ""
+	}
+
+	expectedPrev := ""
+	if prev != nil {
+		expectedPrev = prev.Hash
+	}
+	if log.PrevHash != expectedPrev {
+		return "previous hash does not match the entry before it"
+	}
+
+	if log.computeHash() != log.Hash {
+		return "entry contents do not match its hash"
+	}
+
+	return ""
+}
+
+// computeHash hashes the entry together with the previous hash. Fields are
+// encoded as a JSON array so no value can be shifted into its neighbour.
+func (l *AuditLog) computeHash() string {
+	userID := ""
+	if l.UserID != nil {
+		userID = l.UserID.String()
+	}
+	resourceID := ""
+	if l.ResourceID != nil {
+		resourceID = l.ResourceID.String()
+	}
+
+	payload, _ := json.Marshal([]string{
+		l.PrevHash,
+		strconv.FormatInt(l.Sequence, 10),
+		l.ID.String(),
+		userID,
+		l.Action,
+		l.ResourceType,
+		resourceID,
+		string(l.changesJSON),
+		l.IPAddress,
+		l.UserAgent,
+		strconv.FormatInt(l.CreatedAt.UnixMicro(), 10),
+	})
+
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// canonicalChanges encodes changes the same way before storing and after
+// reading them back from JSONB. Decoding into generic values and encoding
+// again sorts object keys and normalizes numbers, which JSONB may reformat.
+func canonicalChanges(changes map[string]interface{}) ([]byte, error) {
+	if changes == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit changes: %w", err)
+	}
+
+	return canonicalJSON(data)
+}
+
+func canonicalJSON(data []byte) ([]byte, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("failed to decode audit changes: %w", err)
+	}
+
+	return json.Marshal(value)
+}
+
+// Helper function to scan an audit log from a row
+func scanAuditLog(row pgx.Row) (*AuditLog, error) {
+	var log AuditLog
+	var changes []byte
+
+	err := row.Scan(
+		&log.ID,
+		&log.UserID,
+		&log.Username,
+		&log.Action,
+		&log.ResourceType,
+		&log.ResourceID,
+		&changes,
+		&log.IPAddress,
+		&log.UserAgent,
+		&log.Sequence,
+		&log.PrevHash,
+		&log.Hash,
+		&log.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan audit log: %w", err)
+	}
+
+	log.changesJSON, err = canonicalJSON(changes)
+	if err != nil {
+		return nil, err
+	}
+
+	if changes != nil {
+		if err := json.Unmarshal(changes, &log.Changes); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal audit changes: %w", err)
+		}
+	}
+
+	return &log, nil
+}
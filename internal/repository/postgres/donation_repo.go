@@ -12,6 +12,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/reveegate/reveegate/internal/domain/donation"
+	"github.com/reveegate/reveegate/internal/domain/payment"
 )
 
 // DonationRepository implements donation.Repository using PostgreSQL
@@ -103,6 +104,55 @@ func (r *DonationRepository) Update(ctx context.Context, d *donation.Donation) e
 	return nil
 }
 
+// Complete saves a donation marked as paid together with the payment that
+// paid it, in one transaction, unless the donation is already completed. It
+// returns false if another payment completed the donation first, in which
+// case neither is saved.
+func (r *DonationRepository) Complete(ctx context.Context, d *donation.Donation, p *payment.Payment) (bool, error) {
+	metadata, err := json.Marshal(d.Metadata)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	paymentArgs, err := paymentUpdateArgs(p)
+	if err != nil {
+		return false, err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE donations
+		SET status = 'completed', metadata = $2, paid_at = $3, updated_at = NOW()
+		WHERE id = $1 AND status <> 'completed'
+	`
+
+	tag, err := tx.Exec(ctx, query, d.ID, metadata, d.PaidAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to complete donation: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	tag, err = tx.Exec(ctx, paymentUpdateQuery, paymentArgs...)
+	if err != nil {
+		return false, fmt.Errorf("failed to update payment: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, errors.New("payment not found")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit donation: %w", err)
+	}
+
+	return true, nil
+}
+
 // List lists donations with filtering and pagination
 func (r *DonationRepository) List(ctx context.Context, params donation.ListDonationsParams) (*donation.ListDonationsResult, error) {
 	// Set defaults
@@ -217,6 +267,81 @@ func (r *DonationRepository) GetPendingExpired(ctx context.Context, before time.
 	return donations, nil
 }
 
+// CountPendingByClientIP counts pending donations created from an IP since a time
+func (r *DonationRepository) CountPendingByClientIP(ctx context.Context, ip string, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM donations
+		WHERE status = 'pending' AND metadata->>'client_ip' = $1 AND created_at > $2
+	`
+
+	var count int
+	if err := r.pool.QueryRow(ctx, query, ip, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count pending donations by ip: %w", err)
+	}
+
+	return count, nil
+}
+
+// donorIdentity tells donors apart by email, or by name and IP when they
+// gave no email. Arguments $2, $3 and $4 hold the same for the donor asking.
+const (
+	donorIdentity      = `COALESCE(NULLIF(lower(donor_email), ''), lower(donor_name) || '@' || COALESCE(metadata->>'client_ip', ''))`
+	donorIdentityParam = `COALESCE(NULLIF(lower($2), ''), lower($3) || '@' || $4)`
+)
+
+// CountOtherDonorsWithMessage counts donors other than the given one who sent
+// the same message (ignoring case and surrounding space) since a time
+func (r *DonationRepository) CountOtherDonorsWithMessage(ctx context.Context, message, donorEmail, donorName, clientIP string, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(DISTINCT ` + donorIdentity + `)
+		FROM donations
+		WHERE message IS NOT NULL AND message <> ''
+		  AND md5(lower(btrim(message))) = md5(lower(btrim($1)))
+		  AND created_at > $5
+		  AND ` + donorIdentity + ` <> ` + donorIdentityParam
+
+	var count int
+	if err := r.pool.QueryRow(ctx, query, message, donorEmail, donorName, clientIP, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count donors with message: %w", err)
+	}
+
+	return count, nil
+}
+
+// CountByDonor counts donations from a donor since a time
+func (r *DonationRepository) CountByDonor(ctx context.Context, donorEmail, donorName, clientIP string, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM donations
+		WHERE created_at > $1 AND ` + donorIdentity + ` = ` + donorIdentityParam
+
+	var count int
+	if err := r.pool.QueryRow(ctx, query, since, donorEmail, donorName, clientIP).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count donations by donor: %w", err)
+	}
+
+	return count, nil
+}
+
+// HasCompletedDonation checks if a donor has completed a donation before,
+// other than the given one
+func (r *DonationRepository) HasCompletedDonation(ctx context.Context, donorEmail, donorName, clientIP string, exclude uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM donations
+			WHERE status = 'completed' AND id <> $1 AND ` + donorIdentity + ` = ` + donorIdentityParam + `
+		)
+	`
+
+	var exists bool
+	if err := r.pool.QueryRow(ctx, query, exclude, donorEmail, donorName, clientIP).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check donor history: %w", err)
+	}
+
+	return exists, nil
+}
+
 // UpdateStatus updates the status of a donation
 func (r *DonationRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status donation.Status) error {
 	query := `UPDATE donations SET status = $2, updated_at = NOW() WHERE id = $1`
@@ -236,15 +361,31 @@ func (r *DonationRepository) UpdateStatus(ctx context.Context, id uuid.UUID, sta
 // GetStats gets donation statistics for a date range
 func (r *DonationRepository) GetStats(ctx context.Context, startDate, endDate time.Time) (*donation.DonationStats, error) {
 	query := `
-		SELECT 
-			COUNT(*) as total_donations,
-			COALESCE(SUM(amount), 0) as total_amount,
-			COALESCE(AVG(amount), 0) as average_amount,
-			COUNT(*) FILTER (WHERE status = 'completed') as completed_count,
-			COUNT(*) FILTER (WHERE status = 'pending') as pending_count,
-			COUNT(*) FILTER (WHERE status = 'failed') as failed_count
-		FROM donations
-		WHERE created_at >= $1 AND created_at <= $2
+		SELECT
+			d.total_donations, d.total_amount, d.average_amount,
+			d.completed_count, d.completed_amount, d.pending_count, d.failed_count,
+			p.gross_amount, p.total_fees, p.net_amount
+		FROM (
+			SELECT
+				COUNT(*) as total_donations,
+				COALESCE(SUM(amount), 0) as total_amount,
+				COALESCE(AVG(amount), 0) as average_amount,
+				COUNT(*) FILTER (WHERE status = 'completed') as completed_count,
+				COALESCE(SUM(amount) FILTER (WHERE status = 'completed'), 0) as completed_amount,
+				COUNT(*) FILTER (WHERE status = 'pending') as pending_count,
+				COUNT(*) FILTER (WHERE status = 'failed') as failed_count
+			FROM donations
+			WHERE created_at >= $1 AND created_at <= $2
+		) d
+		CROSS JOIN (
+			SELECT
+				COALESCE(SUM(p.amount), 0) as gross_amount,
+				COALESCE(SUM(p.fee_amount), 0) as total_fees,
+				COALESCE(SUM(p.net_amount), 0) as net_amount
+			FROM payments p
+			JOIN donations dn ON dn.id = p.donation_id
+			WHERE p.status = 'paid' AND dn.created_at >= $1 AND dn.created_at <= $2
+		) p
 	`
 
 	var stats donation.DonationStats
@@ -253,14 +394,20 @@ func (r *DonationRepository) GetStats(ctx context.Context, startDate, endDate ti
 		&stats.TotalAmount,
 		&stats.AverageAmount,
 		&stats.CompletedCount,
+		&stats.CompletedAmount,
 		&stats.PendingCount,
 		&stats.FailedCount,
+		&stats.GrossAmount,
+		&stats.TotalFees,
+		&stats.NetAmount,
 	)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get donation stats: %w", err)
 	}
 
+	stats.CompletedDonations = stats.CompletedCount
+
 	return &stats, nil
 }
 
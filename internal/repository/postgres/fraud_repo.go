@@ -0,0 +1,405 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/reveegate/reveegate/internal/domain/fraud"
+)
+
+// Fraud errors
+var (
+	ErrBlockNotFound    = errors.New("block not found")
+	ErrBlockExists      = errors.New("block already exists")
+	ErrRuleNotFound     = errors.New("fraud rule not found")
+	ErrDecisionNotFound = errors.New("fraud decision not found")
+)
+
+// FraudDecisionFilter holds filters for listing fraud decisions
+type FraudDecisionFilter struct {
+	Stage        string
+	Action       string
+	ReviewStatus string
+	DonationID   *uuid.UUID
+	Page         int
+	Limit        int
+}
+
+// FraudDecisionList is a page of fraud decisions
+type FraudDecisionList struct {
+	Decisions  []*fraud.Decision
+	Total      int64
+	Page       int
+	Limit      int
+	TotalPages int
+}
+
+// FraudRepository handles donor blocks, fraud rules and the decision log
+type FraudRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewFraudRepository creates a new fraud repository
+func NewFraudRepository(db *pgxpool.Pool) *FraudRepository {
+	return &FraudRepository{db: db}
+}
+
+// ListBlocks lists all blocks, newest first
+func (r *FraudRepository) ListBlocks(ctx context.Context) ([]*fraud.Block, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, type, value, COALESCE(reason, ''), created_by, expires_at, created_at
+		FROM donor_blocks
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blocks: %w", err)
+	}
+	defer rows.Close()
+
+	blocks := make([]*fraud.Block, 0)
+	for rows.Next() {
+		var b fraud.Block
+		if err := rows.Scan(&b.ID, &b.Type, &b.Value, &b.Reason, &b.CreatedBy, &b.ExpiresAt, &b.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan block: %w", err)
+		}
+		blocks = append(blocks, &b)
+	}
+
+	return blocks, rows.Err()
+}
+
+// CreateBlock stores a new block
+func (r *FraudRepository) CreateBlock(ctx context.Context, b *fraud.Block) error {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO donor_blocks (id, type, value, reason, created_by, expires_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6)
+		RETURNING created_at
+	`, b.ID, b.Type, b.Value, b.Reason, b.CreatedBy, b.ExpiresAt).Scan(&b.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrBlockExists
+		}
+		return fmt.Errorf("failed to create block: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteBlock deletes a block and returns it
+func (r *FraudRepository) DeleteBlock(ctx context.Context, id uuid.UUID) (*fraud.Block, error) {
+	var b fraud.Block
+	err := r.db.QueryRow(ctx, `
+		DELETE FROM donor_blocks WHERE id = $1
+		RETURNING id, type, value, COALESCE(reason, ''), created_by, expires_at, created_at
+	`, id).Scan(&b.ID, &b.Type, &b.Value, &b.Reason, &b.CreatedBy, &b.ExpiresAt, &b.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrBlockNotFound
+		}
+		return nil, fmt.Errorf("failed to delete block: %w", err)
+	}
+
+	return &b, nil
+}
+
+// ListRules lists all rules, oldest first
+func (r *FraudRepository) ListRules(ctx context.Context) ([]*fraud.Rule, error) {
+	return r.queryRules(ctx, `SELECT `+fraudRuleColumns+` FROM fraud_rules ORDER BY created_at`)
+}
+
+// ListEnabledRules lists the enabled rules of a stage
+func (r *FraudRepository) ListEnabledRules(ctx context.Context, stage fraud.Stage) ([]*fraud.Rule, error) {
+	return r.queryRules(ctx, `SELECT `+fraudRuleColumns+` FROM fraud_rules WHERE enabled AND stage = $1 ORDER BY created_at`, stage)
+}
+
+// GetRule gets a rule by ID
+func (r *FraudRepository) GetRule(ctx context.Context, id uuid.UUID) (*fraud.Rule, error) {
+	return scanFraudRule(r.db.QueryRow(ctx, `SELECT `+fraudRuleColumns+` FROM fraud_rules WHERE id = $1`, id))
+}
+
+// CreateRule stores a new rule
+func (r *FraudRepository) CreateRule(ctx context.Context, rule *fraud.Rule) error {
+	if rule.ID == uuid.Nil {
+		rule.ID = uuid.New()
+	}
+
+	params, err := json.Marshal(rule.Params)
+	if err != nil {
+		return fmt.Errorf("failed to marshal rule params: %w", err)
+	}
+
+	err = r.db.QueryRow(ctx, `
+		INSERT INTO fraud_rules (id, name, stage, condition, params, action, enabled, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at, updated_at
+	`, rule.ID, rule.Name, rule.Stage, rule.Condition, params, rule.Action, rule.Enabled, rule.CreatedBy,
+	).Scan(&rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create fraud rule: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateRule saves changes to a rule
+func (r *FraudRepository) UpdateRule(ctx context.Context, rule *fraud.Rule) error {
+	params, err := json.Marshal(rule.Params)
+	if err != nil {
+		return fmt.Errorf("failed to marshal rule params: %w", err)
+	}
+
+	err = r.db.QueryRow(ctx, `
+		UPDATE fraud_rules
+		SET name = $2, stage = $3, condition = $4, params = $5, action = $6, enabled = $7, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`, rule.ID, rule.Name, rule.Stage, rule.Condition, params, rule.Action, rule.Enabled).Scan(&rule.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrRuleNotFound
+		}
+		return fmt.Errorf("failed to update fraud rule: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteRule deletes a rule
+func (r *FraudRepository) DeleteRule(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM fraud_rules WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete fraud rule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrRuleNotFound
+	}
+	return nil
+}
+
+// CreateDecision stores a decision in the log
+func (r *FraudRepository) CreateDecision(ctx context.Context, d *fraud.Decision) error {
+	matches, err := json.Marshal(d.Matches)
+	if err != nil {
+		return fmt.Errorf("failed to marshal decision matches: %w", err)
+	}
+	subject, err := json.Marshal(d.Subject)
+	if err != nil {
+		return fmt.Errorf("failed to marshal decision subject: %w", err)
+	}
+
+	err = r.db.QueryRow(ctx, `
+		INSERT INTO fraud_decisions (id, stage, action, donation_id, payment_id, matches, subject, review_status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''))
+		RETURNING created_at
+	`, d.ID, d.Stage, d.Action, d.Subject.DonationID, d.Subject.PaymentID, matches, subject, d.ReviewStatus,
+	).Scan(&d.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create fraud decision: %w", err)
+	}
+
+	return nil
+}
+
+// GetDecision gets a decision by ID
+func (r *FraudRepository) GetDecision(ctx context.Context, id uuid.UUID) (*fraud.Decision, error) {
+	return scanFraudDecision(r.db.QueryRow(ctx, `SELECT `+fraudDecisionColumns+` FROM fraud_decisions WHERE id = $1`, id))
+}
+
+// ListDecisions lists decisions with filters, newest first
+func (r *FraudRepository) ListDecisions(ctx context.Context, filter FraudDecisionFilter) (*FraudDecisionList, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 {
+		filter.Limit = 50
+	}
+	if filter.Limit > 100 {
+		filter.Limit = 100
+	}
+
+	where := "WHERE 1=1"
+	args := []interface{}{}
+	argCount := 0
+
+	if filter.Stage != "" {
+		argCount++
+		where += fmt.Sprintf(" AND stage = $%d", argCount)
+		args = append(args, filter.Stage)
+	}
+	if filter.Action != "" {
+		argCount++
+		where += fmt.Sprintf(" AND action = $%d", argCount)
+		args = append(args, filter.Action)
+	}
+	if filter.ReviewStatus != "" {
+		argCount++
+		where += fmt.Sprintf(" AND review_status = $%d", argCount)
+		args = append(args, filter.ReviewStatus)
+	}
+	if filter.DonationID != nil {
+		argCount++
+		where += fmt.Sprintf(" AND donation_id = $%d", argCount)
+		args = append(args, *filter.DonationID)
+	}
+
+	var total int64
+	if err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM fraud_decisions "+where, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count fraud decisions: %w", err)
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM fraud_decisions %s ORDER BY created_at DESC LIMIT $%d OFFSET $%d`,
+		fraudDecisionColumns, where, argCount+1, argCount+2)
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fraud decisions: %w", err)
+	}
+	defer rows.Close()
+
+	decisions := make([]*fraud.Decision, 0)
+	for rows.Next() {
+		d, err := scanFraudDecision(rows)
+		if err != nil {
+			return nil, err
+		}
+		decisions = append(decisions, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list fraud decisions: %w", err)
+	}
+
+	totalPages := int(total) / filter.Limit
+	if int(total)%filter.Limit > 0 {
+		totalPages++
+	}
+
+	return &FraudDecisionList{
+		Decisions:  decisions,
+		Total:      total,
+		Page:       filter.Page,
+		Limit:      filter.Limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// Review records the outcome of reviewing a pending decision. Decisions
+// that are not pending are left alone and ErrDecisionNotFound is returned.
+func (r *FraudRepository) Review(ctx context.Context, id uuid.UUID, status string, reviewer *uuid.UUID) (*fraud.Decision, error) {
+	d, err := scanFraudDecision(r.db.QueryRow(ctx, `
+		UPDATE fraud_decisions
+		SET review_status = $2, reviewed_by = $3, reviewed_at = NOW()
+		WHERE id = $1 AND review_status = 'pending'
+		RETURNING `+fraudDecisionColumns,
+		id, status, reviewer,
+	))
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// fraudRuleColumns are the columns read by scanFraudRule
+const fraudRuleColumns = `id, name, stage, condition, params, action, enabled, created_by, created_at, updated_at`
+
+// Helper function to scan a fraud rule from a row
+func scanFraudRule(row pgx.Row) (*fraud.Rule, error) {
+	var rule fraud.Rule
+	var params []byte
+
+	err := row.Scan(
+		&rule.ID,
+		&rule.Name,
+		&rule.Stage,
+		&rule.Condition,
+		&params,
+		&rule.Action,
+		&rule.Enabled,
+		&rule.CreatedBy,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRuleNotFound
+		}
+		return nil, fmt.Errorf("failed to scan fraud rule: %w", err)
+	}
+
+	if err := json.Unmarshal(params, &rule.Params); err != nil {
+		return nil, fmt.Errorf("failed to decode fraud rule params: %w", err)
+	}
+
+	return &rule, nil
+}
+
+// queryRules runs a query returning fraud rules
+func (r *FraudRepository) queryRules(ctx context.Context, query string, args ...interface{}) ([]*fraud.Rule, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fraud rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := make([]*fraud.Rule, 0)
+	for rows.Next() {
+		rule, err := scanFraudRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+// fraudDecisionColumns are the columns read by scanFraudDecision
+const fraudDecisionColumns = `id, stage, action, matches, subject, COALESCE(review_status, ''), reviewed_by, reviewed_at, created_at`
+
+// Helper function to scan a fraud decision from a row
+func scanFraudDecision(row pgx.Row) (*fraud.Decision, error) {
+	var d fraud.Decision
+	var matches, subject []byte
+	var reviewedAt *time.Time
+
+	err := row.Scan(
+		&d.ID,
+		&d.Stage,
+		&d.Action,
+		&matches,
+		&subject,
+		&d.ReviewStatus,
+		&d.ReviewedBy,
+		&reviewedAt,
+		&d.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDecisionNotFound
+		}
+		return nil, fmt.Errorf("failed to scan fraud decision: %w", err)
+	}
+	d.ReviewedAt = reviewedAt
+
+	if err := json.Unmarshal(matches, &d.Matches); err != nil {
+		return nil, fmt.Errorf("failed to decode fraud decision matches: %w", err)
+	}
+	if err := json.Unmarshal(subject, &d.Subject); err != nil {
+		return nil, fmt.Errorf("failed to decode fraud decision subject: %w", err)
+	}
+
+	return &d, nil
+}
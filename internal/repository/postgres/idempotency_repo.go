@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrIdempotencyKeyNotFound is returned when no record exists for a key
+var ErrIdempotencyKeyNotFound = errors.New("idempotency key not found")
+
+// Idempotency key states
+const (
+	IdempotencyStatusProcessing = "processing"
+	IdempotencyStatusCompleted  = "completed"
+)
+
+// IdempotencyKey is a client supplied Idempotency-Key and the response it produced
+type IdempotencyKey struct {
+	Scope          string
+	Key            string
+	Fingerprint    string
+	Status         string
+	LockedUntil    *time.Time
+	ResponseStatus *int
+	ResponseBody   []byte
+	ResourceID     *uuid.UUID
+	CreatedAt      time.Time
+	CompletedAt    *time.Time
+	ExpiresAt      time.Time
+}
+
+// IdempotencyRepository handles idempotency key database operations
+type IdempotencyRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewIdempotencyRepository creates a new idempotency key repository
+func NewIdempotencyRepository(db *pgxpool.Pool) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Acquire claims a key for processing and reports whether the caller owns
+// it. An expired record is replaced, and a processing record whose lock has
+// passed is taken over when the fingerprint matches.
+func (r *IdempotencyRepository) Acquire(ctx context.Context, scope, key, fingerprint string, lock, ttl time.Duration) (bool, error) {
+	query := `
+		INSERT INTO idempotency_keys (scope, key, fingerprint, status, locked_until, expires_at)
+		VALUES ($1, $2, $3, 'processing', NOW() + $4 * INTERVAL '1 millisecond', NOW() + $5 * INTERVAL '1 millisecond')
+		ON CONFLICT (scope, key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint,
+		    status = 'processing',
+		    locked_until = EXCLUDED.locked_until,
+		    response_status = NULL,
+		    response_body = NULL,
+		    resource_id = NULL,
+		    created_at = NOW(),
+		    completed_at = NULL,
+		    expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at < NOW()
+		   OR (idempotency_keys.status = 'processing'
+		       AND idempotency_keys.locked_until < NOW()
+		       AND idempotency_keys.fingerprint = EXCLUDED.fingerprint)
+		RETURNING true
+	`
+
+	var acquired bool
+	err := r.db.QueryRow(ctx, query, scope, key, fingerprint, lock.Milliseconds(), ttl.Milliseconds()).Scan(&acquired)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to acquire idempotency key: %w", err)
+	}
+
+	return acquired, nil
+}
+
+// Get gets the record for a key
+func (r *IdempotencyRepository) Get(ctx context.Context, scope, key string) (*IdempotencyKey, error) {
+	query := `
+		SELECT scope, key, fingerprint, status, locked_until, response_status, response_body,
+		       resource_id, created_at, completed_at, expires_at
+		FROM idempotency_keys
+		WHERE scope = $1 AND key = $2
+	`
+
+	var k IdempotencyKey
+	err := r.db.QueryRow(ctx, query, scope, key).Scan(
+		&k.Scope,
+		&k.Key,
+		&k.Fingerprint,
+		&k.Status,
+		&k.LockedUntil,
+		&k.ResponseStatus,
+		&k.ResponseBody,
+		&k.ResourceID,
+		&k.CreatedAt,
+		&k.CompletedAt,
+		&k.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrIdempotencyKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	return &k, nil
+}
+
+// Complete stores the response of a processing key
+func (r *IdempotencyRepository) Complete(ctx context.Context, scope, key string, status int, body []byte, resourceID *uuid.UUID) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status = 'completed', locked_until = NULL, response_status = $3, response_body = $4,
+		    resource_id = $5, completed_at = NOW()
+		WHERE scope = $1 AND key = $2 AND status = 'processing'
+	`
+
+	tag, err := r.db.Exec(ctx, query, scope, key, status, body, resourceID)
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrIdempotencyKeyNotFound
+	}
+
+	return nil
+}
+
+// Release deletes a processing key so the request can be retried
+func (r *IdempotencyRepository) Release(ctx context.Context, scope, key string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2 AND status = 'processing'`, scope, key)
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired deletes expired keys and returns how many were removed
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at < NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrInvitationNotFound is returned when no pending invitation matches a token
+var ErrInvitationNotFound = errors.New("invitation not found")
+
+// AdminInvitation represents a pending invitation for a new admin
+type AdminInvitation struct {
+	ID         uuid.UUID
+	Email      string
+	Role       string
+	TokenHash  string
+	InvitedBy  uuid.UUID
+	ExpiresAt  time.Time
+	AcceptedAt *time.Time
+	CreatedAt  time.Time
+}
+
+// InvitationRepository handles admin invitation database operations
+type InvitationRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewInvitationRepository creates a new invitation repository
+func NewInvitationRepository(db *pgxpool.Pool) *InvitationRepository {
+	return &InvitationRepository{db: db}
+}
+
+// Create stores a new invitation
+func (r *InvitationRepository) Create(ctx context.Context, inv *AdminInvitation) error {
+	if inv.ID == uuid.Nil {
+		inv.ID = uuid.New()
+	}
+
+	query := `
+		INSERT INTO admin_invitations (id, email, role, token_hash, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		inv.ID,
+		inv.Email,
+		inv.Role,
+		inv.TokenHash,
+		inv.InvitedBy,
+		inv.ExpiresAt,
+	).Scan(&inv.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	return nil
+}
+
+// FindPendingByTokenHash finds an unaccepted, unexpired invitation
+func (r *InvitationRepository) FindPendingByTokenHash(ctx context.Context, tokenHash string) (*AdminInvitation, error) {
+	query := `
+		SELECT id, email, role, token_hash, invited_by, expires_at, accepted_at, created_at
+		FROM admin_invitations
+		WHERE token_hash = $1 AND accepted_at IS NULL AND expires_at > NOW()
+	`
+
+	var inv AdminInvitation
+	var invitedBy *uuid.UUID
+	err := r.db.QueryRow(ctx, query, tokenHash).Scan(
+		&inv.ID,
+		&inv.Email,
+		&inv.Role,
+		&inv.TokenHash,
+		&invitedBy,
+		&inv.ExpiresAt,
+		&inv.AcceptedAt,
+		&inv.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+
+	if invitedBy != nil {
+		inv.InvitedBy = *invitedBy
+	}
+
+	return &inv, nil
+}
+
+// Accept creates the invited admin and marks the invitation as used in one transaction
+func (r *InvitationRepository) Accept(ctx context.Context, inv *AdminInvitation, admin *AdminUser) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := insertAdmin(ctx, tx, admin); err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE admin_invitations
+		SET accepted_at = NOW(), accepted_user_id = $2
+		WHERE id = $1 AND accepted_at IS NULL AND expires_at > NOW()
+	`, inv.ID, admin.ID)
+	if err != nil {
+		return fmt.Errorf("failed to accept invitation: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrInvitationNotFound
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/reveegate/reveegate/internal/domain/payment"
+)
+
+// PaymentMethodRepository implements payment.MethodRepository using PostgreSQL
+type PaymentMethodRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPaymentMethodRepository creates a new payment method repository
+func NewPaymentMethodRepository(pool *pgxpool.Pool) *PaymentMethodRepository {
+	return &PaymentMethodRepository{pool: pool}
+}
+
+// List lists all payment methods in display order
+func (r *PaymentMethodRepository) List(ctx context.Context) ([]*payment.MethodConfig, error) {
+	query := `
+		SELECT method, display_name, category, min_amount, max_amount, enabled,
+		       display_order, maintenance_windows, updated_at
+		FROM payment_methods
+		ORDER BY display_order, method
+	`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payment methods: %w", err)
+	}
+	defer rows.Close()
+
+	methods := make([]*payment.MethodConfig, 0)
+	for rows.Next() {
+		m, err := r.scanMethod(rows)
+		if err != nil {
+			return nil, err
+		}
+		methods = append(methods, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating payment methods: %w", err)
+	}
+
+	return methods, nil
+}
+
+// GetByMethod gets a payment method configuration
+func (r *PaymentMethodRepository) GetByMethod(ctx context.Context, method payment.Method) (*payment.MethodConfig, error) {
+	query := `
+		SELECT method, display_name, category, min_amount, max_amount, enabled,
+		       display_order, maintenance_windows, updated_at
+		FROM payment_methods
+		WHERE method = $1
+	`
+
+	m, err := r.scanMethod(r.pool.QueryRow(ctx, query, string(method)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("payment method not found")
+		}
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Update updates a payment method configuration
+func (r *PaymentMethodRepository) Update(ctx context.Context, m *payment.MethodConfig) error {
+	windows, err := json.Marshal(m.Maintenance)
+	if err != nil {
+		return fmt.Errorf("failed to marshal maintenance windows: %w", err)
+	}
+
+	query := `
+		UPDATE payment_methods
+		SET display_name = $2, min_amount = $3, max_amount = $4, enabled = $5,
+		    display_order = $6, maintenance_windows = $7, updated_at = NOW()
+		WHERE method = $1
+		RETURNING updated_at
+	`
+
+	err = r.pool.QueryRow(ctx, query,
+		string(m.Method),
+		m.DisplayName,
+		m.MinAmount,
+		m.MaxAmount,
+		m.Enabled,
+		m.DisplayOrder,
+		windows,
+	).Scan(&m.UpdatedAt)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("payment method not found")
+		}
+		return fmt.Errorf("failed to update payment method: %w", err)
+	}
+
+	return nil
+}
+
+// Helper function to scan a payment method from a row
+func (r *PaymentMethodRepository) scanMethod(row pgx.Row) (*payment.MethodConfig, error) {
+	var m payment.MethodConfig
+	var methodStr, categoryStr string
+	var windowsBytes []byte
+
+	err := row.Scan(
+		&methodStr,
+		&m.DisplayName,
+		&categoryStr,
+		&m.MinAmount,
+		&m.MaxAmount,
+		&m.Enabled,
+		&m.DisplayOrder,
+		&windowsBytes,
+		&m.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan payment method: %w", err)
+	}
+
+	m.Method = payment.Method(methodStr)
+	m.Category = payment.MethodCategory(categoryStr)
+
+	m.Maintenance = make([]payment.MaintenanceWindow, 0)
+	if len(windowsBytes) > 0 {
+		if err := json.Unmarshal(windowsBytes, &m.Maintenance); err != nil {
+			m.Maintenance = make([]payment.MaintenanceWindow, 0)
+		}
+	}
+
+	return &m, nil
+}
@@ -23,28 +23,35 @@ func NewPaymentRepository(pool *pgxpool.Pool) *PaymentRepository {
 	return &PaymentRepository{pool: pool}
 }
 
-// Create creates a new payment
-func (r *PaymentRepository) Create(ctx context.Context, p *payment.Payment) error {
+// paymentInsertQuery inserts a payment with the arguments of paymentInsertArgs
+const paymentInsertQuery = `
+	INSERT INTO payments (
+		id, donation_id, provider, external_id, payment_method, amount, fee_amount, net_amount,
+		fee_source, fee_covered, refund_needed, status, qr_code_url, va_number, deep_link, expires_at,
+		metadata, created_at, updated_at
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+`
+
+// paymentInsertArgs returns the arguments of paymentInsertQuery
+func paymentInsertArgs(p *payment.Payment) ([]interface{}, error) {
 	metadata, err := json.Marshal(p.Metadata)
 	if err != nil {
-		return fmt.Errorf("failed to marshal metadata: %w", err)
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
-	query := `
-		INSERT INTO payments (
-			id, donation_id, provider, external_id, payment_method, amount, status,
-			qr_code_url, va_number, deep_link, expires_at, metadata, created_at, updated_at
-		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
-	`
-
-	_, err = r.pool.Exec(ctx, query,
+	return []interface{}{
 		p.ID,
 		p.DonationID,
 		string(p.Provider),
 		p.ExternalID,
 		string(p.PaymentMethod),
 		p.Amount,
+		p.FeeAmount,
+		p.NetAmount,
+		string(p.FeeSource),
+		p.FeeCovered,
+		p.RefundNeeded,
 		string(p.Status),
 		p.QRCodeURL,
 		p.VANumber,
@@ -53,20 +60,67 @@ func (r *PaymentRepository) Create(ctx context.Context, p *payment.Payment) erro
 		metadata,
 		p.CreatedAt,
 		p.UpdatedAt,
-	)
+	}, nil
+}
 
+// Create creates a new payment
+func (r *PaymentRepository) Create(ctx context.Context, p *payment.Payment) error {
+	args, err := paymentInsertArgs(p)
 	if err != nil {
+		return err
+	}
+
+	if _, err := r.pool.Exec(ctx, paymentInsertQuery, args...); err != nil {
 		return fmt.Errorf("failed to create payment: %w", err)
 	}
 
 	return nil
 }
 
+// CreateAttempt creates a payment unless its donation already has
+// maxAttempts payments. The donation row is locked while counting, so
+// parallel attempts are counted one after the other. It returns false when
+// the limit is reached.
+func (r *PaymentRepository) CreateAttempt(ctx context.Context, p *payment.Payment, maxAttempts int) (bool, error) {
+	args, err := paymentInsertArgs(p)
+	if err != nil {
+		return false, err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM donations WHERE id = $1 FOR UPDATE`, p.DonationID); err != nil {
+		return false, fmt.Errorf("failed to lock donation: %w", err)
+	}
+
+	var attempts int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM payments WHERE donation_id = $1`, p.DonationID).Scan(&attempts); err != nil {
+		return false, fmt.Errorf("failed to count payment attempts: %w", err)
+	}
+	if attempts >= maxAttempts {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(ctx, paymentInsertQuery, args...); err != nil {
+		return false, fmt.Errorf("failed to create payment: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit payment: %w", err)
+	}
+
+	return true, nil
+}
+
 // GetByID gets a payment by ID
 func (r *PaymentRepository) GetByID(ctx context.Context, id uuid.UUID) (*payment.Payment, error) {
 	query := `
-		SELECT id, donation_id, provider, external_id, payment_method, amount, status,
-		       qr_code_url, va_number, deep_link, expires_at, paid_at, metadata, created_at, updated_at
+		SELECT id, donation_id, provider, external_id, payment_method, amount, fee_amount, net_amount,
+		       fee_source, fee_covered, refund_needed, status, qr_code_url, va_number, deep_link, expires_at, paid_at, metadata, created_at, updated_at
 		FROM payments
 		WHERE id = $1
 	`
@@ -78,8 +132,8 @@ func (r *PaymentRepository) GetByID(ctx context.Context, id uuid.UUID) (*payment
 // GetByDonationID gets a payment by donation ID
 func (r *PaymentRepository) GetByDonationID(ctx context.Context, donationID uuid.UUID) (*payment.Payment, error) {
 	query := `
-		SELECT id, donation_id, provider, external_id, payment_method, amount, status,
-		       qr_code_url, va_number, deep_link, expires_at, paid_at, metadata, created_at, updated_at
+		SELECT id, donation_id, provider, external_id, payment_method, amount, fee_amount, net_amount,
+		       fee_source, fee_covered, refund_needed, status, qr_code_url, va_number, deep_link, expires_at, paid_at, metadata, created_at, updated_at
 		FROM payments
 		WHERE donation_id = $1
 		ORDER BY created_at DESC
@@ -90,11 +144,44 @@ func (r *PaymentRepository) GetByDonationID(ctx context.Context, donationID uuid
 	return r.scanPayment(row)
 }
 
+// ListByDonationID lists all payment attempts of a donation, newest first
+func (r *PaymentRepository) ListByDonationID(ctx context.Context, donationID uuid.UUID) ([]*payment.Payment, error) {
+	query := `
+		SELECT id, donation_id, provider, external_id, payment_method, amount, fee_amount, net_amount,
+		       fee_source, fee_covered, refund_needed, status, qr_code_url, va_number, deep_link,
+		       expires_at, paid_at, metadata, created_at, updated_at
+		FROM payments
+		WHERE donation_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.pool.Query(ctx, query, donationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list donation payments: %w", err)
+	}
+	defer rows.Close()
+
+	payments := make([]*payment.Payment, 0)
+	for rows.Next() {
+		p, err := r.scanPaymentFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating payments: %w", err)
+	}
+
+	return payments, nil
+}
+
 // GetByExternalID gets a payment by provider and external ID
 func (r *PaymentRepository) GetByExternalID(ctx context.Context, provider payment.Provider, externalID string) (*payment.Payment, error) {
 	query := `
-		SELECT id, donation_id, provider, external_id, payment_method, amount, status,
-		       qr_code_url, va_number, deep_link, expires_at, paid_at, metadata, created_at, updated_at
+		SELECT id, donation_id, provider, external_id, payment_method, amount, fee_amount, net_amount,
+		       fee_source, fee_covered, refund_needed, status, qr_code_url, va_number, deep_link, expires_at, paid_at, metadata, created_at, updated_at
 		FROM payments
 		WHERE provider = $1 AND external_id = $2
 	`
@@ -103,21 +190,23 @@ func (r *PaymentRepository) GetByExternalID(ctx context.Context, provider paymen
 	return r.scanPayment(row)
 }
 
-// Update updates a payment
-func (r *PaymentRepository) Update(ctx context.Context, p *payment.Payment) error {
+// paymentUpdateQuery updates a payment with the arguments of paymentUpdateArgs
+const paymentUpdateQuery = `
+	UPDATE payments
+	SET external_id = $2, status = $3, qr_code_url = $4, va_number = $5,
+	    deep_link = $6, paid_at = $7, metadata = $8, fee_amount = $9, net_amount = $10,
+	    fee_source = $11, refund_needed = $12, updated_at = NOW()
+	WHERE id = $1
+`
+
+// paymentUpdateArgs returns the arguments of paymentUpdateQuery
+func paymentUpdateArgs(p *payment.Payment) ([]interface{}, error) {
 	metadata, err := json.Marshal(p.Metadata)
 	if err != nil {
-		return fmt.Errorf("failed to marshal metadata: %w", err)
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
-	query := `
-		UPDATE payments
-		SET external_id = $2, status = $3, qr_code_url = $4, va_number = $5,
-		    deep_link = $6, paid_at = $7, metadata = $8, updated_at = NOW()
-		WHERE id = $1
-	`
-
-	result, err := r.pool.Exec(ctx, query,
+	return []interface{}{
 		p.ID,
 		p.ExternalID,
 		string(p.Status),
@@ -126,8 +215,21 @@ func (r *PaymentRepository) Update(ctx context.Context, p *payment.Payment) erro
 		p.DeepLink,
 		p.PaidAt,
 		metadata,
-	)
+		p.FeeAmount,
+		p.NetAmount,
+		string(p.FeeSource),
+		p.RefundNeeded,
+	}, nil
+}
 
+// Update updates a payment
+func (r *PaymentRepository) Update(ctx context.Context, p *payment.Payment) error {
+	args, err := paymentUpdateArgs(p)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.pool.Exec(ctx, paymentUpdateQuery, args...)
 	if err != nil {
 		return fmt.Errorf("failed to update payment: %w", err)
 	}
@@ -158,8 +260,8 @@ func (r *PaymentRepository) UpdateStatus(ctx context.Context, id uuid.UUID, stat
 // GetPendingExpired gets pending payments that have expired
 func (r *PaymentRepository) GetPendingExpired(ctx context.Context) ([]*payment.Payment, error) {
 	query := `
-		SELECT id, donation_id, provider, external_id, payment_method, amount, status,
-		       qr_code_url, va_number, deep_link, expires_at, paid_at, metadata, created_at, updated_at
+		SELECT id, donation_id, provider, external_id, payment_method, amount, fee_amount, net_amount,
+		       fee_source, fee_covered, refund_needed, status, qr_code_url, va_number, deep_link, expires_at, paid_at, metadata, created_at, updated_at
 		FROM payments
 		WHERE status = 'pending' AND expires_at < NOW()
 	`
@@ -196,8 +298,8 @@ func (r *PaymentRepository) List(ctx context.Context, params payment.ListPayment
 
 	// Build query with filters
 	query := `
-		SELECT id, donation_id, provider, external_id, payment_method, amount, status,
-		       qr_code_url, va_number, deep_link, expires_at, paid_at, metadata, created_at, updated_at
+		SELECT id, donation_id, provider, external_id, payment_method, amount, fee_amount, net_amount,
+		       fee_source, fee_covered, refund_needed, status, qr_code_url, va_number, deep_link, expires_at, paid_at, metadata, created_at, updated_at
 		FROM payments
 		WHERE 1=1
 	`
@@ -219,6 +321,13 @@ func (r *PaymentRepository) List(ctx context.Context, params payment.ListPayment
 		argCount++
 	}
 
+	if params.RefundNeeded != nil {
+		query += fmt.Sprintf(" AND refund_needed = $%d", argCount)
+		countQuery += fmt.Sprintf(" AND refund_needed = $%d", argCount)
+		args = append(args, *params.RefundNeeded)
+		argCount++
+	}
+
 	if params.StartDate != nil {
 		query += fmt.Sprintf(" AND created_at >= $%d", argCount)
 		countQuery += fmt.Sprintf(" AND created_at >= $%d", argCount)
@@ -281,7 +390,7 @@ func (r *PaymentRepository) List(ctx context.Context, params payment.ListPayment
 // Helper function to scan a payment from a row
 func (r *PaymentRepository) scanPayment(row pgx.Row) (*payment.Payment, error) {
 	var p payment.Payment
-	var providerStr, methodStr, statusStr string
+	var providerStr, methodStr, feeSourceStr, statusStr string
 	var metadataBytes []byte
 
 	err := row.Scan(
@@ -291,6 +400,11 @@ func (r *PaymentRepository) scanPayment(row pgx.Row) (*payment.Payment, error) {
 		&p.ExternalID,
 		&methodStr,
 		&p.Amount,
+		&p.FeeAmount,
+		&p.NetAmount,
+		&feeSourceStr,
+		&p.FeeCovered,
+		&p.RefundNeeded,
 		&statusStr,
 		&p.QRCodeURL,
 		&p.VANumber,
@@ -311,6 +425,8 @@ func (r *PaymentRepository) scanPayment(row pgx.Row) (*payment.Payment, error) {
 
 	p.Provider = payment.Provider(providerStr)
 	p.PaymentMethod = payment.Method(methodStr)
+	p.Method = p.PaymentMethod
+	p.FeeSource = payment.FeeSource(feeSourceStr)
 	p.Status = payment.Status(statusStr)
 
 	if len(metadataBytes) > 0 {
@@ -327,7 +443,7 @@ func (r *PaymentRepository) scanPayment(row pgx.Row) (*payment.Payment, error) {
 // Helper function to scan a payment from rows
 func (r *PaymentRepository) scanPaymentFromRows(rows pgx.Rows) (*payment.Payment, error) {
 	var p payment.Payment
-	var providerStr, methodStr, statusStr string
+	var providerStr, methodStr, feeSourceStr, statusStr string
 	var metadataBytes []byte
 
 	err := rows.Scan(
@@ -337,6 +453,11 @@ func (r *PaymentRepository) scanPaymentFromRows(rows pgx.Rows) (*payment.Payment
 		&p.ExternalID,
 		&methodStr,
 		&p.Amount,
+		&p.FeeAmount,
+		&p.NetAmount,
+		&feeSourceStr,
+		&p.FeeCovered,
+		&p.RefundNeeded,
 		&statusStr,
 		&p.QRCodeURL,
 		&p.VANumber,
@@ -354,6 +475,8 @@ func (r *PaymentRepository) scanPaymentFromRows(rows pgx.Rows) (*payment.Payment
 
 	p.Provider = payment.Provider(providerStr)
 	p.PaymentMethod = payment.Method(methodStr)
+	p.Method = p.PaymentMethod
+	p.FeeSource = payment.FeeSource(feeSourceStr)
 	p.Status = payment.Status(statusStr)
 
 	if len(metadataBytes) > 0 {
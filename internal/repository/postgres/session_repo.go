@@ -0,0 +1,282 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionRotated  = errors.New("session refresh token already rotated")
+)
+
+// Session represents a single refresh token of an admin login session.
+// All tokens issued from the same login share a FamilyID.
+type Session struct {
+	ID               uuid.UUID
+	FamilyID         uuid.UUID
+	UserID           uuid.UUID
+	RefreshTokenHash string
+	IPAddress        string
+	UserAgent        string
+	AuthenticatedAt  time.Time
+	LastUsedAt       time.Time
+	ExpiresAt        time.Time
+	RotatedAt        *time.Time
+	RevokedAt        *time.Time
+	RevokeReason     string
+	CreatedAt        time.Time
+}
+
+// IsActive checks if the refresh token can still be exchanged
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RotatedAt == nil && s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// SessionRepository handles admin session database operations
+type SessionRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewSessionRepository creates a new session repository
+func NewSessionRepository(db *pgxpool.Pool) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+const sessionColumns = `
+	id, family_id, user_id, refresh_token_hash, host(ip_address), user_agent,
+	authenticated_at, last_used_at, expires_at, rotated_at, revoked_at,
+	revoke_reason, created_at
+`
+
+// Create inserts a new session
+func (r *SessionRepository) Create(ctx context.Context, s *Session) error {
+	return r.insert(ctx, r.db, s)
+}
+
+// FindByRefreshTokenHash finds a session by the hash of its refresh token,
+// including rotated and revoked ones so reuse can be detected
+func (r *SessionRepository) FindByRefreshTokenHash(ctx context.Context, hash string) (*Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE refresh_token_hash = $1`
+
+	s, err := scanSession(r.db.QueryRow(ctx, query, hash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Rotate marks the current refresh token as used and stores its successor in
+// one transaction. It returns ErrSessionRotated if the token was rotated or
+// revoked concurrently.
+func (r *SessionRepository) Rotate(ctx context.Context, current *Session, next *Session) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE sessions
+		SET rotated_at = NOW()
+		WHERE id = $1 AND rotated_at IS NULL AND revoked_at IS NULL
+	`, current.ID)
+	if err != nil {
+		return fmt.Errorf("failed to rotate session: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrSessionRotated
+	}
+
+	if err := r.insert(ctx, tx, next); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ListActiveByUserID lists the current refresh token of every active session of a user
+func (r *SessionRepository) ListActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*Session, error) {
+	query := `SELECT ` + sessionColumns + `
+		FROM sessions
+		WHERE user_id = $1 AND rotated_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_used_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]*Session, 0)
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// RevokeFamily revokes every token of a login session and returns how many
+// tokens were still unrevoked
+func (r *SessionRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID, reason string) (int64, error) {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE sessions
+		SET revoked_at = NOW(), revoke_reason = $2
+		WHERE family_id = $1 AND revoked_at IS NULL
+	`, familyID, reason)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// IsFamilyRevoked reports whether a session family has been revoked
+func (r *SessionRepository) IsFamilyRevoked(ctx context.Context, familyID uuid.UUID) (bool, error) {
+	var revoked bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM sessions WHERE family_id = $1 AND revoked_at IS NOT NULL)
+	`, familyID).Scan(&revoked)
+	if err != nil {
+		return false, fmt.Errorf("failed to check session revocation: %w", err)
+	}
+
+	return revoked, nil
+}
+
+// RevokeAllByUserID revokes every session of a user and returns the revoked family IDs
+func (r *SessionRepository) RevokeAllByUserID(ctx context.Context, userID uuid.UUID, reason string) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx, `
+		UPDATE sessions
+		SET revoked_at = NOW(), revoke_reason = $2
+		WHERE user_id = $1 AND revoked_at IS NULL
+		RETURNING family_id
+	`, userID, reason)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	defer rows.Close()
+
+	seen := make(map[uuid.UUID]bool)
+	families := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var familyID uuid.UUID
+		if err := rows.Scan(&familyID); err != nil {
+			return nil, fmt.Errorf("failed to scan session family: %w", err)
+		}
+		if !seen[familyID] {
+			seen[familyID] = true
+			families = append(families, familyID)
+		}
+	}
+
+	return families, rows.Err()
+}
+
+// DeleteExpired removes sessions that can no longer be refreshed
+func (r *SessionRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM sessions WHERE expires_at < NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// execer is implemented by both the pool and transactions
+type execer interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func (r *SessionRepository) insert(ctx context.Context, db execer, s *Session) error {
+	var ip *netip.Addr
+	if addr, err := netip.ParseAddr(s.IPAddress); err == nil {
+		ip = &addr
+	}
+
+	query := `
+		INSERT INTO sessions (
+			id, family_id, user_id, refresh_token_hash, ip_address, user_agent,
+			authenticated_at, last_used_at, expires_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at
+	`
+
+	err := db.QueryRow(ctx, query,
+		s.ID,
+		s.FamilyID,
+		s.UserID,
+		s.RefreshTokenHash,
+		ip,
+		s.UserAgent,
+		s.AuthenticatedAt,
+		s.LastUsedAt,
+		s.ExpiresAt,
+	).Scan(&s.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return nil
+}
+
+// Helper function to scan a session from a row
+func scanSession(row pgx.Row) (*Session, error) {
+	var s Session
+	var ip, userAgent, revokeReason *string
+
+	err := row.Scan(
+		&s.ID,
+		&s.FamilyID,
+		&s.UserID,
+		&s.RefreshTokenHash,
+		&ip,
+		&userAgent,
+		&s.AuthenticatedAt,
+		&s.LastUsedAt,
+		&s.ExpiresAt,
+		&s.RotatedAt,
+		&s.RevokedAt,
+		&revokeReason,
+		&s.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan session: %w", err)
+	}
+
+	if ip != nil {
+		s.IPAddress = *ip
+	}
+	if userAgent != nil {
+		s.UserAgent = *userAgent
+	}
+	if revokeReason != nil {
+		s.RevokeReason = *revokeReason
+	}
+
+	return &s, nil
+}
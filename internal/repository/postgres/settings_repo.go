@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Setting keys stored in admin_settings
+const (
+	SettingMFARequired = "mfa_required"
+)
+
+// ErrSettingNotFound is returned when a setting has never been stored
+var ErrSettingNotFound = errors.New("setting not found")
+
+// SettingsRepository handles admin security settings
+type SettingsRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewSettingsRepository creates a new settings repository
+func NewSettingsRepository(db *pgxpool.Pool) *SettingsRepository {
+	return &SettingsRepository{db: db}
+}
+
+// Get loads a setting into dest
+func (r *SettingsRepository) Get(ctx context.Context, key string, dest interface{}) error {
+	var value []byte
+	err := r.db.QueryRow(ctx, `SELECT value FROM admin_settings WHERE key = $1`, key).Scan(&value)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrSettingNotFound
+		}
+		return fmt.Errorf("failed to get setting: %w", err)
+	}
+
+	if err := json.Unmarshal(value, dest); err != nil {
+		return fmt.Errorf("failed to unmarshal setting %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// Set stores a setting
+func (r *SettingsRepository) Set(ctx context.Context, key string, value interface{}, updatedBy uuid.UUID) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal setting %s: %w", key, err)
+	}
+
+	query := `
+		INSERT INTO admin_settings (key, value, updated_by, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (key) DO UPDATE
+		SET value = EXCLUDED.value, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+	`
+
+	if _, err := r.db.Exec(ctx, query, key, data, updatedBy); err != nil {
+		return fmt.Errorf("failed to set setting: %w", err)
+	}
+
+	return nil
+}
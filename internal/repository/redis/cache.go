@@ -60,6 +60,16 @@ func (c *Cache) Exists(ctx context.Context, key string) bool {
 	return result > 0
 }
 
+// ExistsStrict checks if a key exists, reporting Redis errors instead of
+// treating them as a missing key
+func (c *Cache) ExistsStrict(ctx context.Context, key string) (bool, error) {
+	result, err := c.client.Exists(ctx, key).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check key: %w", err)
+	}
+	return result > 0, nil
+}
+
 // SetNX sets a value only if it doesn't exist (for idempotency)
 func (c *Cache) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
 	data, err := json.Marshal(value)
@@ -133,6 +143,11 @@ func (c *Cache) Ping(ctx context.Context) error {
 	return c.client.Ping(ctx).Err()
 }
 
+// PoolStats returns connection pool statistics
+func (c *Cache) PoolStats() *redis.PoolStats {
+	return c.client.PoolStats()
+}
+
 // Close closes the Redis connection
 func (c *Cache) Close() error {
 	return c.client.Close()
@@ -143,12 +158,18 @@ var ErrCacheMiss = fmt.Errorf("cache miss")
 
 // Key prefixes for different data types
 const (
-	KeyPrefixIdempotency   = "idempotency:"
-	KeyPrefixRateLimit     = "ratelimit:"
-	KeyPrefixSession       = "session:"
-	KeyPrefixOverlayToken  = "overlay_token:"
-	KeyPrefixPaymentStatus = "payment_status:"
-	KeyPrefixWebhook       = "webhook:"
+	KeyPrefixIdempotency    = "idempotency:"
+	KeyPrefixRateLimit      = "ratelimit:"
+	KeyPrefixSession        = "session:"
+	KeyPrefixRevokedSession = "revoked_session:"
+	KeyPrefixLoginFailures  = "login_failures:"
+	KeyPrefixLoginDelay     = "login_delay:"
+	KeyPrefixLoginLock      = "login_lock:"
+	KeyPrefixOverlayToken   = "overlay_token:"
+	KeyPrefixChallengeUsed  = "challenge_used:"
+	KeyPrefixPaymentStatus  = "payment_status:"
+	KeyPrefixWebhook        = "webhook:"
+	KeyPaymentMethods       = "payment_methods"
 )
 
 // IdempotencyKey generates an idempotency key
@@ -156,9 +177,14 @@ func IdempotencyKey(provider, externalID, transactionID string) string {
 	return fmt.Sprintf("%s%s:%s:%s", KeyPrefixWebhook, provider, externalID, transactionID)
 }
 
-// RateLimitKey generates a rate limit key for an IP
-func RateLimitKey(ip, endpoint string) string {
-	return fmt.Sprintf("%s%s:%s", KeyPrefixRateLimit, endpoint, ip)
+// RateLimitKey generates the token bucket key for a client of a route group
+func RateLimitKey(group, client string) string {
+	return fmt.Sprintf("%s%s:%s", KeyPrefixRateLimit, group, client)
+}
+
+// ChallengeUsedKey generates the key marking a proof-of-work challenge as used
+func ChallengeUsedKey(id string) string {
+	return KeyPrefixChallengeUsed + id
 }
 
 // SessionKey generates a session key
@@ -166,6 +192,26 @@ func SessionKey(sessionID string) string {
 	return KeyPrefixSession + sessionID
 }
 
+// RevokedSessionKey generates a key marking a revoked session
+func RevokedSessionKey(sessionID string) string {
+	return KeyPrefixRevokedSession + sessionID
+}
+
+// LoginFailuresKey generates a failed login counter key for an IP or account
+func LoginFailuresKey(scope, subject string) string {
+	return fmt.Sprintf("%s%s:%s", KeyPrefixLoginFailures, scope, subject)
+}
+
+// LoginDelayKey generates a key that throttles logins until it expires
+func LoginDelayKey(scope, subject string) string {
+	return fmt.Sprintf("%s%s:%s", KeyPrefixLoginDelay, scope, subject)
+}
+
+// LoginLockKey generates a login lockout key
+func LoginLockKey(scope, subject string) string {
+	return fmt.Sprintf("%s%s:%s", KeyPrefixLoginLock, scope, subject)
+}
+
 // OverlayTokenKey generates an overlay token key
 func OverlayTokenKey(token string) string {
 	return KeyPrefixOverlayToken + token
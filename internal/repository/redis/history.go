@@ -0,0 +1,161 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Event history keys. Published realtime events are also appended to capped
+// streams so clients that reconnect can resume. The stream entry ID is the
+// event ID, which every instance agrees on.
+const (
+	overlayHistoryKey        = "history:overlay"
+	donationHistoryKeyPrefix = "history:donation:"
+)
+
+// History limits
+const (
+	overlayHistoryLength  = 200
+	donationHistoryLength = 20
+	donationHistoryTTL    = 24 * time.Hour
+
+	// Most events replayed to one client
+	historyReplayLimit = 100
+)
+
+// appendHistory appends an event to a capped stream and returns its ID
+func (p *PubSub) appendHistory(ctx context.Context, key string, maxLen int64, ttl time.Duration, event interface{}) (string, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	pipe := p.client.TxPipeline()
+	add := pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		MaxLen: maxLen,
+		Approx: true,
+		Values: map[string]interface{}{"event": data},
+	})
+	if ttl > 0 {
+		pipe.Expire(ctx, key, ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", fmt.Errorf("failed to append event history: %w", err)
+	}
+
+	return add.Val(), nil
+}
+
+// historySince returns the events of a stream published after lastID, oldest
+// first
+func (p *PubSub) historySince(ctx context.Context, key, lastID string) ([]redis.XMessage, error) {
+	if !ValidEventID(lastID) {
+		return nil, fmt.Errorf("invalid event ID %q", lastID)
+	}
+
+	messages, err := p.client.XRangeN(ctx, key, "("+lastID, "+", historyReplayLimit).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event history: %w", err)
+	}
+	return messages, nil
+}
+
+// ValidEventID reports whether id has the form of an event ID
+func ValidEventID(id string) bool {
+	_, _, ok := parseEventID(id)
+	return ok
+}
+
+// EventIDAfter reports whether event ID a was published after b. An empty b
+// is before every event.
+func EventIDAfter(a, b string) bool {
+	if b == "" {
+		return true
+	}
+
+	aMs, aSeq, aOK := parseEventID(a)
+	bMs, bSeq, bOK := parseEventID(b)
+	if !aOK || !bOK {
+		return true
+	}
+	if aMs != bMs {
+		return aMs > bMs
+	}
+	return aSeq > bSeq
+}
+
+// parseEventID splits a stream entry ID "<ms>-<seq>"
+func parseEventID(id string) (uint64, uint64, bool) {
+	msPart, seqPart, ok := strings.Cut(id, "-")
+	if !ok {
+		return 0, 0, false
+	}
+
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	seq, err := strconv.ParseUint(seqPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return ms, seq, true
+}
+
+// historyEvent decodes the event stored in a stream entry
+func historyEvent(msg redis.XMessage, event interface{}) error {
+	data, _ := msg.Values["event"].(string)
+	if err := json.Unmarshal([]byte(data), event); err != nil {
+		return fmt.Errorf("failed to parse event %s: %w", msg.ID, err)
+	}
+	return nil
+}
+
+// DonationEventsSince returns the overlay donation events published after
+// lastEventID, oldest first
+func (p *PubSub) DonationEventsSince(ctx context.Context, lastEventID string) ([]*DonationEvent, error) {
+	messages, err := p.historySince(ctx, overlayHistoryKey, lastEventID)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*DonationEvent, 0, len(messages))
+	for _, msg := range messages {
+		var event DonationEvent
+		if err := historyEvent(msg, &event); err != nil {
+			p.logger.Warn("skipping donation event history entry", "error", err)
+			continue
+		}
+		event.EventID = msg.ID
+		events = append(events, &event)
+	}
+	return events, nil
+}
+
+// DonationStatusSince returns the status events of a donation published after
+// lastEventID, oldest first
+func (p *PubSub) DonationStatusSince(ctx context.Context, donationID, lastEventID string) ([]*DonationStatusEvent, error) {
+	messages, err := p.historySince(ctx, donationHistoryKeyPrefix+donationID, lastEventID)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*DonationStatusEvent, 0, len(messages))
+	for _, msg := range messages {
+		var event DonationStatusEvent
+		if err := historyEvent(msg, &event); err != nil {
+			p.logger.Warn("skipping donation status history entry", "error", err)
+			continue
+		}
+		event.EventID = msg.ID
+		events = append(events, &event)
+	}
+	return events, nil
+}
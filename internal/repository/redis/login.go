@@ -0,0 +1,111 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// reserveLoginScript counts a login attempt before its credentials are
+// checked, so parallel attempts can't all pass before any is counted.
+//
+// KEYS[1] is the lockout, KEYS[2] the delay and KEYS[3] the failure counter
+// of one scope. ARGV[1] is the failure window, ARGV[2] how many attempts go
+// through without a delay, ARGV[3] the first delay and ARGV[4] the longest
+// one, all durations in milliseconds. The delay doubles with every attempt
+// past ARGV[2] and is set by the attempt itself, so the next one waits.
+//
+// Returns {0, attempts} when the attempt is counted, {1, lockout_ms} while
+// the scope is locked and {2, delay_ms} while it is throttled.
+var reserveLoginScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return {1, redis.call('PTTL', KEYS[1])}
+end
+if redis.call('EXISTS', KEYS[2]) == 1 then
+	return {2, redis.call('PTTL', KEYS[2])}
+end
+
+local n = redis.call('INCR', KEYS[3])
+if n == 1 then
+	redis.call('PEXPIRE', KEYS[3], ARGV[1])
+end
+
+local over = n - tonumber(ARGV[2])
+local delay = tonumber(ARGV[3])
+local max = tonumber(ARGV[4])
+if over > 0 and delay > 0 then
+	local i = 1
+	while i < over and delay < max do
+		delay = delay * 2
+		i = i + 1
+	end
+	if max > 0 and delay > max then
+		delay = max
+	end
+	redis.call('SET', KEYS[2], '1', 'PX', delay)
+end
+
+return {0, n}
+`)
+
+// releaseLoginScript uncounts an attempt whose credentials were correct
+var releaseLoginScript = redis.NewScript(`
+local n = tonumber(redis.call('GET', KEYS[1]) or '0')
+if n > 0 then
+	return redis.call('DECR', KEYS[1])
+end
+return 0
+`)
+
+// LoginDelay is the progressive delay applied to repeated login attempts
+type LoginDelay struct {
+	After int           // Attempts that go through without a delay
+	Base  time.Duration // First delay
+	Max   time.Duration // Longest delay
+}
+
+// LoginReservation is the outcome of counting a login attempt
+type LoginReservation struct {
+	Attempts  int64 // Attempts in the window, including this one
+	Locked    bool
+	Throttled bool
+	Wait      time.Duration // Until the lockout or delay ends
+}
+
+// ReserveLoginAttempt atomically counts a login attempt for a scope unless
+// the scope is locked or throttled
+func (c *Cache) ReserveLoginAttempt(ctx context.Context, scope, subject string, window time.Duration, delay LoginDelay) (*LoginReservation, error) {
+	keys := []string{
+		LoginLockKey(scope, subject),
+		LoginDelayKey(scope, subject),
+		LoginFailuresKey(scope, subject),
+	}
+
+	values, err := reserveLoginScript.Run(ctx, c.client, keys,
+		window.Milliseconds(), delay.After, delay.Base.Milliseconds(), delay.Max.Milliseconds(),
+	).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve login attempt: %w", err)
+	}
+	if len(values) != 2 {
+		return nil, fmt.Errorf("failed to reserve login attempt: unexpected script result %v", values)
+	}
+
+	switch values[0] {
+	case 1:
+		return &LoginReservation{Locked: true, Wait: time.Duration(values[1]) * time.Millisecond}, nil
+	case 2:
+		return &LoginReservation{Throttled: true, Wait: time.Duration(values[1]) * time.Millisecond}, nil
+	}
+	return &LoginReservation{Attempts: values[1]}, nil
+}
+
+// ReleaseLoginAttempt uncounts a reserved login attempt
+func (c *Cache) ReleaseLoginAttempt(ctx context.Context, scope, subject string) error {
+	if err := releaseLoginScript.Run(ctx, c.client, []string{LoginFailuresKey(scope, subject)}).Err(); err != nil {
+		return fmt.Errorf("failed to release login attempt: %w", err)
+	}
+	return nil
+}
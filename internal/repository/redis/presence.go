@@ -0,0 +1,130 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Presence keys. Each instance owns one hash of its connected clients; the
+// sorted set records when every instance last refreshed it.
+const (
+	presenceInstancesKey = "presence:instances"
+	presenceKeyPrefix    = "presence:instance:"
+)
+
+// PresenceClient is a realtime client connected to an instance
+type PresenceClient struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`    // overlay or admin
+	Channel     string    `json:"channel"` // Overlay tokens are masked
+	UserID      string    `json:"user_id,omitempty"`
+	ConnectedAt time.Time `json:"connected_at"`
+}
+
+// InstancePresence lists the clients connected to one instance
+type InstancePresence struct {
+	InstanceID string
+	LastSeen   time.Time
+	Clients    []PresenceClient
+}
+
+// Presence tracks which realtime clients are connected to which instance
+type Presence struct {
+	client *redis.Client
+}
+
+// NewPresence creates a new presence store
+func NewPresence(client *redis.Client) *Presence {
+	return &Presence{client: client}
+}
+
+// Publish replaces the clients recorded for an instance. The record expires
+// after ttl unless it is published again, so a crashed instance drops out.
+func (p *Presence) Publish(ctx context.Context, instanceID string, clients []PresenceClient, ttl time.Duration) error {
+	key := presenceKeyPrefix + instanceID
+
+	pipe := p.client.TxPipeline()
+	pipe.Del(ctx, key)
+	if len(clients) > 0 {
+		fields := make([]interface{}, 0, len(clients)*2)
+		for _, c := range clients {
+			data, err := json.Marshal(c)
+			if err != nil {
+				return fmt.Errorf("failed to marshal presence: %w", err)
+			}
+			fields = append(fields, c.ID, data)
+		}
+		pipe.HSet(ctx, key, fields...)
+		pipe.Expire(ctx, key, ttl)
+	}
+	pipe.ZAdd(ctx, presenceInstancesKey, redis.Z{Score: float64(time.Now().Unix()), Member: instanceID})
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to publish presence: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes the presence of an instance that is shutting down
+func (p *Presence) Remove(ctx context.Context, instanceID string) error {
+	pipe := p.client.TxPipeline()
+	pipe.Del(ctx, presenceKeyPrefix+instanceID)
+	pipe.ZRem(ctx, presenceInstancesKey, instanceID)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to remove presence: %w", err)
+	}
+	return nil
+}
+
+// List returns the clients of every instance seen within ttl, dropping
+// instances that stopped refreshing their presence
+func (p *Presence) List(ctx context.Context, ttl time.Duration) ([]InstancePresence, error) {
+	cutoff := strconv.FormatInt(time.Now().Add(-ttl).Unix(), 10)
+	if err := p.client.ZRemRangeByScore(ctx, presenceInstancesKey, "-inf", "("+cutoff).Err(); err != nil {
+		return nil, fmt.Errorf("failed to prune presence: %w", err)
+	}
+
+	instances, err := p.client.ZRangeWithScores(ctx, presenceInstancesKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list instances: %w", err)
+	}
+
+	pipe := p.client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(instances))
+	for i, instance := range instances {
+		cmds[i] = pipe.HGetAll(ctx, presenceKeyPrefix+instance.Member.(string))
+	}
+	if len(instances) > 0 {
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, fmt.Errorf("failed to load presence: %w", err)
+		}
+	}
+
+	result := make([]InstancePresence, len(instances))
+	for i, instance := range instances {
+		result[i] = InstancePresence{
+			InstanceID: instance.Member.(string),
+			LastSeen:   time.Unix(int64(instance.Score), 0),
+			Clients:    []PresenceClient{},
+		}
+		for _, data := range cmds[i].Val() {
+			var c PresenceClient
+			if err := json.Unmarshal([]byte(data), &c); err != nil {
+				continue
+			}
+			result[i].Clients = append(result[i].Clients, c)
+		}
+		sort.Slice(result[i].Clients, func(a, b int) bool {
+			return result[i].Clients[a].ConnectedAt.Before(result[i].Clients[b].ConnectedAt)
+		})
+	}
+
+	return result, nil
+}
@@ -5,19 +5,27 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
 // PubSub channels
 const (
-	ChannelDonationsNew = "donations:new"
+	ChannelDonationsNew   = "donations:new"
+	ChannelDonationStatus = "donations:status"
+	ChannelAdminEvents    = "admin:events"
 )
 
 // PubSub provides Redis pub/sub functionality
 type PubSub struct {
 	client *redis.Client
 	logger *slog.Logger
+
+	// Open subscriptions, for health checks
+	mu   sync.Mutex
+	subs map[*Subscription]struct{}
 }
 
 // NewPubSub creates a new Redis pub/sub client
@@ -25,6 +33,7 @@ func NewPubSub(client *redis.Client, logger *slog.Logger) *PubSub {
 	return &PubSub{
 		client: client,
 		logger: logger,
+		subs:   make(map[*Subscription]struct{}),
 	}
 }
 
@@ -45,17 +54,53 @@ func (p *PubSub) Publish(ctx context.Context, channel string, message interface{
 
 // Subscribe subscribes to a channel and returns a channel for receiving messages
 func (p *PubSub) Subscribe(ctx context.Context, channels ...string) *Subscription {
-	pubsub := p.client.Subscribe(ctx, channels...)
-	return &Subscription{
-		pubsub: pubsub,
-		logger: p.logger,
+	sub := &Subscription{
+		pubsub:   p.client.Subscribe(ctx, channels...),
+		channels: channels,
+		parent:   p,
+		logger:   p.logger,
+	}
+
+	p.mu.Lock()
+	p.subs[sub] = struct{}{}
+	p.mu.Unlock()
+
+	return sub
+}
+
+// SubscriptionHealth is the outcome of pinging one subscription
+type SubscriptionHealth struct {
+	Channels []string
+	Err      error
+}
+
+// PingSubscriptions pings every open subscription over its own connection.
+// An empty result means nothing is subscribed.
+func (p *PubSub) PingSubscriptions(ctx context.Context) []SubscriptionHealth {
+	p.mu.Lock()
+	subs := make([]*Subscription, 0, len(p.subs))
+	for sub := range p.subs {
+		subs = append(subs, sub)
+	}
+	p.mu.Unlock()
+
+	results := make([]SubscriptionHealth, len(subs))
+	for i, sub := range subs {
+		results[i] = SubscriptionHealth{
+			Channels: sub.channels,
+			Err:      sub.pubsub.Ping(ctx),
+		}
 	}
+
+	return results
 }
 
 // Subscription represents a subscription to Redis channels
 type Subscription struct {
-	pubsub *redis.PubSub
-	logger *slog.Logger
+	pubsub   *redis.PubSub
+	channels []string
+	parent   *PubSub
+	logger   *slog.Logger
 }
 
 // Channel returns the message channel
@@ -65,6 +110,10 @@ func (s *Subscription) Channel() <-chan *redis.Message {
 
 // Close closes the subscription
 func (s *Subscription) Close() error {
+	s.parent.mu.Lock()
+	delete(s.parent.subs, s)
+	s.parent.mu.Unlock()
+
 	return s.pubsub.Close()
 }
 
@@ -81,10 +130,25 @@ type DonationEvent struct {
 	Message   string `json:"message"`
 	Amount    int64  `json:"amount"`
 	PaidAt    string `json:"paid_at"`
+	// When the webhook that completed the donation arrived (RFC 3339 with
+	// nanoseconds), for measuring alert delivery. Empty for manual changes.
+	ReceivedAt string `json:"received_at,omitempty"`
+	// W3C trace context of the publisher, so delivery joins its trace
+	TraceContext map[string]string `json:"trace_context,omitempty"`
+	// Position in the overlay event history, for resuming streams
+	EventID string `json:"event_id,omitempty"`
 }
 
-// PublishDonationEvent publishes a new donation event
+// PublishDonationEvent records a new donation event in the overlay history
+// and publishes it. The event is still published when the history can't be
+// written; it just can't be replayed.
 func (p *PubSub) PublishDonationEvent(ctx context.Context, event *DonationEvent) error {
+	id, err := p.appendHistory(ctx, overlayHistoryKey, overlayHistoryLength, 0, event)
+	if err != nil {
+		p.logger.Warn("failed to record donation event", "donation_id", event.ID, "error", err)
+	}
+	event.EventID = id
+
 	return p.Publish(ctx, ChannelDonationsNew, event)
 }
 
@@ -135,3 +199,110 @@ func (p *PubSub) SubscribeDonations(ctx context.Context, callback func(*Donation
 
 	return nil
 }
+
+// DonationStatusEvent reports a status change of one donation to its donor
+type DonationStatusEvent struct {
+	DonationID string `json:"donation_id"`
+	Status     string `json:"status"`
+	DonorName  string `json:"donor_name"`
+	Message    string `json:"message,omitempty"`
+	Amount     int64  `json:"amount"`
+	PaidAt     string `json:"paid_at,omitempty"`
+	Timestamp  string `json:"timestamp"`
+	// Position in the donation's event history, for resuming streams
+	EventID string `json:"event_id,omitempty"`
+}
+
+// NewDonationStatusEvent creates a donation status event
+func NewDonationStatusEvent(donationID, status, donorName, message string, amount int64, paidAt *time.Time) *DonationStatusEvent {
+	event := &DonationStatusEvent{
+		DonationID: donationID,
+		Status:     status,
+		DonorName:  donorName,
+		Message:    message,
+		Amount:     amount,
+		Timestamp:  time.Now().Format(time.RFC3339),
+	}
+	if paidAt != nil {
+		event.PaidAt = paidAt.Format(time.RFC3339)
+	}
+	return event
+}
+
+// PublishDonationStatus records a donation status change in the donation's
+// history and publishes it
+func (p *PubSub) PublishDonationStatus(ctx context.Context, event *DonationStatusEvent) error {
+	id, err := p.appendHistory(ctx, donationHistoryKeyPrefix+event.DonationID, donationHistoryLength, donationHistoryTTL, event)
+	if err != nil {
+		p.logger.Warn("failed to record donation status", "donation_id", event.DonationID, "error", err)
+	}
+	event.EventID = id
+
+	return p.Publish(ctx, ChannelDonationStatus, event)
+}
+
+// SubscribeDonationStatus subscribes to donation status events with a callback
+func (p *PubSub) SubscribeDonationStatus(ctx context.Context, callback func(*DonationStatusEvent)) error {
+	sub := p.Subscribe(ctx, ChannelDonationStatus)
+
+	go func() {
+		defer sub.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-sub.Channel():
+				if msg == nil {
+					return
+				}
+				var event DonationStatusEvent
+				if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+					p.logger.Error("failed to parse donation status event", "error", err)
+					continue
+				}
+				callback(&event)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// AdminEvent represents an event for connected admin dashboards
+type AdminEvent struct {
+	Type      string                 `json:"type"`
+	Data      map[string]interface{} `json:"data"`
+	Timestamp string                 `json:"timestamp"`
+}
+
+// PublishAdminEvent publishes an event to all admin dashboards
+func (p *PubSub) PublishAdminEvent(ctx context.Context, event *AdminEvent) error {
+	return p.Publish(ctx, ChannelAdminEvents, event)
+}
+
+// SubscribeAdminEvents subscribes to admin events with a callback
+func (p *PubSub) SubscribeAdminEvents(ctx context.Context, callback func(*AdminEvent)) error {
+	sub := p.Subscribe(ctx, ChannelAdminEvents)
+
+	go func() {
+		defer sub.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-sub.Channel():
+				if msg == nil {
+					return
+				}
+				var event AdminEvent
+				if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+					p.logger.Error("failed to parse admin event", "error", err)
+					continue
+				}
+				callback(&event)
+			}
+		}
+	}()
+
+	return nil
+}
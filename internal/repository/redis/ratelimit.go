@@ -0,0 +1,81 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript takes one token from the bucket at KEYS[1].
+//
+// The bucket holds up to ARGV[1] tokens and refills completely over ARGV[2]
+// milliseconds. State is the token count and the time it was last refilled,
+// using the Redis clock so every app instance sees the same time. The key
+// expires once the bucket would be full again, which is the same as absent.
+//
+// Returns {allowed, remaining, retry_after_ms, reset_ms}.
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local rate = capacity / period
+
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+
+if now > ts then
+	tokens = math.min(capacity, tokens + (now - ts) * rate)
+	ts = now
+end
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate)
+end
+
+local reset = math.ceil((capacity - tokens) / rate)
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', ts)
+redis.call('PEXPIRE', KEYS[1], math.max(reset, 1))
+
+return {allowed, math.floor(tokens), retry, reset}
+`)
+
+// TokenBucketResult is the outcome of taking a token from a bucket
+type TokenBucketResult struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration // Until a token is available, zero when allowed
+	Reset      time.Duration // Until the bucket is full again
+}
+
+// TakeToken atomically takes one token from a bucket holding up to capacity
+// tokens that refills completely over period
+func (c *Cache) TakeToken(ctx context.Context, key string, capacity int, period time.Duration) (*TokenBucketResult, error) {
+	values, err := tokenBucketScript.Run(ctx, c.client, []string{key}, capacity, period.Milliseconds()).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to take token: %w", err)
+	}
+	if len(values) != 4 {
+		return nil, fmt.Errorf("failed to take token: unexpected script result %v", values)
+	}
+
+	return &TokenBucketResult{
+		Allowed:    values[0] == 1,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+		Reset:      time.Duration(values[3]) * time.Millisecond,
+	}, nil
+}
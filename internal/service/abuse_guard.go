@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/reveegate/reveegate/internal/challenge"
+	"github.com/reveegate/reveegate/internal/config"
+	"github.com/reveegate/reveegate/internal/repository/postgres"
+	redisRepo "github.com/reveegate/reveegate/internal/repository/redis"
+)
+
+// Anti-abuse errors
+var (
+	ErrChallengeRequired    = errors.New("complete the challenge to continue")
+	ErrChallengeUnavailable = errors.New("challenge verification is unavailable, try again later")
+)
+
+// Challenge modes
+const (
+	ChallengeModeOff    = "off"
+	ChallengeModeRisk   = "risk"
+	ChallengeModeAlways = "always"
+)
+
+// RiskLevel is how likely a donation request is to be abuse
+type RiskLevel int
+
+// Risk levels
+const (
+	RiskLow RiskLevel = iota
+	RiskElevated
+	RiskHigh
+)
+
+// String returns the risk level name
+func (l RiskLevel) String() string {
+	switch l {
+	case RiskElevated:
+		return "elevated"
+	case RiskHigh:
+		return "high"
+	default:
+		return "low"
+	}
+}
+
+// DonationAttempt describes a donation request for risk assessment
+type DonationAttempt struct {
+	ClientIP   string
+	DonorName  string
+	DonorEmail string
+	Message    string
+}
+
+// DonationRisk is the assessed risk of a donation request
+type DonationRisk struct {
+	Level                  RiskLevel
+	Signals                []string
+	PendingFromIP          int
+	DuplicateMessageDonors int
+}
+
+// ChallengeError is returned when a request has to solve a challenge first.
+// It carries a fresh challenge for the client.
+type ChallengeError struct {
+	Reason    error // Why the submitted solution was rejected
+	Challenge *challenge.Challenge
+}
+
+// Error implements error
+func (e *ChallengeError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrChallengeRequired, e.Reason)
+}
+
+// Unwrap makes errors.Is(err, ErrChallengeRequired) match
+func (e *ChallengeError) Unwrap() error {
+	return ErrChallengeRequired
+}
+
+// AbuseGuard scores donation requests with heuristics and asks risky ones to
+// solve a proof-of-work or CAPTCHA challenge before a charge is created
+type AbuseGuard struct {
+	donationRepo *postgres.DonationRepository
+	pow          *challenge.ProofOfWork
+	captcha      challenge.Verifier // Nil unless a CAPTCHA provider is configured
+	cfg          config.AbuseConfig
+	logger       *slog.Logger
+}
+
+// NewAbuseGuard creates a new anti-abuse guard
+func NewAbuseGuard(donationRepo *postgres.DonationRepository, cache *redisRepo.Cache, cfg config.AbuseConfig, logger *slog.Logger) *AbuseGuard {
+	g := &AbuseGuard{
+		donationRepo: donationRepo,
+		pow:          challenge.NewProofOfWork(cfg.ChallengeSecret, cfg.ChallengeTTL, challengeReplayStore{cache: cache}),
+		cfg:          cfg,
+		logger:       logger,
+	}
+
+	switch cfg.ChallengeProvider {
+	case challenge.KindHCaptcha:
+		g.captcha = challenge.NewHCaptcha(cfg.CaptchaSiteKey, cfg.CaptchaSecret, cfg.CaptchaVerifyURL)
+	case challenge.KindTurnstile:
+		g.captcha = challenge.NewTurnstile(cfg.CaptchaSiteKey, cfg.CaptchaSecret, cfg.CaptchaVerifyURL)
+	}
+
+	return g
+}
+
+// Assess scores a donation request. Heuristics that cannot be checked count
+// as elevated risk rather than none.
+func (g *AbuseGuard) Assess(ctx context.Context, attempt DonationAttempt) *DonationRisk {
+	risk := &DonationRisk{}
+	since := time.Now().Add(-g.cfg.SignalWindow)
+
+	raise := func(level RiskLevel, signal string) {
+		if level > risk.Level {
+			risk.Level = level
+		}
+		risk.Signals = append(risk.Signals, signal)
+	}
+
+	if attempt.ClientIP != "" {
+		pending, err := g.donationRepo.CountPendingByClientIP(ctx, attempt.ClientIP, since)
+		switch {
+		case err != nil:
+			g.logger.Warn("failed to count pending donations by ip", "error", err)
+			raise(RiskElevated, "pending_per_ip_unknown")
+		case pending >= g.cfg.PendingPerIP:
+			raise(RiskHigh, "pending_per_ip")
+		case pending >= (g.cfg.PendingPerIP+1)/2:
+			raise(RiskElevated, "pending_per_ip")
+		}
+		risk.PendingFromIP = pending
+	}
+
+	if strings.TrimSpace(attempt.Message) != "" {
+		name := attempt.DonorName
+		if name == "" {
+			name = "Anonymous"
+		}
+
+		donors, err := g.donationRepo.CountOtherDonorsWithMessage(ctx, attempt.Message, attempt.DonorEmail, name, attempt.ClientIP, since)
+		switch {
+		case err != nil:
+			g.logger.Warn("failed to count donors with the same message", "error", err)
+			raise(RiskElevated, "duplicate_message_unknown")
+		case donors >= g.cfg.DuplicateMessageDonors:
+			raise(RiskHigh, "duplicate_message")
+		case donors > 0:
+			raise(RiskElevated, "duplicate_message")
+		}
+		risk.DuplicateMessageDonors = donors
+	}
+
+	return risk
+}
+
+// requirement returns the challenge a risk level calls for, nil when none.
+// Elevated risk gets an invisible proof-of-work; high risk gets the CAPTCHA
+// when one is configured, otherwise a harder proof-of-work.
+func (g *AbuseGuard) requirement(level RiskLevel) *challenge.Requirement {
+	switch {
+	case g.cfg.ChallengeMode == ChallengeModeOff:
+		return nil
+	case level == RiskLow && g.cfg.ChallengeMode != ChallengeModeAlways:
+		return nil
+	case level == RiskHigh && g.captcha != nil:
+		return &challenge.Requirement{Kind: g.cfg.ChallengeProvider}
+	case level == RiskHigh:
+		return &challenge.Requirement{Kind: challenge.KindProofOfWork, Difficulty: g.cfg.PoWHighDifficulty}
+	default:
+		return &challenge.Requirement{Kind: challenge.KindProofOfWork, Difficulty: g.cfg.PoWDifficulty}
+	}
+}
+
+// verifier returns the verifier for a requirement
+func (g *AbuseGuard) verifier(req *challenge.Requirement) challenge.Verifier {
+	if req.Kind == challenge.KindProofOfWork {
+		return g.pow
+	}
+	return g.captcha
+}
+
+// Issue returns a challenge a client can solve while the donor fills in the
+// form, based on what is known before the donation is submitted. It returns
+// nil when no challenge is needed yet.
+func (g *AbuseGuard) Issue(ctx context.Context, clientIP string) (*challenge.Challenge, error) {
+	risk := g.Assess(ctx, DonationAttempt{ClientIP: clientIP})
+
+	req := g.requirement(risk.Level)
+	if req == nil {
+		return nil, nil
+	}
+
+	return g.verifier(req).Issue(ctx, *req)
+}
+
+// CheckDonation assesses a donation request and verifies the challenge its
+// risk calls for. minLevel raises the assessed risk, for example when a fraud
+// rule asks for a challenge. A missing or rejected solution returns a
+// *ChallengeError with a new challenge to solve.
+func (g *AbuseGuard) CheckDonation(ctx context.Context, attempt DonationAttempt, solution challenge.Solution, minLevel RiskLevel) (*DonationRisk, error) {
+	risk := g.Assess(ctx, attempt)
+	if minLevel > risk.Level {
+		risk.Level = minLevel
+		risk.Signals = append(risk.Signals, "fraud_rule")
+	}
+
+	req := g.requirement(risk.Level)
+	if req == nil {
+		return risk, nil
+	}
+
+	verifier := g.verifier(req)
+	solution.RemoteIP = attempt.ClientIP
+
+	err := verifier.Verify(ctx, *req, solution)
+	if err == nil {
+		return risk, nil
+	}
+
+	if !isChallengeRejection(err) {
+		g.logger.Error("failed to verify donation challenge", "kind", req.Kind, "error", err)
+		return risk, ErrChallengeUnavailable
+	}
+
+	next, issueErr := verifier.Issue(ctx, *req)
+	if issueErr != nil {
+		g.logger.Error("failed to issue donation challenge", "kind", req.Kind, "error", issueErr)
+		return risk, ErrChallengeUnavailable
+	}
+
+	if !errors.Is(err, challenge.ErrMissing) {
+		g.logger.Info("donation challenge rejected",
+			"ip", attempt.ClientIP,
+			"risk", risk.Level.String(),
+			"signals", risk.Signals,
+			"kind", req.Kind,
+			"reason", err,
+		)
+	}
+
+	return risk, &ChallengeError{Reason: err, Challenge: next}
+}
+
+// isChallengeRejection tells a bad solution apart from a verifier failure
+func isChallengeRejection(err error) bool {
+	for _, target := range []error{
+		challenge.ErrMissing,
+		challenge.ErrInvalid,
+		challenge.ErrExpired,
+		challenge.ErrReplayed,
+		challenge.ErrTooEasy,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// challengeReplayStore marks proof-of-work challenges as used in Redis
+type challengeReplayStore struct {
+	cache *redisRepo.Cache
+}
+
+// MarkUsed implements challenge.ReplayStore
+func (s challengeReplayStore) MarkUsed(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	return s.cache.SetNX(ctx, redisRepo.ChallengeUsedKey(id), true, ttl)
+}
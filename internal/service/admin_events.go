@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	redisRepo "github.com/reveegate/reveegate/internal/repository/redis"
+)
+
+// Admin event types streamed to dashboards over /ws/admin. The part before
+// the dot is the category clients subscribe to and that decides which roles
+// receive the event.
+const (
+	EventDonationCreated     = "donation.created"
+	EventDonationPaid        = "donation.paid"
+	EventDonationExpired     = "donation.expired"
+	EventDonationFailed      = "donation.failed"
+	EventWebhookReceived     = "webhook.received"
+	EventWebhookFailed       = "webhook.failed"
+	EventModerationHeld      = "moderation.held"
+	EventModerationReleased  = "moderation.released"
+	EventLoginFailed         = "security.login_failed"
+	EventLoginLockout        = "security.login_lockout"
+	EventLoginUnlock         = "security.login_unlock"
+	EventOverlayConnected    = "overlay.connected"
+	EventOverlayDisconnected = "overlay.disconnected"
+)
+
+// AdminEventTypes lists every admin event type
+var AdminEventTypes = []string{
+	EventDonationCreated,
+	EventDonationPaid,
+	EventDonationExpired,
+	EventDonationFailed,
+	EventWebhookReceived,
+	EventWebhookFailed,
+	EventModerationHeld,
+	EventModerationReleased,
+	EventLoginFailed,
+	EventLoginLockout,
+	EventLoginUnlock,
+	EventOverlayConnected,
+	EventOverlayDisconnected,
+}
+
+// publishAdminEvent sends an event to the admin dashboards of every instance.
+// Failures are only logged; dashboards catch up with their next snapshot.
+func publishAdminEvent(ctx context.Context, pubsub *redisRepo.PubSub, logger *slog.Logger, eventType string, data map[string]interface{}) {
+	event := &redisRepo.AdminEvent{
+		Type:      eventType,
+		Data:      data,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	if err := pubsub.PublishAdminEvent(ctx, event); err != nil {
+		logger.Error("failed to publish admin event", "type", eventType, "error", err)
+	}
+}
@@ -0,0 +1,382 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/reveegate/reveegate/internal/config"
+	"github.com/reveegate/reveegate/internal/password"
+	"github.com/reveegate/reveegate/internal/repository/postgres"
+)
+
+// Admin user management errors
+var (
+	ErrCannotModifySelf  = errors.New("admins cannot change their own role or status")
+	ErrLastOwner         = errors.New("at least one active owner is required")
+	ErrOwnerExists       = errors.New("an active owner already exists")
+	ErrInvitationInvalid = errors.New("invitation is invalid or expired")
+)
+
+const (
+	// ownerRole is the role that can manage other admins
+	ownerRole = "owner"
+	// invitationTTL is how long an invitation link stays valid
+	invitationTTL = 72 * time.Hour
+)
+
+// SessionRevoker ends all sessions of an admin
+type SessionRevoker interface {
+	LogoutAll(ctx context.Context, userID uuid.UUID) (int, error)
+}
+
+// CreateAdminParams holds the fields for a new admin user
+type CreateAdminParams struct {
+	Username string
+	Email    string
+	Password string
+	Role     string
+}
+
+// Invitation is returned when an admin is invited. The token is only shown once.
+type Invitation struct {
+	ID        uuid.UUID
+	Email     string
+	Role      string
+	Token     string
+	ExpiresAt time.Time
+}
+
+// AdminUserService manages admin accounts
+type AdminUserService struct {
+	adminRepo      *postgres.AdminRepository
+	invitationRepo *postgres.InvitationRepository
+	sessions       SessionRevoker
+	hasher         *password.Hasher
+	policy         password.Policy
+	logger         *slog.Logger
+}
+
+// NewAdminUserService creates a new admin user service
+func NewAdminUserService(
+	adminRepo *postgres.AdminRepository,
+	invitationRepo *postgres.InvitationRepository,
+	sessions SessionRevoker,
+	cfg config.PasswordConfig,
+	logger *slog.Logger,
+) *AdminUserService {
+	return &AdminUserService{
+		adminRepo:      adminRepo,
+		invitationRepo: invitationRepo,
+		sessions:       sessions,
+		hasher: password.NewHasher(password.Params{
+			Memory:      uint32(cfg.Argon2Memory),
+			Iterations:  uint32(cfg.Argon2Iterations),
+			Parallelism: uint8(cfg.Argon2Parallelism),
+		}),
+		policy: password.Policy{
+			MinLength:  cfg.MinLength,
+			MinClasses: cfg.MinClasses,
+		},
+		logger: logger,
+	}
+}
+
+// List lists all admin users
+func (s *AdminUserService) List(ctx context.Context) ([]*postgres.AdminUser, error) {
+	return s.adminRepo.List(ctx)
+}
+
+// Get gets an admin user by ID
+func (s *AdminUserService) Get(ctx context.Context, userID uuid.UUID) (*postgres.AdminUser, error) {
+	return s.adminRepo.FindByID(ctx, userID)
+}
+
+// Create creates an active admin user
+func (s *AdminUserService) Create(ctx context.Context, params CreateAdminParams) (*postgres.AdminUser, error) {
+	passwordHash, err := s.hashNewPassword(params.Password, params.Username, params.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	admin := &postgres.AdminUser{
+		Username:     params.Username,
+		PasswordHash: passwordHash,
+		Email:        params.Email,
+		Role:         params.Role,
+		IsActive:     true,
+	}
+
+	if err := s.adminRepo.Create(ctx, admin); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("admin user created",
+		"admin_id", admin.ID,
+		"username", admin.Username,
+		"role", admin.Role,
+	)
+
+	return admin, nil
+}
+
+// BootstrapOwner creates the first owner account. It refuses when an active
+// owner already exists unless force is set.
+func (s *AdminUserService) BootstrapOwner(ctx context.Context, params CreateAdminParams, force bool) (*postgres.AdminUser, error) {
+	owners, err := s.adminRepo.CountActiveOwners(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if owners > 0 && !force {
+		return nil, ErrOwnerExists
+	}
+
+	params.Role = ownerRole
+	return s.Create(ctx, params)
+}
+
+// Invite creates a single-use invitation for a new admin
+func (s *AdminUserService) Invite(ctx context.Context, invitedBy uuid.UUID, email, role string) (*Invitation, error) {
+	if _, err := s.adminRepo.FindByEmail(ctx, email); err == nil {
+		return nil, postgres.ErrAdminExists
+	} else if !errors.Is(err, postgres.ErrAdminNotFound) {
+		return nil, err
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	inv := &postgres.AdminInvitation{
+		Email:     email,
+		Role:      role,
+		TokenHash: hashToken(token),
+		InvitedBy: invitedBy,
+		ExpiresAt: time.Now().Add(invitationTTL),
+	}
+
+	if err := s.invitationRepo.Create(ctx, inv); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("admin invited",
+		"invitation_id", inv.ID,
+		"role", role,
+		"invited_by", invitedBy,
+	)
+
+	return &Invitation{
+		ID:        inv.ID,
+		Email:     inv.Email,
+		Role:      inv.Role,
+		Token:     token,
+		ExpiresAt: inv.ExpiresAt,
+	}, nil
+}
+
+// AcceptInvitation creates the invited admin with the chosen username and password
+func (s *AdminUserService) AcceptInvitation(ctx context.Context, token, username, newPassword string) (*postgres.AdminUser, error) {
+	inv, err := s.invitationRepo.FindPendingByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, postgres.ErrInvitationNotFound) {
+			return nil, ErrInvitationInvalid
+		}
+		return nil, err
+	}
+
+	passwordHash, err := s.hashNewPassword(newPassword, username, inv.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	admin := &postgres.AdminUser{
+		Username:     username,
+		PasswordHash: passwordHash,
+		Email:        inv.Email,
+		Role:         inv.Role,
+		IsActive:     true,
+	}
+
+	if err := s.invitationRepo.Accept(ctx, inv, admin); err != nil {
+		if errors.Is(err, postgres.ErrInvitationNotFound) {
+			return nil, ErrInvitationInvalid
+		}
+		return nil, err
+	}
+
+	s.logger.Info("admin invitation accepted",
+		"invitation_id", inv.ID,
+		"admin_id", admin.ID,
+		"role", admin.Role,
+	)
+
+	return admin, nil
+}
+
+// UpdateRole changes the role of another admin
+func (s *AdminUserService) UpdateRole(ctx context.Context, actorID, userID uuid.UUID, role string) (*postgres.AdminUser, error) {
+	if actorID == userID {
+		return nil, ErrCannotModifySelf
+	}
+
+	admin, err := s.adminRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if admin.Role == ownerRole && role != ownerRole && admin.IsActive {
+		if err := s.ensureAnotherOwner(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.adminRepo.UpdateRole(ctx, userID, role); err != nil {
+		return nil, err
+	}
+
+	// Existing access tokens carry the old role
+	s.revokeSessions(ctx, userID)
+
+	s.logger.Info("admin role changed",
+		"admin_id", userID,
+		"old_role", admin.Role,
+		"new_role", role,
+		"changed_by", actorID,
+	)
+
+	admin.Role = role
+	return admin, nil
+}
+
+// SetActive activates or deactivates another admin. Deactivation ends all their sessions.
+func (s *AdminUserService) SetActive(ctx context.Context, actorID, userID uuid.UUID, active bool) (*postgres.AdminUser, error) {
+	if actorID == userID {
+		return nil, ErrCannotModifySelf
+	}
+
+	admin, err := s.adminRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !active && admin.IsActive && admin.Role == ownerRole {
+		if err := s.ensureAnotherOwner(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.adminRepo.SetActive(ctx, userID, active); err != nil {
+		return nil, err
+	}
+
+	if !active {
+		s.revokeSessions(ctx, userID)
+	}
+
+	s.logger.Info("admin status changed",
+		"admin_id", userID,
+		"active", active,
+		"changed_by", actorID,
+	)
+
+	admin.IsActive = active
+	return admin, nil
+}
+
+// ResetPassword sets a new password and ends all sessions of the admin.
+// When resetMFA is set, 2FA is removed so the admin can enroll again.
+func (s *AdminUserService) ResetPassword(ctx context.Context, userID uuid.UUID, newPassword string, resetMFA bool) error {
+	admin, err := s.adminRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	passwordHash, err := s.hashNewPassword(newPassword, admin.Username, admin.Email)
+	if err != nil {
+		return err
+	}
+
+	if err := s.adminRepo.UpdatePassword(ctx, userID, passwordHash); err != nil {
+		return err
+	}
+
+	if resetMFA {
+		if err := s.adminRepo.DisableTOTP(ctx, userID); err != nil {
+			return fmt.Errorf("failed to reset 2FA: %w", err)
+		}
+	}
+
+	s.revokeSessions(ctx, userID)
+
+	s.logger.Info("admin password reset",
+		"admin_id", userID,
+		"mfa_reset", resetMFA,
+	)
+
+	return nil
+}
+
+// VerifyPassword checks an admin's password. Hashes using bcrypt or older
+// argon2id parameters are replaced after a successful check. A nil admin
+// never matches but takes as long as a wrong password.
+func (s *AdminUserService) VerifyPassword(ctx context.Context, admin *postgres.AdminUser, plain string) (bool, error) {
+	if admin == nil {
+		s.hasher.VerifyDummy(plain)
+		return false, nil
+	}
+
+	match, needsRehash, err := s.hasher.Verify(plain, admin.PasswordHash)
+	if err != nil || !match {
+		return false, err
+	}
+
+	if needsRehash {
+		upgraded, err := s.hasher.Hash(plain)
+		if err == nil {
+			err = s.adminRepo.UpgradePasswordHash(ctx, admin.ID, admin.PasswordHash, upgraded)
+		}
+		if err != nil {
+			s.logger.Error("failed to upgrade password hash", "admin_id", admin.ID, "error", err)
+		} else {
+			admin.PasswordHash = upgraded
+			s.logger.Info("password hash upgraded", "admin_id", admin.ID)
+		}
+	}
+
+	return true, nil
+}
+
+// hashNewPassword checks a new password against the policy and hashes it
+func (s *AdminUserService) hashNewPassword(plain, username, email string) (string, error) {
+	if err := s.policy.Validate(plain, username, email); err != nil {
+		return "", err
+	}
+	return s.hasher.Hash(plain)
+}
+
+// ensureAnotherOwner fails if the only active owner would lose owner access
+func (s *AdminUserService) ensureAnotherOwner(ctx context.Context) error {
+	owners, err := s.adminRepo.CountActiveOwners(ctx)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// revokeSessions ends all sessions of an admin, logging failures
+func (s *AdminUserService) revokeSessions(ctx context.Context, userID uuid.UUID) {
+	if _, err := s.sessions.LogoutAll(ctx, userID); err != nil {
+		s.logger.Error("failed to revoke admin sessions", "admin_id", userID, "error", err)
+	}
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/reveegate/reveegate/internal/http/middleware"
+	"github.com/reveegate/reveegate/internal/repository/postgres"
+)
+
+// API key errors
+var (
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrAPIKeyScope    = errors.New("scope cannot be granted to an api key")
+	ErrAPIKeyExpiry   = errors.New("api key expiry must be in the future")
+)
+
+// API keys look like "rvg_<43 base64url characters>"
+const (
+	apiKeyPrefix      = "rvg_"
+	apiKeySecretBytes = 32
+	apiKeyShownPrefix = 12
+)
+
+// CreateAPIKeyParams holds the settings for a new API key
+type CreateAPIKeyParams struct {
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time
+	RateLimit *int // Requests per minute, nil uses the default
+	CreatedBy uuid.UUID
+}
+
+// CreatedAPIKey is a new API key together with the key itself, which is only
+// available at creation
+type CreatedAPIKey struct {
+	*postgres.APIKey
+	Key string
+}
+
+// APIKeyService manages API keys and authenticates requests that use them
+type APIKeyService struct {
+	apiKeyRepo       *postgres.APIKeyRepository
+	defaultRateLimit int
+	logger           *slog.Logger
+}
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService(apiKeyRepo *postgres.APIKeyRepository, defaultRateLimit int, logger *slog.Logger) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo:       apiKeyRepo,
+		defaultRateLimit: defaultRateLimit,
+		logger:           logger,
+	}
+}
+
+// List lists all API keys
+func (s *APIKeyService) List(ctx context.Context) ([]*postgres.APIKey, error) {
+	return s.apiKeyRepo.List(ctx)
+}
+
+// Create generates a new API key
+func (s *APIKeyService) Create(ctx context.Context, params CreateAPIKeyParams) (*CreatedAPIKey, error) {
+	scopes := make([]string, 0, len(params.Scopes))
+	seen := make(map[string]bool)
+	for _, scope := range params.Scopes {
+		if !middleware.IsAPIKeyScope(middleware.Permission(scope)) {
+			return nil, fmt.Errorf("%w: %s", ErrAPIKeyScope, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	if params.ExpiresAt != nil && !params.ExpiresAt.After(time.Now()) {
+		return nil, ErrAPIKeyExpiry
+	}
+
+	secret := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	apiKey := &postgres.APIKey{
+		Name:      params.Name,
+		Prefix:    key[:apiKeyShownPrefix],
+		KeyHash:   middleware.HashToken(key),
+		Scopes:    scopes,
+		RateLimit: params.RateLimit,
+		CreatedBy: &params.CreatedBy,
+		ExpiresAt: params.ExpiresAt,
+	}
+
+	if err := s.apiKeyRepo.Create(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("api key created",
+		"api_key_id", apiKey.ID,
+		"name", apiKey.Name,
+		"scopes", scopes,
+		"created_by", params.CreatedBy,
+	)
+
+	return &CreatedAPIKey{APIKey: apiKey, Key: key}, nil
+}
+
+// Revoke revokes an API key and returns it as it was before
+func (s *APIKeyService) Revoke(ctx context.Context, id uuid.UUID) (*postgres.APIKey, error) {
+	apiKey, err := s.apiKeyRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, postgres.ErrAPIKeyNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.apiKeyRepo.Revoke(ctx, id); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("api key revoked", "api_key_id", id, "name", apiKey.Name)
+
+	return apiKey, nil
+}
+
+// RateLimitFor returns the per-minute limit that applies to a key
+func (s *APIKeyService) RateLimitFor(apiKey *postgres.APIKey) int {
+	if apiKey.RateLimit != nil {
+		return *apiKey.RateLimit
+	}
+	return s.defaultRateLimit
+}
+
+// AuthenticateAPIKey implements middleware.APIKeyAuthenticator
+func (s *APIKeyService) AuthenticateAPIKey(ctx context.Context, key, ip string) (*middleware.APIKeyPrincipal, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, middleware.ErrAPIKeyInvalid
+	}
+
+	apiKey, err := s.apiKeyRepo.FindByHash(ctx, middleware.HashToken(key))
+	if err != nil {
+		if errors.Is(err, postgres.ErrAPIKeyNotFound) {
+			return nil, middleware.ErrAPIKeyInvalid
+		}
+		return nil, err
+	}
+
+	if apiKey.RevokedAt != nil {
+		return nil, middleware.ErrAPIKeyInvalid
+	}
+	if apiKey.ExpiresAt != nil && time.Now().After(*apiKey.ExpiresAt) {
+		return nil, middleware.ErrAPIKeyInvalid
+	}
+
+	if err := s.apiKeyRepo.TouchLastUsed(ctx, apiKey.ID, ip); err != nil {
+		s.logger.Warn("failed to record api key use", "api_key_id", apiKey.ID, "error", err)
+	}
+
+	scopes := make([]middleware.Permission, len(apiKey.Scopes))
+	for i, scope := range apiKey.Scopes {
+		scopes[i] = middleware.Permission(scope)
+	}
+
+	return &middleware.APIKeyPrincipal{
+		ID:        apiKey.ID.String(),
+		Name:      apiKey.Name,
+		Scopes:    scopes,
+		RateLimit: s.RateLimitFor(apiKey),
+	}, nil
+}
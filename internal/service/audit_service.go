@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+
+	"github.com/google/uuid"
+
+	"github.com/reveegate/reveegate/internal/repository/postgres"
+)
+
+// Audit actions for admin operations
+const (
+	AuditActionLogin               = "admin.login"
+	AuditActionLoginFailed         = "admin.login_failed"
+	AuditActionLogoutAll           = "session.logout_all"
+	AuditActionSessionRevoke       = "session.revoke"
+	AuditActionAdminCreate         = "admin_user.create"
+	AuditActionAdminInvite         = "admin_user.invite"
+	AuditActionInvitationAccept    = "admin_user.invitation_accept"
+	AuditActionAdminUpdate         = "admin_user.update"
+	AuditActionAdminActivate       = "admin_user.activate"
+	AuditActionAdminDeactivate     = "admin_user.deactivate"
+	AuditActionPasswordReset       = "admin_user.reset_password"
+	AuditActionMFAEnable           = "mfa.enable"
+	AuditActionMFADisable          = "mfa.disable"
+	AuditActionMFARecoveryCodes    = "mfa.recovery_codes"
+	AuditActionMFAPolicyUpdate     = "settings.mfa_policy"
+	AuditActionPaymentReconcile    = "payment.reconcile"
+	AuditActionPaymentMethodUpdate = "payment_method.update"
+	AuditActionOverlayTokenCreate  = "overlay_token.create"
+	AuditActionAPIKeyCreate        = "api_key.create"
+	AuditActionAPIKeyRevoke        = "api_key.revoke"
+	AuditActionBlockCreate         = "fraud.block_create"
+	AuditActionBlockDelete         = "fraud.block_delete"
+	AuditActionFraudRuleCreate     = "fraud.rule_create"
+	AuditActionFraudRuleUpdate     = "fraud.rule_update"
+	AuditActionFraudRuleDelete     = "fraud.rule_delete"
+	AuditActionDecisionApprove     = "fraud.decision_approve"
+	AuditActionDecisionReject      = "fraud.decision_reject"
+	AuditActionDonationsExport     = "donation.export"
+	AuditActionMessageApprove      = "donation.message_approve"
+)
+
+// Audit resource types
+const (
+	AuditResourceAdminUser     = "admin_user"
+	AuditResourceInvitation    = "admin_invitation"
+	AuditResourceSession       = "session"
+	AuditResourceSettings      = "settings"
+	AuditResourcePayment       = "payment"
+	AuditResourcePaymentMethod = "payment_method"
+	AuditResourceOverlayToken  = "overlay_token"
+	AuditResourceAPIKey        = "api_key"
+	AuditResourceDonorBlock    = "donor_block"
+	AuditResourceFraudRule     = "fraud_rule"
+	AuditResourceFraudDecision = "fraud_decision"
+	AuditResourceDonation      = "donation"
+)
+
+// AuditEntry describes an admin action to record. Before and After can be
+// any JSON-encodable value; only the fields that changed are stored.
+type AuditEntry struct {
+	ActorID      *uuid.UUID
+	APIKeyID     string // Set instead of ActorID when an API key made the request
+	Action       string
+	ResourceType string
+	ResourceID   *uuid.UUID
+	Before       interface{}
+	After        interface{}
+	Details      map[string]interface{}
+	IP           string
+	UserAgent    string
+}
+
+// AuditService records admin actions in the tamper-evident audit trail
+type AuditService struct {
+	auditRepo *postgres.AuditRepository
+	logger    *slog.Logger
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(auditRepo *postgres.AuditRepository, logger *slog.Logger) *AuditService {
+	return &AuditService{
+		auditRepo: auditRepo,
+		logger:    logger,
+	}
+}
+
+// Record stores an audit entry. The action it describes has already happened,
+// so failures are logged rather than returned.
+func (s *AuditService) Record(ctx context.Context, entry AuditEntry) {
+	changes := make(map[string]interface{}, len(entry.Details)+2)
+	for k, v := range entry.Details {
+		changes[k] = v
+	}
+	if entry.APIKeyID != "" {
+		changes["api_key_id"] = entry.APIKeyID
+	}
+
+	before, after := diff(toAuditMap(entry.Before), toAuditMap(entry.After))
+	if before != nil {
+		changes["before"] = before
+	}
+	if after != nil {
+		changes["after"] = after
+	}
+
+	log := &postgres.AuditLog{
+		UserID:       entry.ActorID,
+		Action:       entry.Action,
+		ResourceType: entry.ResourceType,
+		ResourceID:   entry.ResourceID,
+		IPAddress:    entry.IP,
+		UserAgent:    entry.UserAgent,
+	}
+	if len(changes) > 0 {
+		log.Changes = changes
+	}
+
+	// Keep recording even if the request that triggered it was cancelled
+	if err := s.auditRepo.Create(context.WithoutCancel(ctx), log); err != nil {
+		s.logger.Error("failed to record audit log",
+			"action", entry.Action,
+			"resource_type", entry.ResourceType,
+			"error", err,
+		)
+	}
+}
+
+// List lists audit log entries
+func (s *AuditService) List(ctx context.Context, filter postgres.AuditLogFilter) (*postgres.AuditLogList, error) {
+	return s.auditRepo.List(ctx, filter)
+}
+
+// Verify checks the audit hash chain for edited or deleted entries
+func (s *AuditService) Verify(ctx context.Context) (*postgres.AuditChainReport, error) {
+	report, err := s.auditRepo.VerifyChain(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !report.Valid {
+		s.logger.Error("audit log chain is broken",
+			"sequence", *report.BrokenAt,
+			"reason", report.Reason,
+		)
+	}
+
+	return report, nil
+}
+
+// diff returns the fields that differ between before and after. A missing
+// side is returned whole, so creations and deletions keep every field.
+func diff(before, after map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	if before == nil || after == nil {
+		return before, after
+	}
+
+	changedBefore := make(map[string]interface{})
+	changedAfter := make(map[string]interface{})
+
+	for k, v := range before {
+		if other, ok := after[k]; !ok || !reflect.DeepEqual(v, other) {
+			changedBefore[k] = v
+		}
+	}
+	for k, v := range after {
+		if other, ok := before[k]; !ok || !reflect.DeepEqual(v, other) {
+			changedAfter[k] = v
+		}
+	}
+
+	return changedBefore, changedAfter
+}
+
+// toAuditMap converts a value to a generic map through its JSON encoding so
+// struct tags decide field names and both sides compare alike
+func toAuditMap(v interface{}) map[string]interface{} {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+
+	// Scalars and lists are wrapped so they still diff as a single field
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return nil
+		}
+		return map[string]interface{}{"value": value}
+	}
+	return m
+}
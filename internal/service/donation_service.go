@@ -8,19 +8,39 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
+	"github.com/reveegate/reveegate/internal/challenge"
 	"github.com/reveegate/reveegate/internal/domain/donation"
+	"github.com/reveegate/reveegate/internal/domain/fraud"
 	"github.com/reveegate/reveegate/internal/domain/payment"
 	"github.com/reveegate/reveegate/internal/domain/provider"
+	"github.com/reveegate/reveegate/internal/metrics"
 	redisRepo "github.com/reveegate/reveegate/internal/repository/redis"
+	"github.com/reveegate/reveegate/internal/telemetry"
 )
 
+// Payment attempt errors
+var (
+	ErrDonationNotPayable = errors.New("donation can no longer be paid")
+	ErrTooManyAttempts    = errors.New("too many payment attempts for this donation")
+)
+
+// maxPaymentAttempts limits how many times a donor can switch payment method
+const maxPaymentAttempts = 5
+
 // DonationService handles donation business logic
 type DonationService struct {
 	donationRepo   donation.Repository
 	paymentRepo    payment.Repository
 	webhookLogRepo payment.WebhookLogRepository
 	provider       provider.Provider
+	providers      provider.ProviderFactory
+	fees           payment.FeeSchedule
+	methods        *PaymentMethodService
+	fraud          *FraudService
+	abuseGuard     *AbuseGuard
 	pubsub         *redisRepo.PubSub
 	cache          *redisRepo.Cache
 	logger         *slog.Logger
@@ -32,6 +52,11 @@ func NewDonationService(
 	paymentRepo payment.Repository,
 	webhookLogRepo payment.WebhookLogRepository,
 	prov provider.Provider,
+	providers provider.ProviderFactory,
+	fees payment.FeeSchedule,
+	methods *PaymentMethodService,
+	fraud *FraudService,
+	abuseGuard *AbuseGuard,
 	pubsub *redisRepo.PubSub,
 	cache *redisRepo.Cache,
 	logger *slog.Logger,
@@ -41,6 +66,11 @@ func NewDonationService(
 		paymentRepo:    paymentRepo,
 		webhookLogRepo: webhookLogRepo,
 		provider:       prov,
+		providers:      providers,
+		fees:           fees,
+		methods:        methods,
+		fraud:          fraud,
+		abuseGuard:     abuseGuard,
 		pubsub:         pubsub,
 		cache:          cache,
 		logger:         logger,
@@ -54,6 +84,10 @@ type CreateDonationParams struct {
 	Message       string
 	Amount        int64
 	PaymentMethod payment.Method
+	CoverFees     bool               // Donor pays the provider fee on top of the amount
+	ClientIP      string             // Kept in metadata for the anti-abuse heuristics
+	Fingerprint   string             // Device fingerprint, kept in metadata for fraud rules
+	Challenge     challenge.Solution // Solution to a challenge issued earlier, if any
 }
 
 // CreateDonationResult holds the result of creating a donation
@@ -63,46 +97,183 @@ type CreateDonationResult struct {
 }
 
 // CreateDonation creates a new donation with payment
-func (s *DonationService) CreateDonation(ctx context.Context, params CreateDonationParams) (*CreateDonationResult, error) {
-	// Validate amount
-	if params.Amount < 5000 {
-		return nil, errors.New("minimum donation amount is Rp 5,000")
-	}
+func (s *DonationService) CreateDonation(ctx context.Context, params CreateDonationParams) (result *CreateDonationResult, err error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "DonationService.CreateDonation",
+		trace.WithAttributes(attribute.String("payment.method", string(params.PaymentMethod))),
+	)
+	defer func() { telemetry.End(span, err) }()
 
-	if params.Amount > 100000000 {
-		return nil, errors.New("maximum donation amount is Rp 100,000,000")
+	// Work out the charged amount and the estimated provider fee
+	quote := s.QuoteFee(params.Amount, params.PaymentMethod, params.CoverFees)
+
+	// Validate method availability and limits against what the donor is charged
+	if err := s.methods.CheckAvailability(ctx, params.PaymentMethod, quote.Total); err != nil {
+		return nil, err
 	}
 
 	// Create donation entity
 	don := donation.NewDonation(params.DonorName, params.DonorEmail, params.Message, params.Amount)
+	if params.ClientIP != "" {
+		don.Metadata["client_ip"] = params.ClientIP
+	}
+	if params.Fingerprint != "" {
+		don.Metadata["fingerprint"] = params.Fingerprint
+	}
+
+	// Blocks and fraud rules come first, then the challenge they or the
+	// anti-abuse heuristics call for
+	minRisk := RiskLow
+	var hold *fraud.Decision
+	decision, err := s.fraud.Evaluate(ctx, fraud.StageCreate, fraudSubject(don, nil))
+	if err != nil {
+		s.logger.Error("failed to evaluate fraud rules, allowing donation", "error", err)
+	} else {
+		switch decision.Action {
+		case fraud.ActionBlock:
+			return nil, ErrDonorBlocked
+		case fraud.ActionChallenge:
+			minRisk = RiskHigh
+		case fraud.ActionHold:
+			don.Metadata["fraud_hold"] = true
+			hold = decision
+		}
+	}
+
+	attempt := DonationAttempt{
+		ClientIP:   params.ClientIP,
+		DonorName:  params.DonorName,
+		DonorEmail: params.DonorEmail,
+		Message:    params.Message,
+	}
+	if _, err := s.abuseGuard.CheckDonation(ctx, attempt, params.Challenge, minRisk); err != nil {
+		return nil, err
+	}
 
 	// Save donation to database
 	if err := s.donationRepo.Create(ctx, don); err != nil {
 		return nil, fmt.Errorf("failed to create donation: %w", err)
 	}
 
+	pay, err := s.startPayment(ctx, don, params.PaymentMethod, params.CoverFees, quote)
+	if err != nil {
+		// Update donation status to failed
+		don.MarkAsFailed()
+		s.donationRepo.Update(ctx, don)
+		return nil, err
+	}
+
+	metrics.RecordDonation(metrics.DonationCreated, pay.PaymentMethod, pay.Provider)
+
+	created := donationEventData(don)
+	created["payment_method"] = pay.PaymentMethod
+	created["provider"] = pay.Provider
+	created["charged"] = pay.Amount
+	publishAdminEvent(ctx, s.pubsub, s.logger, EventDonationCreated, created)
+	if hold != nil {
+		s.publishHold(ctx, don, hold)
+	}
+
+	span.SetAttributes(attribute.String("donation.id", don.ID.String()))
+
+	s.logger.Info("donation created",
+		"donation_id", don.ID,
+		"payment_id", pay.ID,
+		"amount", params.Amount,
+		"charged", pay.Amount,
+		"fee", pay.FeeAmount,
+		"payment_method", params.PaymentMethod,
+	)
+
+	return &CreateDonationResult{
+		Donation: don,
+		Payment:  pay,
+	}, nil
+}
+
+// CreatePaymentAttemptParams holds parameters for retrying a donation with another method
+type CreatePaymentAttemptParams struct {
+	DonationID    uuid.UUID
+	PaymentMethod payment.Method
+	CoverFees     bool
+}
+
+// CreatePaymentAttempt starts a new payment for an unpaid donation, typically with a
+// different method. Pending attempts are cancelled at the provider where possible.
+func (s *DonationService) CreatePaymentAttempt(ctx context.Context, params CreatePaymentAttemptParams) (*CreateDonationResult, error) {
+	don, err := s.donationRepo.GetByID(ctx, params.DonationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !don.CanRetryPayment() {
+		return nil, ErrDonationNotPayable
+	}
+
+	attempts, err := s.paymentRepo.ListByDonationID(ctx, don.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payment attempts: %w", err)
+	}
+
+	if len(attempts) >= maxPaymentAttempts {
+		return nil, ErrTooManyAttempts
+	}
+
+	quote := s.QuoteFee(don.Amount, params.PaymentMethod, params.CoverFees)
+	if err := s.methods.CheckAvailability(ctx, params.PaymentMethod, quote.Total); err != nil {
+		return nil, err
+	}
+
+	// Create the new charge first so the donor is never left without a way to pay
+	pay, err := s.startPayment(ctx, don, params.PaymentMethod, params.CoverFees, quote)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cancelPendingAttempts(ctx, attempts, pay.ID)
+
+	if !don.IsPending() {
+		don.Reopen()
+		if err := s.donationRepo.Update(ctx, don); err != nil {
+			return nil, fmt.Errorf("failed to update donation: %w", err)
+		}
+	}
+
+	s.logger.Info("payment attempt created",
+		"donation_id", don.ID,
+		"payment_id", pay.ID,
+		"attempt", len(attempts)+1,
+		"payment_method", params.PaymentMethod,
+	)
+
+	return &CreateDonationResult{
+		Donation: don,
+		Payment:  pay,
+	}, nil
+}
+
+// startPayment creates a charge at the active provider and saves the payment
+func (s *DonationService) startPayment(ctx context.Context, don *donation.Donation, method payment.Method, coverFees bool, quote FeeQuote) (*payment.Payment, error) {
 	// Set payment expiry (24 hours)
 	expiresAt := time.Now().Add(24 * time.Hour)
 
 	// Create payment entity
-	pay := payment.NewPayment(don.ID, s.provider.GetName(), params.PaymentMethod, params.Amount, expiresAt)
+	pay := payment.NewPayment(don.ID, s.provider.GetName(), method, quote.Total, expiresAt)
+	pay.ApplyFee(quote.Fee, payment.FeeSourceEstimated)
+	pay.FeeCovered = coverFees
 
 	// Create payment with provider
 	paymentReq := provider.PaymentRequest{
 		OrderID:       pay.GenerateOrderID(),
-		Amount:        params.Amount,
-		PaymentMethod: params.PaymentMethod,
-		CustomerName:  params.DonorName,
-		CustomerEmail: params.DonorEmail,
-		Description:   fmt.Sprintf("Donation from %s", params.DonorName),
+		Amount:        pay.Amount,
+		PaymentMethod: method,
+		CustomerName:  don.DonorName,
+		CustomerEmail: don.DonorEmail,
+		Description:   fmt.Sprintf("Donation from %s", don.DonorName),
 		ExpiryTime:    expiresAt,
 	}
 
-	paymentResp, err := s.provider.CreatePayment(ctx, paymentReq)
+	paymentResp, err := s.createAtProvider(ctx, paymentReq)
 	if err != nil {
-		// Update donation status to failed
-		don.MarkAsFailed()
-		s.donationRepo.Update(ctx, don)
 		return nil, fmt.Errorf("failed to create payment: %w", err)
 	}
 
@@ -110,23 +281,124 @@ func (s *DonationService) CreateDonation(ctx context.Context, params CreateDonat
 	pay.SetExternalID(paymentResp.ExternalID)
 	pay.SetPaymentDetails(paymentResp.QRCodeURL, paymentResp.VANumber, paymentResp.DeepLink)
 	pay.ExpiresAt = paymentResp.ExpiresAt
+	if paymentResp.TransactionID != "" {
+		// Needed for status queries on providers that look payments up by their own ID
+		pay.Metadata["transaction_id"] = paymentResp.TransactionID
+	}
+	if traceParent := telemetry.TraceParent(ctx); traceParent != "" {
+		// Lets the webhook that completes the payment link back to this trace
+		pay.Metadata["traceparent"] = traceParent
+	}
 
-	// Save payment to database
-	if err := s.paymentRepo.Create(ctx, pay); err != nil {
+	// Save payment to database. The attempt limit is enforced again here, since
+	// parallel requests can all pass the check before any payment is saved.
+	saved, err := s.paymentRepo.CreateAttempt(ctx, pay, maxPaymentAttempts)
+	if err != nil {
 		return nil, fmt.Errorf("failed to save payment: %w", err)
 	}
+	if !saved {
+		if err := s.cancelAtProvider(ctx, pay); err != nil && !errors.Is(err, provider.ErrCancelNotSupported) {
+			s.logger.Warn("failed to cancel payment over the attempt limit",
+				"donation_id", don.ID,
+				"order_id", pay.ExternalID,
+				"error", err,
+			)
+		}
+		return nil, ErrTooManyAttempts
+	}
 
-	s.logger.Info("donation created",
-		"donation_id", don.ID,
-		"payment_id", pay.ID,
-		"amount", params.Amount,
-		"payment_method", params.PaymentMethod,
+	return pay, nil
+}
+
+// createAtProvider creates a charge at the active provider in its own span
+func (s *DonationService) createAtProvider(ctx context.Context, req provider.PaymentRequest) (resp *provider.PaymentResponse, err error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "provider.CreatePayment",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("payment.provider", string(s.provider.GetName())),
+			attribute.String("payment.method", string(req.PaymentMethod)),
+			attribute.String("payment.order_id", req.OrderID),
+		),
 	)
+	defer func() { telemetry.End(span, err) }()
 
-	return &CreateDonationResult{
-		Donation: don,
-		Payment:  pay,
-	}, nil
+	return s.provider.CreatePayment(ctx, req)
+}
+
+// cancelPendingAttempts cancels every pending attempt except the given payment.
+// Providers without a cancel API leave the charge to expire; if it is paid anyway
+// the first successful attempt still wins and later ones are flagged for refund.
+func (s *DonationService) cancelPendingAttempts(ctx context.Context, attempts []*payment.Payment, keep uuid.UUID) {
+	for _, attempt := range attempts {
+		if attempt.ID == keep || !attempt.IsPending() {
+			continue
+		}
+
+		if err := s.cancelAtProvider(ctx, attempt); err != nil {
+			if errors.Is(err, provider.ErrCancelNotSupported) {
+				s.logger.Info("provider cannot cancel payment, leaving it to expire",
+					"payment_id", attempt.ID,
+					"provider", attempt.Provider,
+					"payment_method", attempt.PaymentMethod,
+				)
+			} else {
+				s.logger.Warn("failed to cancel payment at provider",
+					"payment_id", attempt.ID,
+					"provider", attempt.Provider,
+					"error", err,
+				)
+			}
+		}
+
+		attempt.MarkAsCancelled()
+		if err := s.paymentRepo.Update(ctx, attempt); err != nil {
+			s.logger.Error("failed to cancel payment attempt", "payment_id", attempt.ID, "error", err)
+		}
+	}
+}
+
+// cancelAtProvider cancels a pending payment at the provider that created it
+func (s *DonationService) cancelAtProvider(ctx context.Context, pay *payment.Payment) error {
+	prov, err := s.providers.GetProvider(pay.Provider)
+	if err != nil {
+		return err
+	}
+
+	transactionID, _ := pay.Metadata["transaction_id"].(string)
+
+	return prov.CancelPayment(ctx, provider.StatusRequest{
+		OrderID:       pay.ExternalID,
+		TransactionID: transactionID,
+		PaymentMethod: pay.PaymentMethod,
+	})
+}
+
+// FeeQuote holds the fee breakdown for a donation amount
+type FeeQuote struct {
+	Amount    int64 `json:"amount"`     // Donation amount requested by the donor
+	Fee       int64 `json:"fee"`        // Estimated provider fee
+	Total     int64 `json:"total"`      // Amount charged to the donor
+	NetAmount int64 `json:"net_amount"` // Amount received after fees
+}
+
+// QuoteFee calculates the fee for a donation amount using the active provider's schedule.
+// When the donor covers the fee the total is grossed up so the net amount is at
+// least the requested amount.
+func (s *DonationService) QuoteFee(amount int64, method payment.Method, coverFees bool) FeeQuote {
+	rule := s.fees.Rule(s.provider.GetName(), method)
+
+	total := amount
+	if coverFees {
+		total = rule.GrossUp(amount)
+	}
+	fee := rule.Calculate(total)
+
+	return FeeQuote{
+		Amount:    amount,
+		Fee:       fee,
+		Total:     total,
+		NetAmount: total - fee,
+	}
 }
 
 // GetDonation gets a donation by ID
@@ -141,12 +413,24 @@ func (s *DonationService) GetDonationWithPayment(ctx context.Context, donationID
 		return nil, nil, err
 	}
 
-	pay, err := s.paymentRepo.GetByDonationID(ctx, donationID)
-	if err != nil {
+	attempts, err := s.paymentRepo.ListByDonationID(ctx, donationID)
+	if err != nil || len(attempts) == 0 {
 		return don, nil, nil // Donation exists but no payment
 	}
 
-	return don, pay, nil
+	// Prefer the attempt that completed the donation over later ones
+	for _, pay := range attempts {
+		if pay.IsPaid() && !pay.RefundNeeded {
+			return don, pay, nil
+		}
+	}
+
+	return don, attempts[0], nil
+}
+
+// ListPayments lists payments with filtering
+func (s *DonationService) ListPayments(ctx context.Context, params payment.ListPaymentsParams) (*payment.ListPaymentsResult, error) {
+	return s.paymentRepo.List(ctx, params)
 }
 
 // ListDonations lists donations with filtering
@@ -154,11 +438,96 @@ func (s *DonationService) ListDonations(ctx context.Context, params donation.Lis
 	return s.donationRepo.List(ctx, params)
 }
 
+// ExportDonations passes every donation matching the filters to emit, newest
+// first. Donations created after the export started are left out so pages
+// don't shift while it runs.
+func (s *DonationService) ExportDonations(ctx context.Context, params donation.ListDonationsParams, emit func(*donation.Donation) error) error {
+	if params.EndDate == nil {
+		now := time.Now()
+		params.EndDate = &now
+	}
+	params.Limit = 100
+
+	for params.Page = 1; ; params.Page++ {
+		result, err := s.donationRepo.List(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		for _, don := range result.Donations {
+			if err := emit(don); err != nil {
+				return err
+			}
+		}
+
+		if len(result.Donations) < params.Limit {
+			return nil
+		}
+	}
+}
+
 // GetDonationStats gets donation statistics
 func (s *DonationService) GetDonationStats(ctx context.Context, startDate, endDate time.Time) (*donation.DonationStats, error) {
 	return s.donationRepo.GetStats(ctx, startDate, endDate)
 }
 
+// DashboardStats are the figures the admin dashboard shows for a period
+type DashboardStats struct {
+	TotalDonations     int64   `json:"total_donations"`
+	TotalAmount        int64   `json:"total_amount"`
+	CompletedDonations int64   `json:"completed_donations"`
+	CompletedAmount    int64   `json:"completed_amount"`
+	AverageAmount      float64 `json:"average_amount"`
+	GrossAmount        int64   `json:"gross_amount"`
+	TotalFees          int64   `json:"total_fees"`
+	NetAmount          int64   `json:"net_amount"`
+}
+
+// Dashboard is the admin dashboard overview, served over HTTP and in the
+// snapshot realtime admin clients start from
+type Dashboard struct {
+	Today     DashboardStats `json:"today"`
+	Month     DashboardStats `json:"month"`
+	Timestamp string         `json:"timestamp"`
+}
+
+// GetDashboard gets today's and this month's statistics
+func (s *DonationService) GetDashboard(ctx context.Context) (*Dashboard, error) {
+	now := time.Now()
+	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+
+	today, err := s.donationRepo.GetStats(ctx, todayStart, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get today stats: %w", err)
+	}
+
+	month, err := s.donationRepo.GetStats(ctx, monthStart, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get month stats: %w", err)
+	}
+
+	return &Dashboard{
+		Today:     dashboardStats(today),
+		Month:     dashboardStats(month),
+		Timestamp: now.Format(time.RFC3339),
+	}, nil
+}
+
+// dashboardStats picks the dashboard figures from the donation statistics
+func dashboardStats(stats *donation.DonationStats) DashboardStats {
+	return DashboardStats{
+		TotalDonations:     stats.TotalDonations,
+		TotalAmount:        stats.TotalAmount,
+		CompletedDonations: stats.CompletedDonations,
+		CompletedAmount:    stats.CompletedAmount,
+		AverageAmount:      stats.AverageAmount,
+		GrossAmount:        stats.GrossAmount,
+		TotalFees:          stats.TotalFees,
+		NetAmount:          stats.NetAmount,
+	}
+}
+
 // ProcessWebhookParams holds parameters for processing a webhook
 type ProcessWebhookParams struct {
 	Provider      payment.Provider
@@ -166,11 +535,26 @@ type ProcessWebhookParams struct {
 	TransactionID string
 	Status        payment.Status
 	PaidAt        time.Time
+	Fee           *int64 // Fee reported by the provider, if any
 	RawPayload    []byte
+	ReceivedAt    time.Time // When the webhook arrived, for alert latency
 }
 
 // ProcessWebhook processes a payment webhook
-func (s *DonationService) ProcessWebhook(ctx context.Context, params ProcessWebhookParams) error {
+func (s *DonationService) ProcessWebhook(ctx context.Context, params ProcessWebhookParams) (err error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "DonationService.ProcessWebhook",
+		trace.WithAttributes(
+			attribute.String("payment.provider", string(params.Provider)),
+			attribute.String("payment.order_id", params.OrderID),
+			attribute.String("payment.status", string(params.Status)),
+		),
+	)
+	duplicate := false
+	defer func() {
+		s.publishWebhook(ctx, params, duplicate, err)
+		telemetry.End(span, err)
+	}()
+
 	// Check idempotency
 	idempotencyKey := redisRepo.IdempotencyKey(string(params.Provider), params.OrderID, params.TransactionID)
 
@@ -182,6 +566,7 @@ func (s *DonationService) ProcessWebhook(ctx context.Context, params ProcessWebh
 
 	if !set {
 		// Already processed
+		duplicate = true
 		s.logger.Info("duplicate webhook ignored",
 			"provider", params.Provider,
 			"order_id", params.OrderID,
@@ -196,6 +581,14 @@ func (s *DonationService) ProcessWebhook(ctx context.Context, params ProcessWebh
 		return fmt.Errorf("payment not found: %w", err)
 	}
 
+	// The webhook arrives in its own trace; link it to the one that created the charge
+	span.SetAttributes(attribute.String("donation.id", pay.DonationID.String()))
+	if traceParent, _ := pay.Metadata["traceparent"].(string); traceParent != "" {
+		if link, ok := telemetry.LinkTo(traceParent); ok {
+			span.AddLink(link)
+		}
+	}
+
 	// Skip if already paid
 	if pay.IsPaid() {
 		s.logger.Info("payment already paid", "payment_id", pay.ID)
@@ -207,9 +600,16 @@ func (s *DonationService) ProcessWebhook(ctx context.Context, params ProcessWebh
 	case payment.StatusPaid:
 		pay.MarkAsPaid()
 
-		// Update payment in database
-		if err := s.paymentRepo.Update(ctx, pay); err != nil {
-			return fmt.Errorf("failed to update payment: %w", err)
+		// Replace the estimate with the fee the provider actually charged
+		if params.Fee != nil {
+			if *params.Fee != pay.FeeAmount {
+				s.logger.Info("payment fee corrected",
+					"payment_id", pay.ID,
+					"estimated", pay.FeeAmount,
+					"actual", *params.Fee,
+				)
+			}
+			pay.ApplyFee(*params.Fee, payment.FeeSourceProvider)
 		}
 
 		// Get donation
@@ -218,22 +618,31 @@ func (s *DonationService) ProcessWebhook(ctx context.Context, params ProcessWebh
 			return fmt.Errorf("donation not found: %w", err)
 		}
 
-		// Mark donation as paid
+		// Only the first successful attempt completes the donation
+		if don.IsCompleted() {
+			return s.flagDuplicatePayment(ctx, don, pay)
+		}
+
+		// Save the donation and payment together. The update only applies
+		// while the donation is not completed, so of two attempts paid at
+		// the same time the second is flagged for refund.
+		hold := s.holdAlert(ctx, don, pay)
 		don.MarkAsPaid()
-		if err := s.donationRepo.Update(ctx, don); err != nil {
+		completed, err := s.donationRepo.Complete(ctx, don, pay)
+		if err != nil {
 			return fmt.Errorf("failed to update donation: %w", err)
 		}
+		if !completed {
+			return s.flagDuplicatePayment(ctx, don, pay)
+		}
 
-		// Publish donation event for real-time notification
-		event := redisRepo.NewDonationEvent(
-			don.ID.String(),
-			don.DonorName,
-			don.Message,
-			don.Amount,
-			don.PaidAt.Format(time.RFC3339),
-		)
+		metrics.RecordDonation(metrics.DonationCompleted, pay.PaymentMethod, pay.Provider)
+		s.publishStatus(ctx, don)
 
-		if err := s.pubsub.PublishDonationEvent(ctx, event); err != nil {
+		// Publish donation event for real-time notification
+		if hold != nil {
+			s.publishHold(ctx, don, hold)
+		} else if err := s.publishDonation(ctx, don, params.ReceivedAt); err != nil {
 			s.logger.Error("failed to publish donation event", "error", err)
 			// Don't return error, payment was successful
 		}
@@ -244,78 +653,207 @@ func (s *DonationService) ProcessWebhook(ctx context.Context, params ProcessWebh
 			"amount", don.Amount,
 		)
 
-	case payment.StatusExpired:
-		pay.MarkAsExpired()
-		if err := s.paymentRepo.Update(ctx, pay); err != nil {
-			return fmt.Errorf("failed to update payment: %w", err)
+		// Stop any other attempts the donor left open
+		if attempts, err := s.paymentRepo.ListByDonationID(ctx, don.ID); err == nil {
+			s.cancelPendingAttempts(ctx, attempts, pay.ID)
 		}
 
-		// Update donation status
-		don, _ := s.donationRepo.GetByID(ctx, pay.DonationID)
-		if don != nil {
-			don.MarkAsExpired()
-			s.donationRepo.Update(ctx, don)
+	case payment.StatusExpired, payment.StatusFailed:
+		// A superseded attempt stays cancelled
+		if pay.Status == payment.StatusCancelled {
+			return nil
 		}
 
-	case payment.StatusFailed:
-		pay.MarkAsFailed()
+		if params.Status == payment.StatusExpired {
+			pay.MarkAsExpired()
+		} else {
+			pay.MarkAsFailed()
+		}
 		if err := s.paymentRepo.Update(ctx, pay); err != nil {
 			return fmt.Errorf("failed to update payment: %w", err)
 		}
 
-		// Update donation status
-		don, _ := s.donationRepo.GetByID(ctx, pay.DonationID)
-		if don != nil {
-			don.MarkAsFailed()
-			s.donationRepo.Update(ctx, don)
-		}
+		s.settleFailedAttempt(ctx, pay, params.Status)
+	}
+
+	return nil
+}
+
+// flagDuplicatePayment flags a paid attempt for refund because another
+// attempt already completed its donation
+func (s *DonationService) flagDuplicatePayment(ctx context.Context, don *donation.Donation, pay *payment.Payment) error {
+	pay.FlagForRefund("donation already completed by another payment attempt")
+	if err := s.paymentRepo.Update(ctx, pay); err != nil {
+		return fmt.Errorf("failed to update payment: %w", err)
 	}
 
+	s.logger.Warn("duplicate payment flagged for refund",
+		"donation_id", don.ID,
+		"payment_id", pay.ID,
+		"amount", pay.Amount,
+	)
 	return nil
 }
 
+// publishWebhook tells the admin dashboards how a webhook was processed
+func (s *DonationService) publishWebhook(ctx context.Context, params ProcessWebhookParams, duplicate bool, err error) {
+	data := map[string]interface{}{
+		"provider":       params.Provider,
+		"order_id":       params.OrderID,
+		"transaction_id": params.TransactionID,
+		"status":         params.Status,
+	}
+	if err != nil {
+		data["reason"] = "processing_failed"
+		data["error"] = err.Error()
+		publishAdminEvent(ctx, s.pubsub, s.logger, EventWebhookFailed, data)
+		return
+	}
+
+	data["duplicate"] = duplicate
+	publishAdminEvent(ctx, s.pubsub, s.logger, EventWebhookReceived, data)
+}
+
+// WebhookRejected tells the admin dashboards that a webhook was turned away
+// before processing, e.g. because its signature did not verify
+func (s *DonationService) WebhookRejected(ctx context.Context, prov payment.Provider, reason, ip string) {
+	publishAdminEvent(ctx, s.pubsub, s.logger, EventWebhookFailed, map[string]interface{}{
+		"provider": prov,
+		"reason":   reason,
+		"ip":       ip,
+	})
+}
+
+// settleFailedAttempt updates the donation after an attempt expired or failed.
+// The donation only follows when no other attempt is still pending. It
+// returns the updated donation, or nil when the donation was left as is.
+func (s *DonationService) settleFailedAttempt(ctx context.Context, pay *payment.Payment, status payment.Status) *donation.Donation {
+	don, _ := s.donationRepo.GetByID(ctx, pay.DonationID)
+	if don == nil || !don.IsPending() {
+		return nil
+	}
+
+	attempts, err := s.paymentRepo.ListByDonationID(ctx, don.ID)
+	if err != nil {
+		return nil
+	}
+	for _, attempt := range attempts {
+		if attempt.ID != pay.ID && attempt.IsPending() {
+			return nil
+		}
+	}
+
+	if status == payment.StatusExpired {
+		don.MarkAsExpired()
+		metrics.RecordDonation(metrics.DonationExpired, pay.PaymentMethod, pay.Provider)
+	} else {
+		don.MarkAsFailed()
+		metrics.RecordDonation(metrics.DonationFailed, pay.PaymentMethod, pay.Provider)
+	}
+	if err := s.donationRepo.Update(ctx, don); err != nil {
+		return nil
+	}
+	s.publishStatus(ctx, don)
+
+	return don
+}
+
+// addReconciliationReason records why an admin reconciled a payment by hand
+func addReconciliationReason(pay *payment.Payment, reason string) {
+	pay.Metadata["reconciliation_reason"] = reason
+	pay.Metadata["reconciliation_at"] = time.Now().Format(time.RFC3339)
+}
+
+// ReconcileResult describes what a manual reconciliation changed
+type ReconcileResult struct {
+	PaymentID            uuid.UUID
+	DonationID           uuid.UUID
+	PaymentStatusBefore  payment.Status
+	PaymentStatusAfter   payment.Status
+	DonationStatusBefore donation.Status
+	DonationStatusAfter  donation.Status
+	RefundNeeded         bool
+}
+
 // ManualReconcile manually reconciles a payment
-func (s *DonationService) ManualReconcile(ctx context.Context, paymentID uuid.UUID, status payment.Status, reason string) error {
+func (s *DonationService) ManualReconcile(ctx context.Context, paymentID uuid.UUID, status payment.Status, reason string) (*ReconcileResult, error) {
 	pay, err := s.paymentRepo.GetByID(ctx, paymentID)
 	if err != nil {
-		return fmt.Errorf("payment not found: %w", err)
+		return nil, fmt.Errorf("payment not found: %w", err)
 	}
 
 	don, err := s.donationRepo.GetByID(ctx, pay.DonationID)
 	if err != nil {
-		return fmt.Errorf("donation not found: %w", err)
+		return nil, fmt.Errorf("donation not found: %w", err)
+	}
+
+	result := &ReconcileResult{
+		PaymentID:            pay.ID,
+		DonationID:           don.ID,
+		PaymentStatusBefore:  pay.Status,
+		DonationStatusBefore: don.Status,
 	}
 
 	switch status {
 	case payment.StatusPaid:
+		// Reconciling the attempt that completed the donation changes nothing
+		if pay.IsPaid() && don.IsCompleted() {
+			result.PaymentStatusAfter = pay.Status
+			result.DonationStatusAfter = don.Status
+			result.RefundNeeded = pay.RefundNeeded
+			return result, nil
+		}
+
 		pay.MarkAsPaid()
+		addReconciliationReason(pay, reason)
+
+		// Another attempt already completed the donation
+		if don.IsCompleted() {
+			pay.FlagForRefund("donation already completed by another payment attempt")
+			if err := s.paymentRepo.Update(ctx, pay); err != nil {
+				return nil, fmt.Errorf("failed to update payment: %w", err)
+			}
+			break
+		}
+
+		hold := s.holdAlert(ctx, don, pay)
 		don.MarkAsPaid()
+		completed, err := s.donationRepo.Complete(ctx, don, pay)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update donation: %w", err)
+		}
+		if !completed {
+			// A webhook completed the donation in the meantime
+			pay.FlagForRefund("donation already completed by another payment attempt")
+			if err := s.paymentRepo.Update(ctx, pay); err != nil {
+				return nil, fmt.Errorf("failed to update payment: %w", err)
+			}
+			if don, err = s.donationRepo.GetByID(ctx, pay.DonationID); err != nil {
+				return nil, fmt.Errorf("donation not found: %w", err)
+			}
+			break
+		}
+		metrics.RecordDonation(metrics.DonationCompleted, pay.PaymentMethod, pay.Provider)
+		s.publishStatus(ctx, don)
 
 		// Publish event
-		event := redisRepo.NewDonationEvent(
-			don.ID.String(),
-			don.DonorName,
-			don.Message,
-			don.Amount,
-			don.PaidAt.Format(time.RFC3339),
-		)
-		s.pubsub.PublishDonationEvent(ctx, event)
+		if hold != nil {
+			s.publishHold(ctx, don, hold)
+		} else {
+			s.publishDonation(ctx, don, time.Time{})
+		}
 
 	case payment.StatusFailed:
 		pay.MarkAsFailed()
-		don.MarkAsFailed()
-	}
-
-	// Add reason to metadata
-	pay.Metadata["reconciliation_reason"] = reason
-	pay.Metadata["reconciliation_at"] = time.Now().Format(time.RFC3339)
-
-	if err := s.paymentRepo.Update(ctx, pay); err != nil {
-		return fmt.Errorf("failed to update payment: %w", err)
-	}
+		addReconciliationReason(pay, reason)
+		if err := s.paymentRepo.Update(ctx, pay); err != nil {
+			return nil, fmt.Errorf("failed to update payment: %w", err)
+		}
 
-	if err := s.donationRepo.Update(ctx, don); err != nil {
-		return fmt.Errorf("failed to update donation: %w", err)
+		// The donation only fails when it is pending and no other attempt can still pay it
+		if settled := s.settleFailedAttempt(ctx, pay, payment.StatusFailed); settled != nil {
+			don = settled
+		}
 	}
 
 	s.logger.Info("manual reconciliation completed",
@@ -324,5 +862,163 @@ func (s *DonationService) ManualReconcile(ctx context.Context, paymentID uuid.UU
 		"reason", reason,
 	)
 
-	return nil
+	result.PaymentStatusAfter = pay.Status
+	result.DonationStatusAfter = don.Status
+	result.RefundNeeded = pay.RefundNeeded
+
+	return result, nil
+}
+
+// fraudSubject describes a donation for the fraud rules
+func fraudSubject(don *donation.Donation, pay *payment.Payment) fraud.Subject {
+	subject := fraud.Subject{
+		DonationID: &don.ID,
+		Email:      don.DonorEmail,
+		Name:       don.DonorName,
+		Message:    don.Message,
+		Amount:     don.Amount,
+	}
+	subject.IP, _ = don.Metadata["client_ip"].(string)
+	subject.Fingerprint, _ = don.Metadata["fingerprint"].(string)
+	subject.HeldAtCreation, _ = don.Metadata["fraud_hold"].(bool)
+	if pay != nil {
+		subject.PaymentID = &pay.ID
+	}
+	return subject
+}
+
+// holdAlert evaluates the completion rules for a donation about to be marked
+// paid and returns the decision when its overlay alert has to wait for
+// review, nil otherwise. The caller announces the hold once the donation is
+// completed. Rules that cannot be loaded let the alert through.
+func (s *DonationService) holdAlert(ctx context.Context, don *donation.Donation, pay *payment.Payment) *fraud.Decision {
+	decision, err := s.fraud.Evaluate(ctx, fraud.StageCompletion, fraudSubject(don, pay))
+	if err != nil {
+		s.logger.Error("failed to evaluate fraud rules, releasing alert", "donation_id", don.ID, "error", err)
+		return nil
+	}
+
+	if decision.Action != fraud.ActionHold {
+		return nil
+	}
+
+	don.Metadata["alert_held"] = true
+	s.logger.Warn("donation alert held for review", "donation_id", don.ID, "decision_id", decision.ID)
+	return decision
+}
+
+// publishHold tells the admin dashboards that a donation waits for review
+func (s *DonationService) publishHold(ctx context.Context, don *donation.Donation, decision *fraud.Decision) {
+	reasons := make([]string, 0, len(decision.Matches))
+	for _, match := range decision.Matches {
+		reasons = append(reasons, match.Reason)
+	}
+
+	data := donationEventData(don)
+	data["decision_id"] = decision.ID.String()
+	data["stage"] = decision.Stage
+	data["reasons"] = reasons
+	publishAdminEvent(ctx, s.pubsub, s.logger, EventModerationHeld, data)
+}
+
+// publishDonation publishes a completed donation to the overlays. receivedAt
+// is when the completing webhook arrived, zero when there was none.
+func (s *DonationService) publishDonation(ctx context.Context, don *donation.Donation, receivedAt time.Time) (err error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "publish "+redisRepo.ChannelDonationsNew,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(attribute.String("donation.id", don.ID.String())),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	event := redisRepo.NewDonationEvent(
+		don.ID.String(),
+		don.DonorName,
+		don.Message,
+		don.Amount,
+		don.PaidAt.Format(time.RFC3339),
+	)
+	if !receivedAt.IsZero() {
+		event.ReceivedAt = receivedAt.Format(time.RFC3339Nano)
+	}
+	// The hub continues this trace when it broadcasts the alert
+	event.TraceContext = telemetry.Inject(ctx)
+	return s.pubsub.PublishDonationEvent(ctx, event)
+}
+
+// publishStatus tells the donor's open status streams and the admin
+// dashboards that the donation changed. Failures are only logged; the donor
+// can still fetch the status.
+func (s *DonationService) publishStatus(ctx context.Context, don *donation.Donation) {
+	event := redisRepo.NewDonationStatusEvent(
+		don.ID.String(),
+		string(don.Status),
+		don.DonorName,
+		don.Message,
+		don.Amount,
+		don.PaidAt,
+	)
+	if err := s.pubsub.PublishDonationStatus(ctx, event); err != nil {
+		s.logger.Error("failed to publish donation status", "donation_id", don.ID, "error", err)
+	}
+
+	data := donationEventData(don)
+	switch don.Status {
+	case donation.StatusCompleted:
+		held, _ := don.Metadata["alert_held"].(bool)
+		data["paid_at"] = don.PaidAt
+		data["alert_held"] = held
+		publishAdminEvent(ctx, s.pubsub, s.logger, EventDonationPaid, data)
+	case donation.StatusExpired:
+		publishAdminEvent(ctx, s.pubsub, s.logger, EventDonationExpired, data)
+	case donation.StatusFailed:
+		publishAdminEvent(ctx, s.pubsub, s.logger, EventDonationFailed, data)
+	}
+}
+
+// donationEventData describes a donation in admin events. The donor's email
+// is left out, since not every role may see it.
+func donationEventData(don *donation.Donation) map[string]interface{} {
+	return map[string]interface{}{
+		"donation_id": don.ID.String(),
+		"donor_name":  don.DonorName,
+		"amount":      don.Amount,
+		"status":      don.Status,
+	}
+}
+
+// ReleaseHeldAlert lifts the fraud holds on a donation after review. A paid
+// donation whose alert was held is published to the overlays now; an unpaid
+// one will no longer be held when it completes. It returns false when the
+// donation was not held.
+func (s *DonationService) ReleaseHeldAlert(ctx context.Context, donationID uuid.UUID) (bool, error) {
+	don, err := s.donationRepo.GetByID(ctx, donationID)
+	if err != nil {
+		return false, err
+	}
+
+	held, _ := don.Metadata["alert_held"].(bool)
+	_, heldAtCreation := don.Metadata["fraud_hold"]
+	if !held && !heldAtCreation {
+		return false, nil
+	}
+
+	delete(don.Metadata, "alert_held")
+	delete(don.Metadata, "fraud_hold")
+	if err := s.donationRepo.Update(ctx, don); err != nil {
+		return false, fmt.Errorf("failed to update donation: %w", err)
+	}
+
+	published := held && don.IsCompleted()
+	if published {
+		if err := s.publishDonation(ctx, don, time.Time{}); err != nil {
+			return true, fmt.Errorf("failed to publish donation event: %w", err)
+		}
+		s.logger.Info("held donation alert released", "donation_id", don.ID)
+	}
+
+	data := donationEventData(don)
+	data["alert_published"] = published
+	publishAdminEvent(ctx, s.pubsub, s.logger, EventModerationReleased, data)
+
+	return true, nil
 }
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/reveegate/reveegate/internal/domain/fraud"
+	"github.com/reveegate/reveegate/internal/repository/postgres"
+)
+
+// Fraud errors
+var (
+	ErrDonorBlocked          = errors.New("donations from this donor are not accepted")
+	ErrBlockNotFound         = errors.New("block not found")
+	ErrBlockExists           = errors.New("an identical block already exists")
+	ErrBlockExpiry           = errors.New("block expiry must be in the future")
+	ErrFraudRuleNotFound     = errors.New("fraud rule not found")
+	ErrFraudDecisionNotFound = errors.New("fraud decision not found")
+	ErrDecisionNotPending    = errors.New("fraud decision is not awaiting review")
+)
+
+// FraudService evaluates donor blocks and fraud rules, keeps the decision log
+// and manages both for admins
+type FraudService struct {
+	fraudRepo    *postgres.FraudRepository
+	donationRepo *postgres.DonationRepository
+	logger       *slog.Logger
+}
+
+// NewFraudService creates a new fraud service
+func NewFraudService(fraudRepo *postgres.FraudRepository, donationRepo *postgres.DonationRepository, logger *slog.Logger) *FraudService {
+	return &FraudService{
+		fraudRepo:    fraudRepo,
+		donationRepo: donationRepo,
+		logger:       logger,
+	}
+}
+
+// Evaluate applies the active blocks and the enabled rules of a stage to a
+// subject and records the decision. An error means the rules could not be
+// loaded; callers decide whether to fail open.
+func (s *FraudService) Evaluate(ctx context.Context, stage fraud.Stage, subject fraud.Subject) (*fraud.Decision, error) {
+	blocks, err := s.fraudRepo.ListBlocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := s.fraudRepo.ListEnabledRules(ctx, stage)
+	if err != nil {
+		return nil, err
+	}
+
+	decision := fraud.Evaluate(ctx, stage, subject, blocks, rules, donationFacts{repo: s.donationRepo})
+
+	// Only decisions that act on a donation are logged; creation is evaluated
+	// before the challenge is checked, so logging allows would let anyone
+	// fill the table
+	if decision.Action != fraud.ActionAllow {
+		// The log must be written even if the request goes away
+		if err := s.fraudRepo.CreateDecision(context.WithoutCancel(ctx), decision); err != nil {
+			s.logger.Error("failed to record fraud decision", "decision_id", decision.ID, "error", err)
+		}
+
+		reasons := make([]string, len(decision.Matches))
+		for i, m := range decision.Matches {
+			reasons[i] = m.Reason
+		}
+
+		s.logger.Warn("fraud rules matched",
+			"decision_id", decision.ID,
+			"stage", stage,
+			"action", decision.Action,
+			"donation_id", subject.DonationID,
+			"ip", subject.IP,
+			"reasons", reasons,
+		)
+	}
+
+	return decision, nil
+}
+
+// ListBlocks lists all blocks
+func (s *FraudService) ListBlocks(ctx context.Context) ([]*fraud.Block, error) {
+	return s.fraudRepo.ListBlocks(ctx)
+}
+
+// CreateBlock validates and stores a new block
+func (s *FraudService) CreateBlock(ctx context.Context, block *fraud.Block) error {
+	if err := block.Normalize(); err != nil {
+		return err
+	}
+	if block.ExpiresAt != nil && !block.ExpiresAt.After(time.Now()) {
+		return ErrBlockExpiry
+	}
+
+	if err := s.fraudRepo.CreateBlock(ctx, block); err != nil {
+		if errors.Is(err, postgres.ErrBlockExists) {
+			return ErrBlockExists
+		}
+		return err
+	}
+
+	s.logger.Info("donor block created", "block_id", block.ID, "type", block.Type, "created_by", block.CreatedBy)
+
+	return nil
+}
+
+// DeleteBlock deletes a block and returns it
+func (s *FraudService) DeleteBlock(ctx context.Context, id uuid.UUID) (*fraud.Block, error) {
+	block, err := s.fraudRepo.DeleteBlock(ctx, id)
+	if err != nil {
+		if errors.Is(err, postgres.ErrBlockNotFound) {
+			return nil, ErrBlockNotFound
+		}
+		return nil, err
+	}
+
+	s.logger.Info("donor block deleted", "block_id", id, "type", block.Type)
+
+	return block, nil
+}
+
+// ListRules lists all rules
+func (s *FraudService) ListRules(ctx context.Context) ([]*fraud.Rule, error) {
+	return s.fraudRepo.ListRules(ctx)
+}
+
+// GetRule gets a rule by ID
+func (s *FraudService) GetRule(ctx context.Context, id uuid.UUID) (*fraud.Rule, error) {
+	rule, err := s.fraudRepo.GetRule(ctx, id)
+	if err != nil {
+		if errors.Is(err, postgres.ErrRuleNotFound) {
+			return nil, ErrFraudRuleNotFound
+		}
+		return nil, err
+	}
+	return rule, nil
+}
+
+// CreateRule validates and stores a new rule
+func (s *FraudService) CreateRule(ctx context.Context, rule *fraud.Rule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.fraudRepo.CreateRule(ctx, rule); err != nil {
+		return err
+	}
+
+	s.logger.Info("fraud rule created", "rule_id", rule.ID, "name", rule.Name, "created_by", rule.CreatedBy)
+
+	return nil
+}
+
+// UpdateRule validates and saves changes to a rule
+func (s *FraudService) UpdateRule(ctx context.Context, rule *fraud.Rule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.fraudRepo.UpdateRule(ctx, rule); err != nil {
+		if errors.Is(err, postgres.ErrRuleNotFound) {
+			return ErrFraudRuleNotFound
+		}
+		return err
+	}
+
+	s.logger.Info("fraud rule updated", "rule_id", rule.ID, "name", rule.Name)
+
+	return nil
+}
+
+// DeleteRule deletes a rule
+func (s *FraudService) DeleteRule(ctx context.Context, id uuid.UUID) error {
+	if err := s.fraudRepo.DeleteRule(ctx, id); err != nil {
+		if errors.Is(err, postgres.ErrRuleNotFound) {
+			return ErrFraudRuleNotFound
+		}
+		return err
+	}
+
+	s.logger.Info("fraud rule deleted", "rule_id", id)
+
+	return nil
+}
+
+// ListDecisions lists logged decisions
+func (s *FraudService) ListDecisions(ctx context.Context, filter postgres.FraudDecisionFilter) (*postgres.FraudDecisionList, error) {
+	return s.fraudRepo.ListDecisions(ctx, filter)
+}
+
+// Review approves or rejects a decision awaiting review and returns it as it
+// was before
+func (s *FraudService) Review(ctx context.Context, id uuid.UUID, approve bool, reviewer uuid.UUID) (*fraud.Decision, error) {
+	before, err := s.fraudRepo.GetDecision(ctx, id)
+	if err != nil {
+		if errors.Is(err, postgres.ErrDecisionNotFound) {
+			return nil, ErrFraudDecisionNotFound
+		}
+		return nil, err
+	}
+
+	if before.ReviewStatus != fraud.ReviewPending {
+		return nil, ErrDecisionNotPending
+	}
+
+	status := fraud.ReviewRejected
+	if approve {
+		status = fraud.ReviewApproved
+	}
+
+	if _, err := s.fraudRepo.Review(ctx, id, status, &reviewer); err != nil {
+		if errors.Is(err, postgres.ErrDecisionNotFound) {
+			// Someone else reviewed it first
+			return nil, ErrDecisionNotPending
+		}
+		return nil, err
+	}
+
+	s.logger.Info("fraud decision reviewed", "decision_id", id, "status", status, "reviewed_by", reviewer)
+
+	return before, nil
+}
+
+// donationFacts looks up rule facts in the donation history
+type donationFacts struct {
+	repo *postgres.DonationRepository
+}
+
+// PendingFromIP implements fraud.Facts
+func (f donationFacts) PendingFromIP(ctx context.Context, ip string, since time.Time) (int, error) {
+	return f.repo.CountPendingByClientIP(ctx, ip, since)
+}
+
+// OtherDonorsWithMessage implements fraud.Facts
+func (f donationFacts) OtherDonorsWithMessage(ctx context.Context, s *fraud.Subject, since time.Time) (int, error) {
+	return f.repo.CountOtherDonorsWithMessage(ctx, s.Message, s.Email, s.Name, s.IP, since)
+}
+
+// DonationsFromDonor implements fraud.Facts
+func (f donationFacts) DonationsFromDonor(ctx context.Context, s *fraud.Subject, since time.Time) (int, error) {
+	return f.repo.CountByDonor(ctx, s.Email, s.Name, s.IP, since)
+}
+
+// IsNewDonor implements fraud.Facts
+func (f donationFacts) IsNewDonor(ctx context.Context, s *fraud.Subject) (bool, error) {
+	var exclude uuid.UUID
+	if s.DonationID != nil {
+		exclude = *s.DonationID
+	}
+
+	completed, err := f.repo.HasCompletedDonation(ctx, s.Email, s.Name, s.IP, exclude)
+	if err != nil {
+		return false, fmt.Errorf("failed to look up donor history: %w", err)
+	}
+	return !completed, nil
+}
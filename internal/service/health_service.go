@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/reveegate/reveegate/internal/domain/payment"
+	"github.com/reveegate/reveegate/internal/domain/provider"
+	redisRepo "github.com/reveegate/reveegate/internal/repository/redis"
+)
+
+// Component health states
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+	HealthDown     = "down"
+)
+
+// Overall health states
+const (
+	HealthStatusHealthy   = "healthy"
+	HealthStatusDegraded  = "degraded"
+	HealthStatusUnhealthy = "unhealthy"
+)
+
+const (
+	// healthCheckTimeout bounds each dependency check
+	healthCheckTimeout = 2 * time.Second
+	// slowPingThreshold marks a dependency degraded when pings get this slow
+	slowPingThreshold = 250 * time.Millisecond
+	// providerCheckTTL is how long provider reachability is cached. Provider
+	// APIs are remote and rate limited, so they are not checked on every call.
+	providerCheckTTL      = time.Minute
+	providerCheckTimeout  = 5 * time.Second
+	slowProviderThreshold = 2 * time.Second
+)
+
+// ErrShuttingDown is reported by readiness while the server drains
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// HubStats reports realtime hub statistics
+type HubStats interface {
+	GetStats() map[string]interface{}
+}
+
+// ComponentHealth is the result of checking one dependency
+type ComponentHealth struct {
+	Status    string
+	Critical  bool // Whether the instance can serve traffic without it
+	Latency   time.Duration
+	Error     string
+	Details   map[string]interface{}
+	CheckedAt time.Time
+}
+
+// HealthReport is the result of checking all dependencies
+type HealthReport struct {
+	Status     string
+	Ready      bool
+	Components map[string]*ComponentHealth
+	CheckedAt  time.Time
+}
+
+// HealthService checks the dependencies the server relies on. Liveness only
+// means the process is serving; readiness needs the database, Redis and the
+// pub/sub subscriptions; the detailed report adds the realtime hub and the
+// payment providers.
+type HealthService struct {
+	db        *pgxpool.Pool
+	cache     *redisRepo.Cache
+	pubsub    *redisRepo.PubSub
+	hub       HubStats
+	providers provider.ProviderFactory
+	logger    *slog.Logger
+
+	shuttingDown atomic.Bool
+
+	// Cached provider checks; the mutex is held while they are refreshed
+	providerMu      sync.Mutex
+	providerResults map[payment.Provider]*ComponentHealth
+}
+
+// NewHealthService creates a new health service
+func NewHealthService(
+	db *pgxpool.Pool,
+	cache *redisRepo.Cache,
+	pubsub *redisRepo.PubSub,
+	hub HubStats,
+	providers provider.ProviderFactory,
+	logger *slog.Logger,
+) *HealthService {
+	return &HealthService{
+		db:              db,
+		cache:           cache,
+		pubsub:          pubsub,
+		hub:             hub,
+		providers:       providers,
+		logger:          logger,
+		providerResults: make(map[payment.Provider]*ComponentHealth),
+	}
+}
+
+// BeginShutdown makes readiness fail so load balancers stop sending traffic
+// while in-flight requests finish
+func (s *HealthService) BeginShutdown() {
+	s.shuttingDown.Store(true)
+}
+
+// Readiness checks the dependencies needed to serve traffic
+func (s *HealthService) Readiness(ctx context.Context) *HealthReport {
+	report := s.run(ctx, map[string]func(context.Context) *ComponentHealth{
+		"database": s.checkDatabase,
+		"redis":    s.checkRedis,
+		"pubsub":   s.checkPubSub,
+	})
+
+	if s.shuttingDown.Load() {
+		report.Ready = false
+		report.Status = HealthStatusUnhealthy
+		report.Components["server"] = &ComponentHealth{
+			Status:    HealthDown,
+			Critical:  true,
+			Error:     ErrShuttingDown.Error(),
+			CheckedAt: report.CheckedAt,
+		}
+	}
+
+	return report
+}
+
+// Check checks every dependency, including the ones the server can run
+// without, for the admin health view
+func (s *HealthService) Check(ctx context.Context) *HealthReport {
+	report := s.Readiness(ctx)
+
+	report.Components["websocket"] = s.checkHub()
+	for name, result := range s.checkProviders(ctx) {
+		report.Components["provider:"+string(name)] = result
+	}
+
+	report.Status, report.Ready = overallStatus(report.Components)
+	if s.shuttingDown.Load() {
+		report.Ready = false
+	}
+
+	return report
+}
+
+// run runs checks concurrently and summarizes them
+func (s *HealthService) run(ctx context.Context, checks map[string]func(context.Context) *ComponentHealth) *HealthReport {
+	report := &HealthReport{
+		Components: make(map[string]*ComponentHealth, len(checks)),
+		CheckedAt:  time.Now(),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(context.Context) *ComponentHealth) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+
+			result := check(checkCtx)
+			if result.Status == HealthDown {
+				s.logger.Warn("health check failed", "component", name, "error", result.Error)
+			}
+
+			mu.Lock()
+			report.Components[name] = result
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+
+	report.Status, report.Ready = overallStatus(report.Components)
+	return report
+}
+
+// overallStatus is unhealthy when a critical component is down and degraded
+// when anything else is not ok
+func overallStatus(components map[string]*ComponentHealth) (string, bool) {
+	status := HealthStatusHealthy
+	for _, c := range components {
+		switch {
+		case c.Status == HealthDown && c.Critical:
+			return HealthStatusUnhealthy, false
+		case c.Status != HealthOK:
+			status = HealthStatusDegraded
+		}
+	}
+	return status, true
+}
+
+// checkDatabase pings Postgres and reports pool usage. A pool with every
+// connection in use is degraded.
+func (s *HealthService) checkDatabase(ctx context.Context) *ComponentHealth {
+	start := time.Now()
+	err := s.db.Ping(ctx)
+	result := newComponentHealth(true, time.Since(start), slowPingThreshold, err)
+
+	stat := s.db.Stat()
+	result.Details = map[string]interface{}{
+		"total_conns":            stat.TotalConns(),
+		"idle_conns":             stat.IdleConns(),
+		"acquired_conns":         stat.AcquiredConns(),
+		"constructing_conns":     stat.ConstructingConns(),
+		"max_conns":              stat.MaxConns(),
+		"acquire_count":          stat.AcquireCount(),
+		"empty_acquire_count":    stat.EmptyAcquireCount(),
+		"canceled_acquire_count": stat.CanceledAcquireCount(),
+		"acquire_duration_ms":    stat.AcquireDuration().Milliseconds(),
+	}
+
+	if result.Status == HealthOK && stat.AcquiredConns() >= stat.MaxConns() {
+		result.Status = HealthDegraded
+		result.Error = "connection pool exhausted"
+	}
+
+	return result
+}
+
+// checkRedis pings Redis and reports pool usage
+func (s *HealthService) checkRedis(ctx context.Context) *ComponentHealth {
+	start := time.Now()
+	err := s.cache.Ping(ctx)
+	result := newComponentHealth(true, time.Since(start), slowPingThreshold, err)
+
+	stats := s.cache.PoolStats()
+	result.Details = map[string]interface{}{
+		"total_conns": stats.TotalConns,
+		"idle_conns":  stats.IdleConns,
+		"stale_conns": stats.StaleConns,
+		"hits":        stats.Hits,
+		"misses":      stats.Misses,
+		"timeouts":    stats.Timeouts,
+	}
+
+	return result
+}
+
+// checkPubSub pings every subscription the realtime hub depends on. Without
+// them this instance would not deliver alerts to its overlays.
+func (s *HealthService) checkPubSub(ctx context.Context) *ComponentHealth {
+	start := time.Now()
+	subs := s.pubsub.PingSubscriptions(ctx)
+
+	var err error
+	channels := make([]string, 0, len(subs))
+	for _, sub := range subs {
+		channels = append(channels, sub.Channels...)
+		if sub.Err != nil && err == nil {
+			err = fmt.Errorf("subscription to %s: %w", strings.Join(sub.Channels, ", "), sub.Err)
+		}
+	}
+	if len(subs) == 0 {
+		err = errors.New("no active subscriptions")
+	}
+
+	result := newComponentHealth(true, time.Since(start), slowPingThreshold, err)
+	result.Details = map[string]interface{}{
+		"subscriptions": len(subs),
+		"channels":      channels,
+	}
+
+	return result
+}
+
+// checkHub reports connected realtime clients
+func (s *HealthService) checkHub() *ComponentHealth {
+	return &ComponentHealth{
+		Status:    HealthOK,
+		Details:   s.hub.GetStats(),
+		CheckedAt: time.Now(),
+	}
+}
+
+// checkProviders returns provider reachability, refreshing results older
+// than providerCheckTTL
+func (s *HealthService) checkProviders(ctx context.Context) map[payment.Provider]*ComponentHealth {
+	s.providerMu.Lock()
+	defer s.providerMu.Unlock()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for _, prov := range s.providers.GetAllProviders() {
+		name := prov.GetName()
+		if cached, ok := s.providerResults[name]; ok && time.Since(cached.CheckedAt) < providerCheckTTL {
+			continue
+		}
+
+		wg.Add(1)
+		go func(prov provider.Provider) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, providerCheckTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := prov.Ping(checkCtx)
+			result := newComponentHealth(false, time.Since(start), slowProviderThreshold, err)
+
+			mu.Lock()
+			s.providerResults[prov.GetName()] = result
+			mu.Unlock()
+		}(prov)
+	}
+	wg.Wait()
+
+	results := make(map[payment.Provider]*ComponentHealth, len(s.providerResults))
+	for name, result := range s.providerResults {
+		results[name] = result
+	}
+	return results
+}
+
+// newComponentHealth builds a check result from a ping, degraded when it took
+// longer than slow
+func newComponentHealth(critical bool, latency, slow time.Duration, err error) *ComponentHealth {
+	result := &ComponentHealth{
+		Status:    HealthOK,
+		Critical:  critical,
+		Latency:   latency,
+		CheckedAt: time.Now(),
+	}
+
+	switch {
+	case err != nil:
+		result.Status = HealthDown
+		result.Error = err.Error()
+	case latency > slow:
+		result.Status = HealthDegraded
+		result.Error = fmt.Sprintf("slow response (%s)", latency.Round(time.Millisecond))
+	}
+
+	return result
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/reveegate/reveegate/internal/repository/postgres"
+)
+
+// Idempotency errors
+var (
+	ErrIdempotencyKeyReused  = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyInProgress = errors.New("a request with this idempotency key is still in progress")
+)
+
+const (
+	idempotencyTTL = 24 * time.Hour
+	// Longer than any request can run, after which a crashed request's key
+	// can be taken over by a retry
+	idempotencyLock = time.Minute
+	// How long a duplicate waits for the first request before giving up
+	idempotencyWait    = 10 * time.Second
+	idempotencyPollMin = 50 * time.Millisecond
+	idempotencyPollMax = 500 * time.Millisecond
+	idempotencyPurge   = time.Hour
+)
+
+// IdempotentResponse is a stored response replayed to a duplicate request
+type IdempotentResponse struct {
+	Status int
+	Body   []byte
+}
+
+// IdempotencyClaim is ownership of a key while its request is processed
+type IdempotencyClaim struct {
+	scope string
+	key   string
+}
+
+// IdempotencyService makes requests carrying an Idempotency-Key safe to retry
+type IdempotencyService struct {
+	idempotencyRepo *postgres.IdempotencyRepository
+	logger          *slog.Logger
+
+	lastPurge atomic.Int64 // Unix seconds
+}
+
+// NewIdempotencyService creates a new idempotency service
+func NewIdempotencyService(idempotencyRepo *postgres.IdempotencyRepository, logger *slog.Logger) *IdempotencyService {
+	return &IdempotencyService{
+		idempotencyRepo: idempotencyRepo,
+		logger:          logger,
+	}
+}
+
+// Begin starts processing a request. The first request with a key gets a
+// claim and must call Complete or Release. Duplicates wait for it and get its
+// stored response, or ErrIdempotencyInProgress if it takes too long. A key
+// sent with a different request fails with ErrIdempotencyKeyReused.
+func (s *IdempotencyService) Begin(ctx context.Context, scope, key, fingerprint string) (*IdempotencyClaim, *IdempotentResponse, error) {
+	s.purgeExpired()
+
+	deadline := time.Now().Add(idempotencyWait)
+	poll := idempotencyPollMin
+
+	for {
+		acquired, err := s.idempotencyRepo.Acquire(ctx, scope, key, fingerprint, idempotencyLock, idempotencyTTL)
+		if err != nil {
+			return nil, nil, err
+		}
+		if acquired {
+			return &IdempotencyClaim{scope: scope, key: key}, nil, nil
+		}
+
+		record, err := s.idempotencyRepo.Get(ctx, scope, key)
+		if err != nil && !errors.Is(err, postgres.ErrIdempotencyKeyNotFound) {
+			return nil, nil, err
+		}
+
+		// A missing record was released in between, so try to claim it again
+		if record != nil {
+			if record.Fingerprint != fingerprint {
+				return nil, nil, ErrIdempotencyKeyReused
+			}
+
+			if record.Status == postgres.IdempotencyStatusCompleted && record.ResponseStatus != nil {
+				return nil, &IdempotentResponse{Status: *record.ResponseStatus, Body: record.ResponseBody}, nil
+			}
+
+			if time.Now().After(deadline) {
+				return nil, nil, ErrIdempotencyInProgress
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil, nil, ctx.Err()
+			case <-time.After(poll):
+			}
+			poll = min(2*poll, idempotencyPollMax)
+		}
+	}
+}
+
+// Complete stores the response for a claimed key. It is stored even if the
+// client has gone away, since that client is the one that will retry.
+func (s *IdempotencyService) Complete(ctx context.Context, claim *IdempotencyClaim, status int, body []byte, resourceID *uuid.UUID) {
+	err := s.idempotencyRepo.Complete(context.WithoutCancel(ctx), claim.scope, claim.key, status, body, resourceID)
+	if err != nil {
+		s.logger.Error("failed to store idempotent response", "scope", claim.scope, "key", claim.key, "error", err)
+	}
+}
+
+// Release gives up a claimed key after a failed request so it can be retried
+func (s *IdempotencyService) Release(ctx context.Context, claim *IdempotencyClaim) {
+	if err := s.idempotencyRepo.Release(context.WithoutCancel(ctx), claim.scope, claim.key); err != nil {
+		s.logger.Error("failed to release idempotency key", "scope", claim.scope, "key", claim.key, "error", err)
+	}
+}
+
+// purgeExpired deletes expired keys in the background, at most once an hour
+func (s *IdempotencyService) purgeExpired() {
+	now := time.Now().Unix()
+	last := s.lastPurge.Load()
+	if now-last < int64(idempotencyPurge.Seconds()) || !s.lastPurge.CompareAndSwap(last, now) {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		deleted, err := s.idempotencyRepo.DeleteExpired(ctx)
+		if err != nil {
+			s.logger.Warn("failed to purge idempotency keys", "error", err)
+			return
+		}
+		if deleted > 0 {
+			s.logger.Info("purged expired idempotency keys", "count", deleted)
+		}
+	}()
+}
+
+// IdempotencyFingerprint identifies a request by its method, path and
+// decoded body, so formatting differences in the JSON don't matter
+func IdempotencyFingerprint(method, path string, body interface{}) (string, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(method + " " + path + "\n" + string(data)))
+	return hex.EncodeToString(sum[:]), nil
+}
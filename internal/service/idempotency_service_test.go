@@ -0,0 +1,90 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/reveegate/reveegate/internal/http/dto"
+)
+
+const donationsPath = "/api/v1/donations"
+
+const baseDonationBody = `{"donor_name":"Budi","message":"Semangat!","amount":50000,"payment_method":"qris"}`
+
+func donationFingerprint(t *testing.T, method, path, body string) string {
+	t.Helper()
+
+	var req dto.CreateDonationRequest
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatalf("failed to decode request: %v", err)
+	}
+
+	fingerprint, err := IdempotencyFingerprint(method, path, req)
+	if err != nil {
+		t.Fatalf("IdempotencyFingerprint() error = %v", err)
+	}
+	return fingerprint
+}
+
+func TestIdempotencyFingerprint(t *testing.T) {
+	base := donationFingerprint(t, http.MethodPost, donationsPath, baseDonationBody)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		same   bool
+	}{
+		{"identical request", http.MethodPost, donationsPath, baseDonationBody, true},
+		{"reordered fields", http.MethodPost, donationsPath, `{"payment_method":"qris","amount":50000,"message":"Semangat!","donor_name":"Budi"}`, true},
+		{"extra whitespace", http.MethodPost, donationsPath, "{\n  \"donor_name\": \"Budi\",\n  \"message\": \"Semangat!\",\n  \"amount\": 50000,\n  \"payment_method\": \"qris\"\n}", true},
+		{"explicit defaults", http.MethodPost, donationsPath, `{"donor_name":"Budi","donor_email":"","message":"Semangat!","amount":50000,"payment_method":"qris","cover_fees":false}`, true},
+		{"unknown field", http.MethodPost, donationsPath, `{"donor_name":"Budi","message":"Semangat!","amount":50000,"payment_method":"qris","utm_source":"stream"}`, true},
+		{"different amount", http.MethodPost, donationsPath, `{"donor_name":"Budi","message":"Semangat!","amount":500000,"payment_method":"qris"}`, false},
+		{"different method", http.MethodPost, donationsPath, `{"donor_name":"Budi","message":"Semangat!","amount":50000,"payment_method":"dana"}`, false},
+		{"different message", http.MethodPost, donationsPath, `{"donor_name":"Budi","message":"Semangat!!","amount":50000,"payment_method":"qris"}`, false},
+		{"covering fees", http.MethodPost, donationsPath, `{"donor_name":"Budi","message":"Semangat!","amount":50000,"payment_method":"qris","cover_fees":true}`, false},
+		{"different path", http.MethodPost, "/api/v1/donations/preview", baseDonationBody, false},
+		{"different http method", http.MethodPut, donationsPath, baseDonationBody, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := donationFingerprint(t, tt.method, tt.path, tt.body)
+			if (got == base) != tt.same {
+				t.Errorf("fingerprint matches = %v, want %v", got == base, tt.same)
+			}
+		})
+	}
+}
+
+func TestIdempotencyFingerprintIsStable(t *testing.T) {
+	// Maps marshal with sorted keys, so fingerprints don't depend on iteration order
+	body := map[string]interface{}{"payment_method": "qris", "amount": 50000, "donor_name": "Budi", "message": "Semangat!"}
+
+	first, err := IdempotencyFingerprint(http.MethodPost, donationsPath, body)
+	if err != nil {
+		t.Fatalf("IdempotencyFingerprint() error = %v", err)
+	}
+	if len(first) != 64 {
+		t.Errorf("fingerprint = %q, want a hex SHA-256", first)
+	}
+
+	for i := 0; i < 20; i++ {
+		got, err := IdempotencyFingerprint(http.MethodPost, donationsPath, body)
+		if err != nil {
+			t.Fatalf("IdempotencyFingerprint() error = %v", err)
+		}
+		if got != first {
+			t.Fatalf("fingerprint changed from %s to %s", first, got)
+		}
+	}
+}
+
+func TestIdempotencyFingerprintRejectsUnencodableBody(t *testing.T) {
+	if _, err := IdempotencyFingerprint(http.MethodPost, donationsPath, make(chan int)); err == nil {
+		t.Error("IdempotencyFingerprint() accepted a body that can't be encoded")
+	}
+}
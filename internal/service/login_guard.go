@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/reveegate/reveegate/internal/config"
+	redisRepo "github.com/reveegate/reveegate/internal/repository/redis"
+)
+
+// Login protection errors
+var (
+	ErrLoginThrottled = errors.New("too many failed login attempts, slow down")
+	ErrLoginLocked    = errors.New("login temporarily locked after too many failed attempts")
+)
+
+// Login protection scopes
+const (
+	LoginScopeIP      = "ip"
+	LoginScopeAccount = "account"
+)
+
+// Audit actions for login protection
+const (
+	AuditActionLoginLockout = "login.lockout"
+	AuditActionLoginUnlock  = "login.unlock"
+)
+
+// LoginAttempt identifies who is trying to log in
+type LoginAttempt struct {
+	IP         string
+	Identifier string     // Username or email as submitted
+	AdminID    *uuid.UUID // Set when the identifier matches an admin
+	UserAgent  string
+}
+
+// accountSubject keys account counters by admin ID so username and email
+// share one counter. Unknown identifiers are tracked too, so lockouts do not
+// reveal which accounts exist.
+func (a LoginAttempt) accountSubject() string {
+	if a.AdminID != nil {
+		return a.AdminID.String()
+	}
+	return "name:" + strings.ToLower(strings.TrimSpace(a.Identifier))
+}
+
+// loginScope is one counter an attempt is tracked under
+type loginScope struct {
+	name    string
+	subject string
+	limit   int
+}
+
+// LoginGuard tracks failed admin logins per IP and per account in Redis,
+// slowing down and then locking out repeated failures
+type LoginGuard struct {
+	cache  *redisRepo.Cache
+	audit  *AuditService
+	pubsub *redisRepo.PubSub
+	config config.LoginProtectionConfig
+	logger *slog.Logger
+}
+
+// NewLoginGuard creates a new login guard
+func NewLoginGuard(
+	cache *redisRepo.Cache,
+	audit *AuditService,
+	pubsub *redisRepo.PubSub,
+	cfg config.LoginProtectionConfig,
+	logger *slog.Logger,
+) *LoginGuard {
+	return &LoginGuard{
+		cache:  cache,
+		audit:  audit,
+		pubsub: pubsub,
+		config: cfg,
+		logger: logger,
+	}
+}
+
+// LoginReservation is a login attempt counted before its credentials are
+// checked. It counts as a failure unless it is released.
+type LoginReservation struct {
+	attempt  LoginAttempt
+	reserved []reservedScope
+}
+
+// reservedScope is a scope a reservation was counted under
+type reservedScope struct {
+	scope    loginScope
+	attempts int64
+}
+
+// Reserve counts the attempt under each scope before the password is
+// checked, so parallel guesses can't get past the delay or the lockout. It
+// returns ErrLoginLocked or ErrLoginThrottled with the time to wait if the
+// attempt must be rejected. Redis errors let the attempt through.
+func (g *LoginGuard) Reserve(ctx context.Context, attempt LoginAttempt) (*LoginReservation, time.Duration, error) {
+	res := &LoginReservation{attempt: attempt}
+	delay := redisRepo.LoginDelay{
+		After: g.config.DelayAfter,
+		Base:  g.config.BaseDelay,
+		Max:   g.config.MaxDelay,
+	}
+
+	for _, scope := range g.scopes(attempt) {
+		result, err := g.cache.ReserveLoginAttempt(ctx, scope.name, scope.subject, g.config.FailureWindow, delay)
+		if err != nil {
+			g.logger.Error("failed to reserve login attempt", "scope", scope.name, "error", err)
+			continue
+		}
+
+		switch {
+		case result.Locked:
+			g.Release(ctx, res)
+			return nil, result.Wait, ErrLoginLocked
+		case result.Throttled:
+			g.Release(ctx, res)
+			return nil, result.Wait, ErrLoginThrottled
+		case result.Attempts > int64(scope.limit):
+			// Attempts that raced past the limit before the lockout was set
+			g.Release(ctx, res)
+			g.lock(ctx, attempt, scope, result.Attempts)
+			return nil, g.config.LockoutDuration, ErrLoginLocked
+		}
+
+		res.reserved = append(res.reserved, reservedScope{scope: scope, attempts: result.Attempts})
+	}
+
+	return res, 0, nil
+}
+
+// RecordFailure keeps a reserved attempt counted, starting a lockout when
+// it reached the limit
+func (g *LoginGuard) RecordFailure(ctx context.Context, res *LoginReservation) {
+	data := map[string]interface{}{
+		"identifier": res.attempt.Identifier,
+		"ip":         res.attempt.IP,
+	}
+	if res.attempt.AdminID != nil {
+		data["admin_id"] = res.attempt.AdminID.String()
+	}
+	publishAdminEvent(ctx, g.pubsub, g.logger, EventLoginFailed, data)
+
+	for _, reserved := range res.reserved {
+		if reserved.attempts >= int64(reserved.scope.limit) {
+			g.lock(ctx, res.attempt, reserved.scope, reserved.attempts)
+		}
+	}
+}
+
+// Release uncounts a reserved attempt whose credentials were correct, such
+// as one that still has to pass two-factor authentication
+func (g *LoginGuard) Release(ctx context.Context, res *LoginReservation) {
+	for _, reserved := range res.reserved {
+		if err := g.cache.ReleaseLoginAttempt(ctx, reserved.scope.name, reserved.scope.subject); err != nil {
+			g.logger.Error("failed to release login attempt", "scope", reserved.scope.name, "error", err)
+		}
+	}
+	res.reserved = nil
+}
+
+// RecordSuccess releases the attempt and clears the account's failure
+// counter after a successful login
+func (g *LoginGuard) RecordSuccess(ctx context.Context, res *LoginReservation) {
+	g.Release(ctx, res)
+	g.clear(ctx, LoginScopeAccount, res.attempt.accountSubject(), false)
+}
+
+// UnlockAccount lifts a lockout on an admin account
+func (g *LoginGuard) UnlockAccount(ctx context.Context, actorID, adminID uuid.UUID, ip, userAgent string) error {
+	return g.unlock(ctx, actorID, LoginScopeAccount, adminID.String(), &adminID, ip, userAgent)
+}
+
+// UnlockIP lifts a lockout on an IP address
+func (g *LoginGuard) UnlockIP(ctx context.Context, actorID uuid.UUID, lockedIP, ip, userAgent string) error {
+	return g.unlock(ctx, actorID, LoginScopeIP, lockedIP, nil, ip, userAgent)
+}
+
+// unlock clears all counters for a subject and records who lifted the lockout
+func (g *LoginGuard) unlock(ctx context.Context, actorID uuid.UUID, scope, subject string, resourceID *uuid.UUID, ip, userAgent string) error {
+	if err := g.clear(ctx, scope, subject, true); err != nil {
+		return err
+	}
+
+	entry := AuditEntry{
+		ActorID:    &actorID,
+		Action:     AuditActionLoginUnlock,
+		ResourceID: resourceID,
+		Details: map[string]interface{}{
+			"scope":   scope,
+			"subject": subject,
+		},
+		IP:        ip,
+		UserAgent: userAgent,
+	}
+	if resourceID != nil {
+		entry.ResourceType = AuditResourceAdminUser
+	}
+	g.audit.Record(ctx, entry)
+
+	g.logger.Info("login lockout lifted",
+		"scope", scope,
+		"subject", subject,
+		"unlocked_by", actorID,
+	)
+
+	publishAdminEvent(ctx, g.pubsub, g.logger, EventLoginUnlock, map[string]interface{}{
+		"scope":       scope,
+		"subject":     subject,
+		"unlocked_by": actorID.String(),
+	})
+
+	return nil
+}
+
+// lock starts a lockout unless one is running, records it in the audit trail and notifies admins
+func (g *LoginGuard) lock(ctx context.Context, attempt LoginAttempt, scope loginScope, failures int64) {
+	lockedUntil := time.Now().Add(g.config.LockoutDuration)
+
+	locked, err := g.cache.SetNX(ctx, redisRepo.LoginLockKey(scope.name, scope.subject), "1", g.config.LockoutDuration)
+	if err != nil {
+		g.logger.Error("failed to lock login", "scope", scope.name, "error", err)
+		return
+	}
+	if !locked {
+		// A parallel attempt already started the lockout
+		return
+	}
+
+	// Start counting from zero once the lockout ends
+	g.cache.Delete(ctx, redisRepo.LoginFailuresKey(scope.name, scope.subject))
+	g.cache.Delete(ctx, redisRepo.LoginDelayKey(scope.name, scope.subject))
+
+	details := map[string]interface{}{
+		"scope":        scope.name,
+		"subject":      scope.subject,
+		"identifier":   attempt.Identifier,
+		"ip":           attempt.IP,
+		"failures":     failures,
+		"locked_until": lockedUntil.Format(time.RFC3339),
+	}
+
+	g.logger.Warn("login locked after repeated failures",
+		"scope", scope.name,
+		"subject", scope.subject,
+		"ip", attempt.IP,
+		"failures", failures,
+	)
+
+	entry := AuditEntry{
+		Action:    AuditActionLoginLockout,
+		Details:   details,
+		IP:        attempt.IP,
+		UserAgent: attempt.UserAgent,
+	}
+	if scope.name == LoginScopeAccount && attempt.AdminID != nil {
+		entry.ResourceType = AuditResourceAdminUser
+		entry.ResourceID = attempt.AdminID
+	}
+	g.audit.Record(ctx, entry)
+
+	publishAdminEvent(ctx, g.pubsub, g.logger, EventLoginLockout, details)
+}
+
+// clear removes the failure counter and delay for a subject, and the lockout if requested
+func (g *LoginGuard) clear(ctx context.Context, scope, subject string, includeLock bool) error {
+	keys := []string{
+		redisRepo.LoginFailuresKey(scope, subject),
+		redisRepo.LoginDelayKey(scope, subject),
+	}
+	if includeLock {
+		keys = append(keys, redisRepo.LoginLockKey(scope, subject))
+	}
+
+	for _, key := range keys {
+		if err := g.cache.Delete(ctx, key); err != nil {
+			g.logger.Error("failed to clear login counter", "key", key, "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scopes returns the counters an attempt is tracked under
+func (g *LoginGuard) scopes(attempt LoginAttempt) []loginScope {
+	scopes := []loginScope{{
+		name:    LoginScopeAccount,
+		subject: attempt.accountSubject(),
+		limit:   g.config.MaxAccountFailures,
+	}}
+
+	if attempt.IP != "" {
+		scopes = append(scopes, loginScope{
+			name:    LoginScopeIP,
+			subject: attempt.IP,
+			limit:   g.config.MaxIPFailures,
+		})
+	}
+
+	return scopes
+}
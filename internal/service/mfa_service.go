@@ -0,0 +1,490 @@
+package service
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/reveegate/reveegate/internal/config"
+	"github.com/reveegate/reveegate/internal/repository/postgres"
+	"github.com/reveegate/reveegate/internal/totp"
+)
+
+// MFA errors
+var (
+	ErrMFAInvalidCode      = errors.New("invalid two-factor code")
+	ErrMFAChallengeInvalid = errors.New("invalid or expired MFA challenge")
+	ErrMFANotEnrolled      = errors.New("two-factor authentication is not set up")
+	ErrMFAAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrMFARequired         = errors.New("two-factor authentication is required for all admins")
+	ErrMFAUnavailable      = errors.New("two-factor authentication is not configured on this server")
+)
+
+const (
+	// recoveryCodeCount is the number of recovery codes issued at once
+	recoveryCodeCount = 10
+	// totpSkew accepts codes from one step before and after the current one
+	totpSkew = 1
+)
+
+// ChallengeIssuer signs and verifies MFA challenge tokens
+type ChallengeIssuer interface {
+	GenerateMFAChallenge(userID string, ttl time.Duration) (string, time.Time, error)
+	ValidateMFAChallenge(token string) (string, error)
+}
+
+// MFAChallenge is returned by the password step when a second factor is needed
+type MFAChallenge struct {
+	Token              string
+	ExpiresAt          time.Time
+	EnrollmentRequired bool
+}
+
+// MFAEnrollment holds the secret shown to the admin while setting up 2FA
+type MFAEnrollment struct {
+	Secret          string
+	ProvisioningURI string
+}
+
+// MFAStatus describes the 2FA state of an admin
+type MFAStatus struct {
+	Enabled                bool
+	Required               bool
+	RecoveryCodesRemaining int
+}
+
+// MFAService manages TOTP enrollment, recovery codes and the second login step
+type MFAService struct {
+	adminRepo    *postgres.AdminRepository
+	settingsRepo *postgres.SettingsRepository
+	issuer       ChallengeIssuer
+	config       config.MFAConfig
+	logger       *slog.Logger
+}
+
+// NewMFAService creates a new MFA service
+func NewMFAService(
+	adminRepo *postgres.AdminRepository,
+	settingsRepo *postgres.SettingsRepository,
+	issuer ChallengeIssuer,
+	cfg config.MFAConfig,
+	logger *slog.Logger,
+) *MFAService {
+	return &MFAService{
+		adminRepo:    adminRepo,
+		settingsRepo: settingsRepo,
+		issuer:       issuer,
+		config:       cfg,
+		logger:       logger,
+	}
+}
+
+// IsRequired checks if owners enforce 2FA for all admins
+func (s *MFAService) IsRequired(ctx context.Context) (bool, error) {
+	var required bool
+	if err := s.settingsRepo.Get(ctx, postgres.SettingMFARequired, &required); err != nil {
+		if errors.Is(err, postgres.ErrSettingNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return required, nil
+}
+
+// SetRequired turns 2FA enforcement on or off. The admin changing it must have
+// 2FA enabled so enforcement cannot lock them out.
+func (s *MFAService) SetRequired(ctx context.Context, actorID uuid.UUID, required bool) error {
+	actor, err := s.adminRepo.FindByID(ctx, actorID)
+	if err != nil {
+		return err
+	}
+
+	if required && !actor.TOTPEnabled {
+		return ErrMFANotEnrolled
+	}
+
+	if err := s.settingsRepo.Set(ctx, postgres.SettingMFARequired, required, actorID); err != nil {
+		return err
+	}
+
+	s.logger.Info("2FA enforcement changed",
+		"required", required,
+		"admin_id", actorID,
+	)
+
+	return nil
+}
+
+// Status returns the 2FA state of an admin
+func (s *MFAService) Status(ctx context.Context, userID uuid.UUID) (*MFAStatus, error) {
+	admin, err := s.adminRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	required, err := s.IsRequired(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &MFAStatus{
+		Enabled:  admin.TOTPEnabled,
+		Required: required,
+	}
+
+	if admin.TOTPEnabled {
+		status.RecoveryCodesRemaining, err = s.adminRepo.CountRecoveryCodes(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return status, nil
+}
+
+// BeginLogin is called after the password was verified. It returns nil when
+// the admin can be logged in directly, or a challenge for the second step.
+func (s *MFAService) BeginLogin(ctx context.Context, admin *postgres.AdminUser) (*MFAChallenge, error) {
+	enrollmentRequired := false
+	if !admin.TOTPEnabled {
+		required, err := s.IsRequired(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !required {
+			return nil, nil
+		}
+		enrollmentRequired = true
+	}
+
+	token, expiresAt, err := s.issuer.GenerateMFAChallenge(admin.ID.String(), s.config.ChallengeTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate MFA challenge: %w", err)
+	}
+
+	return &MFAChallenge{
+		Token:              token,
+		ExpiresAt:          expiresAt,
+		EnrollmentRequired: enrollmentRequired,
+	}, nil
+}
+
+// CompleteLogin verifies the second factor for a challenge. Admins who had to
+// enroll during login confirm their new secret with a code and receive their
+// recovery codes.
+func (s *MFAService) CompleteLogin(ctx context.Context, challengeToken, code, recoveryCode string) (*postgres.AdminUser, []string, error) {
+	admin, err := s.adminFromChallenge(ctx, challengeToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !admin.IsActive {
+		return nil, nil, ErrAccountInactive
+	}
+
+	if !admin.TOTPEnabled {
+		if code == "" {
+			return nil, nil, ErrMFAInvalidCode
+		}
+		codes, err := s.confirm(ctx, admin, code)
+		if err != nil {
+			return nil, nil, err
+		}
+		return admin, codes, nil
+	}
+
+	if recoveryCode != "" {
+		used, err := s.adminRepo.UseRecoveryCode(ctx, admin.ID, hashToken(normalizeRecoveryCode(recoveryCode)))
+		if err != nil {
+			return nil, nil, err
+		}
+		if !used {
+			return nil, nil, ErrMFAInvalidCode
+		}
+
+		s.logger.Warn("admin logged in with recovery code", "admin_id", admin.ID)
+		return admin, nil, nil
+	}
+
+	if err := s.verifyCode(ctx, admin, code); err != nil {
+		return nil, nil, err
+	}
+
+	return admin, nil, nil
+}
+
+// ChallengeSubject returns the admin a login challenge was issued to
+func (s *MFAService) ChallengeSubject(challengeToken string) (uuid.UUID, error) {
+	subject, err := s.issuer.ValidateMFAChallenge(challengeToken)
+	if err != nil {
+		return uuid.Nil, ErrMFAChallengeInvalid
+	}
+
+	userID, err := uuid.Parse(subject)
+	if err != nil {
+		return uuid.Nil, ErrMFAChallengeInvalid
+	}
+
+	return userID, nil
+}
+
+// EnrollWithChallenge starts enrollment for an admin who must set up 2FA before logging in
+func (s *MFAService) EnrollWithChallenge(ctx context.Context, challengeToken string) (*MFAEnrollment, error) {
+	admin, err := s.adminFromChallenge(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	return s.enroll(ctx, admin)
+}
+
+// Enroll starts enrollment for a logged in admin
+func (s *MFAService) Enroll(ctx context.Context, userID uuid.UUID) (*MFAEnrollment, error) {
+	admin, err := s.adminRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.enroll(ctx, admin)
+}
+
+// Confirm enables 2FA once the admin proves their authenticator works
+func (s *MFAService) Confirm(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	admin, err := s.adminRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.confirm(ctx, admin, code)
+}
+
+// Disable turns 2FA off for an admin. It is refused while owners enforce 2FA.
+func (s *MFAService) Disable(ctx context.Context, userID uuid.UUID, code string) error {
+	required, err := s.IsRequired(ctx)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrMFARequired
+	}
+
+	admin, err := s.adminRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !admin.TOTPEnabled {
+		return ErrMFANotEnrolled
+	}
+
+	if err := s.verifyCode(ctx, admin, code); err != nil {
+		return err
+	}
+
+	if err := s.adminRepo.DisableTOTP(ctx, admin.ID); err != nil {
+		return err
+	}
+
+	s.logger.Info("2FA disabled", "admin_id", admin.ID)
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes of an admin
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	admin, err := s.adminRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !admin.TOTPEnabled {
+		return nil, ErrMFANotEnrolled
+	}
+
+	if err := s.verifyCode(ctx, admin, code); err != nil {
+		return nil, err
+	}
+
+	return s.issueRecoveryCodes(ctx, admin.ID)
+}
+
+// adminFromChallenge loads the admin a challenge token was issued to
+func (s *MFAService) adminFromChallenge(ctx context.Context, challengeToken string) (*postgres.AdminUser, error) {
+	userID, err := s.ChallengeSubject(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	admin, err := s.adminRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, postgres.ErrAdminNotFound) {
+			return nil, ErrMFAChallengeInvalid
+		}
+		return nil, err
+	}
+
+	return admin, nil
+}
+
+// enroll stores a new pending secret. Re-enrolling before confirmation replaces it.
+func (s *MFAService) enroll(ctx context.Context, admin *postgres.AdminUser) (*MFAEnrollment, error) {
+	if admin.TOTPEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := s.encrypt(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.adminRepo.SetTOTPSecret(ctx, admin.ID, encrypted); err != nil {
+		return nil, err
+	}
+
+	return &MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.config.Issuer, admin.Email, secret),
+	}, nil
+}
+
+// confirm verifies the first code for a pending secret and enables 2FA
+func (s *MFAService) confirm(ctx context.Context, admin *postgres.AdminUser, code string) ([]string, error) {
+	if admin.TOTPEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if admin.TOTPSecret == "" {
+		return nil, ErrMFANotEnrolled
+	}
+
+	if err := s.verifyCode(ctx, admin, code); err != nil {
+		return nil, err
+	}
+
+	if err := s.adminRepo.EnableTOTP(ctx, admin.ID); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("2FA enabled", "admin_id", admin.ID)
+
+	return s.issueRecoveryCodes(ctx, admin.ID)
+}
+
+// verifyCode checks a TOTP code and rejects codes that were already used
+func (s *MFAService) verifyCode(ctx context.Context, admin *postgres.AdminUser, code string) error {
+	if admin.TOTPSecret == "" {
+		return ErrMFANotEnrolled
+	}
+
+	secret, err := s.decrypt(admin.TOTPSecret)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt TOTP secret: %w", err)
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now(), totpSkew)
+	if !ok {
+		return ErrMFAInvalidCode
+	}
+
+	claimed, err := s.adminRepo.ClaimTOTPStep(ctx, admin.ID, step)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return ErrMFAInvalidCode
+	}
+
+	return nil
+}
+
+// issueRecoveryCodes generates new recovery codes and stores their hashes
+func (s *MFAService) issueRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	for i := range codes {
+		b := make([]byte, 10)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		raw := base32.StdEncoding.EncodeToString(b)
+		codes[i] = raw[0:4] + "-" + raw[4:8] + "-" + raw[8:12] + "-" + raw[12:16]
+		hashes[i] = hashToken(raw)
+	}
+
+	if err := s.adminRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// normalizeRecoveryCode strips formatting so codes can be typed loosely
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
+
+// encrypt seals a TOTP secret with AES-GCM
+func (s *MFAService) encrypt(plaintext string) (string, error) {
+	gcm, err := s.cipher()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decrypt opens a TOTP secret sealed by encrypt
+func (s *MFAService) decrypt(ciphertext string) (string, error) {
+	gcm, err := s.cipher()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("ciphertext too short")
+	}
+
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// cipher derives the AES-256-GCM cipher from the configured key
+func (s *MFAService) cipher() (cipher.AEAD, error) {
+	if !s.config.Enabled || s.config.EncryptionKey == "" {
+		return nil, ErrMFAUnavailable
+	}
+
+	key := sha256.Sum256([]byte(s.config.EncryptionKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
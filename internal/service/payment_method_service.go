@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/reveegate/reveegate/internal/domain/donation"
+	"github.com/reveegate/reveegate/internal/domain/payment"
+	"github.com/reveegate/reveegate/internal/domain/provider"
+	redisRepo "github.com/reveegate/reveegate/internal/repository/redis"
+)
+
+// Payment method availability errors
+var (
+	ErrMethodUnavailable    = errors.New("payment method is not available")
+	ErrAmountOutOfRange     = errors.New("amount is outside the limits for this payment method")
+	ErrInvalidMethodConfig  = errors.New("invalid payment method configuration")
+	ErrPaymentMethodMissing = errors.New("payment method not found")
+)
+
+// paymentMethodsCacheTTL bounds how long instances serve a stale catalogue
+const paymentMethodsCacheTTL = time.Minute
+
+// PaymentMethodService manages the payment method catalogue
+type PaymentMethodService struct {
+	methodRepo payment.MethodRepository
+	provider   provider.Provider
+	cache      *redisRepo.Cache
+	logger     *slog.Logger
+}
+
+// NewPaymentMethodService creates a new payment method service
+func NewPaymentMethodService(
+	methodRepo payment.MethodRepository,
+	prov provider.Provider,
+	cache *redisRepo.Cache,
+	logger *slog.Logger,
+) *PaymentMethodService {
+	return &PaymentMethodService{
+		methodRepo: methodRepo,
+		provider:   prov,
+		cache:      cache,
+		logger:     logger,
+	}
+}
+
+// List returns the full catalogue in display order
+func (s *PaymentMethodService) List(ctx context.Context) ([]*payment.MethodConfig, error) {
+	var methods []*payment.MethodConfig
+	if err := s.cache.Get(ctx, redisRepo.KeyPaymentMethods, &methods); err == nil {
+		return methods, nil
+	}
+
+	methods, err := s.methodRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cache.Set(ctx, redisRepo.KeyPaymentMethods, methods, paymentMethodsCacheTTL); err != nil {
+		s.logger.Warn("failed to cache payment methods", "error", err)
+	}
+
+	return methods, nil
+}
+
+// ListAvailable returns the methods donors can use right now with the active provider
+func (s *PaymentMethodService) ListAvailable(ctx context.Context) ([]*payment.MethodConfig, error) {
+	methods, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	available := make([]*payment.MethodConfig, 0, len(methods))
+	for _, m := range methods {
+		if m.IsAvailable(now) && s.provider.IsMethodSupported(m.Method) {
+			available = append(available, m)
+		}
+	}
+
+	return available, nil
+}
+
+// CheckAvailability checks that a method can be used for the given amount
+func (s *PaymentMethodService) CheckAvailability(ctx context.Context, method payment.Method, amount int64) error {
+	methods, err := s.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load payment methods: %w", err)
+	}
+
+	var cfg *payment.MethodConfig
+	for _, m := range methods {
+		if m.Method == method {
+			cfg = m
+			break
+		}
+	}
+
+	if cfg == nil || !cfg.Enabled || !s.provider.IsMethodSupported(method) {
+		return fmt.Errorf("%w: %s", ErrMethodUnavailable, method)
+	}
+
+	if window := cfg.ActiveMaintenance(time.Now()); window != nil {
+		return fmt.Errorf("%w: %s is under maintenance until %s",
+			ErrMethodUnavailable, cfg.DisplayName, window.EndsAt.Format(time.RFC3339))
+	}
+
+	if amount < cfg.MinAmount {
+		return fmt.Errorf("%w: minimum for %s is %s", ErrAmountOutOfRange, cfg.DisplayName, formatRupiah(cfg.MinAmount))
+	}
+
+	if amount > cfg.MaxAmount {
+		return fmt.Errorf("%w: maximum for %s is %s", ErrAmountOutOfRange, cfg.DisplayName, formatRupiah(cfg.MaxAmount))
+	}
+
+	return nil
+}
+
+// Get returns the configuration of a single payment method
+func (s *PaymentMethodService) Get(ctx context.Context, method payment.Method) (*payment.MethodConfig, error) {
+	cfg, err := s.methodRepo.GetByMethod(ctx, method)
+	if err != nil {
+		return nil, ErrPaymentMethodMissing
+	}
+	return cfg, nil
+}
+
+// UpdateMethodParams holds the fields an admin can change on a method.
+// Nil fields are left unchanged.
+type UpdateMethodParams struct {
+	DisplayName  *string
+	MinAmount    *int64
+	MaxAmount    *int64
+	Enabled      *bool
+	DisplayOrder *int
+	Maintenance  *[]payment.MaintenanceWindow
+}
+
+// Update applies admin changes to a payment method
+func (s *PaymentMethodService) Update(ctx context.Context, method payment.Method, params UpdateMethodParams) (*payment.MethodConfig, error) {
+	cfg, err := s.methodRepo.GetByMethod(ctx, method)
+	if err != nil {
+		return nil, ErrPaymentMethodMissing
+	}
+
+	if params.DisplayName != nil {
+		cfg.DisplayName = *params.DisplayName
+	}
+	if params.MinAmount != nil {
+		cfg.MinAmount = *params.MinAmount
+	}
+	if params.MaxAmount != nil {
+		cfg.MaxAmount = *params.MaxAmount
+	}
+	if params.Enabled != nil {
+		cfg.Enabled = *params.Enabled
+	}
+	if params.DisplayOrder != nil {
+		cfg.DisplayOrder = *params.DisplayOrder
+	}
+	if params.Maintenance != nil {
+		cfg.Maintenance = *params.Maintenance
+	}
+
+	if cfg.MinAmount < donation.MinAmount || cfg.MaxAmount > donation.MaxAmount {
+		return nil, fmt.Errorf("%w: limits must be between %s and %s",
+			ErrInvalidMethodConfig, formatRupiah(donation.MinAmount), formatRupiah(donation.MaxAmount))
+	}
+	if cfg.MinAmount > cfg.MaxAmount {
+		return nil, fmt.Errorf("%w: min_amount must not exceed max_amount", ErrInvalidMethodConfig)
+	}
+	for _, w := range cfg.Maintenance {
+		if !w.EndsAt.After(w.StartsAt) {
+			return nil, fmt.Errorf("%w: maintenance window must end after it starts", ErrInvalidMethodConfig)
+		}
+	}
+
+	cfg.PruneMaintenance(time.Now())
+
+	if err := s.methodRepo.Update(ctx, cfg); err != nil {
+		return nil, err
+	}
+
+	if err := s.cache.Delete(ctx, redisRepo.KeyPaymentMethods); err != nil {
+		s.logger.Warn("failed to invalidate payment methods cache", "error", err)
+	}
+
+	s.logger.Info("payment method updated",
+		"method", cfg.Method,
+		"enabled", cfg.Enabled,
+		"min_amount", cfg.MinAmount,
+		"max_amount", cfg.MaxAmount,
+		"maintenance_windows", len(cfg.Maintenance),
+	)
+
+	return cfg, nil
+}
+
+// formatRupiah formats an amount like "Rp 5,000"
+func formatRupiah(amount int64) string {
+	s := fmt.Sprintf("%d", amount)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return "Rp " + s
+}
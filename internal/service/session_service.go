@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/reveegate/reveegate/internal/config"
+	"github.com/reveegate/reveegate/internal/repository/postgres"
+	redisRepo "github.com/reveegate/reveegate/internal/repository/redis"
+)
+
+// Session errors
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrAccountInactive     = errors.New("admin account is inactive")
+)
+
+// Session revoke reasons stored in sessions.revoke_reason
+const (
+	RevokeReasonLogout    = "logout"
+	RevokeReasonLogoutAll = "logout_all"
+	RevokeReasonRevoked   = "revoked"
+	RevokeReasonReuse     = "reuse_detected"
+	RevokeReasonInactive  = "account_inactive"
+)
+
+// AccessTokenIssuer signs access tokens for a session
+type AccessTokenIssuer interface {
+	GenerateAccessToken(userID, subject, role, sessionID string) (string, time.Time, error)
+}
+
+// TokenPair is returned on login and refresh
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+	SessionID    uuid.UUID
+	Role         string
+}
+
+// SessionInfo describes an active login session
+type SessionInfo struct {
+	ID              uuid.UUID
+	IPAddress       string
+	UserAgent       string
+	AuthenticatedAt time.Time
+	LastUsedAt      time.Time
+	ExpiresAt       time.Time
+}
+
+// SessionService manages admin login sessions and refresh token rotation
+type SessionService struct {
+	sessionRepo *postgres.SessionRepository
+	adminRepo   *postgres.AdminRepository
+	issuer      AccessTokenIssuer
+	cache       *redisRepo.Cache
+	config      config.JWTConfig
+	logger      *slog.Logger
+}
+
+// NewSessionService creates a new session service
+func NewSessionService(
+	sessionRepo *postgres.SessionRepository,
+	adminRepo *postgres.AdminRepository,
+	issuer AccessTokenIssuer,
+	cache *redisRepo.Cache,
+	cfg config.JWTConfig,
+	logger *slog.Logger,
+) *SessionService {
+	return &SessionService{
+		sessionRepo: sessionRepo,
+		adminRepo:   adminRepo,
+		issuer:      issuer,
+		cache:       cache,
+		config:      cfg,
+		logger:      logger,
+	}
+}
+
+// Start creates a new session for an admin who just authenticated
+func (s *SessionService) Start(ctx context.Context, admin *postgres.AdminUser, ipAddress, userAgent string) (*TokenPair, error) {
+	if _, err := s.sessionRepo.DeleteExpired(ctx); err != nil {
+		s.logger.Warn("failed to delete expired sessions", "error", err)
+	}
+
+	refreshToken, err := generateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	familyID := uuid.New()
+	session := &postgres.Session{
+		ID:               familyID,
+		FamilyID:         familyID,
+		UserID:           admin.ID,
+		RefreshTokenHash: hashToken(refreshToken),
+		IPAddress:        ipAddress,
+		UserAgent:        userAgent,
+		AuthenticatedAt:  now,
+		LastUsedAt:       now,
+		ExpiresAt:        now.Add(s.config.RefreshTokenTTL),
+	}
+
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	accessToken, expiresAt, err := s.issuer.GenerateAccessToken(admin.ID.String(), admin.Email, admin.Role, familyID.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    expiresAt,
+		SessionID:    familyID,
+		Role:         admin.Role,
+	}, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair. Each refresh token
+// can be used once; presenting a rotated token revokes the whole session.
+func (s *SessionService) Refresh(ctx context.Context, refreshToken, ipAddress, userAgent string) (*TokenPair, error) {
+	current, err := s.sessionRepo.FindByRefreshTokenHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, postgres.ErrSessionNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	if current.RevokedAt != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if current.RotatedAt != nil {
+		s.revokeOnReuse(ctx, current, ipAddress, userAgent)
+		return nil, ErrRefreshTokenReused
+	}
+
+	now := time.Now()
+	if !current.IsActive(now) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	admin, err := s.adminRepo.FindByID(ctx, current.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load admin user: %w", err)
+	}
+
+	if !admin.IsActive {
+		s.revoke(ctx, current.FamilyID, RevokeReasonInactive)
+		return nil, ErrAccountInactive
+	}
+
+	nextToken, err := generateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	next := &postgres.Session{
+		ID:               uuid.New(),
+		FamilyID:         current.FamilyID,
+		UserID:           current.UserID,
+		RefreshTokenHash: hashToken(nextToken),
+		IPAddress:        ipAddress,
+		UserAgent:        userAgent,
+		AuthenticatedAt:  current.AuthenticatedAt,
+		LastUsedAt:       now,
+		ExpiresAt:        now.Add(s.config.RefreshTokenTTL),
+	}
+
+	if err := s.sessionRepo.Rotate(ctx, current, next); err != nil {
+		if errors.Is(err, postgres.ErrSessionRotated) {
+			// Another request exchanged the same token first
+			s.revokeOnReuse(ctx, current, ipAddress, userAgent)
+			return nil, ErrRefreshTokenReused
+		}
+		return nil, err
+	}
+
+	accessToken, expiresAt, err := s.issuer.GenerateAccessToken(admin.ID.String(), admin.Email, admin.Role, current.FamilyID.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: nextToken,
+		ExpiresAt:    expiresAt,
+		SessionID:    current.FamilyID,
+		Role:         admin.Role,
+	}, nil
+}
+
+// ListActive lists the active sessions of an admin user
+func (s *SessionService) ListActive(ctx context.Context, userID uuid.UUID) ([]*SessionInfo, error) {
+	sessions, err := s.sessionRepo.ListActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*SessionInfo, len(sessions))
+	for i, session := range sessions {
+		infos[i] = &SessionInfo{
+			ID:              session.FamilyID,
+			IPAddress:       session.IPAddress,
+			UserAgent:       session.UserAgent,
+			AuthenticatedAt: session.AuthenticatedAt,
+			LastUsedAt:      session.LastUsedAt,
+			ExpiresAt:       session.ExpiresAt,
+		}
+	}
+
+	return infos, nil
+}
+
+// Logout ends a single session
+func (s *SessionService) Logout(ctx context.Context, sessionID uuid.UUID) error {
+	_, err := s.revoke(ctx, sessionID, RevokeReasonLogout)
+	return err
+}
+
+// Revoke ends one of the user's sessions by ID
+func (s *SessionService) Revoke(ctx context.Context, userID, sessionID uuid.UUID) error {
+	sessions, err := s.sessionRepo.ListActiveByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if session.FamilyID == sessionID {
+			_, err := s.revoke(ctx, sessionID, RevokeReasonRevoked)
+			return err
+		}
+	}
+
+	return ErrSessionNotFound
+}
+
+// LogoutAll ends every session of a user and returns how many were ended
+func (s *SessionService) LogoutAll(ctx context.Context, userID uuid.UUID) (int, error) {
+	families, err := s.sessionRepo.RevokeAllByUserID(ctx, userID, RevokeReasonLogoutAll)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, familyID := range families {
+		s.markRevoked(ctx, familyID)
+	}
+
+	s.logger.Info("all admin sessions revoked",
+		"user_id", userID,
+		"sessions", len(families),
+	)
+
+	return len(families), nil
+}
+
+// SessionRevoked reports whether a session family has been revoked according
+// to the database. The auth middleware uses it when Redis can't be reached.
+func (s *SessionService) SessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	familyID, err := uuid.Parse(sessionID)
+	if err != nil {
+		return true, nil
+	}
+	return s.sessionRepo.IsFamilyRevoked(ctx, familyID)
+}
+
+// revoke revokes a session family in the database and blocks its access tokens
+func (s *SessionService) revoke(ctx context.Context, familyID uuid.UUID, reason string) (int64, error) {
+	revoked, err := s.sessionRepo.RevokeFamily(ctx, familyID, reason)
+	if err != nil {
+		return 0, err
+	}
+
+	s.markRevoked(ctx, familyID)
+
+	return revoked, nil
+}
+
+// markRevoked blocks access tokens of a session until they expire on their own
+func (s *SessionService) markRevoked(ctx context.Context, familyID uuid.UUID) {
+	key := redisRepo.RevokedSessionKey(familyID.String())
+	if err := s.cache.SetString(ctx, key, "1", s.config.AccessTokenTTL); err != nil {
+		s.logger.Error("failed to mark session as revoked", "session_id", familyID, "error", err)
+	}
+}
+
+// revokeOnReuse kills a session family after one of its rotated tokens was presented again
+func (s *SessionService) revokeOnReuse(ctx context.Context, session *postgres.Session, ipAddress, userAgent string) {
+	revoked, err := s.revoke(ctx, session.FamilyID, RevokeReasonReuse)
+	if err != nil {
+		s.logger.Error("failed to revoke session after token reuse",
+			"session_id", session.FamilyID,
+			"error", err,
+		)
+		return
+	}
+
+	s.logger.Warn("refresh token reuse detected, session revoked",
+		"session_id", session.FamilyID,
+		"user_id", session.UserID,
+		"ip_address", ipAddress,
+		"user_agent", userAgent,
+		"tokens_revoked", revoked,
+	)
+}
+
+// generateRefreshToken returns a random opaque refresh token
+func generateRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken hashes a refresh token for storage
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
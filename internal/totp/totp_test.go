@@ -0,0 +1,159 @@
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 seed from RFC 6238 Appendix B ("12345678901234567890")
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeAtRFC6238(t *testing.T) {
+	// Appendix B lists 8 digit codes; authenticator apps use the last 6
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		t.Run(time.Unix(tt.unix, 0).UTC().Format(time.RFC3339), func(t *testing.T) {
+			got, err := CodeAt(rfcSecret, Step(time.Unix(tt.unix, 0)))
+			if err != nil {
+				t.Fatalf("CodeAt() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CodeAt() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+
+	codeAt := func(step int64) string {
+		code, err := CodeAt(rfcSecret, step)
+		if err != nil {
+			t.Fatalf("CodeAt() error = %v", err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		skew     int
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current step", codeAt(current), 1, current, true},
+		{"one step behind", codeAt(current - 1), 1, current - 1, true},
+		{"one step ahead", codeAt(current + 1), 1, current + 1, true},
+		{"two steps behind", codeAt(current - 2), 1, 0, false},
+		{"two steps ahead", codeAt(current + 2), 1, 0, false},
+		{"previous step without skew", codeAt(current - 1), 0, 0, false},
+		{"surrounding spaces", " " + codeAt(current) + " ", 0, current, true},
+		{"wrong code", "000000", 1, 0, false},
+		{"too short", codeAt(current)[:5], 1, 0, false},
+		{"eight digits", "07081804", 1, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.code, now, tt.skew)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("Validate() = %d, %v, want %d, %v", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestValidateRejectsInvalidSecret(t *testing.T) {
+	if _, ok := Validate("not base32!", "123456", time.Now(), 1); ok {
+		t.Error("Validate() accepted a code for an invalid secret")
+	}
+}
+
+func TestDecodeSecretIsLenient(t *testing.T) {
+	// Secrets copied by hand come back lower case, grouped and padded
+	now := time.Unix(1234567890, 0)
+	for _, secret := range []string{"gezdgnbvgy3tqojqgezdgnbvgy3tqojq", "GEZD GNBV GY3T QOJQ GEZD GNBV GY3T QOJQ", rfcSecret + "===="} {
+		if _, ok := Validate(secret, "005924", now, 0); !ok {
+			t.Errorf("Validate() rejected the code for secret %q", secret)
+		}
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+
+	uri, err := url.Parse(ProvisioningURI("ReveeGate", "budi@example.com", secret))
+	if err != nil {
+		t.Fatalf("failed to parse URI: %v", err)
+	}
+
+	if uri.Scheme != "otpauth" || uri.Host != "totp" {
+		t.Errorf("URI = %s://%s, want otpauth://totp", uri.Scheme, uri.Host)
+	}
+	if uri.Path != "/ReveeGate:budi@example.com" {
+		t.Errorf("label = %q, want /ReveeGate:budi@example.com", uri.Path)
+	}
+
+	params := uri.Query()
+	want := map[string]string{
+		"secret":    secret,
+		"issuer":    "ReveeGate",
+		"algorithm": "SHA1",
+		"digits":    "6",
+		"period":    "30",
+	}
+	for key, value := range want {
+		if got := params.Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+
+	// An authenticator app reading the URI must produce codes we accept
+	now := time.Now()
+	code, err := CodeAt(params.Get("secret"), Step(now))
+	if err != nil {
+		t.Fatalf("CodeAt() error = %v", err)
+	}
+	if _, ok := Validate(secret, code, now, 0); !ok {
+		t.Error("Validate() rejected a code from the provisioned secret")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	first, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+	second, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+
+	if first == second {
+		t.Error("GenerateSecret() returned the same secret twice")
+	}
+	key, err := decodeSecret(first)
+	if err != nil {
+		t.Fatalf("decodeSecret() error = %v", err)
+	}
+	if len(key) != SecretSize {
+		t.Errorf("secret is %d bytes, want %d", len(key), SecretSize)
+	}
+}